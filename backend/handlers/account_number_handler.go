@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountNumbersPayload struct {
+	AccountNumber string `json:"account_number"`
+	RoutingNumber string `json:"routing_number"`
+}
+
+type RevealPayload struct {
+	Reason string `json:"reason"`
+}
+
+// AccountNumberHandler handles encrypted account number HTTP requests
+type AccountNumberHandler struct {
+	service *services.AccountNumberService
+}
+
+// NewAccountNumberHandler creates a new AccountNumberHandler
+func NewAccountNumberHandler(service *services.AccountNumberService) *AccountNumberHandler {
+	return &AccountNumberHandler{service: service}
+}
+
+// SetNumbers stores an account's numbers encrypted; the response only shows their last four digits
+func (h *AccountNumberHandler) SetNumbers(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	var payload AccountNumbersPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	account, err := h.service.SetNumbers(ctx, userID, id, payload.AccountNumber, payload.RoutingNumber)
+	if err != nil {
+		return accountNumberError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(account)
+}
+
+// RevealNumbers returns an account's full numbers and records the reveal in the audit log
+func (h *AccountNumberHandler) RevealNumbers(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	var payload RevealPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	impersonatorID, _ := middleware.Impersonator(c)
+	numbers, err := h.service.Reveal(ctx, userID, impersonatorID, id, payload.Reason)
+	if err != nil {
+		return accountNumberError(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(numbers)
+}
+
+// RotateKeys re-encrypts account numbers sealed under retired master keys
+func (h *AccountNumberHandler) RotateKeys(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	result, err := h.service.RotateKeys(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+func accountNumberError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidAccountNumbers), errors.Is(err, services.ErrRevealReason):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RolePayload struct {
+	Role string `json:"role"`
+}
+
+type ImpersonatePayload struct {
+	Reason string `json:"reason"`
+}
+
+// AdminHandler handles admin-only user management HTTP requests
+type AdminHandler struct {
+	service *services.AdminService
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(service *services.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	users, err := h.service.ListUsers(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(users)
+}
+
+// SetRole assigns the admin, user or read_only role to a user
+func (h *AdminHandler) SetRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminID, userID, err := adminTarget(c)
+	if err != nil {
+		return err
+	}
+
+	var payload RolePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	user, err := h.service.SetRole(ctx, adminID, userID, payload.Role)
+	if err != nil {
+		return adminError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+func (h *AdminHandler) DisableUser(c *fiber.Ctx) error {
+	return h.setDisabled(c, true)
+}
+
+func (h *AdminHandler) EnableUser(c *fiber.Ctx) error {
+	return h.setDisabled(c, false)
+}
+
+func (h *AdminHandler) setDisabled(c *fiber.Ctx, disabled bool) error {
+	ctx := c.Context()
+	adminID, userID, err := adminTarget(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.service.SetDisabled(ctx, adminID, userID, disabled)
+	if err != nil {
+		return adminError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// Impersonate starts an impersonation; send its ID as X-Impersonation-ID to act as the user
+func (h *AdminHandler) Impersonate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminID, userID, err := adminTarget(c)
+	if err != nil {
+		return err
+	}
+
+	var payload ImpersonatePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	impersonation, err := h.service.Impersonate(ctx, adminID, userID, payload.Reason)
+	if err != nil {
+		return adminError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(impersonation)
+}
+
+func (h *AdminHandler) EndImpersonation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminID, id, err := adminTarget(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.EndImpersonation(ctx, adminID, id); err != nil {
+		return adminError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetAuditLog lists recent privileged actions, optionally filtered by user_id
+func (h *AdminHandler) GetAuditLog(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var target primitive.ObjectID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid User ID")
+		}
+		target = id
+	}
+
+	entries, err := h.service.AuditLog(ctx, target, int64(c.QueryInt("limit")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entries)
+}
+
+// adminTarget returns the calling admin and the ID in the route
+func adminTarget(c *fiber.Ctx) (primitive.ObjectID, primitive.ObjectID, error) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return adminID, adminID, fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return adminID, id, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+	return adminID, id, nil
+}
+
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrImpersonationReason):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrSelfAdminChange), errors.Is(err, services.ErrAccountDisabled):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrImpersonationInvalid):
+		return fiber.NewError(fiber.StatusNotFound, "Impersonation Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AlertPayload struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Threshold  float64  `json:"threshold"`
+	AccountID  string   `json:"account_id"`
+	BudgetID   string   `json:"budget_id"`
+	DaysBefore int      `json:"days_before"`
+	Channels   []string `json:"channels"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// AlertHandler handles alert rule and notification inbox HTTP requests
+type AlertHandler struct {
+	service *services.AlertService
+}
+
+// NewAlertHandler creates a new AlertHandler
+func NewAlertHandler(service *services.AlertService) *AlertHandler {
+	return &AlertHandler{service: service}
+}
+
+func (h *AlertHandler) GetAlerts(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	rules, err := h.service.ListRules(ctx, userID)
+	if err != nil {
+		return alertError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rules)
+}
+
+func (h *AlertHandler) CreateAlert(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	rule, err := parseAlertPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.CreateRule(ctx, userID, rule); err != nil {
+		return alertError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+func (h *AlertHandler) UpdateAlert(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	rule, err := parseAlertPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.UpdateRule(ctx, userID, id, rule); err != nil {
+		return alertError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rule)
+}
+
+func (h *AlertHandler) DeleteAlert(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	if err := h.service.DeleteRule(ctx, userID, id); err != nil {
+		return alertError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetNotifications lists the current user's inbox. Query params: unread (true for unread
+// only), limit (default and at most 100).
+func (h *AlertHandler) GetNotifications(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	notifications, err := h.service.Notifications(ctx, userID, c.QueryBool("unread"), int64(c.QueryInt("limit")))
+	if err != nil {
+		return alertError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(notifications)
+}
+
+func (h *AlertHandler) ReadNotification(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	if err := h.service.MarkRead(ctx, userID, id); err != nil {
+		return alertError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AlertHandler) ReadAllNotifications(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	marked, err := h.service.MarkAllRead(ctx, userID)
+	if err != nil {
+		return alertError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"marked": marked})
+}
+
+func parseAlertPayload(c *fiber.Ctx) (*models.AlertRule, error) {
+	var payload AlertPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+
+	rule := &models.AlertRule{
+		Name:       payload.Name,
+		Kind:       payload.Kind,
+		Threshold:  payload.Threshold,
+		DaysBefore: payload.DaysBefore,
+		Channels:   payload.Channels,
+		Enabled:    payload.Enabled == nil || *payload.Enabled,
+	}
+	var err error
+	if payload.AccountID != "" {
+		if rule.AccountID, err = primitive.ObjectIDFromHex(payload.AccountID); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+		}
+	}
+	if payload.BudgetID != "" {
+		if rule.BudgetID, err = primitive.ObjectIDFromHex(payload.BudgetID); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Budget ID")
+		}
+	}
+	return rule, nil
+}
+
+func alertError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidAlert), errors.Is(err, services.ErrUnknownChannel):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAlertNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Alert Rule Not Found In DB")
+	case errors.Is(err, services.ErrNotificationNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Notification Not Found In DB")
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	case errors.Is(err, services.ErrBudgetNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Budget Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
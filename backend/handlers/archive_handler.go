@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+)
+
+// ArchiveHandler handles account archive export and import HTTP requests
+type ArchiveHandler struct {
+	service *services.ArchiveService
+}
+
+// NewArchiveHandler creates a new ArchiveHandler
+func NewArchiveHandler(service *services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: service}
+}
+
+// ExportArchive streams a zip archive of everything the caller owns
+func (h *ArchiveHandler) ExportArchive(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	export, err := h.service.Export(ctx, userID)
+	if err != nil {
+		return archiveError(err)
+	}
+
+	now := time.Now().UTC()
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="trackme-`+now.Format("2006-01-02")+`.zip"`)
+	// like transaction exports, the writer runs once the headers are out
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Write(context.Background(), w, now); err != nil {
+			log.Printf("archive export for user %s failed: %v", userID.Hex(), err)
+		}
+	})
+	return nil
+}
+
+// ImportArchive reads an archive from the request body into the caller's data. Query
+// params: mode (restore or merge; default merge). The body is spooled to a temporary file
+// rather than held in memory, and may be up to services.ArchiveMaxBytes long.
+func (h *ArchiveHandler) ImportArchive(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	archive, size, err := spoolBody(c, services.ArchiveMaxBytes)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	result, err := h.service.Import(ctx, userID, c.Query("mode", services.ArchiveMerge), archive, size)
+	if err != nil {
+		return archiveError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// spoolBody copies the request body into a temporary file, refusing bodies over limit
+// bytes. Bodies the server streams are read from the connection as they arrive. The
+// caller closes and removes the file.
+func spoolBody(c *fiber.Ctx, limit int64) (*os.File, int64, error) {
+	body := c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	file, err := os.CreateTemp("", "trackme-upload-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(file, io.LimitReader(body, limit+1))
+	if err == nil && size > limit {
+		c.Context().SetConnectionClose()
+		err = fiber.ErrRequestEntityTooLarge
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+func archiveError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	case errors.Is(err, services.ErrInvalidArchive), errors.Is(err, services.ErrArchiveVersion),
+		errors.Is(err, services.ErrArchiveChecksum), errors.Is(err, services.ErrInvalidImportMode):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrArchiveUserNotEmpty):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BillPayload struct {
+	Payee      string  `json:"payee"`
+	Amount     float64 `json:"amount"`
+	Estimated  bool    `json:"estimated"`
+	AccountID  string  `json:"account_id"`
+	Category   string  `json:"category"`
+	Frequency  string  `json:"frequency"`
+	Interval   int     `json:"interval"`
+	DayOfMonth int     `json:"day_of_month"`
+	StartDate  string  `json:"start_date"`
+	EndDate    string  `json:"end_date"`
+	Active     *bool   `json:"active"`
+}
+
+// BillHandler handles bill HTTP requests
+type BillHandler struct {
+	service *services.BillService
+}
+
+// NewBillHandler creates a new BillHandler
+func NewBillHandler(service *services.BillService) *BillHandler {
+	return &BillHandler{service: service}
+}
+
+func (h *BillHandler) GetBills(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	bills, err := h.service.ListBills(ctx, userID)
+	if err != nil {
+		return billError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(bills)
+}
+
+func (h *BillHandler) CreateBill(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	bill, err := parseBillPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.CreateBill(ctx, userID, bill); err != nil {
+		return billError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(bill)
+}
+
+func (h *BillHandler) UpdateBill(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Bill ID")
+	}
+
+	bill, err := parseBillPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.UpdateBill(ctx, userID, id, bill); err != nil {
+		return billError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(bill)
+}
+
+func (h *BillHandler) DeleteBill(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Bill ID")
+	}
+
+	if err := h.service.DeleteBill(ctx, userID, id); err != nil {
+		return billError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func parseBillPayload(c *fiber.Ctx) (*models.Bill, error) {
+	var payload BillPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(payload.AccountID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	bill := &models.Bill{
+		Payee:     payload.Payee,
+		Amount:    payload.Amount,
+		Estimated: payload.Estimated,
+		AccountID: accountID,
+		Category:  payload.Category,
+		Active:    payload.Active == nil || *payload.Active,
+		Schedule: models.Recurrence{
+			Frequency:  payload.Frequency,
+			Interval:   payload.Interval,
+			DayOfMonth: payload.DayOfMonth,
+		},
+	}
+	if bill.Schedule.StartDate, err = time.Parse(dateLayout, payload.StartDate); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+	}
+	if payload.EndDate != "" {
+		if bill.Schedule.EndDate, err = time.Parse(dateLayout, payload.EndDate); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+	}
+	return bill, nil
+}
+
+func billError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidBill):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrBillNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Bill Not Found In DB")
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetHandler handles budget-related HTTP requests
+type BudgetHandler struct {
+	service *services.BudgetService
+}
+
+// NewBudgetHandler creates a new BudgetHandler
+func NewBudgetHandler(service *services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{service: service}
+}
+
+// EvaluateBudget reports spending against a budget and refreshes its status
+func (h *BudgetHandler) EvaluateBudget(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	eval, err := h.service.Evaluate(ctx, userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBudgetNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Budget Not Found In DB")
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(eval)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BudgetTemplatePayload struct {
+	Name            string   `json:"name"`
+	Categories      []string `json:"categories"`
+	MinimumSpending float64  `json:"minimum_spending"`
+	MaximumSpending float64  `json:"maximum_spending"`
+	TargetGoal      float64  `json:"target_goal"`
+	Frequency       string   `json:"frequency"`
+	Interval        int      `json:"interval"`
+	DayOfMonth      int      `json:"day_of_month"`
+	StartDate       string   `json:"start_date"`
+	EndDate         string   `json:"end_date"`
+	Active          *bool    `json:"active"`
+}
+
+// BudgetTemplateHandler handles recurring budget template HTTP requests
+type BudgetTemplateHandler struct {
+	service *services.BudgetTemplateService
+}
+
+// NewBudgetTemplateHandler creates a new BudgetTemplateHandler
+func NewBudgetTemplateHandler(service *services.BudgetTemplateService) *BudgetTemplateHandler {
+	return &BudgetTemplateHandler{service: service}
+}
+
+func (h *BudgetTemplateHandler) ListTemplates(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	templates, err := h.service.ListTemplates(ctx, userID)
+	if err != nil {
+		return budgetTemplateError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(templates)
+}
+
+func (h *BudgetTemplateHandler) CreateTemplate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload BudgetTemplatePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	template := budgetTemplateFromPayload(payload)
+	var err error
+	if template.Schedule.StartDate, err = time.Parse(dateLayout, payload.StartDate); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+	}
+	if payload.EndDate != "" {
+		if template.Schedule.EndDate, err = time.Parse(dateLayout, payload.EndDate); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+	}
+
+	if err := h.service.CreateTemplate(ctx, userID, template); err != nil {
+		return budgetTemplateError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(template)
+}
+
+// UpdateTemplate changes a template's bounds, categories or active flag; the schedule fields are ignored
+func (h *BudgetTemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Template ID")
+	}
+
+	var payload BudgetTemplatePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	template, err := h.service.UpdateTemplate(ctx, userID, id, budgetTemplateFromPayload(payload))
+	if err != nil {
+		return budgetTemplateError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(template)
+}
+
+// ListPeriods returns every budget generated from the template
+func (h *BudgetTemplateHandler) ListPeriods(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Template ID")
+	}
+
+	budgets, err := h.service.Periods(ctx, userID, id)
+	if err != nil {
+		return budgetTemplateError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(budgets)
+}
+
+// GetPerformance reports spending and hit rate across the template's periods
+func (h *BudgetTemplateHandler) GetPerformance(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Template ID")
+	}
+
+	report, err := h.service.Performance(ctx, userID, id)
+	if err != nil {
+		return budgetTemplateError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
+// GeneratePeriods creates any budget periods that are due across all templates
+func (h *BudgetTemplateHandler) GeneratePeriods(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	result, err := h.service.MaterializeAll(ctx, time.Now().UTC())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+func budgetTemplateFromPayload(payload BudgetTemplatePayload) *models.BudgetTemplate {
+	return &models.BudgetTemplate{
+		Name:            payload.Name,
+		Categories:      payload.Categories,
+		MinimumSpending: payload.MinimumSpending,
+		MaximumSpending: payload.MaximumSpending,
+		TargetGoal:      payload.TargetGoal,
+		Active:          payload.Active == nil || *payload.Active,
+		Schedule: models.Recurrence{
+			Frequency:  payload.Frequency,
+			Interval:   payload.Interval,
+			DayOfMonth: payload.DayOfMonth,
+		},
+	}
+}
+
+func budgetTemplateError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidTemplate):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTemplateNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Budget Template Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GrantPayload struct {
+	Name           string `json:"name"`
+	Shares         int    `json:"shares"`
+	VestingStart   string `json:"vesting_start"`
+	VestingMonths  int    `json:"vesting_months"`
+	CliffMonths    int    `json:"cliff_months"`
+	IntervalMonths int    `json:"interval_months"`
+}
+
+// CalendarHandler handles money calendar and equity grant HTTP requests
+type CalendarHandler struct {
+	service *services.CalendarService
+}
+
+// NewCalendarHandler creates a new CalendarHandler
+func NewCalendarHandler(service *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// GetCalendar lists bills, paydays and vests. Query params: start, end (YYYY-MM-DD,
+// default 30 days ago to 90 days ahead), format (json or ics).
+func (h *CalendarHandler) GetCalendar(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+	}
+	format := c.Query("format", "json")
+	if format != "json" && format != "ics" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid format, expected json or ics")
+	}
+
+	now := time.Now().UTC()
+	calendar, err := h.service.Calendar(ctx, userID, start, end, now)
+	if err != nil {
+		return calendarError(err)
+	}
+
+	if format == "ics" {
+		c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="trackme.ics"`)
+		return c.Status(fiber.StatusOK).Send(services.FormatICS(calendar.Events, now))
+	}
+	return c.Status(fiber.StatusOK).JSON(calendar)
+}
+
+func (h *CalendarHandler) GetGrants(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	grants, err := h.service.ListGrants(ctx, userID)
+	if err != nil {
+		return calendarError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(grants)
+}
+
+func (h *CalendarHandler) CreateGrant(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload GrantPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	vestingStart, err := time.Parse(dateLayout, payload.VestingStart)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid vesting_start, expected YYYY-MM-DD")
+	}
+
+	grant := &models.EquityGrant{
+		Name:           payload.Name,
+		Shares:         payload.Shares,
+		VestingStart:   vestingStart,
+		VestingMonths:  payload.VestingMonths,
+		CliffMonths:    payload.CliffMonths,
+		IntervalMonths: payload.IntervalMonths,
+	}
+	if err := h.service.CreateGrant(ctx, userID, grant); err != nil {
+		return calendarError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(grant)
+}
+
+func (h *CalendarHandler) DeleteGrant(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Grant ID")
+	}
+
+	if err := h.service.DeleteGrant(ctx, userID, id); err != nil {
+		return calendarError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func calendarError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidGrant), errors.Is(err, services.ErrInvalidCalendarRange):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrGrantNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Grant Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ConnectionPayload struct {
+	Provider    string `json:"provider"`
+	AccessToken string `json:"access_token"`
+}
+
+// ConnectionHandler handles bank connection HTTP requests
+type ConnectionHandler struct {
+	service *services.SyncService
+}
+
+// NewConnectionHandler creates a new ConnectionHandler
+func NewConnectionHandler(service *services.SyncService) *ConnectionHandler {
+	return &ConnectionHandler{service: service}
+}
+
+// GetConnections lists the user's bank connections with their last sync outcome
+func (h *ConnectionHandler) GetConnections(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	connections, err := h.service.ListConnections(ctx, userID)
+	if err != nil {
+		return connectionError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(connections)
+}
+
+// CreateConnection links a bank through a provider and runs the first sync
+func (h *ConnectionHandler) CreateConnection(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload ConnectionPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	result, err := h.service.Connect(ctx, userID, payload.Provider, payload.AccessToken)
+	if err != nil {
+		return connectionError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// SyncConnection brings one connection up to date
+func (h *ConnectionHandler) SyncConnection(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	result, err := h.service.Sync(ctx, userID, id)
+	if err != nil {
+		return connectionError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// SyncAll brings every connection of the user up to date
+func (h *ConnectionHandler) SyncAll(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	results, err := h.service.SyncAll(ctx, userID)
+	if err != nil {
+		return connectionError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(results)
+}
+
+// DeleteConnection forgets a connection; its synced accounts are kept
+func (h *ConnectionHandler) DeleteConnection(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	if err := h.service.Disconnect(ctx, userID, id); err != nil {
+		return connectionError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func connectionError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidConnection), errors.Is(err, services.ErrUnknownProvider):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrConnectionNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Connection Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
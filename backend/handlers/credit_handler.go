@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreditScorePayload struct {
+	Bureau     string `json:"bureau"`
+	Score      int    `json:"score"`
+	Source     string `json:"source"`
+	ReportedAt string `json:"reported_at"`
+}
+
+type CreditThresholdsPayload struct {
+	Thresholds []float64 `json:"thresholds"`
+}
+
+type CreditLimitPayload struct {
+	CreditLimit float64 `json:"credit_limit"`
+}
+
+// CreditHandler handles credit score and utilization HTTP requests
+type CreditHandler struct {
+	service *services.CreditService
+}
+
+// NewCreditHandler creates a new CreditHandler
+func NewCreditHandler(service *services.CreditService) *CreditHandler {
+	return &CreditHandler{service: service}
+}
+
+// GetOverview returns the current user's score history, trends and utilization
+func (h *CreditHandler) GetOverview(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	overview, err := h.service.Overview(ctx, userID)
+	if err != nil {
+		return creditError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(overview)
+}
+
+// RecordScore adds a bureau score reading for the current user
+func (h *CreditHandler) RecordScore(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload CreditScorePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	reading := models.CreditScoreReading{
+		UserID: userID,
+		Bureau: payload.Bureau,
+		Score:  payload.Score,
+		Source: payload.Source,
+	}
+	if payload.ReportedAt != "" {
+		reportedAt, err := time.Parse(dateLayout, payload.ReportedAt)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid reported_at, expected YYYY-MM-DD")
+		}
+		reading.ReportedAt = reportedAt
+	}
+
+	if err := h.service.RecordReading(ctx, &reading); err != nil {
+		return creditError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(reading)
+}
+
+// GetUtilization returns per-card and overall credit utilization with alerts
+func (h *CreditHandler) GetUtilization(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	report, err := h.service.Utilization(ctx, userID)
+	if err != nil {
+		return creditError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
+// SetThresholds replaces the current user's utilization alert thresholds
+func (h *CreditHandler) SetThresholds(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload CreditThresholdsPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	settings, err := h.service.SetThresholds(ctx, userID, payload.Thresholds)
+	if err != nil {
+		return creditError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(settings)
+}
+
+// SetCreditLimit records the credit limit of a credit card account
+func (h *CreditHandler) SetCreditLimit(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+
+	var payload CreditLimitPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.service.SetCreditLimit(ctx, userID, accountID, payload.CreditLimit); err != nil {
+		return creditError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func creditError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCreditReading),
+		errors.Is(err, services.ErrInvalidThresholds),
+		errors.Is(err, services.ErrInvalidCreditLimit),
+		errors.Is(err, services.ErrNotCreditCard):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
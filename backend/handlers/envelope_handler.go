@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EnvelopePayload struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type EnvelopeAssignPayload struct {
+	Month  string  `json:"month"`
+	Amount float64 `json:"amount"`
+	Memo   string  `json:"memo"`
+}
+
+type EnvelopeMovePayload struct {
+	FromID string  `json:"from_id"`
+	ToID   string  `json:"to_id"`
+	Month  string  `json:"month"`
+	Amount float64 `json:"amount"`
+	Memo   string  `json:"memo"`
+}
+
+// EnvelopeHandler handles envelope budgeting HTTP requests
+type EnvelopeHandler struct {
+	service *services.EnvelopeService
+}
+
+// NewEnvelopeHandler creates a new EnvelopeHandler
+func NewEnvelopeHandler(service *services.EnvelopeService) *EnvelopeHandler {
+	return &EnvelopeHandler{service: service}
+}
+
+// GetMonth returns every envelope for ?month=YYYY-MM, defaulting to the current month
+func (h *EnvelopeHandler) GetMonth(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	month, err := parseMonth(c.Query("month"))
+	if err != nil {
+		return err
+	}
+
+	budget, err := h.service.Month(ctx, userID, month)
+	if err != nil {
+		return envelopeError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(budget)
+}
+
+func (h *EnvelopeHandler) CreateEnvelope(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload EnvelopePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	envelope := models.Envelope{Name: payload.Name, Category: payload.Category}
+	if err := h.service.CreateEnvelope(ctx, userID, &envelope); err != nil {
+		return envelopeError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(envelope)
+}
+
+// Assign moves ready-to-assign income into an envelope
+func (h *EnvelopeHandler) Assign(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	envelopeID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Envelope ID")
+	}
+
+	var payload EnvelopeAssignPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	month, err := parseMonth(payload.Month)
+	if err != nil {
+		return err
+	}
+
+	budget, err := h.service.Assign(ctx, userID, envelopeID, month, payload.Amount, payload.Memo)
+	if err != nil {
+		return envelopeError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(budget)
+}
+
+// Move shifts money between two envelopes
+func (h *EnvelopeHandler) Move(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload EnvelopeMovePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	fromID, err := primitive.ObjectIDFromHex(payload.FromID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Envelope ID")
+	}
+	toID, err := primitive.ObjectIDFromHex(payload.ToID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Envelope ID")
+	}
+	month, err := parseMonth(payload.Month)
+	if err != nil {
+		return err
+	}
+
+	move := services.EnvelopeMove{FromID: fromID, ToID: toID, Month: month, Amount: payload.Amount, Memo: payload.Memo}
+	budget, err := h.service.Move(ctx, userID, move)
+	if err != nil {
+		return envelopeError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(budget)
+}
+
+// parseMonth reads a YYYY-MM month, defaulting to the current one
+func parseMonth(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Now().UTC(), nil
+	}
+	month, err := time.Parse(models.MonthLayout, raw)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid month, expected YYYY-MM")
+	}
+	return month, nil
+}
+
+func envelopeError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidEnvelope), errors.Is(err, services.ErrInvalidEnvelopeEntry):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInsufficientEnvelope):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrEnvelopeNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Envelope Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+)
+
+// ExportHandler handles transaction and report export HTTP requests
+type ExportHandler struct {
+	service *services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(service *services.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// ExportTransactions streams transactions as a download. Query params: format (csv, ofx
+// or ndjson; default csv), start, end (YYYY-MM-DD) and account_id (comma separated).
+func (h *ExportHandler) ExportTransactions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountIDs, err := parseObjectIDList(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	export, err := h.service.Transactions(ctx, userID, c.Query("format", services.ExportCSV), accountIDs, start, end)
+	if err != nil {
+		return exportError(err)
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType())
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="transactions.`+export.Format+`"`)
+	now := time.Now().UTC()
+	// the writer runs after the handler returns, once the headers are out, so the
+	// request context can't be used and failures can only be logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Write(context.Background(), w, now); err != nil {
+			log.Printf("transaction export for user %s failed: %v", userID.Hex(), err)
+		}
+	})
+	return nil
+}
+
+// ExportBudgetReport downloads a CSV of the caller's budgets overlapping start through end
+func (h *ExportHandler) ExportBudgetReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="budgets.csv"`)
+	if err := h.service.WriteBudgetReport(ctx, userID, start, end, c); err != nil {
+		return exportError(err)
+	}
+	return nil
+}
+
+// ExportNetWorthReport downloads a CSV of the caller's daily net worth from start through end
+func (h *ExportHandler) ExportNetWorthReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="net-worth.csv"`)
+	if err := h.service.WriteNetWorthReport(ctx, userID, start, end, c); err != nil {
+		return exportError(err)
+	}
+	return nil
+}
+
+func exportError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	case errors.Is(err, services.ErrInvalidExportFormat):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
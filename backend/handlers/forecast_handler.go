@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RecurringItemPayload struct {
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"`
+	AccountID   string  `json:"account_id"`
+	ToAccountID string  `json:"to_account_id"`
+	Amount      float64 `json:"amount"`
+	Category    string  `json:"category"`
+	Frequency   string  `json:"frequency"`
+	Interval    int     `json:"interval"`
+	DayOfMonth  int     `json:"day_of_month"`
+	StartDate   string  `json:"start_date"`
+	EndDate     string  `json:"end_date"`
+	Active      *bool   `json:"active"`
+}
+
+// ForecastHandler handles recurring item and cash flow forecast HTTP requests
+type ForecastHandler struct {
+	service *services.ForecastService
+}
+
+// NewForecastHandler creates a new ForecastHandler
+func NewForecastHandler(service *services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{service: service}
+}
+
+// GetForecast projects the current user's balances. Query params: days (default 30), floor (default 0).
+func (h *ForecastHandler) GetForecast(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	days := c.QueryInt("days", 30)
+	floor, err := strconv.ParseFloat(c.Query("floor", "0"), 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor")
+	}
+
+	forecast, err := h.service.Forecast(ctx, userID, days, floor)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(forecast)
+}
+
+func (h *ForecastHandler) ListRecurring(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	items, err := h.service.ListItems(ctx, userID)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+func (h *ForecastHandler) CreateRecurring(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	item, err := parseRecurringPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.CreateItem(ctx, userID, item); err != nil {
+		return forecastError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+func (h *ForecastHandler) UpdateRecurring(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Recurring Item ID")
+	}
+
+	item, err := parseRecurringPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.UpdateItem(ctx, userID, id, item); err != nil {
+		return forecastError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+func (h *ForecastHandler) DeleteRecurring(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Recurring Item ID")
+	}
+
+	if err := h.service.DeleteItem(ctx, userID, id); err != nil {
+		return forecastError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func parseRecurringPayload(c *fiber.Ctx) (*models.RecurringItem, error) {
+	var payload RecurringItemPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(payload.AccountID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	item := &models.RecurringItem{
+		Name:      payload.Name,
+		Kind:      payload.Kind,
+		AccountID: accountID,
+		Amount:    payload.Amount,
+		Category:  payload.Category,
+		Active:    payload.Active == nil || *payload.Active,
+		Schedule: models.Recurrence{
+			Frequency:  payload.Frequency,
+			Interval:   payload.Interval,
+			DayOfMonth: payload.DayOfMonth,
+		},
+	}
+	if payload.ToAccountID != "" {
+		if item.ToAccountID, err = primitive.ObjectIDFromHex(payload.ToAccountID); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid To Account ID")
+		}
+	}
+	if item.Schedule.StartDate, err = time.Parse(dateLayout, payload.StartDate); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+	}
+	if payload.EndDate != "" {
+		if item.Schedule.EndDate, err = time.Parse(dateLayout, payload.EndDate); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+	}
+	return item, nil
+}
+
+func forecastError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidRecurring), errors.Is(err, services.ErrInvalidForecast):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRecurringNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Recurring Item Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GoalPayload struct {
+	Name           string   `json:"name"`
+	AccountIDs     []string `json:"account_ids"`
+	TargetAmount   float64  `json:"target_amount"`
+	TargetDate     string   `json:"target_date"`
+	StartingAmount float64  `json:"starting_amount"`
+	StartDate      string   `json:"start_date"`
+}
+
+// GoalHandler handles savings goal HTTP requests
+type GoalHandler struct {
+	service *services.GoalService
+}
+
+// NewGoalHandler creates a new GoalHandler
+func NewGoalHandler(service *services.GoalService) *GoalHandler {
+	return &GoalHandler{service: service}
+}
+
+// ListGoals returns the current user's goals with progress and projections
+func (h *GoalHandler) ListGoals(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	goals, err := h.service.ListGoals(ctx, userID)
+	if err != nil {
+		return goalError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(goals)
+}
+
+func (h *GoalHandler) GetGoal(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Goal ID")
+	}
+
+	goal, err := h.service.GetGoal(ctx, userID, id)
+	if err != nil {
+		return goalError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(goal)
+}
+
+func (h *GoalHandler) CreateGoal(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	goal, err := parseGoalPayload(c)
+	if err != nil {
+		return err
+	}
+
+	progress, err := h.service.CreateGoal(ctx, userID, goal)
+	if err != nil {
+		return goalError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(progress)
+}
+
+func (h *GoalHandler) UpdateGoal(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Goal ID")
+	}
+
+	goal, err := parseGoalPayload(c)
+	if err != nil {
+		return err
+	}
+
+	progress, err := h.service.UpdateGoal(ctx, userID, id, goal)
+	if err != nil {
+		return goalError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(progress)
+}
+
+func (h *GoalHandler) DeleteGoal(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Goal ID")
+	}
+
+	if err := h.service.DeleteGoal(ctx, userID, id); err != nil {
+		return goalError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func parseGoalPayload(c *fiber.Ctx) (*models.SavingsGoal, error) {
+	var payload GoalPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+
+	var accountIDs []primitive.ObjectID
+	for _, hex := range payload.AccountIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+		}
+		accountIDs = append(accountIDs, id)
+	}
+	targetDate, err := time.Parse(dateLayout, payload.TargetDate)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid target_date, expected YYYY-MM-DD")
+	}
+
+	goal := &models.SavingsGoal{
+		Name:           payload.Name,
+		AccountIDs:     accountIDs,
+		TargetAmount:   payload.TargetAmount,
+		TargetDate:     targetDate,
+		StartingAmount: payload.StartingAmount,
+	}
+	if payload.StartDate != "" {
+		if goal.StartDate, err = time.Parse(dateLayout, payload.StartDate); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+		}
+	}
+	return goal, nil
+}
+
+func goalError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidGoal):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrGoalNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Savings Goal Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type HouseholdPayload struct {
+	Name string `json:"name"`
+}
+
+type InvitationPayload struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type AcceptInvitationPayload struct {
+	Token string `json:"token"`
+}
+
+type MemberRolePayload struct {
+	Role string `json:"role"`
+}
+
+// HouseholdHandler handles household membership and sharing HTTP requests
+type HouseholdHandler struct {
+	service *services.HouseholdService
+}
+
+// NewHouseholdHandler creates a new HouseholdHandler
+func NewHouseholdHandler(service *services.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{service: service}
+}
+
+// ListHouseholds returns the households the current user belongs to
+func (h *HouseholdHandler) ListHouseholds(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	households, err := h.service.ListHouseholds(ctx, userID)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(households)
+}
+
+// CreateHousehold starts a household owned by the current user
+func (h *HouseholdHandler) CreateHousehold(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload HouseholdPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	household, err := h.service.CreateHousehold(ctx, userID, payload.Name)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(household)
+}
+
+func (h *HouseholdHandler) GetHousehold(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Household ID")
+	}
+
+	household, err := h.service.GetHousehold(ctx, userID, id)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(household)
+}
+
+// Invite issues an invitation token; the token is only returned in this response
+func (h *HouseholdHandler) Invite(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Household ID")
+	}
+
+	var payload InvitationPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if payload.Role == "" {
+		payload.Role = models.HouseholdRoleViewer
+	}
+
+	issued, err := h.service.Invite(ctx, userID, id, payload.Email, payload.Role)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+func (h *HouseholdHandler) ListInvitations(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Household ID")
+	}
+
+	invitations, err := h.service.ListInvitations(ctx, userID, id)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(invitations)
+}
+
+// AcceptInvitation joins the current user to the household that issued the token
+func (h *HouseholdHandler) AcceptInvitation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload AcceptInvitationPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	household, err := h.service.AcceptInvitation(ctx, userID, payload.Token)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(household)
+}
+
+func (h *HouseholdHandler) SetMemberRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, memberID, err := householdParams(c, "memberId")
+	if err != nil {
+		return err
+	}
+
+	var payload MemberRolePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	household, err := h.service.SetMemberRole(ctx, userID, id, memberID, payload.Role)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(household)
+}
+
+// RemoveMember removes a member; any member may remove themselves
+func (h *HouseholdHandler) RemoveMember(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, memberID, err := householdParams(c, "memberId")
+	if err != nil {
+		return err
+	}
+
+	household, err := h.service.RemoveMember(ctx, userID, id, memberID)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(household)
+}
+
+func (h *HouseholdHandler) ShareAccount(c *fiber.Ctx) error {
+	return h.share(c, "accountId", h.service.ShareAccount)
+}
+
+func (h *HouseholdHandler) UnshareAccount(c *fiber.Ctx) error {
+	return h.share(c, "accountId", h.service.UnshareAccount)
+}
+
+func (h *HouseholdHandler) ShareBudget(c *fiber.Ctx) error {
+	return h.share(c, "budgetId", h.service.ShareBudget)
+}
+
+func (h *HouseholdHandler) UnshareBudget(c *fiber.Ctx) error {
+	return h.share(c, "budgetId", h.service.UnshareBudget)
+}
+
+// share runs one of the service's share or unshare operations on the resource named by param
+func (h *HouseholdHandler) share(c *fiber.Ctx, param string, op func(ctx context.Context, userID, id, resourceID primitive.ObjectID) (*models.Household, error)) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, resourceID, err := householdParams(c, param)
+	if err != nil {
+		return err
+	}
+
+	household, err := op(ctx, userID, id, resourceID)
+	if err != nil {
+		return householdError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(household)
+}
+
+// householdParams parses the household ID and a second ID from the route
+func householdParams(c *fiber.Ctx, param string) (primitive.ObjectID, primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return id, id, fiber.NewError(fiber.StatusBadRequest, "Invalid Household ID")
+	}
+	other, err := primitive.ObjectIDFromHex(c.Params(param))
+	if err != nil {
+		return id, other, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+	return id, other, nil
+}
+
+func householdError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrAccountNotOwned),
+		errors.Is(err, services.ErrBudgetNotOwned):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidHousehold), errors.Is(err, services.ErrInvitationInvalid):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrLastHouseholdOwner), errors.Is(err, services.ErrHouseholdChanged):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrHouseholdNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Household Not Found In DB")
+	case errors.Is(err, services.ErrBudgetNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Budget Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
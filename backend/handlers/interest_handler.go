@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InterestSettingsPayload struct {
+	InterestRate         float64 `json:"interest_rate"`
+	InterestMethod       string  `json:"interest_method"`
+	CompoundingFrequency string  `json:"compounding_frequency"`
+	DayCountConvention   string  `json:"day_count_convention"`
+}
+
+// InterestHandler handles interest accrual HTTP requests
+type InterestHandler struct {
+	service *services.InterestService
+}
+
+// NewInterestHandler creates a new InterestHandler
+func NewInterestHandler(service *services.InterestService) *InterestHandler {
+	return &InterestHandler{service: service}
+}
+
+// GetInterest returns the year-to-date interest statement of an account
+func (h *InterestHandler) GetInterest(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	statement, err := h.service.Statement(ctx, userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAccountNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(statement)
+}
+
+// UpdateInterestSettings configures the rate, method and conventions used for accrual
+func (h *InterestHandler) UpdateInterestSettings(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	var payload InterestSettingsPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	account, err := h.service.UpdateSettings(ctx, userID, id, &models.Account{
+		InterestRate:         payload.InterestRate,
+		InterestMethod:       payload.InterestMethod,
+		CompoundingFrequency: payload.CompoundingFrequency,
+		DayCountConvention:   payload.DayCountConvention,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInterestSettings):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrAccountNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(account)
+}
+
+// RunAccruals accrues interest on every account up to the as_of date (default today)
+func (h *InterestHandler) RunAccruals(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	asOf := time.Now().UTC()
+	if raw := c.Query("as_of"); raw != "" {
+		parsed, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid As Of Date")
+		}
+		asOf = parsed
+	}
+
+	result, err := h.service.RunAccruals(ctx, asOf)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/services"
+)
+
+// JobHandler handles background job HTTP requests
+type JobHandler struct {
+	scheduler *services.Scheduler
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(scheduler *services.Scheduler) *JobHandler {
+	return &JobHandler{scheduler: scheduler}
+}
+
+// ListJobs shows every job's schedule, lock and last outcome
+func (h *JobHandler) ListJobs(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	jobs, err := h.scheduler.Jobs(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(jobs)
+}
+
+// ListRuns shows recent job runs, optionally filtered by job and by status (status=failed)
+func (h *JobHandler) ListRuns(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	runs, err := h.scheduler.Runs(ctx, c.Query("job"), c.Query("status"), int64(c.QueryInt("limit")))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidJobFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(runs)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReconciliationPayload struct {
+	StatementBalance float64 `json:"statement_balance"`
+	StatementDate    string  `json:"statement_date"`
+}
+
+type ClearPayload struct {
+	TransactionIDs []string `json:"transaction_ids"`
+	Cleared        bool     `json:"cleared"`
+}
+
+// ReconciliationHandler handles account reconciliation HTTP requests
+type ReconciliationHandler struct {
+	service *services.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new ReconciliationHandler
+func NewReconciliationHandler(service *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{service: service}
+}
+
+// StartReconciliation opens a reconciliation for an account against a statement
+func (h *ReconciliationHandler) StartReconciliation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	var payload ReconciliationPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	statementDate, err := time.Parse(dateLayout, payload.StatementDate)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Statement Date")
+	}
+	statementDate = statementDate.Add(24*time.Hour - time.Nanosecond)
+
+	sheet, err := h.service.Start(ctx, userID, accountID, payload.StatementBalance, statementDate)
+	if err != nil {
+		return reconciliationError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(sheet)
+}
+
+// GetReconciliation returns the worksheet for a reconciliation
+func (h *ReconciliationHandler) GetReconciliation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	sheet, err := h.service.GetWorksheet(ctx, userID, id)
+	if err != nil {
+		return reconciliationError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sheet)
+}
+
+// ClearTransactions marks transactions as cleared or uncleared
+func (h *ReconciliationHandler) ClearTransactions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	var payload ClearPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	txnIDs := make([]primitive.ObjectID, 0, len(payload.TransactionIDs))
+	for _, hex := range payload.TransactionIDs {
+		txnID, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid Transaction ID")
+		}
+		txnIDs = append(txnIDs, txnID)
+	}
+
+	sheet, err := h.service.SetCleared(ctx, userID, id, txnIDs, payload.Cleared)
+	if err != nil {
+		return reconciliationError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sheet)
+}
+
+// CompleteReconciliation locks the cleared transactions once the difference is zero
+func (h *ReconciliationHandler) CompleteReconciliation(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	rec, err := h.service.Complete(ctx, userID, id)
+	if err != nil {
+		return reconciliationError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rec)
+}
+
+// GetHistory lists past and in-progress reconciliations for an account
+func (h *ReconciliationHandler) GetHistory(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	recs, err := h.service.History(ctx, userID, accountID)
+	if err != nil {
+		return reconciliationError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(recs)
+}
+
+func reconciliationError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	case errors.Is(err, services.ErrReconciliationNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Reconciliation Not Found In DB")
+	case errors.Is(err, services.ErrReconciliationInProgress),
+		errors.Is(err, services.ErrReconciliationClosed),
+		errors.Is(err, services.ErrReconciliationUnbalanced):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTransactionsNotOpen):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
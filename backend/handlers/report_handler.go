@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+// ReportHandler handles spending report HTTP requests
+type ReportHandler struct {
+	service *services.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler(service *services.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// GetSpendingReport totals income and spending per group and time bucket. Query params:
+// group_by (category, merchant, account or tag; default category), interval (day, week,
+// month or year; default month), start, end (YYYY-MM-DD) and account_id (comma separated).
+func (h *ReportHandler) GetSpendingReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountIDs, err := parseObjectIDList(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	report, err := h.service.Spending(ctx, userID, services.ReportRequest{
+		AccountIDs: accountIDs,
+		GroupBy:    c.Query("group_by", models.ReportByCategory),
+		Interval:   c.Query("interval", models.ReportMonthly),
+		Start:      start,
+		End:        end,
+	}, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReport), errors.Is(err, services.ErrInvalidReportRange):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
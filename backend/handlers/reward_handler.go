@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RewardProgramPayload struct {
+	Name       string  `json:"name"`
+	PointValue float64 `json:"point_value"`
+}
+
+type RewardRulePayload struct {
+	AccountID  string  `json:"account_id"`
+	ProgramID  string  `json:"program_id"`
+	Category   string  `json:"category"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type RedemptionPayload struct {
+	AccountID   string  `json:"account_id"`
+	Points      float64 `json:"points"`
+	Description string  `json:"description"`
+}
+
+// RewardHandler handles rewards-related HTTP requests
+type RewardHandler struct {
+	service *services.RewardService
+}
+
+// NewRewardHandler creates a new RewardHandler
+func NewRewardHandler(service *services.RewardService) *RewardHandler {
+	return &RewardHandler{service: service}
+}
+
+// GetSummary returns the current user's points balances and cashback rate by card
+func (h *RewardHandler) GetSummary(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	summary, err := h.service.Summary(ctx, userID)
+	if err != nil {
+		return rewardError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(summary)
+}
+
+func (h *RewardHandler) ListPrograms(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	programs, err := h.service.ListPrograms(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(programs)
+}
+
+func (h *RewardHandler) CreateProgram(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var payload RewardProgramPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	program := models.RewardProgram{Name: payload.Name, PointValue: payload.PointValue}
+	if err := h.service.CreateProgram(ctx, &program); err != nil {
+		return rewardError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(program)
+}
+
+func (h *RewardHandler) ListRules(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+
+	rules, err := h.service.ListRules(ctx, userID, accountID)
+	if err != nil {
+		return rewardError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rules)
+}
+
+func (h *RewardHandler) CreateRule(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload RewardRulePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(payload.AccountID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	programID, err := primitive.ObjectIDFromHex(payload.ProgramID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Program ID")
+	}
+
+	rule := models.RewardRule{
+		AccountID:  accountID,
+		ProgramID:  programID,
+		Category:   payload.Category,
+		Multiplier: payload.Multiplier,
+	}
+	if err := h.service.CreateRule(ctx, userID, &rule); err != nil {
+		return rewardError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// Redeem spends points from one of the current user's cards
+func (h *RewardHandler) Redeem(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload RedemptionPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+	accountID, err := primitive.ObjectIDFromHex(payload.AccountID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+
+	redemption := models.RewardRedemption{
+		AccountID:   accountID,
+		Points:      payload.Points,
+		Description: payload.Description,
+	}
+	if err := h.service.Redeem(ctx, userID, &redemption); err != nil {
+		return rewardError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(redemption)
+}
+
+func rewardError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidReward):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInsufficientPoints):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrProgramNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Reward Program Not Found In DB")
+	case errors.Is(err, services.ErrAccountNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/services"
+)
+
+type LoginPayload struct {
+	// Login is the user's username or email
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// SessionHandler handles sign-in HTTP requests
+type SessionHandler struct {
+	service *services.SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(service *services.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// Login exchanges a username or email and password for a session token, sent back as
+// "Authorization: Bearer <session_token>"
+func (h *SessionHandler) Login(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var payload LoginPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	issued, err := h.service.Login(ctx, payload.Login, payload.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		case errors.Is(err, services.ErrAccountDisabled):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
@@ -0,0 +1,308 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// fieldCipher encrypts under the active one of the 2026-04 and 2026-10 master keys
+func fieldCipher(t *testing.T, active string) *services.FieldCipher {
+	t.Helper()
+	keys, err := services.NewLocalKeyProvider(active, map[string][]byte{
+		"2026-04": bytes.Repeat([]byte{1}, 32),
+		"2026-10": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	return services.NewFieldCipher(keys)
+}
+
+// numberStore keeps a single account, saving its numbers in place unless they changed
+// since previous was read
+func numberStore(account *models.Account) *MockAccountRepository {
+	return &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			if id != account.ID {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *account
+			return &copied, nil
+		},
+		SaveNumbersFunc: func(ctx context.Context, saved, previous *models.Account) error {
+			if previous != nil && !sameNumbers(account, previous) {
+				return mongo.ErrNoDocuments
+			}
+			*account = *saved
+			return nil
+		},
+		ListForEncryptFunc: func(ctx context.Context, activeKeyID string) ([]models.Account, error) {
+			return []models.Account{*account}, nil
+		},
+	}
+}
+
+func sameNumbers(a, b *models.Account) bool {
+	nonce := func(value *models.EncryptedValue) string {
+		if value == nil {
+			return ""
+		}
+		return string(value.Nonce)
+	}
+	return a.AccountNumber == b.AccountNumber && a.RoutingNumber == b.RoutingNumber &&
+		nonce(a.AccountNumberSealed) == nonce(b.AccountNumberSealed) && nonce(a.RoutingNumberSealed) == nonce(b.RoutingNumberSealed)
+}
+
+func newAccountNumberApp(accounts *MockAccountRepository, cipher *services.FieldCipher, audit *MockAuditRepository) *fiber.App {
+	access := services.NewAccessService(accounts, noHouseholds())
+	handler := handlers.NewAccountNumberHandler(services.NewAccountNumberService(accounts, cipher, access, audit))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Put("/accounts/:id/numbers", handler.SetNumbers)
+	app.Post("/accounts/:id/numbers/reveal", handler.RevealNumbers)
+	app.Post("/encryption/rotations", handler.RotateKeys)
+	return app
+}
+
+// newRevealApp serves an account whose numbers the owner has already set
+func newRevealApp(t *testing.T, audit *MockAuditRepository) (*fiber.App, *models.Account) {
+	t.Helper()
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID()}
+	app := newAccountNumberApp(numberStore(account), fieldCipher(t, "2026-10"), audit)
+	rec := userRequest(t, app, account.OwnerID, "PUT", "/accounts/"+account.ID.Hex()+"/numbers", handlers.AccountNumbersPayload{AccountNumber: "000123456789", RoutingNumber: "021000021"})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Failed to set numbers: %d %s", rec.Code, rec.Body.String())
+	}
+	return app, account
+}
+
+// Test SetNumbers - responses only show the last four digits
+func TestSetNumbers_MasksResponse(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID()}
+	app := newAccountNumberApp(numberStore(account), fieldCipher(t, "2026-10"), auditStore(nil))
+
+	rec := userRequest(t, app, account.OwnerID, "PUT", "/accounts/"+account.ID.Hex()+"/numbers", handlers.AccountNumbersPayload{AccountNumber: "000123456789", RoutingNumber: "021000021"})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "123456789") || strings.Contains(body, "021000021") {
+		t.Errorf("Expected only masked numbers in the response, got %s", body)
+	}
+	if !strings.Contains(body, `"account_number_last4":"6789"`) || !strings.Contains(body, `"routing_number_last4":"0021"`) {
+		t.Errorf("Expected last-4 masks in the response, got %s", body)
+	}
+}
+
+// Test SetNumbers - numbers are stored sealed under the active key
+func TestSetNumbers_StoresSealed(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID()}
+	app := newAccountNumberApp(numberStore(account), fieldCipher(t, "2026-10"), auditStore(nil))
+
+	userRequest(t, app, account.OwnerID, "PUT", "/accounts/"+account.ID.Hex()+"/numbers", handlers.AccountNumbersPayload{AccountNumber: "000123456789", RoutingNumber: "021000021"})
+	if account.AccountNumber != "" || account.AccountNumberSealed == nil || account.AccountNumberSealed.KeyID != "2026-10" {
+		t.Errorf("Expected the account number sealed under 2026-10, got %+v", account)
+	}
+}
+
+// Test SetNumbers - routing numbers must pass the check digit
+func TestSetNumbers_BadRoutingNumber(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID()}
+	accounts := numberStore(account)
+	accounts.SaveNumbersFunc = func(ctx context.Context, saved, previous *models.Account) error {
+		t.Error("Expected nothing to be saved")
+		return nil
+	}
+	app := newAccountNumberApp(accounts, fieldCipher(t, "2026-10"), auditStore(nil))
+
+	rec := userRequest(t, app, account.OwnerID, "PUT", "/accounts/"+account.ID.Hex()+"/numbers", handlers.AccountNumbersPayload{AccountNumber: "000123456789", RoutingNumber: "021000022"})
+	if rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+// Test RevealNumbers - a reason is required
+func TestRevealNumbers_RequiresReason(t *testing.T) {
+	var entries []models.AuditEntry
+	app, account := newRevealApp(t, auditStore(&entries))
+
+	rec := userRequest(t, app, account.OwnerID, "POST", "/accounts/"+account.ID.Hex()+"/numbers/reveal", handlers.RevealPayload{})
+	if rec.Code != fiber.StatusBadRequest || len(entries) != 0 {
+		t.Errorf("Expected status 400 with nothing audited, got %d", rec.Code)
+	}
+}
+
+// Test RevealNumbers - users without access are refused
+func TestRevealNumbers_Forbidden(t *testing.T) {
+	var entries []models.AuditEntry
+	app, account := newRevealApp(t, auditStore(&entries))
+
+	rec := userRequest(t, app, primitive.NewObjectID(), "POST", "/accounts/"+account.ID.Hex()+"/numbers/reveal", handlers.RevealPayload{Reason: "curious"})
+	if rec.Code != fiber.StatusForbidden || len(entries) != 0 {
+		t.Errorf("Expected status 403 with nothing audited, got %d", rec.Code)
+	}
+}
+
+// Test RevealNumbers - the owner sees the full numbers and the reveal is audited
+func TestRevealNumbers_Audited(t *testing.T) {
+	var entries []models.AuditEntry
+	app, account := newRevealApp(t, auditStore(&entries))
+
+	rec := userRequest(t, app, account.OwnerID, "POST", "/accounts/"+account.ID.Hex()+"/numbers/reveal", handlers.RevealPayload{Reason: "setting up direct deposit"})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"account_number":"000123456789"`) || !strings.Contains(rec.Body.String(), `"routing_number":"021000021"`) {
+		t.Errorf("Expected the full numbers, got %s", rec.Body.String())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected one audit entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Action != models.AuditAccountNumbersRevealed || entry.ActorID != account.OwnerID || entry.ResourceID != account.ID || entry.Detail != "setting up direct deposit" {
+		t.Errorf("Unexpected audit entry %+v", entry)
+	}
+}
+
+// Test RotateKeys - legacy plaintext numbers are sealed
+func TestRotateKeys_SealsPlaintext(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID(), AccountNumber: "000123456789", RoutingNumber: "021000021"}
+	app := newAccountNumberApp(numberStore(account), fieldCipher(t, "2026-04"), auditStore(nil))
+
+	rec := userRequest(t, app, account.OwnerID, "POST", "/encryption/rotations", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if account.AccountNumber != "" || account.AccountNumberSealed == nil || account.AccountNumberSealed.KeyID != "2026-04" {
+		t.Errorf("Expected legacy plaintext sealed under 2026-04, got %+v", account)
+	}
+}
+
+// Test RotateKeys - numbers under a retired key move to the active key
+func TestRotateKeys_ReencryptsRetiredKey(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID(), AccountNumber: "000123456789", RoutingNumber: "021000021"}
+	accounts := numberStore(account)
+	userRequest(t, newAccountNumberApp(accounts, fieldCipher(t, "2026-04"), auditStore(nil)), account.OwnerID, "POST", "/encryption/rotations", nil)
+
+	rec := userRequest(t, newAccountNumberApp(accounts, fieldCipher(t, "2026-10"), auditStore(nil)), account.OwnerID, "POST", "/encryption/rotations", nil)
+	if rec.Code != fiber.StatusOK || !strings.Contains(rec.Body.String(), `"reencrypted":1`) {
+		t.Fatalf("Expected one account re-encrypted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if account.AccountNumberSealed.KeyID != "2026-10" || account.RoutingNumberSealed.KeyID != "2026-10" {
+		t.Errorf("Expected numbers sealed under 2026-10, got %+v", account)
+	}
+	if account.MaskedAccountNumber() != "****6789" {
+		t.Errorf("Expected mask ****6789, got %q", account.MaskedAccountNumber())
+	}
+}
+
+// Test RotateKeys - numbers set while a rotation runs are not reverted
+func TestRotateKeys_KeepsConcurrentChange(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: primitive.NewObjectID(), AccountNumber: "000123456789", RoutingNumber: "021000021"}
+	accounts := numberStore(account)
+	userRequest(t, newAccountNumberApp(accounts, fieldCipher(t, "2026-04"), auditStore(nil)), account.OwnerID, "POST", "/encryption/rotations", nil)
+	stale := *account
+	accounts.ListForEncryptFunc = func(ctx context.Context, activeKeyID string) ([]models.Account, error) {
+		return []models.Account{stale}, nil
+	}
+	app := newAccountNumberApp(accounts, fieldCipher(t, "2026-10"), auditStore(nil))
+	userRequest(t, app, account.OwnerID, "PUT", "/accounts/"+account.ID.Hex()+"/numbers", handlers.AccountNumbersPayload{AccountNumber: "000987654321", RoutingNumber: "021000021"})
+
+	rec := userRequest(t, app, account.OwnerID, "POST", "/encryption/rotations", nil)
+	if rec.Code != fiber.StatusOK || !strings.Contains(rec.Body.String(), `"reencrypted":0`) || !strings.Contains(rec.Body.String(), `"failures":0`) {
+		t.Fatalf("Expected the changed account to be skipped, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if account.MaskedAccountNumber() != "****4321" {
+		t.Errorf("Expected the new number to be kept, got %q", account.MaskedAccountNumber())
+	}
+}
+
+// Test RotateKeys - TOTP secrets, bank access tokens and webhook secrets move to the active key as well
+func TestRotateKeys_ReencryptsSecrets(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: primitive.NewObjectID()}
+	users := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			copied := *user
+			return &copied, nil
+		},
+		SaveTOTPFunc: func(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error {
+			user.TOTPSecret, user.TOTPEnabled, user.RecoveryCodes = secret, enabled, recoveryCodes
+			return nil
+		},
+		ListStaleTOTPFunc: func(ctx context.Context, activeKeyID string) ([]models.User, error) {
+			return []models.User{*user}, nil
+		},
+		ResealTOTPFunc: func(ctx context.Context, id primitive.ObjectID, previous, secret *models.EncryptedValue) error {
+			user.TOTPSecret = secret
+			return nil
+		},
+		RecordTOTPAttemptFunc: func(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error) {
+			return 1, nil
+		},
+		UseTOTPStepFunc: func(ctx context.Context, id primitive.ObjectID, step int64) error {
+			return nil
+		},
+	}
+	sessions := sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{})
+	connections := map[primitive.ObjectID]*models.BankConnection{}
+	endpoints := map[primitive.ObjectID]*models.WebhookEndpoint{}
+	var deliveries []*models.WebhookDelivery
+	webhooks := webhookStore(endpoints, &deliveries)
+
+	old := fieldCipher(t, "2026-04")
+	enrollment, err := services.NewTwoFactorService(users, sessions, old).Enroll(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to enroll: %v", err)
+	}
+	oldSync := services.NewSyncService(connectionStore(connections), &MockAccountRepository{}, &MockTransactionRepository{}, users, old)
+	oldSync.RegisterProvider(services.NewFakeBankProvider(nil))
+	connected, err := oldSync.Connect(ctx, user.ID, services.FakeBankProviderName, "sandbox-user")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	webhook, err := services.NewWebhookService(webhooks, old, nil).CreateEndpoint(ctx, user.ID, "https://hooks.example.com/track-me", "", []string{models.EventBudgetExceeded})
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+
+	current := fieldCipher(t, "2026-10")
+	twoFactor := services.NewTwoFactorService(users, sessions, current)
+	accounts := &MockAccountRepository{
+		ListForEncryptFunc: func(ctx context.Context, activeKeyID string) ([]models.Account, error) {
+			return nil, nil
+		},
+	}
+	accountNumbers := services.NewAccountNumberService(accounts, current, &AllowAllAccess{}, auditStore(nil))
+	accountNumbers.AddRotator(twoFactor)
+	accountNumbers.AddRotator(services.NewSyncService(connectionStore(connections), &MockAccountRepository{}, &MockTransactionRepository{}, users, current))
+	accountNumbers.AddRotator(services.NewWebhookService(webhooks, current, nil))
+
+	result, err := accountNumbers.RotateKeys(ctx)
+	if err != nil || result.Secrets != 3 || result.Reencrypted != 3 || result.Failures != 0 {
+		t.Fatalf("Expected three secrets re-encrypted, got %+v, %v", result, err)
+	}
+	sealed := []*models.EncryptedValue{user.TOTPSecret, connections[connected.Connection.ID].AccessTokenSealed, endpoints[webhook.Endpoint.ID].SecretSealed}
+	for i, value := range sealed {
+		if value.KeyID != "2026-10" {
+			t.Errorf("Expected secret %d sealed under 2026-10, got %s", i, value.KeyID)
+		}
+	}
+
+	secret, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	if _, err := twoFactor.Confirm(ctx, user.ID, "login-session", currentCode(secret)); err != nil {
+		t.Errorf("Expected the re-encrypted TOTP secret to still check codes, got %v", err)
+	}
+}
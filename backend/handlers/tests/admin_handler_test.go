@@ -0,0 +1,378 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/routes"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockAuditRepository is a mock implementation of repository.AuditRepository for testing
+type MockAuditRepository struct {
+	CreateEntryFunc         func(ctx context.Context, entry *models.AuditEntry) error
+	ListEntriesFunc         func(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error)
+	CreateImpersonationFunc func(ctx context.Context, impersonation *models.Impersonation) error
+	GetImpersonationFunc    func(ctx context.Context, id primitive.ObjectID) (*models.Impersonation, error)
+	EndImpersonationFunc    func(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+func (m *MockAuditRepository) CreateEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if m.CreateEntryFunc != nil {
+		return m.CreateEntryFunc(ctx, entry)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAuditRepository) ListEntries(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error) {
+	if m.ListEntriesFunc != nil {
+		return m.ListEntriesFunc(ctx, targetUserID, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAuditRepository) CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) error {
+	if m.CreateImpersonationFunc != nil {
+		return m.CreateImpersonationFunc(ctx, impersonation)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAuditRepository) GetImpersonation(ctx context.Context, id primitive.ObjectID) (*models.Impersonation, error) {
+	if m.GetImpersonationFunc != nil {
+		return m.GetImpersonationFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAuditRepository) EndImpersonation(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if m.EndImpersonationFunc != nil {
+		return m.EndImpersonationFunc(ctx, id, at)
+	}
+	return errors.New("not implemented")
+}
+
+// auditStore appends audit entries to entries, oldest first, and keeps impersonations
+// in memory. A nil entries discards the log.
+func auditStore(entries *[]models.AuditEntry) *MockAuditRepository {
+	if entries == nil {
+		entries = &[]models.AuditEntry{}
+	}
+	impersonations := map[primitive.ObjectID]*models.Impersonation{}
+	return &MockAuditRepository{
+		CreateEntryFunc: func(ctx context.Context, entry *models.AuditEntry) error {
+			entry.ID = primitive.NewObjectID()
+			*entries = append(*entries, *entry)
+			return nil
+		},
+		ListEntriesFunc: func(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error) {
+			var out []models.AuditEntry
+			for i := len(*entries) - 1; i >= 0 && int64(len(out)) < limit; i-- {
+				if targetUserID.IsZero() || (*entries)[i].TargetUserID == targetUserID {
+					out = append(out, (*entries)[i])
+				}
+			}
+			return out, nil
+		},
+		CreateImpersonationFunc: func(ctx context.Context, impersonation *models.Impersonation) error {
+			impersonation.ID = primitive.NewObjectID()
+			stored := *impersonation
+			impersonations[impersonation.ID] = &stored
+			return nil
+		},
+		GetImpersonationFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Impersonation, error) {
+			impersonation, ok := impersonations[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *impersonation
+			return &copied, nil
+		},
+		EndImpersonationFunc: func(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+			impersonation, ok := impersonations[id]
+			if !ok || !impersonation.EndedAt.IsZero() {
+				return mongo.ErrNoDocuments
+			}
+			impersonation.EndedAt = at
+			return nil
+		},
+	}
+}
+
+type adminFixture struct {
+	app      *fiber.App
+	users    map[primitive.ObjectID]*models.User
+	entries  []models.AuditEntry
+	adminID  primitive.ObjectID
+	userID   primitive.ObjectID
+	viewerID primitive.ObjectID
+}
+
+// newAdminFixture serves the user and admin routes behind the real authorization
+// middleware, with one admin, one user and one read-only user
+func newAdminFixture() *adminFixture {
+	f := &adminFixture{
+		adminID:  primitive.NewObjectID(),
+		userID:   primitive.NewObjectID(),
+		viewerID: primitive.NewObjectID(),
+	}
+	f.users = map[primitive.ObjectID]*models.User{
+		f.adminID:  {ID: f.adminID, Username: "admin", Role: models.RoleAdmin},
+		f.userID:   {ID: f.userID, Username: "user"},
+		f.viewerID: {ID: f.viewerID, Username: "viewer", Role: models.RoleReadOnly},
+	}
+
+	userRepo := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			if user, ok := f.users[id]; ok {
+				copied := *user
+				return &copied, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		GetAllUsersFunc: func(ctx context.Context) ([]models.User, error) {
+			var out []models.User
+			for _, user := range f.users {
+				out = append(out, *user)
+			}
+			return out, nil
+		},
+		SetRoleFunc: func(ctx context.Context, id primitive.ObjectID, role string) error {
+			f.users[id].Role = role
+			return nil
+		},
+		SetDisabledFunc: func(ctx context.Context, id primitive.ObjectID, disabled bool, at time.Time) error {
+			f.users[id].Disabled = disabled
+			return nil
+		},
+	}
+
+	adminService := services.NewAdminService(userRepo, auditStore(&f.entries))
+	f.app = fiber.New()
+	f.app.Use(middleware.UserContextMiddleware())
+	f.app.Use(middleware.AuthorizationMiddleware(adminService))
+	routes.SetupProductRoutes(f.app, handlers.NewUserHandler(services.NewUserService(userRepo)))
+	routes.SetupAdminRoutes(f.app, handlers.NewAdminHandler(adminService))
+	return f
+}
+
+// impersonate starts an impersonation of targetID by the admin
+func (f *adminFixture) impersonate(t *testing.T, targetID primitive.ObjectID) *models.Impersonation {
+	t.Helper()
+	rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+targetID.Hex()+"/impersonate", handlers.ImpersonatePayload{Reason: "support ticket 12"})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected impersonation to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var impersonation models.Impersonation
+	_ = json.Unmarshal(rec.Body.Bytes(), &impersonation)
+	return &impersonation
+}
+
+// as sends a request by callerID under the impersonation
+func (f *adminFixture) as(t *testing.T, callerID primitive.ObjectID, impersonation *models.Impersonation, method, path string) int {
+	t.Helper()
+	req := testRequest(method, path, nil)
+	req.Header.Set("X-User-ID", callerID.Hex())
+	req.Header.Set("X-Impersonation-ID", impersonation.ID.Hex())
+	resp, err := f.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// Test admin routes - users and read-only users are refused every admin route and nothing changes
+func TestAdmin_NonAdminsAreForbidden(t *testing.T) {
+	f := newAdminFixture()
+	target := "/api/admin/users/" + f.userID.Hex()
+
+	for _, callerID := range []primitive.ObjectID{f.userID, f.viewerID} {
+		for _, req := range []struct{ method, path string }{
+			{"GET", "/api/admin/users"},
+			{"GET", "/api/products/"},
+			{"POST", target + "/disable"},
+			{"PUT", target + "/role"},
+			{"POST", target + "/impersonate"},
+			{"GET", "/api/admin/audit"},
+		} {
+			rec := userRequest(t, f.app, callerID, req.method, req.path, handlers.RolePayload{Role: models.RoleAdmin})
+			if rec.Code != fiber.StatusForbidden {
+				t.Errorf("Expected %s %s by %s to be forbidden, got %d", req.method, req.path, f.users[callerID].Username, rec.Code)
+			}
+		}
+	}
+	if f.users[f.userID].Role != "" || f.users[f.userID].Disabled || len(f.entries) != 0 {
+		t.Errorf("Expected no changes from forbidden requests")
+	}
+}
+
+// Test admin routes - an anonymous request is unauthorized
+func TestAdmin_AnonymousIsUnauthorized(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, primitive.NilObjectID, "GET", "/api/admin/users", nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected anonymous request to be unauthorized, got %d", rec.Code)
+	}
+}
+
+// Test ListUsers - an admin lists every user
+func TestAdmin_ListsUsers(t *testing.T) {
+	f := newAdminFixture()
+
+	rec := userRequest(t, f.app, f.adminID, "GET", "/api/admin/users", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected admin to list users, got %d", rec.Code)
+	}
+	var users []models.User
+	_ = json.Unmarshal(rec.Body.Bytes(), &users)
+	if len(users) != len(f.users) {
+		t.Errorf("Expected %d users, got %d", len(f.users), len(users))
+	}
+}
+
+// Test user routes - a user reads their own profile but not another's
+func TestAdmin_UserReadsOnlyOwnProfile(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.userID, "GET", "/api/products/"+f.userID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected a user to read their own profile, got %d", rec.Code)
+	}
+	if rec := userRequest(t, f.app, f.userID, "GET", "/api/products/"+f.viewerID.Hex(), nil); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected a user to be refused another profile, got %d", rec.Code)
+	}
+}
+
+// Test user routes - a read-only user cannot write, even to their own profile
+func TestAdmin_ReadOnlyUserCannotWrite(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.viewerID, "DELETE", "/api/products/"+f.viewerID.Hex(), nil); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected a read-only user to be refused a delete, got %d", rec.Code)
+	}
+}
+
+// Test user routes - an admin reads any profile
+func TestAdmin_AdminReadsAnyProfile(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.adminID, "GET", "/api/products/"+f.viewerID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected an admin to read any profile, got %d", rec.Code)
+	}
+}
+
+// Test DisableUser - a disabled user is locked out and the change is audited
+func TestAdmin_DisableLocksUserOut(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+f.userID.Hex()+"/disable", nil); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected admin to disable the user, got %d", rec.Code)
+	}
+	if rec := userRequest(t, f.app, f.userID, "GET", "/api/products/"+f.userID.Hex(), nil); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected a disabled user to be forbidden, got %d", rec.Code)
+	}
+	if len(f.entries) != 1 || f.entries[0].Action != models.AuditUserDisabled || f.entries[0].ActorID != f.adminID {
+		t.Errorf("Expected the disable to be audited, got %+v", f.entries)
+	}
+}
+
+// Test DisableUser - an admin cannot disable themselves
+func TestAdmin_CannotDisableSelf(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+f.adminID.Hex()+"/disable", nil); rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected an admin to be unable to disable themselves, got %d", rec.Code)
+	}
+	if f.users[f.adminID].Disabled {
+		t.Errorf("Expected the admin to stay enabled")
+	}
+}
+
+// Test EnableUser - a re-enabled user gets back in and the change is audited
+func TestAdmin_EnableRestoresAccess(t *testing.T) {
+	f := newAdminFixture()
+	f.users[f.userID].Disabled = true
+
+	if rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+f.userID.Hex()+"/enable", nil); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected admin to enable the user, got %d", rec.Code)
+	}
+	if rec := userRequest(t, f.app, f.userID, "GET", "/api/products/"+f.userID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected a re-enabled user to get in, got %d", rec.Code)
+	}
+	if len(f.entries) != 1 || f.entries[0].Action != models.AuditUserEnabled {
+		t.Errorf("Expected the enable to be audited, got %+v", f.entries)
+	}
+}
+
+// Test Impersonate - an impersonation needs a reason
+func TestAdmin_ImpersonationRequiresReason(t *testing.T) {
+	f := newAdminFixture()
+
+	if rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+f.viewerID.Hex()+"/impersonate", handlers.ImpersonatePayload{}); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected impersonation without a reason to be rejected, got %d", rec.Code)
+	}
+	if len(f.entries) != 0 {
+		t.Errorf("Expected nothing audited, got %+v", f.entries)
+	}
+}
+
+// Test Impersonate - the admin acts as the user, with the user's role
+func TestAdmin_ImpersonationActsWithUsersRole(t *testing.T) {
+	f := newAdminFixture()
+	impersonation := f.impersonate(t, f.viewerID)
+
+	if status := f.as(t, f.adminID, impersonation, "GET", "/api/products/"+f.viewerID.Hex()); status != fiber.StatusOK {
+		t.Errorf("Expected the admin to read as the user, got %d", status)
+	}
+	if status := f.as(t, f.adminID, impersonation, "DELETE", "/api/products/"+f.viewerID.Hex()); status != fiber.StatusForbidden {
+		t.Errorf("Expected the read-only user's role to apply, got %d", status)
+	}
+}
+
+// Test Impersonate - admin routes are closed while impersonating
+func TestAdmin_ImpersonationClosesAdminRoutes(t *testing.T) {
+	f := newAdminFixture()
+	impersonation := f.impersonate(t, f.viewerID)
+
+	if status := f.as(t, f.adminID, impersonation, "GET", "/api/admin/users"); status != fiber.StatusForbidden {
+		t.Errorf("Expected admin routes to be closed while impersonating, got %d", status)
+	}
+}
+
+// Test Impersonate - only the admin who started an impersonation can use it
+func TestAdmin_ImpersonationBoundToAdmin(t *testing.T) {
+	f := newAdminFixture()
+	impersonation := f.impersonate(t, f.viewerID)
+
+	if status := f.as(t, f.userID, impersonation, "GET", "/api/products/"+f.viewerID.Hex()); status != fiber.StatusForbidden {
+		t.Errorf("Expected a non-admin to be unable to use the impersonation, got %d", status)
+	}
+}
+
+// Test EndImpersonation - an ended impersonation is refused and both ends are audited
+func TestAdmin_EndedImpersonationIsRefusedAndAudited(t *testing.T) {
+	f := newAdminFixture()
+	impersonation := f.impersonate(t, f.viewerID)
+
+	if rec := userRequest(t, f.app, f.adminID, "DELETE", "/api/admin/impersonations/"+impersonation.ID.Hex(), nil); rec.Code != fiber.StatusNoContent {
+		t.Fatalf("Expected impersonation to end, got %d", rec.Code)
+	}
+	if status := f.as(t, f.adminID, impersonation, "GET", "/api/products/"+f.viewerID.Hex()); status != fiber.StatusForbidden {
+		t.Errorf("Expected an ended impersonation to be refused, got %d", status)
+	}
+
+	rec := userRequest(t, f.app, f.adminID, "GET", "/api/admin/audit?user_id="+f.viewerID.Hex(), nil)
+	var entries []models.AuditEntry
+	_ = json.Unmarshal(rec.Body.Bytes(), &entries)
+	if len(entries) != 2 || entries[0].Action != models.AuditImpersonationEnded || entries[1].Detail != "support ticket 12" {
+		t.Errorf("Expected start and end of the impersonation in the audit log, got %+v", entries)
+	}
+}
@@ -0,0 +1,674 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockAlertRepository is a mock implementation of repository.AlertRepository for testing
+type MockAlertRepository struct {
+	CreateRuleFunc         func(ctx context.Context, rule *models.AlertRule) error
+	GetRuleByIDFunc        func(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error)
+	ListRulesFunc          func(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error)
+	ListEnabledByKindFunc  func(ctx context.Context, kinds []string) ([]models.AlertRule, error)
+	SaveRuleFunc           func(ctx context.Context, rule *models.AlertRule) error
+	DeleteRuleFunc         func(ctx context.Context, id, userID primitive.ObjectID) error
+	RecordFiringFunc       func(ctx context.Context, ruleID primitive.ObjectID, key string, at time.Time) (bool, error)
+	ReleaseFiringFunc      func(ctx context.Context, ruleID primitive.ObjectID, key string) error
+	CreateNotificationFunc func(ctx context.Context, notification *models.Notification) error
+	ListNotificationsFunc  func(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error)
+	MarkReadFunc           func(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error
+	MarkAllReadFunc        func(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error)
+}
+
+func (m *MockAlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	if m.CreateRuleFunc != nil {
+		return m.CreateRuleFunc(ctx, rule)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) GetRuleByID(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error) {
+	if m.GetRuleByIDFunc != nil {
+		return m.GetRuleByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) ListRules(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error) {
+	if m.ListRulesFunc != nil {
+		return m.ListRulesFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) ListEnabledByKind(ctx context.Context, kinds []string) ([]models.AlertRule, error) {
+	if m.ListEnabledByKindFunc != nil {
+		return m.ListEnabledByKindFunc(ctx, kinds)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) SaveRule(ctx context.Context, rule *models.AlertRule) error {
+	if m.SaveRuleFunc != nil {
+		return m.SaveRuleFunc(ctx, rule)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) DeleteRule(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.DeleteRuleFunc != nil {
+		return m.DeleteRuleFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) RecordFiring(ctx context.Context, ruleID primitive.ObjectID, key string, at time.Time) (bool, error) {
+	if m.RecordFiringFunc != nil {
+		return m.RecordFiringFunc(ctx, ruleID, key, at)
+	}
+	return false, errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) ReleaseFiring(ctx context.Context, ruleID primitive.ObjectID, key string) error {
+	if m.ReleaseFiringFunc != nil {
+		return m.ReleaseFiringFunc(ctx, ruleID, key)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	if m.CreateNotificationFunc != nil {
+		return m.CreateNotificationFunc(ctx, notification)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) ListNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error) {
+	if m.ListNotificationsFunc != nil {
+		return m.ListNotificationsFunc(ctx, userID, unreadOnly, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) MarkRead(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+	if m.MarkReadFunc != nil {
+		return m.MarkReadFunc(ctx, id, userID, at)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAlertRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+	if m.MarkAllReadFunc != nil {
+		return m.MarkAllReadFunc(ctx, userID, at)
+	}
+	return 0, errors.New("not implemented")
+}
+
+// MockRecurringRepository is a mock implementation of repository.RecurringRepository for testing
+type MockRecurringRepository struct {
+	GetItemByIDFunc     func(ctx context.Context, id primitive.ObjectID) (*models.RecurringItem, error)
+	ListItemsByUserFunc func(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error)
+	CreateItemFunc      func(ctx context.Context, item *models.RecurringItem) error
+	SaveItemFunc        func(ctx context.Context, item *models.RecurringItem) error
+	DeleteItemFunc      func(ctx context.Context, id primitive.ObjectID) error
+}
+
+func (m *MockRecurringRepository) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringItem, error) {
+	if m.GetItemByIDFunc != nil {
+		return m.GetItemByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRecurringRepository) ListItemsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error) {
+	if m.ListItemsByUserFunc != nil {
+		return m.ListItemsByUserFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRecurringRepository) CreateItem(ctx context.Context, item *models.RecurringItem) error {
+	if m.CreateItemFunc != nil {
+		return m.CreateItemFunc(ctx, item)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRecurringRepository) SaveItem(ctx context.Context, item *models.RecurringItem) error {
+	if m.SaveItemFunc != nil {
+		return m.SaveItemFunc(ctx, item)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRecurringRepository) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	if m.DeleteItemFunc != nil {
+		return m.DeleteItemFunc(ctx, id)
+	}
+	return errors.New("not implemented")
+}
+
+// smtpStandIn is a local SMTP server that accepts every message and keeps it
+type smtpStandIn struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &smtpStandIn{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+	reply("220 localhost stand-in")
+	var msg smtpMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			msg = smtpMessage{from: smtpAddress(line)}
+			reply("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, smtpAddress(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStandIn) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func smtpAddress(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+// firingRepository serves rules and keeps the notifications they send, recording each
+// rule and key once as the unique index on alert firings does
+func firingRepository(rules []models.AlertRule, notifications *[]models.Notification) *MockAlertRepository {
+	fired := map[string]bool{}
+	return &MockAlertRepository{
+		ListRulesFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error) {
+			var owned []models.AlertRule
+			for _, rule := range rules {
+				if rule.UserID == userID {
+					owned = append(owned, rule)
+				}
+			}
+			return owned, nil
+		},
+		ListEnabledByKindFunc: func(ctx context.Context, kinds []string) ([]models.AlertRule, error) {
+			var enabled []models.AlertRule
+			for _, rule := range rules {
+				if rule.Enabled && slices.Contains(kinds, rule.Kind) {
+					enabled = append(enabled, rule)
+				}
+			}
+			return enabled, nil
+		},
+		RecordFiringFunc: func(ctx context.Context, ruleID primitive.ObjectID, key string, at time.Time) (bool, error) {
+			id := ruleID.Hex() + ":" + key
+			if fired[id] {
+				return false, nil
+			}
+			fired[id] = true
+			return true, nil
+		},
+		ReleaseFiringFunc: func(ctx context.Context, ruleID primitive.ObjectID, key string) error {
+			delete(fired, ruleID.Hex()+":"+key)
+			return nil
+		},
+		CreateNotificationFunc: func(ctx context.Context, notification *models.Notification) error {
+			*notifications = append(*notifications, *notification)
+			return nil
+		},
+	}
+}
+
+func alertRule(kind string, threshold float64, channels ...string) models.AlertRule {
+	if len(channels) == 0 {
+		channels = []string{models.ChannelInApp}
+	}
+	return models.AlertRule{ID: primitive.NewObjectID(), UserID: testUserID, Name: kind, Kind: kind, Threshold: threshold, Channels: channels, Enabled: true}
+}
+
+// newAlertService sends to the inbox, and by email through smtp when it is set
+func newAlertService(alerts *MockAlertRepository, accounts *MockAccountRepository, transactions *MockTransactionRepository, budgets *MockBudgetRepository, recurring *MockRecurringRepository, bills *MockBillRepository, smtp *smtpStandIn) *services.AlertService {
+	users := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			return &models.User{ID: id, Email: "sam@example.com"}, nil
+		},
+	}
+	service := services.NewAlertService(alerts, users, accounts, transactions, budgets, recurring, bills, &AllowAllAccess{Accounts: []primitive.ObjectID{primitive.NewObjectID()}})
+	service.RegisterChannel(services.NewInAppChannel(alerts))
+	if smtp != nil {
+		service.RegisterChannel(services.NewSMTPChannel("127.0.0.1", smtp.port(), "alerts@trackme.test", "", ""))
+	}
+	return service
+}
+
+func newAlertApp(service *services.AlertService) *fiber.App {
+	handler := handlers.NewAlertHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/alerts", handler.CreateAlert)
+	app.Put("/alerts/:id", handler.UpdateAlert)
+	app.Get("/notifications", handler.GetNotifications)
+	app.Post("/notifications/read", handler.ReadAllNotifications)
+	app.Post("/notifications/:id/read", handler.ReadNotification)
+	return app
+}
+
+func transactionEvent(txn models.Transaction) models.Event {
+	return models.Event{Type: models.EventTransactionCreated, UserID: testUserID, Data: txn}
+}
+
+// Test HandleEvent - a transaction over the threshold lands in the inbox
+func TestHandleEvent_LargeTransaction(t *testing.T) {
+	var notifications []models.Notification
+	alerts := firingRepository([]models.AlertRule{alertRule(models.AlertLargeTransaction, 250)}, &notifications)
+	service := newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil)
+
+	small := models.Transaction{ID: primitive.NewObjectID(), Name: "Coffee", Type: models.TransactionTypeDebit, Amount: 4.5}
+	large := models.Transaction{ID: primitive.NewObjectID(), Name: "Laptop Store", Type: models.TransactionTypeDebit, Amount: 1299.99}
+	for _, txn := range []models.Transaction{small, large} {
+		if err := service.HandleEvent(context.Background(), transactionEvent(txn)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if len(notifications) != 1 || !strings.Contains(notifications[0].Title, "Laptop Store") || notifications[0].Kind != models.AlertLargeTransaction {
+		t.Errorf("Expected one large transaction notification, got %+v", notifications)
+	}
+}
+
+// Test HandleEvent - the same transaction alerts once
+func TestHandleEvent_FiresOnce(t *testing.T) {
+	var notifications []models.Notification
+	alerts := firingRepository([]models.AlertRule{alertRule(models.AlertLargeTransaction, 250)}, &notifications)
+	service := newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil)
+
+	large := models.Transaction{ID: primitive.NewObjectID(), Name: "Laptop Store", Type: models.TransactionTypeDebit, Amount: 1299.99}
+	_ = service.HandleEvent(context.Background(), transactionEvent(large))
+	_ = service.HandleEvent(context.Background(), transactionEvent(large))
+	if len(notifications) != 1 {
+		t.Errorf("Expected one notification, got %d", len(notifications))
+	}
+}
+
+// Test HandleEvent - email rules send to the user's address
+func TestHandleEvent_Email(t *testing.T) {
+	smtp := newSMTPStandIn(t)
+	var notifications []models.Notification
+	alerts := firingRepository([]models.AlertRule{alertRule(models.AlertLargeTransaction, 250, models.ChannelEmail)}, &notifications)
+	service := newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, smtp)
+
+	large := models.Transaction{ID: primitive.NewObjectID(), Name: "Laptop Store", Type: models.TransactionTypeDebit, Amount: 1299.99}
+	if err := service.HandleEvent(context.Background(), transactionEvent(large)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mail := smtp.received()
+	if len(mail) != 1 || !slices.Equal(mail[0].to, []string{"sam@example.com"}) || mail[0].from != "alerts@trackme.test" {
+		t.Fatalf("Expected one email to the user, got %+v", mail)
+	}
+	if !strings.Contains(mail[0].data, "Subject: Large transaction: $1299.99 at Laptop Store") || !strings.Contains(mail[0].data, "over your alert amount of $250.00") {
+		t.Errorf("Unexpected email:\n%s", mail[0].data)
+	}
+	if len(notifications) != 0 {
+		t.Errorf("Expected an email rule to leave the inbox alone, got %d", len(notifications))
+	}
+}
+
+// flakyChannel stands in for the email channel, failing its first failures sends
+type flakyChannel struct {
+	failures int
+	sent     int
+}
+
+func (c *flakyChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (c *flakyChannel) Send(ctx context.Context, user *models.User, notification *models.Notification) error {
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("connection refused")
+	}
+	c.sent++
+	return nil
+}
+
+// Test HandleEvent - an alert no channel delivered fires again on the next event
+func TestHandleEvent_RetriesUndelivered(t *testing.T) {
+	var notifications []models.Notification
+	alerts := firingRepository([]models.AlertRule{alertRule(models.AlertLargeTransaction, 250, models.ChannelEmail)}, &notifications)
+	service := newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil)
+	email := &flakyChannel{failures: 1}
+	service.RegisterChannel(email)
+
+	large := models.Transaction{ID: primitive.NewObjectID(), Name: "Laptop Store", Type: models.TransactionTypeDebit, Amount: 1299.99}
+	for range 3 {
+		if err := service.HandleEvent(context.Background(), transactionEvent(large)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if email.sent != 1 {
+		t.Errorf("Expected the failed email to be sent once on retry, got %d", email.sent)
+	}
+}
+
+// Test SMTPChannel - a server that stops answering cannot hold up the send past ctx
+func TestSMTPChannel_HonoursContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	channel := services.NewSMTPChannel("127.0.0.1", listener.Addr().(*net.TCPAddr).Port, "alerts@trackme.test", "", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err = channel.Send(ctx, &models.User{Email: "sam@example.com"}, &models.Notification{Title: "Stalled"})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(started) > 5*time.Second {
+		t.Errorf("Expected the send to stop at the deadline, got %v after %s", err, time.Since(started))
+	}
+}
+
+// Test Evaluate - budget rules fire at or past their percentage
+func TestEvaluate_BudgetThreshold(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	groceries := models.Budget{ID: primitive.NewObjectID(), UserID: testUserID, MaximumSpending: 400,
+		StartDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)}
+	budgets := &MockBudgetRepository{
+		ListActiveFunc: func(ctx context.Context, userID primitive.ObjectID, at time.Time) ([]models.Budget, error) {
+			return []models.Budget{groceries}, nil
+		},
+	}
+	transactions := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{{ID: primitive.NewObjectID(), Name: "Corner Grocery", BudgetID: groceries.ID, Type: models.TransactionTypeDebit, Amount: 340}}, nil
+		},
+	}
+	var notifications []models.Notification
+	alerts := firingRepository([]models.AlertRule{alertRule(models.AlertBudgetThreshold, 80), alertRule(models.AlertBudgetThreshold, 100)}, &notifications)
+	service := newAlertService(alerts, &MockAccountRepository{}, transactions, budgets, &MockRecurringRepository{}, &MockBillRepository{}, nil)
+
+	result, err := service.Evaluate(context.Background(), now)
+	if err != nil || result.Rules != 2 || result.Fired != 1 {
+		t.Fatalf("Expected one of two rules to fire, got %+v (%v)", result, err)
+	}
+	if notifications[0].Title != "Budget at 85%" {
+		t.Errorf("Expected Budget at 85%%, got %q", notifications[0].Title)
+	}
+}
+
+// Test Evaluate - a low balance alerts once a day while it stays low
+func TestEvaluate_BalanceFloorDaily(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	account := &models.Account{ID: primitive.NewObjectID(), AccountLabel: "Everyday", AccountType: models.AccountTypeChecking, AvailableBalance: 80}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+	}
+	rule := alertRule(models.AlertBalanceFloor, 100)
+	rule.AccountID = account.ID
+	var notifications []models.Notification
+	service := newAlertService(firingRepository([]models.AlertRule{rule}, &notifications), accounts, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil)
+
+	for _, at := range []time.Time{now, now.Add(15 * time.Minute), now.AddDate(0, 0, 1)} {
+		if _, err := service.Evaluate(context.Background(), at); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if len(notifications) != 2 || notifications[0].Title != "Low balance: Everyday" {
+		t.Errorf("Expected one low balance alert on each day, got %+v", notifications)
+	}
+}
+
+// Test Evaluate - recurring bills due within DaysBefore alert once
+func TestEvaluate_BillDue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	items := []models.RecurringItem{
+		{ID: primitive.NewObjectID(), UserID: testUserID, Name: "Power Bill", Kind: models.RecurringBill, Amount: 95, Active: true,
+			Schedule: models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)}},
+		{ID: primitive.NewObjectID(), UserID: testUserID, Name: "Car Insurance", Kind: models.RecurringBill, Amount: 120, Active: true,
+			Schedule: models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)}},
+	}
+	recurring := &MockRecurringRepository{
+		ListItemsByUserFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error) {
+			return items, nil
+		},
+	}
+	bills := &MockBillRepository{
+		ListBillsFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error) {
+			return nil, nil
+		},
+	}
+	rule := alertRule(models.AlertBillDue, 0)
+	rule.DaysBefore = 3
+	var notifications []models.Notification
+	service := newAlertService(firingRepository([]models.AlertRule{rule}, &notifications), &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, recurring, bills, nil)
+
+	_, _ = service.Evaluate(context.Background(), now)
+	_, _ = service.Evaluate(context.Background(), now.Add(15*time.Minute))
+	if len(notifications) != 1 || notifications[0].Title != "Bill due Oct 20, 2026: Power Bill" {
+		t.Errorf("Expected one alert for the power bill, got %+v", notifications)
+	}
+}
+
+// Test CreateAlert - rules need a known kind, a sensible threshold and an available channel
+func TestCreateAlert_Invalid(t *testing.T) {
+	alerts := &MockAlertRepository{
+		CreateRuleFunc: func(ctx context.Context, rule *models.AlertRule) error {
+			t.Errorf("Expected %+v not to be stored", rule)
+			return nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	invalid := []handlers.AlertPayload{
+		{Kind: "stock_price", Threshold: 10},
+		{Kind: models.AlertLargeTransaction},
+		{Kind: models.AlertBudgetThreshold, Threshold: -5},
+		{Kind: models.AlertBillDue, DaysBefore: services.AlertMaxBillDays + 1},
+		{Kind: models.AlertBalanceFloor, Channels: []string{"sms"}},
+	}
+	for _, payload := range invalid {
+		if rec := userRequest(t, app, testUserID, "POST", "/alerts", payload); rec.Code != fiber.StatusBadRequest {
+			t.Errorf("Expected %+v to be refused, got %d", payload, rec.Code)
+		}
+	}
+}
+
+// Test CreateAlert - the name defaults to the kind and channels to the inbox
+func TestCreateAlert_Defaults(t *testing.T) {
+	var stored *models.AlertRule
+	alerts := &MockAlertRepository{
+		CreateRuleFunc: func(ctx context.Context, rule *models.AlertRule) error {
+			stored = rule
+			return nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	rec := userRequest(t, app, testUserID, "POST", "/alerts", handlers.AlertPayload{Kind: models.AlertRecurringCharge})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stored.Name != models.AlertRecurringCharge || !slices.Equal(stored.Channels, []string{models.ChannelInApp}) || !stored.Enabled {
+		t.Errorf("Expected defaults for name and channels, got %+v", stored)
+	}
+}
+
+// Test UpdateAlert - another user's rule is not found
+func TestUpdateAlert_OtherUser(t *testing.T) {
+	rule := alertRule(models.AlertRecurringCharge, 0)
+	alerts := &MockAlertRepository{
+		GetRuleByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error) {
+			return &rule, nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	rec := userRequest(t, app, primitive.NewObjectID(), "PUT", "/alerts/"+rule.ID.Hex(), handlers.AlertPayload{Kind: models.AlertRecurringCharge})
+	if rec.Code != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+// Test UpdateAlert - the owner's changes are saved
+func TestUpdateAlert_Saves(t *testing.T) {
+	rule := alertRule(models.AlertRecurringCharge, 0)
+	var saved *models.AlertRule
+	alerts := &MockAlertRepository{
+		GetRuleByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error) {
+			return &rule, nil
+		},
+		SaveRuleFunc: func(ctx context.Context, rule *models.AlertRule) error {
+			saved = rule
+			return nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, newSMTPStandIn(t)))
+
+	rec := userRequest(t, app, testUserID, "PUT", "/alerts/"+rule.ID.Hex(), handlers.AlertPayload{Kind: models.AlertRecurringCharge, Channels: []string{models.ChannelEmail}})
+	if rec.Code != fiber.StatusOK || saved == nil || !slices.Equal(saved.Channels, []string{models.ChannelEmail}) || saved.ID != rule.ID {
+		t.Errorf("Expected the rule to be saved with email, got %d %+v", rec.Code, saved)
+	}
+}
+
+// Test GetNotifications - unread=true lists only unread notifications, at most 100
+func TestGetNotifications_UnreadOnly(t *testing.T) {
+	var gotUnread bool
+	var gotLimit int64
+	alerts := &MockAlertRepository{
+		ListNotificationsFunc: func(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error) {
+			gotUnread, gotLimit = unreadOnly, limit
+			return nil, nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	rec := userRequest(t, app, testUserID, "GET", "/notifications?unread=true&limit=500", nil)
+	var notifications []models.Notification
+	_ = json.Unmarshal(rec.Body.Bytes(), &notifications)
+	if rec.Code != fiber.StatusOK || notifications == nil || !gotUnread || gotLimit != 100 {
+		t.Errorf("Expected an empty unread inbox capped at 100, got %d unread=%v limit=%d", rec.Code, gotUnread, gotLimit)
+	}
+}
+
+// Test ReadNotification - unknown notifications are not found
+func TestReadNotification_NotFound(t *testing.T) {
+	alerts := &MockAlertRepository{
+		MarkReadFunc: func(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+			return mongo.ErrNoDocuments
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	rec := userRequest(t, app, testUserID, "POST", "/notifications/"+primitive.NewObjectID().Hex()+"/read", nil)
+	if rec.Code != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+// Test ReadAllNotifications - the response counts what was marked
+func TestReadAllNotifications(t *testing.T) {
+	alerts := &MockAlertRepository{
+		MarkAllReadFunc: func(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+			return 4, nil
+		},
+	}
+	app := newAlertApp(newAlertService(alerts, &MockAccountRepository{}, &MockTransactionRepository{}, &MockBudgetRepository{}, &MockRecurringRepository{}, &MockBillRepository{}, nil))
+
+	rec := userRequest(t, app, testUserID, "POST", "/notifications/read", nil)
+	if rec.Code != fiber.StatusOK || !strings.Contains(rec.Body.String(), `"marked":4`) {
+		t.Errorf("Expected four notifications marked read, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,589 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockGoalRepository is a mock implementation of repository.GoalRepository for testing
+type MockGoalRepository struct {
+	GetGoalByIDFunc     func(ctx context.Context, id primitive.ObjectID) (*models.SavingsGoal, error)
+	ListGoalsByUserFunc func(ctx context.Context, userID primitive.ObjectID) ([]models.SavingsGoal, error)
+	CreateGoalFunc      func(ctx context.Context, goal *models.SavingsGoal) error
+	SaveGoalFunc        func(ctx context.Context, goal *models.SavingsGoal) error
+	DeleteGoalFunc      func(ctx context.Context, id primitive.ObjectID) error
+}
+
+func (m *MockGoalRepository) GetGoalByID(ctx context.Context, id primitive.ObjectID) (*models.SavingsGoal, error) {
+	if m.GetGoalByIDFunc != nil {
+		return m.GetGoalByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockGoalRepository) ListGoalsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.SavingsGoal, error) {
+	if m.ListGoalsByUserFunc != nil {
+		return m.ListGoalsByUserFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockGoalRepository) CreateGoal(ctx context.Context, goal *models.SavingsGoal) error {
+	if m.CreateGoalFunc != nil {
+		return m.CreateGoalFunc(ctx, goal)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockGoalRepository) SaveGoal(ctx context.Context, goal *models.SavingsGoal) error {
+	if m.SaveGoalFunc != nil {
+		return m.SaveGoalFunc(ctx, goal)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockGoalRepository) DeleteGoal(ctx context.Context, id primitive.ObjectID) error {
+	if m.DeleteGoalFunc != nil {
+		return m.DeleteGoalFunc(ctx, id)
+	}
+	return errors.New("not implemented")
+}
+
+// archiveBodyLimit is the test server's body limit, set low so that archives go past it
+const archiveBodyLimit = 1024
+
+// archiveData is one instance's records, read and written by the mocks archiveApp builds
+type archiveData struct {
+	users        map[primitive.ObjectID]*models.User
+	accounts     map[primitive.ObjectID]models.Account
+	transactions []models.Transaction
+	budgets      []models.Budget
+	templates    []models.BudgetTemplate
+	goals        []models.SavingsGoal
+	rules        []models.AlertRule
+	streamed     []repository.TransactionFilter
+	batches      []int
+}
+
+func newArchiveData() *archiveData {
+	return &archiveData{users: map[primitive.ObjectID]*models.User{}, accounts: map[primitive.ObjectID]models.Account{}}
+}
+
+// archiveUsers serves data's users, copying them out the way the database would
+func archiveUsers(data *archiveData) *MockUserRepository {
+	return &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			user, ok := data.users[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *user
+			return &copied, nil
+		},
+		UpdateUserFunc: func(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error) {
+			user := data.users[id]
+			user.Username, user.Email, user.NetWorth, user.CreditScore = update.Username, update.Email, update.NetWorth, update.CreditScore
+			return user, nil
+		},
+		AddAccountFunc: func(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error {
+			data.users[id].Accounts = append(data.users[id].Accounts, accountID.Hex())
+			return nil
+		},
+	}
+}
+
+// newArchiveApp serves the archive routes over data, with users standing in for the user repository
+func newArchiveApp(data *archiveData, users *MockUserRepository) *fiber.App {
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			account, ok := data.accounts[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			return &account, nil
+		},
+		CreateAccountFunc: func(ctx context.Context, account *models.Account) error {
+			data.accounts[account.ID] = *account
+			return nil
+		},
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			var ids []primitive.ObjectID
+			for id, account := range data.accounts {
+				if account.OwnerID == ownerID {
+					ids = append(ids, id)
+				}
+			}
+			slices.SortFunc(ids, func(a, b primitive.ObjectID) int { return strings.Compare(a.Hex(), b.Hex()) })
+			return ids, nil
+		},
+	}
+	transactions := &MockTransactionRepository{
+		StreamTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter, fn func(txn *models.Transaction) error) error {
+			data.streamed = append(data.streamed, filter)
+			for _, txn := range data.transactions {
+				if slices.Contains(filter.AccountIDs, txn.AccountID) {
+					if err := fn(&txn); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		CreateTransactionsFunc: func(ctx context.Context, txns []models.Transaction) error {
+			data.batches = append(data.batches, len(txns))
+			data.transactions = append(data.transactions, txns...)
+			return nil
+		},
+		DeleteByAccountsFunc: func(ctx context.Context, accountIDs []primitive.ObjectID) error {
+			data.transactions = slices.DeleteFunc(data.transactions, func(txn models.Transaction) bool {
+				return slices.Contains(accountIDs, txn.AccountID)
+			})
+			return nil
+		},
+	}
+	budgets := &MockBudgetRepository{
+		ListBudgetsFunc: func(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error) {
+			return slices.DeleteFunc(slices.Clone(data.budgets), func(budget models.Budget) bool { return budget.UserID != userID }), nil
+		},
+		CreateBudgetFunc: func(ctx context.Context, budget *models.Budget) error {
+			data.budgets = append(data.budgets, *budget)
+			return nil
+		},
+	}
+	templates := &MockBudgetTemplateRepository{
+		ListTemplatesFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error) {
+			return slices.DeleteFunc(slices.Clone(data.templates), func(template models.BudgetTemplate) bool { return template.UserID != userID }), nil
+		},
+		CreateTemplateFunc: func(ctx context.Context, template *models.BudgetTemplate) error {
+			data.templates = append(data.templates, *template)
+			return nil
+		},
+	}
+	goals := &MockGoalRepository{
+		ListGoalsByUserFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.SavingsGoal, error) {
+			return slices.DeleteFunc(slices.Clone(data.goals), func(goal models.SavingsGoal) bool { return goal.UserID != userID }), nil
+		},
+		CreateGoalFunc: func(ctx context.Context, goal *models.SavingsGoal) error {
+			data.goals = append(data.goals, *goal)
+			return nil
+		},
+	}
+	alerts := &MockAlertRepository{
+		ListRulesFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error) {
+			return slices.DeleteFunc(slices.Clone(data.rules), func(rule models.AlertRule) bool { return rule.UserID != userID }), nil
+		},
+		CreateRuleFunc: func(ctx context.Context, rule *models.AlertRule) error {
+			data.rules = append(data.rules, *rule)
+			return nil
+		},
+	}
+
+	service := services.NewArchiveService(users, accounts, transactions, budgets, templates, goals, alerts, &MockTxRunner{})
+	handler := handlers.NewArchiveHandler(service)
+	app := fiber.New(fiber.Config{BodyLimit: archiveBodyLimit, StreamRequestBody: true, DisablePreParseMultipartForm: true})
+	app.Use(middleware.BodyLimitMiddleware(archiveBodyLimit, "/archive/import"))
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/archive", handler.ExportArchive)
+	app.Post("/archive/import", handler.ImportArchive)
+	return app
+}
+
+// seedArchive gives userID a checking account and a card with a transfer between them, a
+// void purchase and a split one, and a budget from a template with a goal and a rule on top
+func seedArchive(data *archiveData, userID primitive.ObjectID) {
+	checking, card := primitive.NewObjectID(), primitive.NewObjectID()
+	data.accounts[checking] = models.Account{ID: checking, AccountLabel: "Everyday Checking", AccountType: models.AccountTypeChecking,
+		AccountNumberLast4: "4821", CurrentBalance: 2500, ConnectionID: primitive.NewObjectID(), ExternalID: "acc-1", OwnerID: userID}
+	data.accounts[card] = models.Account{ID: card, AccountLabel: "Rewards Card", AccountType: models.AccountTypeCreditCard, CurrentBalance: -300, OwnerID: userID}
+	data.users[userID] = &models.User{ID: userID, Username: "casey", Email: "casey@example.com", NetWorth: 2200, CreditScore: 742,
+		Accounts: []string{checking.Hex(), card.Hex()}, Role: models.RoleAdmin}
+
+	template := models.BudgetTemplate{ID: primitive.NewObjectID(), UserID: userID, Name: "Groceries", Categories: []string{"Groceries"}, MaximumSpending: 400, Active: true}
+	budget := models.Budget{ID: primitive.NewObjectID(), UserID: userID, TemplateID: template.ID, MaximumSpending: 400,
+		StartDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)}
+	data.templates = append(data.templates, template)
+	data.budgets = append(data.budgets, budget)
+	data.goals = append(data.goals, models.SavingsGoal{ID: primitive.NewObjectID(), UserID: userID, Name: "Trip",
+		AccountIDs: []primitive.ObjectID{checking, primitive.NewObjectID()}, TargetAmount: 3000})
+	data.rules = append(data.rules, models.AlertRule{ID: primitive.NewObjectID(), UserID: userID, Name: "Over budget", Kind: models.AlertBudgetThreshold,
+		Threshold: 80, BudgetID: budget.ID, AccountID: checking, Enabled: true})
+
+	transfer, reconciliation := primitive.NewObjectID(), primitive.NewObjectID()
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	data.transactions = append(data.transactions,
+		models.Transaction{ID: primitive.NewObjectID(), AccountID: checking, Name: "Card payment", Type: models.TransactionTypeDebit, Amount: 200,
+			TransferID: transfer, ReconciliationID: reconciliation, TransactionDate: day, CreatedBy: userID},
+		models.Transaction{ID: primitive.NewObjectID(), AccountID: card, Name: "Card payment", Type: models.TransactionTypeCredit, Amount: 200,
+			TransferID: transfer, TransactionDate: day, CreatedBy: userID},
+		models.Transaction{ID: primitive.NewObjectID(), AccountID: card, Name: "Market", Type: models.TransactionTypeDebit, Amount: 80, TransactionDate: day,
+			BudgetID: budget.ID, Splits: []models.TransactionSplit{{Amount: 60, Category: "Groceries", BudgetID: budget.ID}, {Amount: 20, Category: "Home"}}},
+		models.Transaction{ID: primitive.NewObjectID(), AccountID: card, Name: "Duplicate charge", Type: models.TransactionTypeDebit, Amount: 80,
+			Status: models.TransactionStatusVoid, TransactionDate: day},
+	)
+}
+
+func archiveRequest(t *testing.T, app *fiber.App, userID primitive.ObjectID, method, path string, body []byte) (int, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("X-User-ID", userID.Hex())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
+
+// seededArchive seeds testUserID on a fresh instance and exports them
+func seededArchive(t *testing.T) (*archiveData, []byte) {
+	t.Helper()
+	source := newArchiveData()
+	seedArchive(source, testUserID)
+	status, archive := archiveRequest(t, newArchiveApp(source, archiveUsers(source)), testUserID, "GET", "/archive", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusOK, status, archive)
+	}
+	return source, archive
+}
+
+// restoredArchive restores a seeded archive into a new user on another instance
+func restoredArchive(t *testing.T) (source, target *archiveData, userID primitive.ObjectID) {
+	t.Helper()
+	source, archive := seededArchive(t)
+	target, userID = newArchiveData(), primitive.NewObjectID()
+	target.users[userID] = &models.User{ID: userID, Username: "casey2", Role: models.RoleUser}
+	status, body := archiveRequest(t, newArchiveApp(target, archiveUsers(target)), userID, "POST", "/archive/import?mode=restore", archive)
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+	return source, target, userID
+}
+
+// archiveEntry decodes the entry name of an archive into v
+func archiveEntry(t *testing.T, archive []byte, name string, v any) {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	raw, err := reader.Open(name)
+	if err != nil {
+		t.Fatalf("Expected an entry %s: %v", name, err)
+	}
+	defer raw.Close()
+	if err := json.NewDecoder(raw).Decode(v); err != nil {
+		t.Fatalf("Failed to decode %s: %v", name, err)
+	}
+}
+
+// rezip copies an archive, passing every entry through edit
+func rezip(t *testing.T, data []byte, edit func(name string, content []byte) []byte) []byte {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	for _, file := range archive.File {
+		entry, _ := file.Open()
+		content, _ := io.ReadAll(entry)
+		entry.Close()
+		w, _ := writer.Create(file.Name)
+		_, _ = w.Write(edit(file.Name, content))
+	}
+	_ = writer.Close()
+	return out.Bytes()
+}
+
+// Test ExportArchive - the manifest lists every entry with its record count
+func TestExportArchive_Manifest(t *testing.T) {
+	_, archive := seededArchive(t)
+
+	var manifest services.ArchiveManifest
+	archiveEntry(t, archive, "manifest.json", &manifest)
+	last := manifest.Entries[len(manifest.Entries)-1]
+	if manifest.Format != services.ArchiveFormat || manifest.Version != services.ArchiveVersion || manifest.UserID != testUserID ||
+		len(manifest.Entries) != 7 || last.Name != "transactions.ndjson" || last.Records != 4 || last.SHA256 == "" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+}
+
+// Test ExportArchive - transactions on every account are streamed, void ones included
+func TestExportArchive_StreamsVoidTransactions(t *testing.T) {
+	source, _ := seededArchive(t)
+
+	if len(source.streamed) != 1 || !source.streamed[0].IncludeVoid || len(source.streamed[0].AccountIDs) != 2 {
+		t.Errorf("Expected void transactions on both accounts to be streamed, got %+v", source.streamed)
+	}
+}
+
+// Test ExportArchive - accounts leave without their institution connection
+func TestExportArchive_DropsConnections(t *testing.T) {
+	_, archive := seededArchive(t)
+
+	var exported []models.Account
+	archiveEntry(t, archive, "accounts.json", &exported)
+	if len(exported) != 2 || !exported[0].ConnectionID.IsZero() || exported[0].AccountNumberLast4 != "4821" {
+		t.Errorf("Expected accounts without their connection, got %+v", exported)
+	}
+}
+
+// Test ExportArchive - accounts listed on the profile but owned by someone else stay out
+func TestExportArchive_OwnedAccountsOnly(t *testing.T) {
+	data := newArchiveData()
+	seedArchive(data, testUserID)
+	other := primitive.NewObjectID()
+	data.accounts[other] = models.Account{ID: other, AccountLabel: "Neighbour's Savings", OwnerID: primitive.NewObjectID()}
+	data.users[testUserID].Accounts = append(data.users[testUserID].Accounts, other.Hex())
+
+	status, archive := archiveRequest(t, newArchiveApp(data, archiveUsers(data)), testUserID, "GET", "/archive", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusOK, status, archive)
+	}
+	var exported []models.Account
+	archiveEntry(t, archive, "accounts.json", &exported)
+	if len(exported) != 2 || slices.ContainsFunc(exported, func(account models.Account) bool { return account.ID == other }) {
+		t.Errorf("Expected the 2 owned accounts only, got %+v", exported)
+	}
+}
+
+// Test ImportArchive - a restore reports every record it created
+func TestImportArchive_Restore(t *testing.T) {
+	_, archive := seededArchive(t)
+	target, userID := newArchiveData(), primitive.NewObjectID()
+	target.users[userID] = &models.User{ID: userID, Username: "casey2"}
+
+	status, body := archiveRequest(t, newArchiveApp(target, archiveUsers(target)), userID, "POST", "/archive/import?mode=restore", archive)
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+	var result services.ArchiveImportResult
+	_ = json.Unmarshal(body, &result)
+	if result != (services.ArchiveImportResult{Mode: "restore", Accounts: 2, Transactions: 4, Budgets: 1, BudgetTemplates: 1, Goals: 1, AlertRules: 1}) {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+}
+
+// Test ImportArchive - a restore brings back the profile's figures but not its identity or role
+func TestImportArchive_RestoresProfile(t *testing.T) {
+	_, target, userID := restoredArchive(t)
+
+	user := target.users[userID]
+	if user.Username != "casey2" || user.Role != models.RoleUser || user.CreditScore != 742 || user.NetWorth != 2200 || len(user.Accounts) != 2 {
+		t.Errorf("Expected the profile's figures on the restoring user, got %+v", user)
+	}
+}
+
+// Test ImportArchive - restored accounts get new IDs and no connection
+func TestImportArchive_NewAccountIDs(t *testing.T) {
+	source, target, userID := restoredArchive(t)
+
+	for _, hex := range target.users[userID].Accounts {
+		id, _ := primitive.ObjectIDFromHex(hex)
+		if _, ok := source.accounts[id]; ok {
+			t.Errorf("Expected restored accounts to get new IDs, got %s", hex)
+		}
+		if account := target.accounts[id]; account.OwnerID != userID || !account.ConnectionID.IsZero() {
+			t.Errorf("Expected restored accounts owned by the user without a connection, got %+v", account)
+		}
+	}
+}
+
+// Test ImportArchive - budgets, goals and rules point at the restored records
+func TestImportArchive_RelinksRecords(t *testing.T) {
+	source, target, userID := restoredArchive(t)
+
+	template, budget := target.templates[0], target.budgets[0]
+	if template.UserID != userID || budget.UserID != userID || budget.TemplateID != template.ID || budget.ID == source.budgets[0].ID {
+		t.Errorf("Expected the budget on its new template, got %+v from %+v", budget, template)
+	}
+	checking := target.goals[0].AccountIDs
+	if len(checking) != 1 || !slices.Contains(target.users[userID].Accounts, checking[0].Hex()) {
+		t.Fatalf("Expected the goal on the new checking account only, got %v", checking)
+	}
+	if rule := target.rules[0]; rule.UserID != userID || rule.BudgetID != budget.ID || rule.AccountID != checking[0] {
+		t.Errorf("Expected the rule on the new budget and account, got %+v", rule)
+	}
+}
+
+// Test ImportArchive - transfer legs share a new transfer ID, reconciliations are cleared and splits follow their budget
+func TestImportArchive_RelinksTransactions(t *testing.T) {
+	source, target, userID := restoredArchive(t)
+
+	txns, budget := target.transactions, target.budgets[0]
+	if len(txns) != 4 || txns[0].AccountID != target.goals[0].AccountIDs[0] || txns[0].TransferID != txns[1].TransferID || txns[0].TransferID.IsZero() ||
+		txns[0].TransferID == source.transactions[0].TransferID || !txns[0].ReconciliationID.IsZero() || txns[0].CreatedBy != userID {
+		t.Fatalf("Expected the transfer legs relinked and unreconciled on the new accounts, got %+v", txns)
+	}
+	if txns[2].BudgetID != budget.ID || txns[2].Splits[0].BudgetID != budget.ID || !txns[2].Splits[1].BudgetID.IsZero() ||
+		txns[3].Status != models.TransactionStatusVoid {
+		t.Errorf("Expected the split on the new budget and the void charge kept, got %+v and %+v", txns[2], txns[3])
+	}
+}
+
+// Test ImportArchive - a restore into a user that already has records conflicts
+func TestImportArchive_RestoreNeedsEmptyUser(t *testing.T) {
+	data, archive := seededArchive(t)
+
+	status, body := archiveRequest(t, newArchiveApp(data, archiveUsers(data)), testUserID, "POST", "/archive/import?mode=restore", archive)
+	if status != fiber.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", fiber.StatusConflict, status, body)
+	}
+	if len(data.accounts) != 2 || len(data.transactions) != 4 {
+		t.Errorf("Expected nothing to be imported, got %d accounts and %d transactions", len(data.accounts), len(data.transactions))
+	}
+}
+
+// Test ImportArchive - merging adds the archive's records next to the user's own
+func TestImportArchive_Merge(t *testing.T) {
+	data, archive := seededArchive(t)
+
+	status, body := archiveRequest(t, newArchiveApp(data, archiveUsers(data)), testUserID, "POST", "/archive/import", archive)
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+	if len(data.users[testUserID].Accounts) != 4 || len(data.accounts) != 4 || len(data.transactions) != 8 || len(data.budgets) != 2 ||
+		len(data.goals) != 2 || len(data.rules) != 2 {
+		t.Errorf("Expected the archive's records next to the originals, got %d accounts and %d transactions", len(data.accounts), len(data.transactions))
+	}
+}
+
+// Test ImportArchive - merging leaves the profile alone
+func TestImportArchive_MergeKeepsProfile(t *testing.T) {
+	data, archive := seededArchive(t)
+	data.users[testUserID].CreditScore = 780
+
+	archiveRequest(t, newArchiveApp(data, archiveUsers(data)), testUserID, "POST", "/archive/import", archive)
+	if user := data.users[testUserID]; user.Username != "casey" || user.Role != models.RoleAdmin || user.CreditScore != 780 {
+		t.Errorf("Expected the profile to be left alone, got %+v", user)
+	}
+}
+
+// Test ImportArchive - archives that don't match their manifest are refused before anything is written
+func TestImportArchive_Rejected(t *testing.T) {
+	data, archive := seededArchive(t)
+	app := newArchiveApp(data, archiveUsers(data))
+
+	tampered := rezip(t, archive, func(name string, content []byte) []byte {
+		if name == "accounts.json" {
+			return bytes.Replace(content, []byte("Everyday Checking"), []byte("Everyday Chequing"), 1)
+		}
+		return content
+	})
+	future := rezip(t, archive, func(name string, content []byte) []byte {
+		if name == "manifest.json" {
+			return bytes.Replace(content, []byte(`"version": 1`), []byte(`"version": 2`), 1)
+		}
+		return content
+	})
+	missing := rezip(t, archive, func(name string, content []byte) []byte {
+		if name == "manifest.json" {
+			var manifest services.ArchiveManifest
+			_ = json.Unmarshal(content, &manifest)
+			manifest.Entries = manifest.Entries[1:]
+			content, _ = json.Marshal(manifest)
+		}
+		return content
+	})
+
+	for _, tc := range []struct {
+		name, path string
+		body       []byte
+		want       string
+	}{
+		{"tampered entry", "/archive/import", tampered, "Checksum"},
+		{"newer version", "/archive/import", future, "Version"},
+		{"missing entry", "/archive/import", missing, "Not A Valid"},
+		{"not a zip", "/archive/import", []byte("id,date\n"), "Not A Valid"},
+		{"unknown mode", "/archive/import?mode=replace", archive, "Import Mode"},
+	} {
+		status, body := archiveRequest(t, app, testUserID, "POST", tc.path, tc.body)
+		if status != fiber.StatusBadRequest || !strings.Contains(string(body), tc.want) {
+			t.Errorf("%s: expected a 400 mentioning %q, got %d: %s", tc.name, tc.want, status, body)
+		}
+	}
+	if len(data.accounts) != 2 || len(data.transactions) != 4 {
+		t.Errorf("Expected nothing to be imported, got %d accounts and %d transactions", len(data.accounts), len(data.transactions))
+	}
+}
+
+// Test ImportArchive - archives past the server's body limit are imported
+func TestImportArchive_PastBodyLimit(t *testing.T) {
+	data, archive := seededArchive(t)
+	if len(archive) <= archiveBodyLimit {
+		t.Fatalf("Expected an archive past %d bytes, got %d", archiveBodyLimit, len(archive))
+	}
+
+	if status, body := archiveRequest(t, newArchiveApp(data, archiveUsers(data)), testUserID, "POST", "/archive/import", archive); status != fiber.StatusCreated {
+		t.Errorf("Expected status code %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+}
+
+// Test ImportArchive - other routes still refuse bodies past the server's limit
+func TestImportArchive_OtherRoutesKeepBodyLimit(t *testing.T) {
+	data, archive := seededArchive(t)
+	app := newArchiveApp(data, archiveUsers(data))
+	app.Post("/echo", func(c *fiber.Ctx) error { return c.Send(c.Body()) })
+
+	if status, _ := archiveRequest(t, app, testUserID, "POST", "/echo", archive); status != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status code %d (Request Entity Too Large), got %d", fiber.StatusRequestEntityTooLarge, status)
+	}
+}
+
+// Test ImportArchive - transactions are inserted in batches
+func TestImportArchive_BatchesTransactions(t *testing.T) {
+	source := newArchiveData()
+	seedArchive(source, testUserID)
+	card := source.transactions[1].AccountID
+	for i := 0; i < 600; i++ {
+		source.transactions = append(source.transactions, models.Transaction{ID: primitive.NewObjectID(), AccountID: card, Name: "Coffee",
+			Type: models.TransactionTypeDebit, Amount: 4, TransactionDate: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)})
+	}
+	_, archive := archiveRequest(t, newArchiveApp(source, archiveUsers(source)), testUserID, "GET", "/archive", nil)
+
+	target := newArchiveData()
+	target.users[testUserID] = &models.User{ID: testUserID, Username: "casey"}
+	if status, body := archiveRequest(t, newArchiveApp(target, archiveUsers(target)), testUserID, "POST", "/archive/import", archive); status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+	if !slices.Equal(target.batches, []int{500, 104}) {
+		t.Errorf("Expected batches of 500 and 104 transactions, got %v", target.batches)
+	}
+}
+
+// Test ImportArchive - transactions written before a later step fails are deleted again
+func TestImportArchive_FailureRemovesTransactions(t *testing.T) {
+	_, archive := seededArchive(t)
+	target := newArchiveData()
+	target.users[testUserID] = &models.User{ID: testUserID, Username: "casey"}
+	users := archiveUsers(target)
+	users.AddAccountFunc = func(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error {
+		return errors.New("write conflict")
+	}
+
+	if status, _ := archiveRequest(t, newArchiveApp(target, users), testUserID, "POST", "/archive/import", archive); status != fiber.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusInternalServerError, status)
+	}
+	if len(target.batches) != 1 || len(target.transactions) != 0 {
+		t.Errorf("Expected the imported transactions to be deleted, got %d left after %v", len(target.transactions), target.batches)
+	}
+}
@@ -0,0 +1,268 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockBillRepository is a mock implementation of repository.BillRepository for testing
+type MockBillRepository struct {
+	CreateBillFunc          func(ctx context.Context, bill *models.Bill) error
+	GetBillByIDFunc         func(ctx context.Context, id primitive.ObjectID) (*models.Bill, error)
+	ListBillsFunc           func(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error)
+	ListActiveByAccountFunc func(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error)
+	SaveBillFunc            func(ctx context.Context, bill *models.Bill) error
+	DeleteBillFunc          func(ctx context.Context, id, userID primitive.ObjectID) error
+	RecordPaymentFunc       func(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error
+}
+
+func (m *MockBillRepository) CreateBill(ctx context.Context, bill *models.Bill) error {
+	if m.CreateBillFunc != nil {
+		return m.CreateBillFunc(ctx, bill)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBillRepository) GetBillByID(ctx context.Context, id primitive.ObjectID) (*models.Bill, error) {
+	if m.GetBillByIDFunc != nil {
+		return m.GetBillByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBillRepository) ListBills(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error) {
+	if m.ListBillsFunc != nil {
+		return m.ListBillsFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBillRepository) ListActiveByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error) {
+	if m.ListActiveByAccountFunc != nil {
+		return m.ListActiveByAccountFunc(ctx, accountID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBillRepository) SaveBill(ctx context.Context, bill *models.Bill) error {
+	if m.SaveBillFunc != nil {
+		return m.SaveBillFunc(ctx, bill)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBillRepository) DeleteBill(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.DeleteBillFunc != nil {
+		return m.DeleteBillFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBillRepository) RecordPayment(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error {
+	if m.RecordPaymentFunc != nil {
+		return m.RecordPaymentFunc(ctx, id, payment)
+	}
+	return errors.New("not implemented")
+}
+
+// MockGrantRepository is a mock implementation of repository.GrantRepository for testing
+type MockGrantRepository struct {
+	CreateGrantFunc func(ctx context.Context, grant *models.EquityGrant) error
+	ListGrantsFunc  func(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error)
+	DeleteGrantFunc func(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+func (m *MockGrantRepository) CreateGrant(ctx context.Context, grant *models.EquityGrant) error {
+	if m.CreateGrantFunc != nil {
+		return m.CreateGrantFunc(ctx, grant)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockGrantRepository) ListGrants(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error) {
+	if m.ListGrantsFunc != nil {
+		return m.ListGrantsFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockGrantRepository) DeleteGrant(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.DeleteGrantFunc != nil {
+		return m.DeleteGrantFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func monthlyRent(accountID primitive.ObjectID) models.Bill {
+	return models.Bill{
+		ID:        primitive.NewObjectID(),
+		UserID:    testUserID,
+		Payee:     "Maple Apartments",
+		Amount:    1450,
+		AccountID: accountID,
+		Active:    true,
+		Schedule:  models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func newCalendarApp(bills *MockBillRepository, recurring *MockRecurringRepository, grants *MockGrantRepository) *fiber.App {
+	billHandler := handlers.NewBillHandler(services.NewBillService(bills, &AllowAllAccess{}))
+	calendarHandler := handlers.NewCalendarHandler(services.NewCalendarService(bills, recurring, grants))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/bills", billHandler.CreateBill)
+	app.Get("/calendar", calendarHandler.GetCalendar)
+	return app
+}
+
+// calendarSources lists one paid rent bill, a biweekly payday and a vesting grant
+func calendarSources() (*MockBillRepository, *MockRecurringRepository, *MockGrantRepository) {
+	checking := primitive.NewObjectID()
+	rent := monthlyRent(checking)
+	rent.Payments = []models.BillPayment{{DueDate: time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC), TransactionID: primitive.NewObjectID(), Amount: 1450}}
+	bills := &MockBillRepository{
+		ListBillsFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error) {
+			return []models.Bill{rent}, nil
+		},
+	}
+	recurring := &MockRecurringRepository{
+		ListItemsByUserFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error) {
+			return []models.RecurringItem{{ID: primitive.NewObjectID(), UserID: testUserID, Name: "Acme Payroll", Kind: models.RecurringIncome, AccountID: checking, Amount: 2150, Active: true,
+				Schedule: models.Recurrence{Frequency: models.FrequencyBiweekly, StartDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)}}}, nil
+		},
+	}
+	grants := &MockGrantRepository{
+		ListGrantsFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error) {
+			return []models.EquityGrant{{ID: primitive.NewObjectID(), UserID: testUserID, Name: "2025 RSU", Shares: 480,
+				VestingStart: time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC), VestingMonths: 48, CliffMonths: 12, IntervalMonths: 3}}, nil
+		},
+	}
+	return bills, recurring, grants
+}
+
+// Test CreateBill - a bill needs a payee
+func TestCreateBill_RequiresPayee(t *testing.T) {
+	bills := &MockBillRepository{
+		CreateBillFunc: func(ctx context.Context, bill *models.Bill) error {
+			t.Error("Expected no bill to be stored")
+			return nil
+		},
+	}
+	app := newCalendarApp(bills, &MockRecurringRepository{}, &MockGrantRepository{})
+
+	rec := userRequest(t, app, testUserID, "POST", "/bills", handlers.BillPayload{Amount: 10, AccountID: primitive.NewObjectID().Hex(), Frequency: models.FrequencyMonthly, StartDate: "2026-01-03"})
+	if rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+// Test HandleEvent - a matching debit pays the nearest due date
+func TestHandleEvent_PaysBill(t *testing.T) {
+	checking := primitive.NewObjectID()
+	rent := monthlyRent(checking)
+	var payments []models.BillPayment
+	bills := &MockBillRepository{
+		ListActiveByAccountFunc: func(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error) {
+			return []models.Bill{rent}, nil
+		},
+		RecordPaymentFunc: func(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error {
+			payments = append(payments, payment)
+			return nil
+		},
+	}
+	service := services.NewBillService(bills, &AllowAllAccess{})
+
+	payment := models.Transaction{ID: primitive.NewObjectID(), Name: "MAPLE APARTMENTS RENT", AccountID: checking, Type: models.TransactionTypeDebit,
+		Amount: 1450, TransactionDate: time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)}
+	if err := service.HandleEvent(context.Background(), models.Event{Type: models.EventTransactionCreated, UserID: testUserID, Data: payment}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(payments) != 1 || payments[0].TransactionID != payment.ID || !payments[0].DueDate.Equal(time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the November rent paid, got %+v", payments)
+	}
+}
+
+// Test HandleEvent - a due date paid meanwhile is not an error
+func TestHandleEvent_AlreadyPaid(t *testing.T) {
+	checking := primitive.NewObjectID()
+	bills := &MockBillRepository{
+		ListActiveByAccountFunc: func(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error) {
+			return []models.Bill{monthlyRent(checking)}, nil
+		},
+		RecordPaymentFunc: func(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error {
+			return mongo.ErrNoDocuments
+		},
+	}
+	service := services.NewBillService(bills, &AllowAllAccess{})
+
+	payment := models.Transaction{ID: primitive.NewObjectID(), Name: "MAPLE APARTMENTS RENT", AccountID: checking, Type: models.TransactionTypeDebit,
+		Amount: 1450, TransactionDate: time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)}
+	if err := service.HandleEvent(context.Background(), models.Event{Type: models.EventTransactionCreated, UserID: testUserID, Data: payment}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// Test GetCalendar - bills, paydays and vests are listed in date order
+func TestGetCalendar_ListsEvents(t *testing.T) {
+	app := newCalendarApp(calendarSources())
+
+	rec := userRequest(t, app, testUserID, "GET", "/calendar?start=2026-10-25&end=2026-12-05", nil)
+	var calendar services.Calendar
+	_ = json.Unmarshal(rec.Body.Bytes(), &calendar)
+	var got []string
+	for _, event := range calendar.Events {
+		entry := event.Date.Format("01-02") + " " + event.Kind
+		if event.Paid {
+			entry += " paid"
+		}
+		got = append(got, entry)
+	}
+	want := "10-30 payday, 11-03 bill paid, 11-10 vest, 11-13 payday, 11-27 payday, 12-03 bill"
+	if rec.Code != fiber.StatusOK || strings.Join(got, ", ") != want {
+		t.Errorf("Expected %s, got %d %s", want, rec.Code, strings.Join(got, ", "))
+	}
+}
+
+// Test GetCalendar - format=ics serves an iCalendar feed
+func TestGetCalendar_ICS(t *testing.T) {
+	app := newCalendarApp(calendarSources())
+
+	req := httptest.NewRequest("GET", "/calendar?start=2026-10-25&end=2026-12-05&format=ics", nil)
+	req.Header.Set("X-User-ID", testUserID.Hex())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	ics := string(body)
+	if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/calendar") {
+		t.Fatalf("Expected an iCalendar feed, got %d %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 6 || !strings.Contains(ics, "SUMMARY:2025 RSU: 120 shares vest") {
+		t.Errorf("Expected six events, got:\n%s", ics)
+	}
+}
+
+// Test GetCalendar - ranges over calendarMaxDays are refused
+func TestGetCalendar_RangeTooLong(t *testing.T) {
+	app := newCalendarApp(calendarSources())
+
+	rec := userRequest(t, app, testUserID, "GET", "/calendar?start=2026-01-01&end=2028-01-01", nil)
+	if rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
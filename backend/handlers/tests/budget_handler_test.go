@@ -0,0 +1,311 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockBudgetRepository is a mock implementation of repository.BudgetRepository for testing
+type MockBudgetRepository struct {
+	GetBudgetByIDFunc      func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error)
+	UpdateBudgetStatusFunc func(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error
+	CreateBudgetFunc       func(ctx context.Context, budget *models.Budget) error
+	ListByTemplateFunc     func(ctx context.Context, templateID primitive.ObjectID) ([]models.Budget, error)
+	ListActiveFunc         func(ctx context.Context, userID primitive.ObjectID, at time.Time) ([]models.Budget, error)
+	ListBudgetsFunc        func(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error)
+	ListAllBudgetsFunc     func(ctx context.Context, start, end time.Time) ([]models.Budget, error)
+}
+
+func (m *MockBudgetRepository) GetBudgetByID(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+	if m.GetBudgetByIDFunc != nil {
+		return m.GetBudgetByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) UpdateBudgetStatus(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error {
+	if m.UpdateBudgetStatusFunc != nil {
+		return m.UpdateBudgetStatusFunc(ctx, id, isMeetingBudget)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	if m.CreateBudgetFunc != nil {
+		return m.CreateBudgetFunc(ctx, budget)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) ListBudgetsByTemplate(ctx context.Context, templateID primitive.ObjectID) ([]models.Budget, error) {
+	if m.ListByTemplateFunc != nil {
+		return m.ListByTemplateFunc(ctx, templateID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) ListActiveBudgets(ctx context.Context, userID primitive.ObjectID, at time.Time) ([]models.Budget, error) {
+	if m.ListActiveFunc != nil {
+		return m.ListActiveFunc(ctx, userID, at)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) ListBudgets(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error) {
+	if m.ListBudgetsFunc != nil {
+		return m.ListBudgetsFunc(ctx, userID, start, end)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetRepository) ListAllBudgets(ctx context.Context, start, end time.Time) ([]models.Budget, error) {
+	if m.ListAllBudgetsFunc != nil {
+		return m.ListAllBudgetsFunc(ctx, start, end)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// Test EvaluateBudget - only the split portion allocated to the budget counts
+func TestEvaluateBudget_CountsSplitPortion(t *testing.T) {
+	groceries := &models.Budget{
+		ID:              primitive.NewObjectID(),
+		MaximumSpending: 100,
+		StartDate:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:         time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		IsMeetingBudget: true,
+	}
+	var storedStatus *bool
+
+	budgetRepo := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return groceries, nil
+		},
+		UpdateBudgetStatusFunc: func(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error {
+			storedStatus = &isMeetingBudget
+			return nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{
+				{Type: models.TransactionTypeDebit, Amount: 120, Splits: []models.TransactionSplit{
+					{Amount: 80, Category: "Groceries", BudgetID: groceries.ID},
+					{Amount: 40, Category: "Household"},
+				}},
+				{Type: models.TransactionTypeDebit, Amount: 30, BudgetID: groceries.ID},
+				{Type: models.TransactionTypeCredit, Amount: 5, BudgetID: groceries.ID},
+			}, nil
+		},
+	}
+
+	handler := handlers.NewBudgetHandler(services.NewBudgetService(budgetRepo, transactionRepo, &AllowAllAccess{Accounts: []primitive.ObjectID{primitive.NewObjectID()}}))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/budgets/:id/evaluation", handler.EvaluateBudget)
+
+	resp, err := app.Test(testRequest("GET", "/budgets/"+groceries.ID.Hex()+"/evaluation", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var eval services.BudgetEvaluation
+	if err := json.Unmarshal(body, &eval); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if eval.Spent != 105 {
+		t.Errorf("Expected spent 105, got %v", eval.Spent)
+	}
+	if eval.IsMeetingBudget {
+		t.Errorf("Expected the budget to be reported as not met")
+	}
+	if storedStatus != nil {
+		t.Errorf("Expected evaluating to leave the stored status alone")
+	}
+}
+
+// Test EvaluateBudget - Budget Not Found
+func TestEvaluateBudget_NotFound(t *testing.T) {
+	budgetRepo := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+
+	handler := handlers.NewBudgetHandler(services.NewBudgetService(budgetRepo, &MockTransactionRepository{}, &AllowAllAccess{}))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/budgets/:id/evaluation", handler.EvaluateBudget)
+
+	resp, err := app.Test(testRequest("GET", "/budgets/"+primitive.NewObjectID().Hex()+"/evaluation", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status code %d (Not Found), got %d", fiber.StatusNotFound, resp.StatusCode)
+	}
+}
+
+// Test EvaluateBudget - Budgets Without An Owner Are Forbidden
+func TestEvaluateBudget_OwnerlessForbidden(t *testing.T) {
+	budgetRepo := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return &models.Budget{ID: id, MaximumSpending: 100}, nil
+		},
+	}
+	access := services.NewAccessService(&MockAccountRepository{}, noHouseholds())
+
+	handler := handlers.NewBudgetHandler(services.NewBudgetService(budgetRepo, &MockTransactionRepository{}, access))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/budgets/:id/evaluation", handler.EvaluateBudget)
+
+	resp, err := app.Test(testRequest("GET", "/budgets/"+primitive.NewObjectID().Hex()+"/evaluation", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden), got %d", fiber.StatusForbidden, resp.StatusCode)
+	}
+}
+
+// Test EvaluateBudget - only transactions on accounts the budget's owner can read count
+func TestEvaluateBudget_ScopedToOwnerAccounts(t *testing.T) {
+	ownerAccount := primitive.NewObjectID()
+	budget := &models.Budget{ID: primitive.NewObjectID(), UserID: testUserID, MaximumSpending: 100}
+	budgetRepo := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return budget, nil
+		},
+	}
+	accounts := &MockAccountRepository{
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			if ownerID == testUserID {
+				return []primitive.ObjectID{ownerAccount}, nil
+			}
+			return nil, nil
+		},
+		ListSharedIDsFunc: func(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return nil, nil
+		},
+	}
+	var filtered []primitive.ObjectID
+	transactionRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			filtered = filter.AccountIDs
+			return nil, nil
+		},
+	}
+	access := services.NewAccessService(accounts, noHouseholds())
+
+	handler := handlers.NewBudgetHandler(services.NewBudgetService(budgetRepo, transactionRepo, access))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/budgets/:id/evaluation", handler.EvaluateBudget)
+
+	resp, err := app.Test(testRequest("GET", "/budgets/"+budget.ID.Hex()+"/evaluation", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+	if len(filtered) != 1 || filtered[0] != ownerAccount {
+		t.Errorf("Expected transactions of the owner's account %s only, got %v", ownerAccount.Hex(), filtered)
+	}
+}
+
+// Test RefreshStatuses - changed statuses are stored and an overrun raises budget.exceeded
+func TestRefreshStatuses_StoresChangesAndPublishesOverruns(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	over := models.Budget{ID: primitive.NewObjectID(), UserID: testUserID, MaximumSpending: 100, IsMeetingBudget: true}
+	steady := models.Budget{ID: primitive.NewObjectID(), UserID: testUserID, MaximumSpending: 500, IsMeetingBudget: true}
+	stored := map[primitive.ObjectID]bool{}
+	budgetRepo := &MockBudgetRepository{
+		ListAllBudgetsFunc: func(ctx context.Context, start, end time.Time) ([]models.Budget, error) {
+			if !end.Equal(now) || !start.Before(now) {
+				t.Errorf("Expected budgets running up to %v, got %v through %v", now, start, end)
+			}
+			return []models.Budget{over, steady}, nil
+		},
+		UpdateBudgetStatusFunc: func(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error {
+			stored[id] = isMeetingBudget
+			return nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{{Type: models.TransactionTypeDebit, Amount: 150, BudgetID: filter.BudgetID}}, nil
+		},
+	}
+	service := services.NewBudgetService(budgetRepo, transactionRepo, &AllowAllAccess{Accounts: []primitive.ObjectID{primitive.NewObjectID()}})
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service.SetEventBus(bus)
+
+	result, err := service.RefreshStatuses(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Failed to refresh statuses: %v", err)
+	}
+	if result.Budgets != 2 || result.Changed != 1 || result.Exceeded != 1 {
+		t.Errorf("Expected 2 budgets, 1 changed and 1 exceeded, got %+v", result)
+	}
+	if met, ok := stored[over.ID]; !ok || met {
+		t.Errorf("Expected the overrun budget to be stored as not met")
+	}
+	if _, ok := stored[steady.ID]; ok {
+		t.Errorf("Expected the unchanged budget not to be written")
+	}
+	exceeded := recorder.ofType(models.EventBudgetExceeded)
+	if len(exceeded) != 1 || exceeded[0].UserID != testUserID {
+		t.Errorf("Expected one budget.exceeded event for the owner, got %+v", exceeded)
+	}
+}
+
+// Test EvaluateBudget - reading an overrun budget publishes nothing
+func TestEvaluateBudget_PublishesNothing(t *testing.T) {
+	budget := &models.Budget{ID: primitive.NewObjectID(), UserID: testUserID, MaximumSpending: 100, IsMeetingBudget: true}
+	budgetRepo := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return budget, nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{{Type: models.TransactionTypeDebit, Amount: 150, BudgetID: budget.ID}}, nil
+		},
+	}
+	service := services.NewBudgetService(budgetRepo, transactionRepo, &AllowAllAccess{Accounts: []primitive.ObjectID{primitive.NewObjectID()}})
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service.SetEventBus(bus)
+
+	eval, err := service.Evaluate(context.Background(), testUserID, budget.ID)
+	if err != nil {
+		t.Fatalf("Failed to evaluate budget: %v", err)
+	}
+	if eval.IsMeetingBudget {
+		t.Errorf("Expected the budget to be reported as not met")
+	}
+	if len(recorder.ofType(models.EventBudgetExceeded)) != 0 {
+		t.Errorf("Expected evaluating not to publish budget.exceeded")
+	}
+}
@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockBudgetTemplateRepository is a mock implementation of repository.BudgetTemplateRepository for testing
+type MockBudgetTemplateRepository struct {
+	GetTemplateByIDFunc         func(ctx context.Context, id primitive.ObjectID) (*models.BudgetTemplate, error)
+	ListTemplatesFunc           func(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error)
+	ListActiveTemplatesFunc     func(ctx context.Context) ([]models.BudgetTemplate, error)
+	CreateTemplateFunc          func(ctx context.Context, template *models.BudgetTemplate) error
+	SaveTemplateFunc            func(ctx context.Context, template *models.BudgetTemplate) error
+	AdvanceGeneratedThroughFunc func(ctx context.Context, id primitive.ObjectID, from, to time.Time) error
+}
+
+func (m *MockBudgetTemplateRepository) GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*models.BudgetTemplate, error) {
+	if m.GetTemplateByIDFunc != nil {
+		return m.GetTemplateByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetTemplateRepository) ListTemplates(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error) {
+	if m.ListTemplatesFunc != nil {
+		return m.ListTemplatesFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetTemplateRepository) ListActiveTemplates(ctx context.Context) ([]models.BudgetTemplate, error) {
+	if m.ListActiveTemplatesFunc != nil {
+		return m.ListActiveTemplatesFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBudgetTemplateRepository) CreateTemplate(ctx context.Context, template *models.BudgetTemplate) error {
+	if m.CreateTemplateFunc != nil {
+		return m.CreateTemplateFunc(ctx, template)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBudgetTemplateRepository) SaveTemplate(ctx context.Context, template *models.BudgetTemplate) error {
+	if m.SaveTemplateFunc != nil {
+		return m.SaveTemplateFunc(ctx, template)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBudgetTemplateRepository) AdvanceGeneratedThrough(ctx context.Context, id primitive.ObjectID, from, to time.Time) error {
+	if m.AdvanceGeneratedThroughFunc != nil {
+		return m.AdvanceGeneratedThroughFunc(ctx, id, from, to)
+	}
+	return errors.New("not implemented")
+}
+
+func monthlyTemplate(userID primitive.ObjectID) models.BudgetTemplate {
+	return models.BudgetTemplate{
+		ID:              primitive.NewObjectID(),
+		UserID:          userID,
+		Name:            "Groceries",
+		Categories:      []string{"Groceries"},
+		MaximumSpending: 400,
+		Active:          true,
+		Schedule:        models.Recurrence{Frequency: models.FrequencyMonthly, DayOfMonth: 1, StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+// Test ListPeriods - reading periods never generates new ones
+func TestListPeriods_DoesNotGenerate(t *testing.T) {
+	template := monthlyTemplate(testUserID)
+	templates := &MockBudgetTemplateRepository{
+		GetTemplateByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.BudgetTemplate, error) {
+			return &template, nil
+		},
+	}
+	budgets := &MockBudgetRepository{
+		CreateBudgetFunc: func(ctx context.Context, budget *models.Budget) error {
+			t.Error("Expected no budget to be created on read")
+			return nil
+		},
+		ListByTemplateFunc: func(ctx context.Context, templateID primitive.ObjectID) ([]models.Budget, error) {
+			return nil, nil
+		},
+	}
+
+	service := services.NewBudgetTemplateService(templates, budgets, &MockAccountRepository{}, &MockTransactionRepository{})
+	handler := handlers.NewBudgetTemplateHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/budget-templates/:id/periods", handler.ListPeriods)
+
+	resp, _ := app.Test(testRequest("GET", "/budget-templates/"+template.ID.Hex()+"/periods", nil))
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+// Test Materialize - overlapping runs on one template create each period once
+func TestMaterialize_ConcurrentRuns(t *testing.T) {
+	stored := monthlyTemplate(testUserID)
+	var mu sync.Mutex
+	periods := map[time.Time]int{}
+
+	templates := &MockBudgetTemplateRepository{
+		AdvanceGeneratedThroughFunc: func(ctx context.Context, id primitive.ObjectID, from, to time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !stored.GeneratedThrough.Equal(from) {
+				return mongo.ErrNoDocuments
+			}
+			stored.GeneratedThrough = to
+			return nil
+		},
+	}
+	budgets := &MockBudgetRepository{
+		CreateBudgetFunc: func(ctx context.Context, budget *models.Budget) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if periods[budget.StartDate] > 0 {
+				return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+			}
+			periods[budget.StartDate]++
+			return nil
+		},
+	}
+	service := services.NewBudgetTemplateService(templates, budgets, &MockAccountRepository{}, &MockTransactionRepository{})
+
+	initial := stored
+	asOf := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	created := make([]int, 4)
+	var wg sync.WaitGroup
+	for i := range created {
+		run := initial
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := service.Materialize(context.Background(), &run, asOf)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			created[i] = n
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range created {
+		total += n
+	}
+	if len(periods) != 6 || total != 6 {
+		t.Errorf("Expected 6 periods created once each, got %d periods from %d creates", len(periods), total)
+	}
+	for start, count := range periods {
+		if count != 1 {
+			t.Errorf("Expected period %v once, got %d", start, count)
+		}
+	}
+}
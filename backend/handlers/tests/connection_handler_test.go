@@ -0,0 +1,622 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockConnectionRepository is a mock implementation of repository.ConnectionRepository for testing
+type MockConnectionRepository struct {
+	CreateConnectionFunc  func(ctx context.Context, connection *models.BankConnection) error
+	GetConnectionByIDFunc func(ctx context.Context, id primitive.ObjectID) (*models.BankConnection, error)
+	ListByUserFunc        func(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error)
+	ListAllFunc           func(ctx context.Context) ([]models.BankConnection, error)
+	SaveSyncStateFunc     func(ctx context.Context, id primitive.ObjectID, cursor string, syncedAt time.Time, syncErr string) error
+	DeleteConnectionFunc  func(ctx context.Context, id, userID primitive.ObjectID) error
+	ListStaleTokensFunc   func(ctx context.Context, activeKeyID string) ([]models.BankConnection, error)
+	ResealTokenFunc       func(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error
+}
+
+func (m *MockConnectionRepository) CreateConnection(ctx context.Context, connection *models.BankConnection) error {
+	if m.CreateConnectionFunc != nil {
+		return m.CreateConnectionFunc(ctx, connection)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) GetConnectionByID(ctx context.Context, id primitive.ObjectID) (*models.BankConnection, error) {
+	if m.GetConnectionByIDFunc != nil {
+		return m.GetConnectionByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error) {
+	if m.ListByUserFunc != nil {
+		return m.ListByUserFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) ListAll(ctx context.Context) ([]models.BankConnection, error) {
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) SaveSyncState(ctx context.Context, id primitive.ObjectID, cursor string, syncedAt time.Time, syncErr string) error {
+	if m.SaveSyncStateFunc != nil {
+		return m.SaveSyncStateFunc(ctx, id, cursor, syncedAt, syncErr)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) DeleteConnection(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.DeleteConnectionFunc != nil {
+		return m.DeleteConnectionFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) ListStaleTokens(ctx context.Context, activeKeyID string) ([]models.BankConnection, error) {
+	if m.ListStaleTokensFunc != nil {
+		return m.ListStaleTokensFunc(ctx, activeKeyID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockConnectionRepository) ResealToken(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+	if m.ResealTokenFunc != nil {
+		return m.ResealTokenFunc(ctx, id, previous, sealed)
+	}
+	return errors.New("not implemented")
+}
+
+// eventRecorder is an event subscriber that keeps every event it receives
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []models.Event
+}
+
+func (r *eventRecorder) HandleEvent(ctx context.Context, event models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *eventRecorder) ofType(eventType string) []models.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []models.Event
+	for _, event := range r.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// connectionStore keeps connections in memory
+func connectionStore(connections map[primitive.ObjectID]*models.BankConnection) *MockConnectionRepository {
+	var mu sync.Mutex
+	return &MockConnectionRepository{
+		CreateConnectionFunc: func(ctx context.Context, connection *models.BankConnection) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored := *connection
+			connections[connection.ID] = &stored
+			return nil
+		},
+		GetConnectionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.BankConnection, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			connection, ok := connections[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *connection
+			return &copied, nil
+		},
+		ListByUserFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var out []models.BankConnection
+			for _, connection := range connections {
+				if connection.UserID == userID {
+					out = append(out, *connection)
+				}
+			}
+			return out, nil
+		},
+		SaveSyncStateFunc: func(ctx context.Context, id primitive.ObjectID, cursor string, syncedAt time.Time, syncErr string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			connection, ok := connections[id]
+			if !ok {
+				return mongo.ErrNoDocuments
+			}
+			if syncErr != "" {
+				connection.LastError = syncErr
+				return nil
+			}
+			connection.Cursor, connection.LastSyncedAt, connection.LastError = cursor, syncedAt, ""
+			return nil
+		},
+		ListStaleTokensFunc: func(ctx context.Context, activeKeyID string) ([]models.BankConnection, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var out []models.BankConnection
+			for _, connection := range connections {
+				if connection.AccessTokenSealed != nil && connection.AccessTokenSealed.KeyID != activeKeyID {
+					out = append(out, *connection)
+				}
+			}
+			return out, nil
+		},
+		ResealTokenFunc: func(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+			mu.Lock()
+			defer mu.Unlock()
+			connection, ok := connections[id]
+			if !ok || !bytes.Equal(connection.AccessTokenSealed.Nonce, previous.Nonce) {
+				return mongo.ErrNoDocuments
+			}
+			connection.AccessTokenSealed = sealed
+			return nil
+		},
+	}
+}
+
+// syncedAccountStore upserts accounts by connection and external ID, as the unique
+// index on accounts does
+func syncedAccountStore(accounts map[string]*models.Account) *MockAccountRepository {
+	var mu sync.Mutex
+	return &MockAccountRepository{
+		UpsertExternalFunc: func(ctx context.Context, account *models.Account) (*models.Account, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			key := account.ConnectionID.Hex() + "/" + account.ExternalID
+			stored, ok := accounts[key]
+			if !ok {
+				stored = &models.Account{ID: primitive.NewObjectID(), ConnectionID: account.ConnectionID, ExternalID: account.ExternalID}
+				accounts[key] = stored
+			}
+			stored.AccountLabel, stored.AccountType, stored.AccountNumberLast4 = account.AccountLabel, account.AccountType, account.AccountNumberLast4
+			copied := *stored
+			return &copied, nil
+		},
+		SetBalancesFunc: func(ctx context.Context, id primitive.ObjectID, current, available float64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, account := range accounts {
+				if account.ID == id {
+					account.CurrentBalance, account.AvailableBalance = current, available
+					return nil
+				}
+			}
+			return mongo.ErrNoDocuments
+		},
+	}
+}
+
+// syncedTransactionStore upserts transactions by account and external ID, as the unique
+// index on transactions does, moving a pending transaction to its posted external ID.
+// Reconciled and void transactions are never changed.
+func syncedTransactionStore(transactions *[]*models.Transaction) *MockTransactionRepository {
+	var mu sync.Mutex
+	return &MockTransactionRepository{
+		GetExternalFunc: func(ctx context.Context, accountID primitive.ObjectID, externalID string) (*models.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, stored := range *transactions {
+				if stored.AccountID == accountID && stored.ExternalID == externalID {
+					copied := *stored
+					return &copied, nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		UpsertExternalFunc: func(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, stored := range *transactions {
+				if stored.AccountID != txn.AccountID {
+					continue
+				}
+				if stored.ExternalID == txn.ExternalID && (stored.IsLocked() || stored.Status == models.TransactionStatusVoid) {
+					return false, nil
+				}
+				if stored.IsLocked() || stored.Status == models.TransactionStatusVoid {
+					continue
+				}
+				if stored.ExternalID == txn.ExternalID || (pendingExternalID != "" && stored.ExternalID == pendingExternalID) {
+					stored.ExternalID, stored.Name, stored.Status = txn.ExternalID, txn.Name, txn.Status
+					stored.Amount, stored.Type, stored.TransactionPosted = txn.Amount, txn.Type, txn.TransactionPosted
+					return false, nil
+				}
+			}
+			if txn.ID.IsZero() {
+				txn.ID = primitive.NewObjectID()
+			}
+			created := *txn
+			*transactions = append(*transactions, &created)
+			return true, nil
+		},
+	}
+}
+
+// profileAccounts keeps the accounts added to user's profile
+func profileAccounts(user *models.User) *MockUserRepository {
+	var mu sync.Mutex
+	return &MockUserRepository{
+		AddAccountFunc: func(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if id != user.ID {
+				return mongo.ErrNoDocuments
+			}
+			if !slices.Contains(user.Accounts, accountID.Hex()) {
+				user.Accounts = append(user.Accounts, accountID.Hex())
+			}
+			return nil
+		},
+	}
+}
+
+// newSyncService syncs from a fake bank whose day is read from now
+func newSyncService(t *testing.T, connections *MockConnectionRepository, accounts *MockAccountRepository, transactions *MockTransactionRepository, users *MockUserRepository, now *time.Time) *services.SyncService {
+	t.Helper()
+	keys, err := services.NewLocalKeyProvider("2026-10", map[string][]byte{"2026-10": bytes.Repeat([]byte{4}, 32)})
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	service := services.NewSyncService(connections, accounts, transactions, users, services.NewFieldCipher(keys))
+	service.RegisterProvider(services.NewFakeBankProvider(func() time.Time { return *now }))
+	return service
+}
+
+func newConnectionApp(service *services.SyncService) *fiber.App {
+	handler := handlers.NewConnectionHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/connections", handler.GetConnections)
+	app.Post("/connections", handler.CreateConnection)
+	app.Post("/connections/sync", handler.SyncAll)
+	app.Post("/connections/:id/sync", handler.SyncConnection)
+	app.Delete("/connections/:id", handler.DeleteConnection)
+	return app
+}
+
+func connectFakeBank(t *testing.T, service *services.SyncService, userID primitive.ObjectID) *services.ConnectResult {
+	t.Helper()
+	result, err := service.Connect(context.Background(), userID, services.FakeBankProviderName, "sandbox-user")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return result
+}
+
+// Test CreateConnection - the first sync imports the bank's accounts onto the profile
+func TestCreateConnection_ImportsAccounts(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+
+	rec := userRequest(t, newConnectionApp(service), user.ID, "POST", "/connections", handlers.ConnectionPayload{Provider: services.FakeBankProviderName, AccessToken: "sandbox-user"})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result services.ConnectResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &result)
+	if result.Sync == nil || result.Sync.Accounts != 3 || result.Sync.Failures != 0 {
+		t.Fatalf("Unexpected first sync %+v", result.Sync)
+	}
+	if len(user.Accounts) != 3 {
+		t.Errorf("Expected three accounts on the profile, got %d", len(user.Accounts))
+	}
+}
+
+// Test CreateConnection - the first sync imports history as positive debits and credits
+func TestCreateConnection_ImportsHistory(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+
+	result := connectFakeBank(t, service, user.ID)
+	if len(transactions) == 0 || result.Sync.Created != len(transactions) {
+		t.Fatalf("Expected the history to be created, got %d created of %d", result.Sync.Created, len(transactions))
+	}
+	for _, txn := range transactions {
+		if txn.Amount <= 0 || (txn.Type != models.TransactionTypeDebit && txn.Type != models.TransactionTypeCredit) {
+			t.Errorf("Expected a positive debit or credit, got %+v", txn)
+		}
+	}
+}
+
+// Test CreateConnection - the access token is stored sealed with a cursor at yesterday
+func TestCreateConnection_SealsAccessToken(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	connections := map[primitive.ObjectID]*models.BankConnection{}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(connections), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+
+	result := connectFakeBank(t, service, user.ID)
+	stored := connections[result.Connection.ID]
+	if stored.AccessTokenSealed == nil || bytes.Contains(stored.AccessTokenSealed.Ciphertext, []byte("sandbox-user")) {
+		t.Errorf("Expected a sealed access token, got %+v", stored.AccessTokenSealed)
+	}
+	if stored.Cursor != "2026-10-17" {
+		t.Errorf("Expected the cursor at 2026-10-17, got %q", stored.Cursor)
+	}
+}
+
+// Test CreateConnection - unknown providers are refused before anything is stored
+func TestCreateConnection_UnknownProvider(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	connections := map[primitive.ObjectID]*models.BankConnection{}
+	service := newSyncService(t, connectionStore(connections), &MockAccountRepository{}, &MockTransactionRepository{}, &MockUserRepository{}, &now)
+
+	rec := userRequest(t, newConnectionApp(service), testUserID, "POST", "/connections", handlers.ConnectionPayload{Provider: "nowhere", AccessToken: "x"})
+	if rec.Code != fiber.StatusBadRequest || len(connections) != 0 {
+		t.Errorf("Expected status 400 with nothing stored, got %d", rec.Code)
+	}
+}
+
+// Test SyncConnection - other users cannot sync a connection
+func TestSyncConnection_Forbidden(t *testing.T) {
+	connection := &models.BankConnection{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Provider: services.FakeBankProviderName}
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{connection.ID: connection}),
+		&MockAccountRepository{}, &MockTransactionRepository{}, &MockUserRepository{}, &now)
+
+	rec := userRequest(t, newConnectionApp(service), testUserID, "POST", "/connections/"+connection.ID.Hex()+"/sync", nil)
+	if rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rec.Code)
+	}
+}
+
+// Test SyncConnection - syncing again creates nothing
+func TestSyncConnection_Idempotent(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	result := connectFakeBank(t, service, user.ID)
+	total := len(transactions)
+
+	rec := userRequest(t, newConnectionApp(service), user.ID, "POST", "/connections/"+result.Connection.ID.Hex()+"/sync", nil)
+	var again services.SyncResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &again)
+	if rec.Code != fiber.StatusOK || again.Created != 0 || len(transactions) != total || len(user.Accounts) != 3 {
+		t.Errorf("Expected a repeat sync to create nothing, got %d %+v with %d transactions", rec.Code, again, len(transactions))
+	}
+}
+
+// Test SyncAll - pending transactions are posted in place the next day
+func TestSyncAll_PostsPendingInPlace(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	connectFakeBank(t, service, user.ID)
+	pending := 0
+	for _, txn := range transactions {
+		if txn.Status == models.TransactionStatusPending {
+			pending++
+		}
+	}
+	if pending == 0 {
+		t.Fatalf("Expected pending transactions from today")
+	}
+
+	now = now.AddDate(0, 0, 1)
+	if rec := userRequest(t, newConnectionApp(service), user.ID, "POST", "/connections/sync", nil); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	posted := 0
+	for _, txn := range transactions {
+		if txn.TransactionDate.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+			if txn.Status != models.TransactionStatusPosted || txn.TransactionPosted.IsZero() {
+				t.Errorf("Expected yesterday's pending transaction to post, got %+v", txn)
+			}
+			posted++
+		}
+	}
+	if posted != pending {
+		t.Errorf("Expected %d posted transactions in place of the pending ones, got %d", pending, posted)
+	}
+}
+
+// Test Sync - a posted transaction settling a reconciled pending one is skipped, not added beside it
+func TestSync_SkipsPostingLockedPending(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	connected := connectFakeBank(t, service, user.ID)
+	locked := 0
+	for _, txn := range transactions {
+		if txn.Status == models.TransactionStatusPending {
+			txn.ReconciliationID = primitive.NewObjectID()
+			locked++
+		}
+	}
+	if locked == 0 {
+		t.Fatalf("Expected pending transactions from today")
+	}
+	before := len(transactions)
+
+	now = now.AddDate(0, 0, 1)
+	result, err := service.Sync(context.Background(), user.ID, connected.Connection.ID)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if result.Skipped != locked || result.Failures != 0 {
+		t.Errorf("Expected %d skipped and no failures, got %+v", locked, result)
+	}
+	for _, txn := range transactions[before:] {
+		if txn.TransactionDate.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected no second copy of a reconciled pending transaction, got %+v", txn)
+		}
+	}
+	for _, txn := range transactions[:before] {
+		if txn.IsLocked() && txn.Status != models.TransactionStatusPending {
+			t.Errorf("Expected the reconciled transaction to stay pending, got %+v", txn)
+		}
+	}
+}
+
+// Test Sync - each newly synced transaction raises transaction.created for its user
+func TestSync_PublishesCreatedTransactions(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service.SetEventBus(bus)
+
+	connectFakeBank(t, service, user.ID)
+	created := recorder.ofType(models.EventTransactionCreated)
+	if len(created) != len(transactions) {
+		t.Fatalf("Expected one event per synced transaction, got %d for %d", len(created), len(transactions))
+	}
+	for i, event := range created {
+		txn, ok := event.Data.(models.Transaction)
+		if !ok || txn.ID != transactions[i].ID || event.UserID != user.ID {
+			t.Errorf("Expected event %d about transaction %s for the user, got %+v", i, transactions[i].ID.Hex(), event)
+		}
+	}
+}
+
+// Test Sync - a repeat sync raises no events
+func TestSync_RepeatPublishesNothing(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	result := connectFakeBank(t, service, user.ID)
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service.SetEventBus(bus)
+
+	if _, err := service.Sync(context.Background(), user.ID, result.Connection.ID); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("Expected no events, got %d", len(recorder.events))
+	}
+}
+
+// Test Sync - overlapping syncs of one connection create each transaction once
+func TestSync_ConcurrentSyncs(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	result := connectFakeBank(t, service, user.ID)
+	before := len(transactions)
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service.SetEventBus(bus)
+
+	now = now.AddDate(0, 0, 7)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Sync(context.Background(), user.ID, result.Connection.ID); err != nil {
+				t.Errorf("Sync failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, txn := range transactions {
+		key := txn.AccountID.Hex() + "/" + txn.ExternalID
+		if seen[key] {
+			t.Errorf("Expected transaction %s once", key)
+		}
+		seen[key] = true
+	}
+	if created := recorder.ofType(models.EventTransactionCreated); len(transactions) == before || len(created) != len(transactions)-before {
+		t.Errorf("Expected one event for each of the %d new transactions, got %d", len(transactions)-before, len(created))
+	}
+}
+
+// Test Sync - a synced payment marks the bill it pays as paid
+func TestSync_SyncedPaymentPaysBill(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	accounts := map[string]*models.Account{}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(accounts),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	result := connectFakeBank(t, service, user.ID)
+
+	var checking primitive.ObjectID
+	for _, account := range accounts {
+		if account.AccountType == models.AccountTypeChecking {
+			checking = account.ID
+		}
+	}
+	due := time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC)
+	bill := models.Bill{ID: primitive.NewObjectID(), UserID: user.ID, Payee: "Maple Apartments", Amount: 1450, AccountID: checking, Active: true,
+		Schedule: models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: due}}
+	var payments []models.BillPayment
+	bills := &MockBillRepository{
+		ListActiveByAccountFunc: func(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error) {
+			if accountID != checking {
+				return nil, nil
+			}
+			return []models.Bill{bill}, nil
+		},
+		RecordPaymentFunc: func(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error {
+			payments = append(payments, payment)
+			return nil
+		},
+	}
+	bus := services.NewEventBus()
+	bus.Subscribe(services.NewBillService(bills, &AllowAllAccess{}))
+	service.SetEventBus(bus)
+
+	now = time.Date(2026, 11, 5, 15, 0, 0, 0, time.UTC)
+	if _, err := service.Sync(context.Background(), user.ID, result.Connection.ID); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(payments) != 1 || !payments[0].DueDate.Equal(due) || payments[0].TransactionID.IsZero() {
+		t.Errorf("Expected the November rent to be paid by the synced transaction, got %+v", payments)
+	}
+}
@@ -0,0 +1,277 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockCreditRepository is a mock implementation of repository.CreditRepository for testing
+type MockCreditRepository struct {
+	ListReadingsFunc  func(ctx context.Context, userID primitive.ObjectID) ([]models.CreditScoreReading, error)
+	CreateReadingFunc func(ctx context.Context, reading *models.CreditScoreReading) error
+	GetSettingsFunc   func(ctx context.Context, userID primitive.ObjectID) (*models.CreditSettings, error)
+	SaveSettingsFunc  func(ctx context.Context, settings *models.CreditSettings) error
+}
+
+func (m *MockCreditRepository) ListReadings(ctx context.Context, userID primitive.ObjectID) ([]models.CreditScoreReading, error) {
+	if m.ListReadingsFunc != nil {
+		return m.ListReadingsFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockCreditRepository) CreateReading(ctx context.Context, reading *models.CreditScoreReading) error {
+	if m.CreateReadingFunc != nil {
+		return m.CreateReadingFunc(ctx, reading)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockCreditRepository) GetSettings(ctx context.Context, userID primitive.ObjectID) (*models.CreditSettings, error) {
+	if m.GetSettingsFunc != nil {
+		return m.GetSettingsFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockCreditRepository) SaveSettings(ctx context.Context, settings *models.CreditSettings) error {
+	if m.SaveSettingsFunc != nil {
+		return m.SaveSettingsFunc(ctx, settings)
+	}
+	return errors.New("not implemented")
+}
+
+// creditStore keeps score readings in readings and each user's credit settings
+func creditStore(readings *[]models.CreditScoreReading) *MockCreditRepository {
+	settings := map[primitive.ObjectID]*models.CreditSettings{}
+	return &MockCreditRepository{
+		ListReadingsFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.CreditScoreReading, error) {
+			var out []models.CreditScoreReading
+			for _, reading := range *readings {
+				if reading.UserID == userID {
+					out = append(out, reading)
+				}
+			}
+			return out, nil
+		},
+		CreateReadingFunc: func(ctx context.Context, reading *models.CreditScoreReading) error {
+			reading.ID = primitive.NewObjectID()
+			*readings = append(*readings, *reading)
+			return nil
+		},
+		GetSettingsFunc: func(ctx context.Context, userID primitive.ObjectID) (*models.CreditSettings, error) {
+			saved, ok := settings[userID]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			return saved, nil
+		},
+		SaveSettingsFunc: func(ctx context.Context, saved *models.CreditSettings) error {
+			settings[saved.UserID] = saved
+			return nil
+		},
+	}
+}
+
+// ownedCards serves cards as the accounts of ownerID
+func ownedCards(ownerID primitive.ObjectID, cards ...models.Account) *MockAccountRepository {
+	accounts := map[primitive.ObjectID]models.Account{}
+	var accountIDs []primitive.ObjectID
+	for _, card := range cards {
+		card.OwnerID = ownerID
+		accounts[card.ID] = card
+		accountIDs = append(accountIDs, card.ID)
+	}
+	return &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			account, ok := accounts[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			return &account, nil
+		},
+		ListOwnedIDsFunc: func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+			if id != ownerID {
+				return nil, nil
+			}
+			return accountIDs, nil
+		},
+	}
+}
+
+// profileScore keeps the credit score stored on the profile in score
+func profileScore(score *int) *MockUserRepository {
+	return &MockUserRepository{
+		UpdateCreditScoreFunc: func(ctx context.Context, id primitive.ObjectID, value int) error {
+			*score = value
+			return nil
+		},
+	}
+}
+
+func newCreditApp(credits *MockCreditRepository, users *MockUserRepository, accounts *MockAccountRepository) *fiber.App {
+	handler := handlers.NewCreditHandler(services.NewCreditService(credits, users, accounts, services.NewAccessService(accounts, noHouseholds())))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/credit/scores", handler.RecordScore)
+	app.Get("/credit/utilization", handler.GetUtilization)
+	app.Put("/credit/thresholds", handler.SetThresholds)
+	app.Put("/accounts/:id/credit-limit", handler.SetCreditLimit)
+	return app
+}
+
+// utilizationCards are a card at 80% of its limit, a card at under 7% and a checking account
+func utilizationCards() (high, low, checking models.Account) {
+	high = models.Account{ID: primitive.NewObjectID(), AccountLabel: "Travel Card", AccountType: models.AccountTypeCreditCard, CurrentBalance: -800, CreditLimit: 1000}
+	low = models.Account{ID: primitive.NewObjectID(), AccountLabel: "Cash Card", AccountType: models.AccountTypeCreditCard, CurrentBalance: -200, CreditLimit: 3000}
+	checking = models.Account{ID: primitive.NewObjectID(), AccountType: models.AccountTypeChecking, CurrentBalance: 5000}
+	return high, low, checking
+}
+
+func utilizationReport(t *testing.T, app *fiber.App, userID primitive.ObjectID) services.UtilizationReport {
+	t.Helper()
+	rec := userRequest(t, app, userID, "GET", "/credit/utilization", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report services.UtilizationReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return report
+}
+
+// userRequest sends payload as JSON on behalf of userID and records the response
+func userRequest(t *testing.T, app *fiber.App, userID primitive.ObjectID, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var body io.Reader
+	if payload != nil {
+		data, _ := json.Marshal(payload)
+		body = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID.Hex())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	recorder.Code = resp.StatusCode
+	respBody, _ := io.ReadAll(resp.Body)
+	recorder.Body.Write(respBody)
+	return recorder
+}
+
+// Test RecordScore - only the newest reading updates the profile score
+func TestCredit_RecordScoreUpdatesProfileWhenNewest(t *testing.T) {
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID))
+	today := time.Now().UTC().Format("2006-01-02")
+	lastYear := time.Now().UTC().AddDate(-1, 0, 0).Format("2006-01-02")
+
+	if rec := userRequest(t, app, testUserID, "POST", "/credit/scores", handlers.CreditScorePayload{Bureau: "Experian", Score: 720, ReportedAt: today}); rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := userRequest(t, app, testUserID, "POST", "/credit/scores", handlers.CreditScorePayload{Bureau: "experian", Score: 650, ReportedAt: lastYear}); rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if score != 720 {
+		t.Errorf("Expected profile score to stay at the newest reading 720, got %d", score)
+	}
+	if len(readings) != 2 {
+		t.Errorf("Expected 2 readings stored, got %d", len(readings))
+	}
+}
+
+// Test RecordScore - out-of-range scores are rejected
+func TestCredit_RecordScoreOutOfRange(t *testing.T) {
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID))
+
+	rec := userRequest(t, app, testUserID, "POST", "/credit/scores", handlers.CreditScorePayload{Bureau: "experian", Score: 900})
+
+	if rec.Code != fiber.StatusBadRequest || len(readings) != 0 {
+		t.Errorf("Expected status 400 and nothing stored, got %d with %d readings", rec.Code, len(readings))
+	}
+}
+
+// Test RecordScore - unknown bureaus are rejected
+func TestCredit_RecordScoreUnknownBureau(t *testing.T) {
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID))
+
+	rec := userRequest(t, app, testUserID, "POST", "/credit/scores", handlers.CreditScorePayload{Bureau: "fico-ish", Score: 700})
+
+	if rec.Code != fiber.StatusBadRequest || len(readings) != 0 {
+		t.Errorf("Expected status 400 and nothing stored, got %d with %d readings", rec.Code, len(readings))
+	}
+}
+
+// Test GetUtilization - per-card and overall ratios raise alerts past the default thresholds
+func TestCredit_UtilizationAlerts(t *testing.T) {
+	high, low, checking := utilizationCards()
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID, high, low, checking))
+
+	report := utilizationReport(t, app, testUserID)
+
+	if len(report.Cards) != 2 {
+		t.Fatalf("Expected 2 credit cards, got %d", len(report.Cards))
+	}
+	if report.Utilization != 0.25 {
+		t.Errorf("Expected overall utilization 0.25, got %v", report.Utilization)
+	}
+	if len(report.Alerts) != 1 || report.Alerts[0].AccountID != high.ID || report.Alerts[0].Threshold != 0.75 {
+		t.Errorf("Expected a single 75%% alert on the travel card, got %+v", report.Alerts)
+	}
+}
+
+// Test SetThresholds - lower thresholds raise the card and overall alerts
+func TestCredit_SetThresholdsChangesAlerts(t *testing.T) {
+	high, low, checking := utilizationCards()
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID, high, low, checking))
+
+	rec := userRequest(t, app, testUserID, "PUT", "/credit/thresholds", handlers.CreditThresholdsPayload{Thresholds: []float64{0.2}})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if report := utilizationReport(t, app, testUserID); len(report.Alerts) != 2 {
+		t.Errorf("Expected card and overall alerts at a 20%% threshold, got %+v", report.Alerts)
+	}
+}
+
+// Test SetCreditLimit - only the card's owner may change its limit
+func TestCredit_SetCreditLimitForbiddenForOthers(t *testing.T) {
+	card := models.Account{ID: primitive.NewObjectID(), AccountLabel: "Travel Card", AccountType: models.AccountTypeCreditCard, CreditLimit: 1000}
+	var readings []models.CreditScoreReading
+	var score int
+	app := newCreditApp(creditStore(&readings), profileScore(&score), ownedCards(testUserID, card))
+
+	rec := userRequest(t, app, primitive.NewObjectID(), "PUT", "/accounts/"+card.ID.Hex()+"/credit-limit", handlers.CreditLimitPayload{CreditLimit: 50000})
+	if rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden), got %d", fiber.StatusForbidden, rec.Code)
+	}
+}
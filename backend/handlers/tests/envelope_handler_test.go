@@ -0,0 +1,255 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockEnvelopeRepository is a mock implementation of repository.EnvelopeRepository for testing
+type MockEnvelopeRepository struct {
+	GetEnvelopeByIDFunc func(ctx context.Context, id primitive.ObjectID) (*models.Envelope, error)
+	ListEnvelopesFunc   func(ctx context.Context, userID primitive.ObjectID) ([]models.Envelope, error)
+	CreateEnvelopeFunc  func(ctx context.Context, envelope *models.Envelope) error
+	ListEntriesFunc     func(ctx context.Context, userID primitive.ObjectID, throughMonth string) ([]models.EnvelopeEntry, error)
+	CreateEntriesFunc   func(ctx context.Context, entries []models.EnvelopeEntry) error
+}
+
+func (m *MockEnvelopeRepository) GetEnvelopeByID(ctx context.Context, id primitive.ObjectID) (*models.Envelope, error) {
+	if m.GetEnvelopeByIDFunc != nil {
+		return m.GetEnvelopeByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockEnvelopeRepository) ListEnvelopes(ctx context.Context, userID primitive.ObjectID) ([]models.Envelope, error) {
+	if m.ListEnvelopesFunc != nil {
+		return m.ListEnvelopesFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockEnvelopeRepository) CreateEnvelope(ctx context.Context, envelope *models.Envelope) error {
+	if m.CreateEnvelopeFunc != nil {
+		return m.CreateEnvelopeFunc(ctx, envelope)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockEnvelopeRepository) ListEntries(ctx context.Context, userID primitive.ObjectID, throughMonth string) ([]models.EnvelopeEntry, error) {
+	if m.ListEntriesFunc != nil {
+		return m.ListEntriesFunc(ctx, userID, throughMonth)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockEnvelopeRepository) CreateEntries(ctx context.Context, entries []models.EnvelopeEntry) error {
+	if m.CreateEntriesFunc != nil {
+		return m.CreateEntriesFunc(ctx, entries)
+	}
+	return errors.New("not implemented")
+}
+
+// envelopeStore serves envelopes and keeps their entries in entries
+func envelopeStore(envelopes []models.Envelope, entries *[]models.EnvelopeEntry) *MockEnvelopeRepository {
+	return &MockEnvelopeRepository{
+		GetEnvelopeByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Envelope, error) {
+			for i := range envelopes {
+				if envelopes[i].ID == id {
+					return &envelopes[i], nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		ListEnvelopesFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.Envelope, error) {
+			return envelopes, nil
+		},
+		ListEntriesFunc: func(ctx context.Context, userID primitive.ObjectID, throughMonth string) ([]models.EnvelopeEntry, error) {
+			var out []models.EnvelopeEntry
+			for _, entry := range *entries {
+				if entry.Month <= throughMonth {
+					out = append(out, entry)
+				}
+			}
+			return out, nil
+		},
+		CreateEntriesFunc: func(ctx context.Context, created []models.EnvelopeEntry) error {
+			*entries = append(*entries, created...)
+			return nil
+		},
+	}
+}
+
+// envelopeBudget is testUserID's groceries and dining envelopes with 400 and 100
+// assigned in January, then 300 and 100 in February
+type envelopeBudget struct {
+	groceries, dining models.Envelope
+	entries           []models.EnvelopeEntry
+}
+
+func newEnvelopeBudget() *envelopeBudget {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &envelopeBudget{
+		groceries: models.Envelope{ID: primitive.NewObjectID(), UserID: testUserID, Name: "Groceries", Category: "Groceries", CreatedAt: jan},
+		dining:    models.Envelope{ID: primitive.NewObjectID(), UserID: testUserID, Name: "Eating Out", Category: "Dining", CreatedAt: jan},
+	}
+	assign := func(envelope models.Envelope, month string, amount float64) models.EnvelopeEntry {
+		return models.EnvelopeEntry{UserID: testUserID, EnvelopeID: envelope.ID, Month: month, Kind: models.EnvelopeEntryAssign, Amount: amount}
+	}
+	b.entries = []models.EnvelopeEntry{
+		assign(b.groceries, "2026-01", 400), assign(b.dining, "2026-01", 100),
+		assign(b.groceries, "2026-02", 300), assign(b.dining, "2026-02", 100),
+	}
+	return b
+}
+
+// newEnvelopeApp serves the budget over a checking account paid 3000 in January that spent
+// 350 on groceries and 150 dining, then in February 120 dining and a 60/40 groceries split
+func newEnvelopeApp(b *envelopeBudget) *fiber.App {
+	checking := primitive.NewObjectID()
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	txn := func(day time.Time, txnType, category string, amount float32) models.Transaction {
+		return models.Transaction{AccountID: checking, Type: txnType, Category: category, Amount: amount, TransactionDate: day}
+	}
+	txns := []models.Transaction{
+		txn(jan.AddDate(0, 0, 1), models.TransactionTypeCredit, "Salary", 3000),
+		txn(jan.AddDate(0, 0, 5), models.TransactionTypeDebit, "Groceries", 350),
+		txn(jan.AddDate(0, 0, 9), models.TransactionTypeDebit, "dining", 150),
+		txn(jan.AddDate(0, 1, 3), models.TransactionTypeDebit, "Dining", 120),
+		{AccountID: checking, Type: models.TransactionTypeDebit, Amount: 100, TransactionDate: jan.AddDate(0, 1, 7), Splits: []models.TransactionSplit{
+			{Amount: 60, Category: "Groceries"},
+			{Amount: 40, Category: "Household"},
+		}},
+	}
+	accounts := &MockAccountRepository{
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return []primitive.ObjectID{checking}, nil
+		},
+	}
+	transactions := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return txns, nil
+		},
+	}
+
+	envelopes := envelopeStore([]models.Envelope{b.groceries, b.dining}, &b.entries)
+	handler := handlers.NewEnvelopeHandler(services.NewEnvelopeService(envelopes, accounts, transactions))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/envelopes", handler.GetMonth)
+	app.Post("/envelopes/moves", handler.Move)
+	app.Post("/envelopes/:id/assign", handler.Assign)
+	return app
+}
+
+func decodeEnvelopeBudget(t *testing.T, body []byte) (services.EnvelopeBudget, map[primitive.ObjectID]services.EnvelopeMonth) {
+	t.Helper()
+	var budget services.EnvelopeBudget
+	if err := json.Unmarshal(body, &budget); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	envelopes := map[primitive.ObjectID]services.EnvelopeMonth{}
+	for _, envelope := range budget.Envelopes {
+		envelopes[envelope.EnvelopeID] = envelope
+	}
+	return budget, envelopes
+}
+
+// february fetches the February envelope budget
+func february(t *testing.T, app *fiber.App) (services.EnvelopeBudget, map[primitive.ObjectID]services.EnvelopeMonth) {
+	t.Helper()
+	rec := userRequest(t, app, testUserID, "GET", "/envelopes?month=2026-02", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	return decodeEnvelopeBudget(t, rec.Body.Bytes())
+}
+
+// Test GetMonth - income less everything assigned is ready to assign; unmatched categories are unbudgeted
+func TestEnvelopes_ReadyToAssign(t *testing.T) {
+	budget, _ := february(t, newEnvelopeApp(newEnvelopeBudget()))
+
+	if budget.ReadyToAssign != 2100 || budget.Unbudgeted != 40 {
+		t.Errorf("Expected 2100 ready to assign and 40 unbudgeted, got %v and %v", budget.ReadyToAssign, budget.Unbudgeted)
+	}
+}
+
+// Test GetMonth - leftover amounts roll into the next month
+func TestEnvelopes_LeftoverRollsOver(t *testing.T) {
+	b := newEnvelopeBudget()
+	_, envelopes := february(t, newEnvelopeApp(b))
+
+	if groceries := envelopes[b.groceries.ID]; groceries.Carryover != 50 || groceries.Spent != 60 || groceries.Available != 290 {
+		t.Errorf("Expected groceries 50 carried, 60 spent, 290 available, got %+v", groceries)
+	}
+}
+
+// Test GetMonth - overspending carries into the next month as a negative amount
+func TestEnvelopes_OverspendingRollsOver(t *testing.T) {
+	b := newEnvelopeBudget()
+	_, envelopes := february(t, newEnvelopeApp(b))
+
+	if dining := envelopes[b.dining.ID]; dining.Carryover != -50 || dining.Available != -70 || !dining.Overspent {
+		t.Errorf("Expected dining to carry -50 and be overspent at -70, got %+v", dining)
+	}
+}
+
+// Test Move - moving money covers an overspent envelope
+func TestEnvelopes_MoveCoversOverspending(t *testing.T) {
+	b := newEnvelopeBudget()
+	app := newEnvelopeApp(b)
+
+	rec := userRequest(t, app, testUserID, "POST", "/envelopes/moves", handlers.EnvelopeMovePayload{
+		FromID: b.groceries.ID.Hex(), ToID: b.dining.ID.Hex(), Month: "2026-02", Amount: 70,
+	})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if _, envelopes := decodeEnvelopeBudget(t, rec.Body.Bytes()); envelopes[b.groceries.ID].Available != 220 || envelopes[b.dining.ID].Available != 0 {
+		t.Errorf("Expected groceries 220 and dining 0 after the move, got %+v", envelopes)
+	}
+}
+
+// Test Move - the source envelope can't be overdrawn
+func TestEnvelopes_MoveRefusesOverdraw(t *testing.T) {
+	b := newEnvelopeBudget()
+	app := newEnvelopeApp(b)
+
+	rec := userRequest(t, app, testUserID, "POST", "/envelopes/moves", handlers.EnvelopeMovePayload{
+		FromID: b.groceries.ID.Hex(), ToID: b.dining.ID.Hex(), Month: "2026-02", Amount: 500,
+	})
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status 409 when overdrawing, got %d", rec.Code)
+	}
+	if len(b.entries) != 4 {
+		t.Errorf("Expected no entries to be added, got %d", len(b.entries)-4)
+	}
+}
+
+// Test Assign - assigning reduces ready to assign
+func TestEnvelopes_Assign(t *testing.T) {
+	b := newEnvelopeBudget()
+	app := newEnvelopeApp(b)
+
+	rec := userRequest(t, app, testUserID, "POST", "/envelopes/"+b.dining.ID.Hex()+"/assign", handlers.EnvelopeAssignPayload{Month: "2026-02", Amount: 100})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if budget, _ := decodeEnvelopeBudget(t, rec.Body.Bytes()); budget.ReadyToAssign != 2000 {
+		t.Errorf("Expected 2000 ready to assign, got %v", budget.ReadyToAssign)
+	}
+}
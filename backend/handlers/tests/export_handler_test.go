@@ -0,0 +1,290 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockNetWorthRepository is a mock implementation of repository.NetWorthRepository for testing
+type MockNetWorthRepository struct {
+	SaveSnapshotFunc  func(ctx context.Context, snapshot *models.NetWorthSnapshot) error
+	ListSnapshotsFunc func(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.NetWorthSnapshot, error)
+}
+
+func (m *MockNetWorthRepository) SaveSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error {
+	if m.SaveSnapshotFunc != nil {
+		return m.SaveSnapshotFunc(ctx, snapshot)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockNetWorthRepository) ListSnapshots(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.NetWorthSnapshot, error) {
+	if m.ListSnapshotsFunc != nil {
+		return m.ListSnapshotsFunc(ctx, userID, start, end)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// exportStatement streams a payroll credit to checking, and a posted and a pending card
+// purchase, recording each stream's filter in streamed
+func exportStatement(checking, card primitive.ObjectID, streamed *[]repository.TransactionFilter) *MockTransactionRepository {
+	txns := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: checking, AccountNumber: "****4821", Name: "Payroll", Category: "Income", Type: models.TransactionTypeCredit,
+			Amount: 2150, TransactionDate: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), TransactionPosted: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: primitive.NewObjectID(), AccountID: card, Name: "=HYPERLINK(\"http://evil\")", Category: "Shopping", Type: models.TransactionTypeDebit,
+			Amount: 19.99, TransactionDate: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), Tags: []string{"gift", "holiday"}},
+		{ID: primitive.NewObjectID(), AccountID: card, Name: "Corner Grocery & Deli Of The Upper West Side", Category: "Groceries", Type: models.TransactionTypeDebit,
+			Status: models.TransactionStatusPending, Amount: 42.5, TransactionDate: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Description: "Milk <2%>"},
+	}
+	return &MockTransactionRepository{
+		StreamTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter, fn func(txn *models.Transaction) error) error {
+			*streamed = append(*streamed, filter)
+			for i := range txns {
+				txn := txns[i]
+				if !slices.Contains(filter.AccountIDs, txn.AccountID) || (filter.Status != "" && txn.EffectiveStatus() != filter.Status) {
+					continue
+				}
+				if err := fn(&txn); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func exportAccounts(checking, card primitive.ObjectID) *MockAccountRepository {
+	return &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			switch id {
+			case checking:
+				return &models.Account{ID: id, AccountLabel: "Everyday Checking", AccountType: models.AccountTypeChecking, CurrentBalance: 3120.55}, nil
+			case card:
+				return &models.Account{ID: id, AccountLabel: "Rewards Card", AccountType: models.AccountTypeCreditCard, CurrentBalance: -62.49}, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+}
+
+func newExportApp(transactions *MockTransactionRepository, accounts *MockAccountRepository, budgets *MockBudgetRepository, snapshots *MockNetWorthRepository, access *AllowAllAccess) *fiber.App {
+	handler := handlers.NewExportHandler(services.NewExportService(transactions, accounts, budgets, snapshots, access))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/exports/transactions", handler.ExportTransactions)
+	app.Get("/exports/budgets", handler.ExportBudgetReport)
+	app.Get("/exports/net-worth", handler.ExportNetWorthReport)
+	return app
+}
+
+// newStatementApp exports exportStatement over a checking account and a card
+func newStatementApp(streamed *[]repository.TransactionFilter) (app *fiber.App, checking, card primitive.ObjectID) {
+	checking, card = primitive.NewObjectID(), primitive.NewObjectID()
+	app = newExportApp(exportStatement(checking, card, streamed), exportAccounts(checking, card), &MockBudgetRepository{}, &MockNetWorthRepository{},
+		&AllowAllAccess{Accounts: []primitive.ObjectID{checking, card}})
+	return app, checking, card
+}
+
+// download performs a GET and returns the response with its whole body
+func download(t *testing.T, app *fiber.App, path string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("X-User-ID", testUserID.Hex())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+// Test ExportTransactions - CSV is the default download
+func TestExportTransactions_CSV(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	resp, body := download(t, app, "/exports/transactions")
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "text/csv; charset=utf-8" ||
+		!strings.Contains(resp.Header.Get(fiber.HeaderContentDisposition), "transactions.csv") {
+		t.Fatalf("Expected a CSV download, got %d %v", resp.StatusCode, resp.Header)
+	}
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil || len(records) != 4 {
+		t.Fatalf("Expected a header and three rows, got %v: %q", err, body)
+	}
+	if got := strings.Join(records[1][1:], "|"); got != "2026-09-30|2026-10-01|Everyday Checking|****4821|Payroll|Income|credit|posted|2150.00||" {
+		t.Errorf("Unexpected payroll row: %s", got)
+	}
+}
+
+// Test ExportTransactions - CSV cells that start a formula are escaped and debits are signed
+func TestExportTransactions_CSVEscapesFormulas(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	_, body := download(t, app, "/exports/transactions")
+	records, _ := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if len(records) != 4 {
+		t.Fatalf("Expected a header and three rows, got %q", body)
+	}
+	if shopping := records[2]; shopping[5] != `'=HYPERLINK("http://evil")` || shopping[9] != "-19.99" || shopping[10] != "gift;holiday" {
+		t.Errorf("Expected an escaped formula, a signed amount and tags, got %q", shopping)
+	}
+}
+
+// Test ExportTransactions - every account is exported from a single stream
+func TestExportTransactions_StreamsOnce(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	download(t, app, "/exports/transactions")
+	if len(streamed) != 1 || len(streamed[0].AccountIDs) != 2 {
+		t.Errorf("Expected one stream over both accounts, got %+v", streamed)
+	}
+}
+
+// Test ExportTransactions - NDJSON writes one transaction per line
+func TestExportTransactions_NDJSON(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, card := newStatementApp(&streamed)
+
+	resp, body := download(t, app, "/exports/transactions?format=ndjson&account_id="+card.Hex())
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	var last models.Transaction
+	if resp.Header.Get(fiber.HeaderContentType) != "application/x-ndjson" || len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &last) != nil ||
+		last.Status != models.TransactionStatusPending {
+		t.Errorf("Expected two JSON lines for the card, got %q", body)
+	}
+}
+
+// Test ExportTransactions - unknown formats are refused
+func TestExportTransactions_UnknownFormat(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	if resp, _ := download(t, app, "/exports/transactions?format=xlsx"); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+// Test ExportTransactions - accounts the caller cannot read are not found
+func TestExportTransactions_UnknownAccount(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	if resp, _ := download(t, app, "/exports/transactions?account_id="+primitive.NewObjectID().Hex()); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+// Test ExportTransactions - OFX holds a bank and a card statement of posted transactions
+func TestExportTransactions_OFX(t *testing.T) {
+	var streamed []repository.TransactionFilter
+	app, _, _ := newStatementApp(&streamed)
+
+	resp, body := download(t, app, "/exports/transactions?format=ofx&start=2026-09-01&end=2026-10-31")
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "application/x-ofx" {
+		t.Fatalf("Expected an OFX download, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	var elements []string
+	decoder := xml.NewDecoder(bufio.NewReader(strings.NewReader(body)))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected well-formed OFX, got %v:\n%s", err, body)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			elements = append(elements, start.Name.Local)
+		}
+	}
+	if !slices.Contains(elements, "STMTRS") || !slices.Contains(elements, "CCSTMTRS") {
+		t.Errorf("Expected bank and card statements, got %v", elements)
+	}
+	if strings.Count(body, "<STMTTRN>") != 2 || strings.Contains(body, "Corner Grocery") {
+		t.Errorf("Expected only the two posted transactions, got:\n%s", body)
+	}
+	for _, want := range []string{
+		"<TRNAMT>-19.99</TRNAMT>",
+		"<NAME>=HYPERLINK(&#34;http://evil&#34;)</NAME>",
+		"<DTSTART>20260901000000.000[0:GMT]</DTSTART>",
+		"<LEDGERBAL><BALAMT>-62.49</BALAMT>",
+		"<ACCTTYPE>CHECKING</ACCTTYPE>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the OFX to contain %s:\n%s", want, body)
+		}
+	}
+}
+
+// Test ExportBudgetReport - budgets are reported against their spending without transfers
+func TestExportBudgetReport_CSV(t *testing.T) {
+	groceries := primitive.NewObjectID()
+	var filters []repository.TransactionFilter
+	transactions := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			filters = append(filters, filter)
+			return []models.Transaction{{Type: models.TransactionTypeDebit, Amount: 212.4, BudgetID: groceries}}, nil
+		},
+	}
+	budgets := &MockBudgetRepository{
+		ListBudgetsFunc: func(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error) {
+			return []models.Budget{{ID: groceries, UserID: userID, MaximumSpending: 400, StartDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				EndDate: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)}}, nil
+		},
+	}
+	checking := primitive.NewObjectID()
+	app := newExportApp(transactions, &MockAccountRepository{}, budgets, &MockNetWorthRepository{}, &AllowAllAccess{Accounts: []primitive.ObjectID{checking}})
+
+	resp, body := download(t, app, "/exports/budgets?start=2026-10-01&end=2026-10-31")
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if resp.StatusCode != fiber.StatusOK || err != nil || len(records) != 2 ||
+		strings.Join(records[1][1:], "|") != "2026-10-01|2026-10-31|0.00|400.00|0.00|212.40|187.60|true" {
+		t.Errorf("Unexpected budget report %d: %q", resp.StatusCode, body)
+	}
+	if len(filters) != 1 || !filters[0].ExcludeTransfers || len(filters[0].AccountIDs) != 1 || filters[0].AccountIDs[0] != checking {
+		t.Errorf("Expected the budget's transactions on the owner's accounts without transfers, got %+v", filters)
+	}
+}
+
+// Test ExportNetWorthReport - snapshots come out as CSV with the change from the day before
+func TestExportNetWorthReport_CSV(t *testing.T) {
+	snapshots := &MockNetWorthRepository{
+		ListSnapshotsFunc: func(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.NetWorthSnapshot, error) {
+			return []models.NetWorthSnapshot{
+				{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Assets: 5000, Liabilities: -1200, NetWorth: 3800},
+				{Date: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Assets: 5100.25, Liabilities: -1180, NetWorth: 3920.25},
+			}, nil
+		},
+	}
+	app := newExportApp(&MockTransactionRepository{}, &MockAccountRepository{}, &MockBudgetRepository{}, snapshots, &AllowAllAccess{})
+
+	resp, body := download(t, app, "/exports/net-worth")
+	want := "date,assets,liabilities,net_worth,change\n2026-10-01,5000.00,-1200.00,3800.00,\n2026-10-02,5100.25,-1180.00,3920.25,120.25\n"
+	if resp.StatusCode != fiber.StatusOK || !strings.Contains(resp.Header.Get(fiber.HeaderContentDisposition), "net-worth.csv") || body != want {
+		t.Errorf("Unexpected net worth report %d:\n%s", resp.StatusCode, body)
+	}
+}
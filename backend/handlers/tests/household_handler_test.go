@@ -0,0 +1,447 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockHouseholdRepository is a mock implementation of repository.HouseholdRepository for testing
+type MockHouseholdRepository struct {
+	GetHouseholdByIDFunc         func(ctx context.Context, id primitive.ObjectID) (*models.Household, error)
+	ListByMemberFunc             func(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error)
+	CreateHouseholdFunc          func(ctx context.Context, household *models.Household) error
+	SaveHouseholdFunc            func(ctx context.Context, household *models.Household) error
+	CreateInvitationFunc         func(ctx context.Context, invitation *models.HouseholdInvitation) error
+	GetInvitationByTokenHashFunc func(ctx context.Context, tokenHash string) (*models.HouseholdInvitation, error)
+	ListInvitationsFunc          func(ctx context.Context, householdID primitive.ObjectID) ([]models.HouseholdInvitation, error)
+	AcceptInvitationFunc         func(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+func (m *MockHouseholdRepository) GetHouseholdByID(ctx context.Context, id primitive.ObjectID) (*models.Household, error) {
+	if m.GetHouseholdByIDFunc != nil {
+		return m.GetHouseholdByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) ListByMember(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error) {
+	if m.ListByMemberFunc != nil {
+		return m.ListByMemberFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *models.Household) error {
+	if m.CreateHouseholdFunc != nil {
+		return m.CreateHouseholdFunc(ctx, household)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) SaveHousehold(ctx context.Context, household *models.Household) error {
+	if m.SaveHouseholdFunc != nil {
+		return m.SaveHouseholdFunc(ctx, household)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error {
+	if m.CreateInvitationFunc != nil {
+		return m.CreateInvitationFunc(ctx, invitation)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.HouseholdInvitation, error) {
+	if m.GetInvitationByTokenHashFunc != nil {
+		return m.GetInvitationByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) ListInvitations(ctx context.Context, householdID primitive.ObjectID) ([]models.HouseholdInvitation, error) {
+	if m.ListInvitationsFunc != nil {
+		return m.ListInvitationsFunc(ctx, householdID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.AcceptInvitationFunc != nil {
+		return m.AcceptInvitationFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+// householdStore keeps households and invitations in the given maps. Saves follow
+// the repository's version check: a household saved at a stale version is not matched.
+func householdStore(households map[primitive.ObjectID]*models.Household, invitations map[primitive.ObjectID]*models.HouseholdInvitation) *MockHouseholdRepository {
+	copyHousehold := func(household *models.Household) *models.Household {
+		copied := *household
+		copied.Members = append([]models.HouseholdMember{}, household.Members...)
+		copied.Accounts = append([]primitive.ObjectID{}, household.Accounts...)
+		copied.Budgets = append([]primitive.ObjectID{}, household.Budgets...)
+		return &copied
+	}
+	return &MockHouseholdRepository{
+		GetHouseholdByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Household, error) {
+			household, ok := households[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			return copyHousehold(household), nil
+		},
+		ListByMemberFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error) {
+			var out []models.Household
+			for _, household := range households {
+				if _, ok := household.Member(userID); ok {
+					out = append(out, *copyHousehold(household))
+				}
+			}
+			return out, nil
+		},
+		CreateHouseholdFunc: func(ctx context.Context, household *models.Household) error {
+			household.ID = primitive.NewObjectID()
+			households[household.ID] = copyHousehold(household)
+			return nil
+		},
+		SaveHouseholdFunc: func(ctx context.Context, household *models.Household) error {
+			stored, ok := households[household.ID]
+			if !ok || stored.Version != household.Version {
+				return mongo.ErrNoDocuments
+			}
+			household.Version++
+			households[household.ID] = copyHousehold(household)
+			return nil
+		},
+		CreateInvitationFunc: func(ctx context.Context, invitation *models.HouseholdInvitation) error {
+			invitation.ID = primitive.NewObjectID()
+			stored := *invitation
+			invitations[invitation.ID] = &stored
+			return nil
+		},
+		GetInvitationByTokenHashFunc: func(ctx context.Context, tokenHash string) (*models.HouseholdInvitation, error) {
+			for _, invitation := range invitations {
+				if invitation.TokenHash == tokenHash {
+					copied := *invitation
+					return &copied, nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		ListInvitationsFunc: func(ctx context.Context, householdID primitive.ObjectID) ([]models.HouseholdInvitation, error) {
+			var out []models.HouseholdInvitation
+			for _, invitation := range invitations {
+				if invitation.HouseholdID == householdID {
+					out = append(out, *invitation)
+				}
+			}
+			return out, nil
+		},
+		AcceptInvitationFunc: func(ctx context.Context, id, userID primitive.ObjectID) error {
+			invitation, ok := invitations[id]
+			if !ok || !invitation.AcceptedBy.IsZero() {
+				return mongo.ErrNoDocuments
+			}
+			invitation.AcceptedBy = userID
+			invitation.AcceptedAt = time.Now().UTC()
+			return nil
+		},
+	}
+}
+
+// noHouseholds is a household repository for callers that belong to no household
+func noHouseholds() *MockHouseholdRepository {
+	return householdStore(map[primitive.ObjectID]*models.Household{}, map[primitive.ObjectID]*models.HouseholdInvitation{})
+}
+
+// rollbackTx runs the unit of work and, when it fails, puts the invitations back the
+// way they were, as aborting a database transaction would
+type rollbackTx struct {
+	invitations map[primitive.ObjectID]*models.HouseholdInvitation
+}
+
+func (r *rollbackTx) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := map[primitive.ObjectID]models.HouseholdInvitation{}
+	for id, invitation := range r.invitations {
+		saved[id] = *invitation
+	}
+	err := fn(ctx)
+	if err != nil {
+		for id, invitation := range saved {
+			*r.invitations[id] = invitation
+		}
+	}
+	return err
+}
+
+type householdFixture struct {
+	app         *fiber.App
+	households  map[primitive.ObjectID]*models.Household
+	invitations map[primitive.ObjectID]*models.HouseholdInvitation
+	// raced makes the next household read come back one save behind the store
+	raced    bool
+	ownerID  primitive.ObjectID
+	memberID primitive.ObjectID
+	outsider primitive.ObjectID
+	account  *models.Account
+	imported int
+}
+
+// newHouseholdFixture wires the household and transaction handlers to the real
+// AccessService. The owner holds one account; the member and outsider hold none.
+func newHouseholdFixture() *householdFixture {
+	f := &householdFixture{
+		households:  map[primitive.ObjectID]*models.Household{},
+		invitations: map[primitive.ObjectID]*models.HouseholdInvitation{},
+		ownerID:     primitive.NewObjectID(),
+		memberID:    primitive.NewObjectID(),
+		outsider:    primitive.NewObjectID(),
+		account:     &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 100},
+	}
+	f.account.OwnerID = f.ownerID
+
+	users := map[primitive.ObjectID]*models.User{
+		f.ownerID:  {ID: f.ownerID, Email: "owner@example.com"},
+		f.memberID: {ID: f.memberID, Email: "Partner@Example.com"},
+		f.outsider: {ID: f.outsider, Email: "outsider@example.com"},
+	}
+	userRepo := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+	householdRepo := householdStore(f.households, f.invitations)
+	getHousehold := householdRepo.GetHouseholdByIDFunc
+	householdRepo.GetHouseholdByIDFunc = func(ctx context.Context, id primitive.ObjectID) (*models.Household, error) {
+		household, err := getHousehold(ctx, id)
+		if err == nil && f.raced {
+			f.raced = false
+			f.households[id].Version++
+		}
+		return household, err
+	}
+	accountRepo := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			if id == f.account.ID {
+				return f.account, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		SetAvailableFunc: func(ctx context.Context, id primitive.ObjectID, value float64) error {
+			return nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			f.account.CurrentBalance += delta
+			return nil
+		},
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			if ownerID == f.account.OwnerID {
+				return []primitive.ObjectID{f.account.ID}, nil
+			}
+			return nil, nil
+		},
+		ListSharedIDsFunc: func(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+			for _, id := range householdIDs {
+				if slices.Contains(f.account.HouseholdIDs, id) {
+					return []primitive.ObjectID{f.account.ID}, nil
+				}
+			}
+			return nil, nil
+		},
+		ShareFunc: func(ctx context.Context, id, householdID primitive.ObjectID) error {
+			if !slices.Contains(f.account.HouseholdIDs, householdID) {
+				f.account.HouseholdIDs = append(f.account.HouseholdIDs, householdID)
+			}
+			return nil
+		},
+		UnshareFunc: func(ctx context.Context, id, householdID primitive.ObjectID) error {
+			f.account.HouseholdIDs = slices.DeleteFunc(f.account.HouseholdIDs, func(shared primitive.ObjectID) bool { return shared == householdID })
+			return nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return nil, nil
+		},
+		CreateTransactionFunc: func(ctx context.Context, txn *models.Transaction) error {
+			f.imported++
+			return nil
+		},
+	}
+
+	access := services.NewAccessService(accountRepo, householdRepo)
+	households := handlers.NewHouseholdHandler(services.NewHouseholdService(householdRepo, userRepo, accountRepo, &MockBudgetRepository{}, &rollbackTx{invitations: f.invitations}))
+	transactions := handlers.NewTransactionHandler(services.NewTransactionService(transactionRepo, accountRepo, &MockBudgetRepository{}, &MockTxRunner{}, access))
+
+	f.app = fiber.New()
+	f.app.Use(middleware.UserContextMiddleware())
+	f.app.Post("/households", households.CreateHousehold)
+	f.app.Post("/households/invitations/accept", households.AcceptInvitation)
+	f.app.Post("/households/:id/invitations", households.Invite)
+	f.app.Get("/households/:id", households.GetHousehold)
+	f.app.Put("/households/:id/members/:memberId", households.SetMemberRole)
+	f.app.Put("/households/:id/accounts/:accountId", households.ShareAccount)
+	f.app.Post("/accounts/:id/transactions/import", transactions.ImportTransactions)
+	return f
+}
+
+// join creates a household owned by the owner, shares the owner's account and has
+// the member accept an invitation with role
+func (f *householdFixture) join(t *testing.T, role string) primitive.ObjectID {
+	t.Helper()
+	rec := userRequest(t, f.app, f.ownerID, "POST", "/households", handlers.HouseholdPayload{Name: "Home"})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected household to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var household models.Household
+	_ = json.Unmarshal(rec.Body.Bytes(), &household)
+
+	path := "/households/" + household.ID.Hex()
+	if rec := userRequest(t, f.app, f.ownerID, "PUT", path+"/accounts/"+f.account.ID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected account to be shared, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = userRequest(t, f.app, f.ownerID, "POST", path+"/invitations", handlers.InvitationPayload{Email: "partner@example.com", Role: role})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected invitation to be issued, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var issued services.IssuedInvitation
+	_ = json.Unmarshal(rec.Body.Bytes(), &issued)
+
+	rec = userRequest(t, f.app, f.memberID, "POST", "/households/invitations/accept", handlers.AcceptInvitationPayload{Token: issued.Token})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected invitation to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	return household.ID
+}
+
+func (f *householdFixture) importTransaction(t *testing.T, userID primitive.ObjectID) int {
+	t.Helper()
+	payload := handlers.ImportPayload{Transactions: []handlers.ImportTransactionPayload{
+		{Name: "GROCER", Type: models.TransactionTypeDebit, Amount: 30, TransactionDate: time.Now().UTC()},
+	}}
+	return userRequest(t, f.app, userID, "POST", "/accounts/"+f.account.ID.Hex()+"/transactions/import", payload).Code
+}
+
+// Test Households - a viewer cannot write to a shared account until promoted to editor
+func TestHouseholds_ViewerIsReadOnlyUntilPromoted(t *testing.T) {
+	f := newHouseholdFixture()
+	householdID := f.join(t, models.HouseholdRoleViewer)
+
+	if status := f.importTransaction(t, f.memberID); status != fiber.StatusForbidden {
+		t.Fatalf("Expected viewer import to be forbidden, got %d", status)
+	}
+	if f.imported != 0 {
+		t.Fatalf("Expected nothing imported, got %d", f.imported)
+	}
+
+	path := "/households/" + householdID.Hex() + "/members/" + f.memberID.Hex()
+	if rec := userRequest(t, f.app, f.memberID, "PUT", path, handlers.MemberRolePayload{Role: models.HouseholdRoleEditor}); rec.Code != fiber.StatusForbidden {
+		t.Fatalf("Expected a viewer to be unable to promote themselves, got %d", rec.Code)
+	}
+	if rec := userRequest(t, f.app, f.ownerID, "PUT", path, handlers.MemberRolePayload{Role: models.HouseholdRoleEditor}); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected owner to promote the member, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if status := f.importTransaction(t, f.memberID); status != fiber.StatusCreated {
+		t.Fatalf("Expected editor import to succeed, got %d", status)
+	}
+	if f.imported != 1 {
+		t.Errorf("Expected one transaction imported, got %d", f.imported)
+	}
+}
+
+// Test Households - users outside the household cannot touch its shared accounts
+func TestHouseholds_OutsiderIsForbidden(t *testing.T) {
+	f := newHouseholdFixture()
+	f.join(t, models.HouseholdRoleEditor)
+
+	if status := f.importTransaction(t, f.outsider); status != fiber.StatusForbidden {
+		t.Fatalf("Expected an outsider to be refused, got %d", status)
+	}
+	if f.imported != 0 {
+		t.Errorf("Expected nothing imported, got %d", f.imported)
+	}
+}
+
+// Test AcceptInvitation - a token is single use and bound to the invited email
+func TestHouseholds_InvitationIsSingleUseAndEmailBound(t *testing.T) {
+	f := newHouseholdFixture()
+	householdID := f.join(t, models.HouseholdRoleViewer)
+
+	rec := userRequest(t, f.app, f.ownerID, "POST", "/households/"+householdID.Hex()+"/invitations", handlers.InvitationPayload{Email: "someone@example.com"})
+	var issued services.IssuedInvitation
+	_ = json.Unmarshal(rec.Body.Bytes(), &issued)
+
+	if rec := userRequest(t, f.app, f.memberID, "POST", "/households/invitations/accept", handlers.AcceptInvitationPayload{Token: issued.Token}); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected a token for another email to be rejected, got %d", rec.Code)
+	}
+	if rec := userRequest(t, f.app, f.memberID, "POST", "/households/"+householdID.Hex()+"/invitations", handlers.InvitationPayload{Email: "x@example.com"}); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected a viewer to be unable to invite, got %d", rec.Code)
+	}
+}
+
+// Test SetMemberRole - a save based on a stale read is refused instead of undoing the other change
+func TestHouseholds_StaleSaveIsRefused(t *testing.T) {
+	f := newHouseholdFixture()
+	householdID := f.join(t, models.HouseholdRoleViewer)
+	saved := f.households[householdID].Version
+
+	f.raced = true
+	path := "/households/" + householdID.Hex() + "/members/" + f.memberID.Hex()
+	if rec := userRequest(t, f.app, f.ownerID, "PUT", path, handlers.MemberRolePayload{Role: models.HouseholdRoleEditor}); rec.Code != fiber.StatusConflict {
+		t.Fatalf("Expected a stale save to conflict, got %d: %s", rec.Code, rec.Body.String())
+	}
+	member, _ := f.households[householdID].Member(f.memberID)
+	if member.Role != models.HouseholdRoleViewer || f.households[householdID].Version != saved+1 {
+		t.Errorf("Expected the stored household to keep the other save only, got role %q at version %d", member.Role, f.households[householdID].Version)
+	}
+
+	if rec := userRequest(t, f.app, f.ownerID, "PUT", path, handlers.MemberRolePayload{Role: models.HouseholdRoleEditor}); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected a retry to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Test AcceptInvitation - an invitation stays open when adding the member fails
+func TestHouseholds_InvitationStaysOpenWhenJoinFails(t *testing.T) {
+	f := newHouseholdFixture()
+	rec := userRequest(t, f.app, f.ownerID, "POST", "/households", handlers.HouseholdPayload{Name: "Home"})
+	var household models.Household
+	_ = json.Unmarshal(rec.Body.Bytes(), &household)
+	rec = userRequest(t, f.app, f.ownerID, "POST", "/households/"+household.ID.Hex()+"/invitations", handlers.InvitationPayload{Email: "partner@example.com", Role: models.HouseholdRoleEditor})
+	var issued services.IssuedInvitation
+	_ = json.Unmarshal(rec.Body.Bytes(), &issued)
+
+	f.raced = true
+	accept := handlers.AcceptInvitationPayload{Token: issued.Token}
+	if rec := userRequest(t, f.app, f.memberID, "POST", "/households/invitations/accept", accept); rec.Code != fiber.StatusConflict {
+		t.Fatalf("Expected the join to conflict, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !f.invitations[issued.Invitation.ID].AcceptedBy.IsZero() {
+		t.Fatalf("Expected the invitation to stay open")
+	}
+
+	if rec := userRequest(t, f.app, f.memberID, "POST", "/households/invitations/accept", accept); rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected the invitation to be accepted on retry, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := userRequest(t, f.app, f.memberID, "GET", "/households/"+household.ID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected the member to see the household, got %d", rec.Code)
+	}
+}
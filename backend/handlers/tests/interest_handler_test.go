@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Test RunAccruals - overlapping runs post a month's interest once
+func TestRunAccruals_ConcurrentRuns(t *testing.T) {
+	stored := models.Account{
+		ID:              primitive.NewObjectID(),
+		OwnerID:         testUserID,
+		AccountType:     models.AccountTypeSavings,
+		CurrentBalance:  10000,
+		InterestRate:    0.05,
+		LastAccrualDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var mu sync.Mutex
+	postings := 0
+
+	// every run lists the account before any of them saves
+	listed := make(chan struct{})
+	var ready sync.WaitGroup
+	ready.Add(3)
+	accounts := &MockAccountRepository{
+		ListInterestFunc: func(ctx context.Context) ([]models.Account, error) {
+			mu.Lock()
+			account := stored
+			mu.Unlock()
+			ready.Done()
+			<-listed
+			return []models.Account{account}, nil
+		},
+		SaveInterestFunc: func(ctx context.Context, account *models.Account, previous time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !stored.LastAccrualDate.Equal(previous) {
+				return mongo.ErrNoDocuments
+			}
+			stored.LastAccrualDate = account.LastAccrualDate
+			stored.AccruedInterest = account.AccruedInterest
+			return nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored.CurrentBalance += delta
+			return nil
+		},
+	}
+	transactions := &MockTransactionRepository{
+		CreateTransactionFunc: func(ctx context.Context, txn *models.Transaction) error {
+			mu.Lock()
+			defer mu.Unlock()
+			postings++
+			return nil
+		},
+	}
+	service := services.NewInterestService(accounts, transactions, &MockTxRunner{}, &AllowAllAccess{})
+
+	results := make([]*services.InterestRunResult, 3)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := service.RunAccruals(context.Background(), time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			results[i] = result
+		}()
+	}
+	ready.Wait()
+	close(listed)
+	wg.Wait()
+
+	skipped := 0
+	for _, result := range results {
+		skipped += result.Skipped
+		if result.Failures != 0 {
+			t.Errorf("Expected no failures, got %+v", result)
+		}
+	}
+	if postings != 1 || skipped != 2 {
+		t.Errorf("Expected September's interest posted once with two runs skipped, got %d postings and %d skipped", postings, skipped)
+	}
+}
@@ -0,0 +1,293 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockJobRepository is a mock implementation of repository.JobRepository for testing
+type MockJobRepository struct {
+	EnsureJobFunc   func(ctx context.Context, name, schedule string, nextRunAt time.Time) error
+	AcquireLockFunc func(ctx context.Context, name, instance string, now, until time.Time) (*models.JobState, error)
+	FinishRunFunc   func(ctx context.Context, name, instance string, state *models.JobState) error
+	ListJobsFunc    func(ctx context.Context) ([]models.JobState, error)
+	CreateRunFunc   func(ctx context.Context, run *models.JobRun) error
+	ListRunsFunc    func(ctx context.Context, filter repository.JobRunFilter) ([]models.JobRun, error)
+}
+
+func (m *MockJobRepository) EnsureJob(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+	if m.EnsureJobFunc != nil {
+		return m.EnsureJobFunc(ctx, name, schedule, nextRunAt)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockJobRepository) AcquireLock(ctx context.Context, name, instance string, now, until time.Time) (*models.JobState, error) {
+	if m.AcquireLockFunc != nil {
+		return m.AcquireLockFunc(ctx, name, instance, now, until)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockJobRepository) FinishRun(ctx context.Context, name, instance string, state *models.JobState) error {
+	if m.FinishRunFunc != nil {
+		return m.FinishRunFunc(ctx, name, instance, state)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockJobRepository) ListJobs(ctx context.Context) ([]models.JobState, error) {
+	if m.ListJobsFunc != nil {
+		return m.ListJobsFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockJobRepository) CreateRun(ctx context.Context, run *models.JobRun) error {
+	if m.CreateRunFunc != nil {
+		return m.CreateRunFunc(ctx, run)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockJobRepository) ListRuns(ctx context.Context, filter repository.JobRunFilter) ([]models.JobRun, error) {
+	if m.ListRunsFunc != nil {
+		return m.ListRunsFunc(ctx, filter)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// jobStore keeps job state in memory with the same locking rules as Mongo
+func jobStore(jobs map[string]*models.JobState) *MockJobRepository {
+	var mu sync.Mutex
+	return &MockJobRepository{
+		EnsureJobFunc: func(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if job, ok := jobs[name]; ok && job.Schedule == schedule {
+				return nil
+			}
+			jobs[name] = &models.JobState{Name: name, Schedule: schedule, NextRunAt: nextRunAt}
+			return nil
+		},
+		AcquireLockFunc: func(ctx context.Context, name, instance string, now, until time.Time) (*models.JobState, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			job, ok := jobs[name]
+			if !ok || job.NextRunAt.After(now) || job.LockedUntil.After(now) {
+				return nil, mongo.ErrNoDocuments
+			}
+			job.LockedBy, job.LockedUntil = instance, until
+			copied := *job
+			return &copied, nil
+		},
+		FinishRunFunc: func(ctx context.Context, name, instance string, state *models.JobState) error {
+			mu.Lock()
+			defer mu.Unlock()
+			job, ok := jobs[name]
+			if !ok || job.LockedBy != instance {
+				return mongo.ErrNoDocuments
+			}
+			job.NextRunAt, job.Attempt, job.LastRunAt = state.NextRunAt, state.Attempt, state.LastRunAt
+			job.LastStatus, job.LastError = state.LastStatus, state.LastError
+			job.LockedBy, job.LockedUntil = "", time.Time{}
+			return nil
+		},
+		CreateRunFunc: func(ctx context.Context, run *models.JobRun) error {
+			return nil
+		},
+	}
+}
+
+func failingJob(ctx context.Context, now time.Time) (any, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+// Test Register - job names are unique
+func TestRegister_DuplicateName(t *testing.T) {
+	scheduler := services.NewScheduler(&MockJobRepository{})
+	_ = scheduler.Register("nightly", "0 2 * * *", failingJob)
+
+	if err := scheduler.Register("nightly", "@daily", failingJob); err != services.ErrDuplicateJob {
+		t.Errorf("Expected ErrDuplicateJob, got %v", err)
+	}
+}
+
+// Test Tick - nothing runs before the job is due
+func TestTick_NotDue(t *testing.T) {
+	scheduler := services.NewScheduler(jobStore(map[string]*models.JobState{}))
+	_ = scheduler.Register("nightly", "0 2 * * *", func(ctx context.Context, now time.Time) (any, error) {
+		t.Error("Expected the job not to run")
+		return nil, nil
+	})
+	start := time.Date(2026, 10, 18, 1, 30, 0, 0, time.UTC)
+	_ = scheduler.Ensure(context.Background(), start)
+
+	if runs := scheduler.Tick(context.Background(), start.Add(10*time.Minute)); len(runs) != 0 {
+		t.Errorf("Expected nothing due before 02:00, got %d runs", len(runs))
+	}
+}
+
+// Test Tick - a job locked by another instance is skipped
+func TestTick_SkipsLockedJob(t *testing.T) {
+	due := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	jobs := map[string]*models.JobState{
+		"nightly": {Name: "nightly", Schedule: "0 2 * * *", NextRunAt: due, LockedBy: "other", LockedUntil: due.Add(time.Minute)},
+	}
+	scheduler := services.NewScheduler(jobStore(jobs))
+	_ = scheduler.Register("nightly", "0 2 * * *", func(ctx context.Context, now time.Time) (any, error) {
+		t.Error("Expected the job not to run")
+		return nil, nil
+	})
+
+	if runs := scheduler.Tick(context.Background(), due); len(runs) != 0 {
+		t.Errorf("Expected a locked job to be skipped, got %d runs", len(runs))
+	}
+}
+
+// Test Tick - a successful run records its result and is rescheduled from its cron
+func TestTick_ReschedulesFromCron(t *testing.T) {
+	due := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	jobs := map[string]*models.JobState{}
+	scheduler := services.NewScheduler(jobStore(jobs))
+	_ = scheduler.Register("nightly", "0 2 * * *", func(ctx context.Context, now time.Time) (any, error) {
+		return services.SnapshotRunResult{Users: 3, Snapshots: 2}, nil
+	})
+	_ = scheduler.Ensure(context.Background(), due.Add(-time.Hour))
+
+	runs := scheduler.Tick(context.Background(), due)
+	if len(runs) != 1 || runs[0].Status != models.JobRunSucceeded || runs[0].Result["snapshots"] != float64(2) {
+		t.Fatalf("Expected a successful run with its result, got %+v", runs)
+	}
+	job := jobs["nightly"]
+	if !job.NextRunAt.Equal(due.AddDate(0, 0, 1)) || job.LockedBy != "" || job.LastStatus != models.JobRunSucceeded {
+		t.Errorf("Expected the job unlocked and due tomorrow, got %+v", job)
+	}
+}
+
+// Test Tick - instances ticking together run a due job once
+func TestTick_ConcurrentInstances(t *testing.T) {
+	due := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	repo := jobStore(map[string]*models.JobState{})
+	var calls atomic.Int32
+	instances := make([]*services.Scheduler, 4)
+	for i := range instances {
+		instances[i] = services.NewScheduler(repo)
+		_ = instances[i].Register("nightly", "0 2 * * *", func(ctx context.Context, now time.Time) (any, error) {
+			calls.Add(1)
+			return nil, nil
+		})
+		_ = instances[i].Ensure(context.Background(), due.Add(-time.Hour))
+	}
+
+	var wg sync.WaitGroup
+	for _, scheduler := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.Tick(context.Background(), due)
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected exactly one run, got %d", calls.Load())
+	}
+}
+
+// Test Tick - failed runs are retried with growing backoff
+func TestTick_RetriesWithBackoff(t *testing.T) {
+	jobs := map[string]*models.JobState{}
+	scheduler := services.NewScheduler(jobStore(jobs))
+	_ = scheduler.Register("sync", "0 */6 * * *", failingJob)
+	now := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)
+	_ = scheduler.Ensure(context.Background(), now.Add(-time.Minute))
+
+	for attempt := 1; attempt <= services.JobMaxRetries; attempt++ {
+		runs := scheduler.Tick(context.Background(), now)
+		if len(runs) != 1 || runs[0].Status != models.JobRunFailed || runs[0].Attempt != attempt-1 {
+			t.Fatalf("Expected a failed run on attempt %d, got %+v", attempt, runs)
+		}
+		job := jobs["sync"]
+		if want := now.Add(services.RetryBackoff(attempt)); job.Attempt != attempt || !job.NextRunAt.Equal(want) {
+			t.Fatalf("Expected retry %d at %s, got %+v", attempt, want, job)
+		}
+		now = job.NextRunAt
+	}
+}
+
+// Test Tick - after the last retry a failing job waits for its next scheduled time
+func TestTick_StopsRetrying(t *testing.T) {
+	now := time.Date(2026, 10, 18, 6, 7, 0, 0, time.UTC)
+	jobs := map[string]*models.JobState{
+		"sync": {Name: "sync", Schedule: "0 */6 * * *", NextRunAt: now, Attempt: services.JobMaxRetries},
+	}
+	scheduler := services.NewScheduler(jobStore(jobs))
+	_ = scheduler.Register("sync", "0 */6 * * *", failingJob)
+
+	scheduler.Tick(context.Background(), now)
+	if job := jobs["sync"]; job.Attempt != 0 || !job.NextRunAt.Equal(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected retries to stop until the next scheduled time, got %+v", job)
+	}
+}
+
+// Test Tick - a panicking job is recorded as a failure
+func TestTick_RecordsPanic(t *testing.T) {
+	now := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)
+	jobs := map[string]*models.JobState{}
+	scheduler := services.NewScheduler(jobStore(jobs))
+	_ = scheduler.Register("panics", "0 */6 * * *", func(ctx context.Context, now time.Time) (any, error) {
+		panic("boom")
+	})
+	_ = scheduler.Ensure(context.Background(), now.Add(-time.Minute))
+
+	runs := scheduler.Tick(context.Background(), now)
+	if len(runs) != 1 || runs[0].Status != models.JobRunFailed || !strings.Contains(jobs["panics"].LastError, "boom") {
+		t.Errorf("Expected a panic to be recorded as a failure, got %+v", runs)
+	}
+}
+
+// Test ListRuns - admins can list failures of one job
+func TestListRuns_Filter(t *testing.T) {
+	var got repository.JobRunFilter
+	repo := &MockJobRepository{
+		ListRunsFunc: func(ctx context.Context, filter repository.JobRunFilter) ([]models.JobRun, error) {
+			got = filter
+			return []models.JobRun{{Job: "sync", Status: models.JobRunFailed, Error: "provider unavailable"}}, nil
+		},
+	}
+	handler := handlers.NewJobHandler(services.NewScheduler(repo))
+	app := fiber.New()
+	app.Get("/jobs/runs", handler.ListRuns)
+
+	rec := userRequest(t, app, testUserID, "GET", "/jobs/runs?job=sync&status=failed", nil)
+	var runs []models.JobRun
+	_ = json.Unmarshal(rec.Body.Bytes(), &runs)
+	if rec.Code != fiber.StatusOK || len(runs) != 1 || got.Job != "sync" || got.Status != models.JobRunFailed {
+		t.Errorf("Expected the failed sync runs, got %d %+v with %+v", rec.Code, runs, got)
+	}
+}
+
+// Test ListRuns - unknown statuses are refused
+func TestListRuns_UnknownStatus(t *testing.T) {
+	handler := handlers.NewJobHandler(services.NewScheduler(&MockJobRepository{}))
+	app := fiber.New()
+	app.Get("/jobs/runs", handler.ListRuns)
+
+	if rec := userRequest(t, app, testUserID, "GET", "/jobs/runs?status=pending", nil); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
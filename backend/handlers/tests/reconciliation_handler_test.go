@@ -0,0 +1,348 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockReconciliationRepository is a mock implementation of repository.ReconciliationRepository for testing
+type MockReconciliationRepository struct {
+	GetReconciliationByIDFunc func(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error)
+	GetInProgressFunc         func(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error)
+	GetLastCompletedFunc      func(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error)
+	ListByAccountFunc         func(ctx context.Context, accountID primitive.ObjectID) ([]models.Reconciliation, error)
+	CreateReconciliationFunc  func(ctx context.Context, rec *models.Reconciliation) error
+	SaveReconciliationFunc    func(ctx context.Context, rec *models.Reconciliation) error
+}
+
+func (m *MockReconciliationRepository) GetReconciliationByID(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error) {
+	if m.GetReconciliationByIDFunc != nil {
+		return m.GetReconciliationByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReconciliationRepository) GetInProgress(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+	if m.GetInProgressFunc != nil {
+		return m.GetInProgressFunc(ctx, accountID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReconciliationRepository) GetLastCompleted(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+	if m.GetLastCompletedFunc != nil {
+		return m.GetLastCompletedFunc(ctx, accountID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReconciliationRepository) ListByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Reconciliation, error) {
+	if m.ListByAccountFunc != nil {
+		return m.ListByAccountFunc(ctx, accountID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReconciliationRepository) CreateReconciliation(ctx context.Context, rec *models.Reconciliation) error {
+	if m.CreateReconciliationFunc != nil {
+		return m.CreateReconciliationFunc(ctx, rec)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockReconciliationRepository) SaveReconciliation(ctx context.Context, rec *models.Reconciliation) error {
+	if m.SaveReconciliationFunc != nil {
+		return m.SaveReconciliationFunc(ctx, rec)
+	}
+	return errors.New("not implemented")
+}
+
+// reconciliationStore is a MockReconciliationRepository backed by recs. Like the unique
+// index on in-progress reconciliations, it refuses a second one for the same account.
+func reconciliationStore(recs map[primitive.ObjectID]*models.Reconciliation) *MockReconciliationRepository {
+	var mu sync.Mutex
+	inProgress := func(accountID primitive.ObjectID) *models.Reconciliation {
+		for _, rec := range recs {
+			if rec.AccountID == accountID && rec.Status == models.ReconciliationInProgress {
+				return rec
+			}
+		}
+		return nil
+	}
+	return &MockReconciliationRepository{
+		GetReconciliationByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			rec, ok := recs[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *rec
+			return &copied, nil
+		},
+		GetInProgressFunc: func(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if rec := inProgress(accountID); rec != nil {
+				copied := *rec
+				return &copied, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		GetLastCompletedFunc: func(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+		CreateReconciliationFunc: func(ctx context.Context, rec *models.Reconciliation) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if inProgress(rec.AccountID) != nil {
+				return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+			}
+			rec.ID = primitive.NewObjectID()
+			copied := *rec
+			recs[rec.ID] = &copied
+			return nil
+		},
+		SaveReconciliationFunc: func(ctx context.Context, rec *models.Reconciliation) error {
+			mu.Lock()
+			defer mu.Unlock()
+			copied := *rec
+			recs[rec.ID] = &copied
+			return nil
+		},
+	}
+}
+
+// statementLedger is a MockTransactionRepository over txns that, like the Mongo
+// repository, only lists and clears posted transactions when asked to. Locked
+// transaction IDs are collected in locked.
+func statementLedger(txns []models.Transaction, locked *[]primitive.ObjectID) *MockTransactionRepository {
+	posted := func(txn *models.Transaction) bool {
+		return txn.Status == "" || txn.Status == models.TransactionStatusPosted
+	}
+	return &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			var out []models.Transaction
+			for i := range txns {
+				if filter.Status == models.TransactionStatusPosted && !posted(&txns[i]) {
+					continue
+				}
+				out = append(out, txns[i])
+			}
+			return out, nil
+		},
+		SetClearedFunc: func(ctx context.Context, accountID primitive.ObjectID, ids []primitive.ObjectID, cleared bool) (int64, error) {
+			var matched int64
+			for _, id := range ids {
+				for i := range txns {
+					if txns[i].ID == id && txns[i].AccountID == accountID && posted(&txns[i]) {
+						txns[i].Cleared = cleared
+						matched++
+					}
+				}
+			}
+			return matched, nil
+		},
+		LockTransactionsFunc: func(ctx context.Context, ids []primitive.ObjectID, reconciliationID primitive.ObjectID) error {
+			*locked = ids
+			return nil
+		},
+	}
+}
+
+// statementTransactions is a deposit of 1000 and charges of 50 and 10, all dated
+// before a 2026-04-30 statement
+func statementTransactions(accountID primitive.ObjectID) []models.Transaction {
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	return []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: accountID, Type: models.TransactionTypeCredit, Amount: 1000, TransactionDate: day},
+		{ID: primitive.NewObjectID(), AccountID: accountID, Type: models.TransactionTypeDebit, Amount: 50, TransactionDate: day},
+		{ID: primitive.NewObjectID(), AccountID: accountID, Type: models.TransactionTypeDebit, Amount: 10, TransactionDate: day},
+	}
+}
+
+func newReconciliationApp(account *models.Account, transactions *MockTransactionRepository, recs *MockReconciliationRepository) *fiber.App {
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+	}
+	service := services.NewReconciliationService(accounts, transactions, recs, &MockTxRunner{}, &AllowAllAccess{})
+	handler := handlers.NewReconciliationHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/accounts/:id/reconciliations", handler.StartReconciliation)
+	app.Post("/reconciliations/:id/clear", handler.ClearTransactions)
+	app.Post("/reconciliations/:id/complete", handler.CompleteReconciliation)
+	return app
+}
+
+// startReconciliation opens a reconciliation against a 950 statement and returns its worksheet
+func startReconciliation(t *testing.T, app *fiber.App, accountID primitive.ObjectID) *services.ReconciliationWorksheet {
+	t.Helper()
+	rec := userRequest(t, app, testUserID, "POST", "/accounts/"+accountID.Hex()+"/reconciliations", handlers.ReconciliationPayload{
+		StatementBalance: 950,
+		StatementDate:    "2026-04-30",
+	})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var sheet services.ReconciliationWorksheet
+	if err := json.Unmarshal(rec.Body.Bytes(), &sheet); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return &sheet
+}
+
+// clearTransactions marks txns as cleared (or not) and returns the response status and worksheet
+func clearTransactions(t *testing.T, app *fiber.App, sheet *services.ReconciliationWorksheet, cleared bool, txns ...models.Transaction) (int, *services.ReconciliationWorksheet) {
+	t.Helper()
+	payload := handlers.ClearPayload{Cleared: cleared}
+	for _, txn := range txns {
+		payload.TransactionIDs = append(payload.TransactionIDs, txn.ID.Hex())
+	}
+	rec := userRequest(t, app, testUserID, "POST", "/reconciliations/"+sheet.Reconciliation.ID.Hex()+"/clear", payload)
+	var updated services.ReconciliationWorksheet
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	return rec.Code, &updated
+}
+
+// Test StartReconciliation - the worksheet opens with nothing cleared
+func TestStartReconciliation_OpensWorksheet(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(statementTransactions(account.ID), &locked), reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{}))
+
+	sheet := startReconciliation(t, app, account.ID)
+
+	if len(sheet.Transactions) != 3 || sheet.ClearedTotal != 0 || sheet.Difference != 950 {
+		t.Errorf("Expected 3 transactions and a difference of 950, got %d and %v", len(sheet.Transactions), sheet.Difference)
+	}
+}
+
+// Test StartReconciliation - an account has only one reconciliation in progress
+func TestStartReconciliation_AlreadyInProgress(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(nil, &locked), reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{}))
+	startReconciliation(t, app, account.ID)
+
+	rec := userRequest(t, app, testUserID, "POST", "/accounts/"+account.ID.Hex()+"/reconciliations", handlers.ReconciliationPayload{
+		StatementBalance: 950,
+		StatementDate:    "2026-04-30",
+	})
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+}
+
+// Test StartReconciliation - a start that loses the race on the unique index is refused
+func TestStartReconciliation_ConcurrentStart(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	recs := reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{})
+	recs.GetInProgressFunc = func(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+		return nil, mongo.ErrNoDocuments
+	}
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(nil, &locked), recs)
+	startReconciliation(t, app, account.ID)
+
+	rec := userRequest(t, app, testUserID, "POST", "/accounts/"+account.ID.Hex()+"/reconciliations", handlers.ReconciliationPayload{
+		StatementBalance: 950,
+		StatementDate:    "2026-04-30",
+	})
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+}
+
+// Test ClearTransactions - clearing transactions closes the difference
+func TestClearTransactions_UpdatesDifference(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	txns := statementTransactions(account.ID)
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(txns, &locked), reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{}))
+	sheet := startReconciliation(t, app, account.ID)
+
+	status, sheet := clearTransactions(t, app, sheet, true, txns[0], txns[1])
+
+	if status != fiber.StatusOK || sheet.ClearedTotal != 950 || sheet.Difference != 0 {
+		t.Errorf("Expected status code %d and a difference of 0, got %d and %v", fiber.StatusOK, status, sheet.Difference)
+	}
+}
+
+// Test ClearTransactions - pending transactions stay off the worksheet and can't be cleared
+func TestClearTransactions_PendingExcluded(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	txns := append(statementTransactions(account.ID), models.Transaction{
+		ID: primitive.NewObjectID(), AccountID: account.ID, Type: models.TransactionTypeDebit, Status: models.TransactionStatusPending,
+		Amount: 25, Cleared: true, TransactionDate: time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC),
+	})
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(txns, &locked), reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{}))
+	sheet := startReconciliation(t, app, account.ID)
+
+	if len(sheet.Transactions) != 3 || sheet.ClearedTotal != 0 {
+		t.Errorf("Expected the pending charge off the worksheet, got %d transactions cleared to %v", len(sheet.Transactions), sheet.ClearedTotal)
+	}
+	if status, _ := clearTransactions(t, app, sheet, true, txns[3]); status != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d (Bad Request) clearing a pending charge, got %d", fiber.StatusBadRequest, status)
+	}
+}
+
+// Test CompleteReconciliation - refused while the cleared total misses the statement
+func TestCompleteReconciliation_Unbalanced(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID()}
+	txns := statementTransactions(account.ID)
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(txns, &locked), reconciliationStore(map[primitive.ObjectID]*models.Reconciliation{}))
+	sheet := startReconciliation(t, app, account.ID)
+	clearTransactions(t, app, sheet, true, txns...)
+
+	rec := userRequest(t, app, testUserID, "POST", "/reconciliations/"+sheet.Reconciliation.ID.Hex()+"/complete", nil)
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict) while unbalanced, got %d", fiber.StatusConflict, rec.Code)
+	}
+	if locked != nil {
+		t.Errorf("Expected no transactions locked, got %v", locked)
+	}
+}
+
+// Test CompleteReconciliation - cleared transactions are locked and the balance discrepancy recorded
+func TestCompleteReconciliation_LocksCleared(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 940}
+	txns := statementTransactions(account.ID)
+	recs := map[primitive.ObjectID]*models.Reconciliation{}
+	var locked []primitive.ObjectID
+	app := newReconciliationApp(account, statementLedger(txns, &locked), reconciliationStore(recs))
+	sheet := startReconciliation(t, app, account.ID)
+	clearTransactions(t, app, sheet, true, txns[0], txns[1])
+
+	rec := userRequest(t, app, testUserID, "POST", "/reconciliations/"+sheet.Reconciliation.ID.Hex()+"/complete", nil)
+
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusOK, rec.Code, rec.Body.String())
+	}
+	if len(locked) != 2 {
+		t.Errorf("Expected 2 locked transactions, got %d", len(locked))
+	}
+	if completed := recs[sheet.Reconciliation.ID]; completed.Status != models.ReconciliationCompleted || completed.BalanceDiscrepancy != -10 {
+		t.Errorf("Expected a completed reconciliation with a -10 discrepancy, got %s and %v", completed.Status, completed.BalanceDiscrepancy)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockReportRepository is a mock implementation of repository.ReportRepository for testing
+type MockReportRepository struct {
+	SpendingTotalsFunc func(ctx context.Context, query repository.ReportQuery) ([]repository.ReportRow, error)
+}
+
+func (m *MockReportRepository) SpendingTotals(ctx context.Context, query repository.ReportQuery) ([]repository.ReportRow, error) {
+	if m.SpendingTotalsFunc != nil {
+		return m.SpendingTotalsFunc(ctx, query)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func newReportApp(reports *MockReportRepository, accounts *MockAccountRepository, access *AllowAllAccess) *fiber.App {
+	handler := handlers.NewReportHandler(services.NewReportService(reports, accounts, access))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/reports/spending", handler.GetSpendingReport)
+	return app
+}
+
+// Test GetSpendingReport - the current and previous periods are aggregated over the caller's accounts
+func TestGetSpendingReport_QueriesBothPeriods(t *testing.T) {
+	checking, savings := primitive.NewObjectID(), primitive.NewObjectID()
+	var queries []repository.ReportQuery
+	reports := &MockReportRepository{
+		SpendingTotalsFunc: func(ctx context.Context, query repository.ReportQuery) ([]repository.ReportRow, error) {
+			queries = append(queries, query)
+			return nil, nil
+		},
+	}
+	app := newReportApp(reports, &MockAccountRepository{}, &AllowAllAccess{Accounts: []primitive.ObjectID{checking, savings}})
+
+	rec := userRequest(t, app, testUserID, "GET", "/reports/spending?group_by=category&interval=month&start=2026-01-10&end=2026-03-31", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	jan := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	oct := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	if len(queries) != 2 || len(queries[0].AccountIDs) != 2 || queries[0].GroupBy != models.ReportByCategory ||
+		!queries[0].Start.Equal(jan) || !queries[1].Start.Equal(oct) || !queries[1].End.Equal(jan.Add(-time.Nanosecond)) {
+		t.Errorf("Expected this quarter and last over both accounts, got %+v", queries)
+	}
+}
+
+// Test GetSpendingReport - account groups are labelled, falling back to the account ID
+func TestGetSpendingReport_LabelsAccounts(t *testing.T) {
+	checking, savings := primitive.NewObjectID(), primitive.NewObjectID()
+	jan := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	oct := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	rows := map[time.Time][]repository.ReportRow{
+		jan: {{Bucket: jan, Key: checking.Hex(), Spending: 812.5, Count: 9}},
+		oct: {{Bucket: oct, Key: checking.Hex(), Spending: 650}, {Bucket: oct, Key: savings.Hex(), Income: 20}},
+	}
+	reports := &MockReportRepository{
+		SpendingTotalsFunc: func(ctx context.Context, query repository.ReportQuery) ([]repository.ReportRow, error) {
+			return rows[query.Start], nil
+		},
+	}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			if id == checking {
+				return &models.Account{ID: id, AccountLabel: "Everyday Checking"}, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+	app := newReportApp(reports, accounts, &AllowAllAccess{Accounts: []primitive.ObjectID{checking, savings}})
+
+	rec := userRequest(t, app, testUserID, "GET", "/reports/spending?group_by=account&interval=month&start=2026-01-10&end=2026-03-31", nil)
+	var report services.SpendingReport
+	_ = json.Unmarshal(rec.Body.Bytes(), &report)
+	if len(report.Groups) != 2 || report.Groups[0].Label != "Everyday Checking" || report.Groups[0].PreviousSpending != 650 ||
+		report.Groups[1].Label != savings.Hex() || len(report.Buckets) != 3 || report.Buckets[0].Groups[0].Label != "Everyday Checking" {
+		t.Errorf("Expected labelled account groups over three months, got %+v", report)
+	}
+}
+
+// Test GetSpendingReport - unknown groupings, intervals and bad ranges are refused
+func TestGetSpendingReport_InvalidQuery(t *testing.T) {
+	reports := &MockReportRepository{
+		SpendingTotalsFunc: func(ctx context.Context, query repository.ReportQuery) ([]repository.ReportRow, error) {
+			t.Error("Expected no query to run")
+			return nil, nil
+		},
+	}
+	app := newReportApp(reports, &MockAccountRepository{}, &AllowAllAccess{})
+
+	for _, query := range []string{"group_by=payee", "interval=fortnight", "start=2026-03-01&end=2026-01-01", "start=yesterday"} {
+		if rec := userRequest(t, app, testUserID, "GET", "/reports/spending?"+query, nil); rec.Code != fiber.StatusBadRequest {
+			t.Errorf("Expected %s to be refused, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/routes"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockRewardRepository is a mock implementation of repository.RewardRepository for testing
+type MockRewardRepository struct {
+	GetProgramByIDFunc   func(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error)
+	ListProgramsFunc     func(ctx context.Context) ([]models.RewardProgram, error)
+	CreateProgramFunc    func(ctx context.Context, program *models.RewardProgram) error
+	ListRulesFunc        func(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error)
+	CreateRuleFunc       func(ctx context.Context, rule *models.RewardRule) error
+	ListRedemptionsFunc  func(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRedemption, error)
+	CreateRedemptionFunc func(ctx context.Context, redemption *models.RewardRedemption) error
+}
+
+func (m *MockRewardRepository) GetProgramByID(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error) {
+	if m.GetProgramByIDFunc != nil {
+		return m.GetProgramByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) ListPrograms(ctx context.Context) ([]models.RewardProgram, error) {
+	if m.ListProgramsFunc != nil {
+		return m.ListProgramsFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) CreateProgram(ctx context.Context, program *models.RewardProgram) error {
+	if m.CreateProgramFunc != nil {
+		return m.CreateProgramFunc(ctx, program)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) ListRules(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error) {
+	if m.ListRulesFunc != nil {
+		return m.ListRulesFunc(ctx, accountIDs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) CreateRule(ctx context.Context, rule *models.RewardRule) error {
+	if m.CreateRuleFunc != nil {
+		return m.CreateRuleFunc(ctx, rule)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) ListRedemptions(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRedemption, error) {
+	if m.ListRedemptionsFunc != nil {
+		return m.ListRedemptionsFunc(ctx, accountIDs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRewardRepository) CreateRedemption(ctx context.Context, redemption *models.RewardRedemption) error {
+	if m.CreateRedemptionFunc != nil {
+		return m.CreateRedemptionFunc(ctx, redemption)
+	}
+	return errors.New("not implemented")
+}
+
+// rewardCard is a card earning 4x on dining and 1x on everything else in a program
+// worth a cent a point. Its redemptions are kept in redemptions.
+type rewardCard struct {
+	ownerID     primitive.ObjectID
+	accountID   primitive.ObjectID
+	program     models.RewardProgram
+	rules       []models.RewardRule
+	mu          sync.Mutex
+	redemptions []models.RewardRedemption
+	redeemed    float64
+}
+
+func newRewardCard() *rewardCard {
+	card := &rewardCard{
+		ownerID:   primitive.NewObjectID(),
+		accountID: primitive.NewObjectID(),
+		program:   models.RewardProgram{ID: primitive.NewObjectID(), Name: "Membership Rewards", PointValue: 0.01},
+	}
+	card.rules = []models.RewardRule{
+		{AccountID: card.accountID, ProgramID: card.program.ID, Multiplier: 1},
+		{AccountID: card.accountID, ProgramID: card.program.ID, Category: "Dining", Multiplier: 4},
+	}
+	return card
+}
+
+// rewards serves the card's program and rules and records redemptions
+func (c *rewardCard) rewards() *MockRewardRepository {
+	return &MockRewardRepository{
+		GetProgramByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error) {
+			if id != c.program.ID {
+				return nil, mongo.ErrNoDocuments
+			}
+			return &c.program, nil
+		},
+		ListProgramsFunc: func(ctx context.Context) ([]models.RewardProgram, error) {
+			return []models.RewardProgram{c.program}, nil
+		},
+		CreateProgramFunc: func(ctx context.Context, program *models.RewardProgram) error {
+			program.ID = primitive.NewObjectID()
+			return nil
+		},
+		ListRulesFunc: func(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error) {
+			var rules []models.RewardRule
+			for _, rule := range c.rules {
+				if slices.Contains(accountIDs, rule.AccountID) {
+					rules = append(rules, rule)
+				}
+			}
+			return rules, nil
+		},
+		CreateRuleFunc: func(ctx context.Context, rule *models.RewardRule) error {
+			rule.ID = primitive.NewObjectID()
+			c.rules = append(c.rules, *rule)
+			return nil
+		},
+		ListRedemptionsFunc: func(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRedemption, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return slices.Clone(c.redemptions), nil
+		},
+		CreateRedemptionFunc: func(ctx context.Context, redemption *models.RewardRedemption) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			redemption.ID = primitive.NewObjectID()
+			c.redemptions = append(c.redemptions, *redemption)
+			return nil
+		},
+	}
+}
+
+// accounts serves the card as an account of its owner and spends points only while
+// the redeemed total stays within the points earned
+func (c *rewardCard) accounts() *MockAccountRepository {
+	return &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return &models.Account{ID: id, AccountLabel: "Gold Card", OwnerID: c.ownerID}, nil
+		},
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return []primitive.ObjectID{c.accountID}, nil
+		},
+		SpendPointsFunc: func(ctx context.Context, id primitive.ObjectID, points, earned float64) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.redeemed+points > earned {
+				return mongo.ErrNoDocuments
+			}
+			c.redeemed += points
+			return nil
+		},
+	}
+}
+
+// newRewardService serves the card with purchases of amount earning points
+func newRewardService(card *rewardCard, spend map[float32]float32) *services.RewardService {
+	var txns []models.Transaction
+	for amount, points := range spend {
+		txns = append(txns, models.Transaction{AccountID: card.accountID, Type: models.TransactionTypeDebit, Amount: amount, PointsRewarded: points})
+	}
+	transactions := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return txns, nil
+		},
+	}
+	accounts := card.accounts()
+	return services.NewRewardService(card.rewards(), &MockUserRepository{}, accounts, transactions, &MockTxRunner{},
+		services.NewAccessService(accounts, noHouseholds()))
+}
+
+func newRewardApp(service *services.RewardService) *fiber.App {
+	handler := handlers.NewRewardHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/rewards", handler.GetSummary)
+	app.Get("/rewards/rules", handler.ListRules)
+	app.Post("/rewards/rules", handler.CreateRule)
+	app.Post("/rewards/redemptions", handler.Redeem)
+	return app
+}
+
+// Test Enrich - each split allocation earns at its category multiplier
+func TestRewards_EnrichUsesCategoryMultipliers(t *testing.T) {
+	card := newRewardCard()
+	txn := &models.Transaction{
+		AccountID: card.accountID,
+		Type:      models.TransactionTypeDebit,
+		Amount:    100,
+		Splits: []models.TransactionSplit{
+			{Amount: 60, Category: "Dining"},
+			{Amount: 40, Category: "Groceries"},
+		},
+	}
+
+	if err := newRewardService(card, nil).Enrich(context.Background(), txn); err != nil {
+		t.Fatalf("Enrich failed: %v", err)
+	}
+	if txn.PointsRewarded != 280 {
+		t.Errorf("Expected 280 points (60x4 + 40x1), got %v", txn.PointsRewarded)
+	}
+}
+
+// Test GetSummary - balance, value and effective cashback rate per card
+func TestRewards_Summary(t *testing.T) {
+	card := newRewardCard()
+	app := newRewardApp(newRewardService(card, map[float32]float32{200: 800, 300: 300}))
+
+	rec := userRequest(t, app, card.ownerID, "GET", "/rewards", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusOK, rec.Code)
+	}
+
+	var summary services.RewardsSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(summary.Cards) != 1 {
+		t.Fatalf("Expected 1 card, got %d", len(summary.Cards))
+	}
+	if got := summary.Cards[0]; got.PointsBalance != 1100 || got.BalanceValue != 11 || got.EffectiveRate != 0.022 {
+		t.Errorf("Expected 1100 points worth $11 at 2.2%%, got %+v", got)
+	}
+}
+
+// Test Redeem - cannot spend more points than the card holds
+func TestRewards_RedeemInsufficientPoints(t *testing.T) {
+	card := newRewardCard()
+	app := newRewardApp(newRewardService(card, map[float32]float32{50: 50}))
+
+	rec := userRequest(t, app, card.ownerID, "POST", "/rewards/redemptions", handlers.RedemptionPayload{AccountID: card.accountID.Hex(), Points: 500})
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+}
+
+// Test Redeem - the value comes from the program's point value, not the request
+func TestRewards_RedeemValuesPointsOnServer(t *testing.T) {
+	card := newRewardCard()
+	app := newRewardApp(newRewardService(card, map[float32]float32{250: 1000}))
+
+	rec := userRequest(t, app, card.ownerID, "POST", "/rewards/redemptions", map[string]any{"account_id": card.accountID.Hex(), "points": 400, "value": 4000})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusCreated, rec.Code)
+	}
+
+	var redemption models.RewardRedemption
+	if err := json.Unmarshal(rec.Body.Bytes(), &redemption); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if redemption.Value != 4 {
+		t.Errorf("Expected 400 points worth $4, got %v", redemption.Value)
+	}
+}
+
+// Test Redeem - concurrent redemptions never spend more points than were earned
+func TestRewards_ConcurrentRedemptions(t *testing.T) {
+	card := newRewardCard()
+	service := newRewardService(card, map[float32]float32{250: 1000})
+
+	errs := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = service.Redeem(context.Background(), card.ownerID, &models.RewardRedemption{AccountID: card.accountID, Points: 400})
+		}()
+	}
+	wg.Wait()
+
+	redeemed := 0
+	for _, err := range errs {
+		if err == nil {
+			redeemed++
+		} else if !errors.Is(err, services.ErrInsufficientPoints) {
+			t.Errorf("Expected ErrInsufficientPoints, got %v", err)
+		}
+	}
+	if redeemed != 2 || len(card.redemptions) != 2 || card.redeemed != 800 {
+		t.Errorf("Expected 2 redemptions of 800 points, got %d (%d recorded, %v points)", redeemed, len(card.redemptions), card.redeemed)
+	}
+}
+
+// Test ListRules - only the card's owner sees its earn rules
+func TestRewards_ListRulesForbiddenForOthers(t *testing.T) {
+	card := newRewardCard()
+	app := newRewardApp(newRewardService(card, nil))
+
+	rec := userRequest(t, app, primitive.NewObjectID(), "GET", "/rewards/rules?account_id="+card.accountID.Hex(), nil)
+
+	if rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden), got %d", fiber.StatusForbidden, rec.Code)
+	}
+}
+
+// Test CreateRule - only the card's owner may add earn rules
+func TestRewards_CreateRuleForbiddenForOthers(t *testing.T) {
+	card := newRewardCard()
+	app := newRewardApp(newRewardService(card, nil))
+
+	rec := userRequest(t, app, primitive.NewObjectID(), "POST", "/rewards/rules",
+		handlers.RewardRulePayload{AccountID: card.accountID.Hex(), ProgramID: card.program.ID.Hex(), Category: "Travel", Multiplier: 10})
+
+	if rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden), got %d", fiber.StatusForbidden, rec.Code)
+	}
+	if len(card.rules) != 2 {
+		t.Errorf("Expected the card to keep its 2 rules, got %d", len(card.rules))
+	}
+}
+
+// Test CreateProgram - programs are shared by everyone, so only admins may add them
+func TestRewards_CreateProgramRequiresAdmin(t *testing.T) {
+	card := newRewardCard()
+	adminID := primitive.NewObjectID()
+	users := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			if id == adminID {
+				return &models.User{ID: id, Role: models.RoleAdmin}, nil
+			}
+			return &models.User{ID: id, Role: models.RoleUser}, nil
+		},
+	}
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Use(middleware.AuthorizationMiddleware(services.NewAdminService(users, auditStore(nil))))
+	routes.SetupRewardRoutes(app, handlers.NewRewardHandler(newRewardService(card, nil)))
+	program := handlers.RewardProgramPayload{Name: "Ultimate Rewards", PointValue: 0.0125}
+
+	if rec := userRequest(t, app, card.ownerID, "POST", "/api/rewards/programs", program); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden) for a user, got %d", fiber.StatusForbidden, rec.Code)
+	}
+	if rec := userRequest(t, app, adminID, "POST", "/api/rewards/programs", program); rec.Code != fiber.StatusCreated {
+		t.Errorf("Expected status code %d for an admin, got %d: %s", fiber.StatusCreated, rec.Code, rec.Body.String())
+	}
+}
+
+// Test SetSplits - splitting a purchase earns again at each split's multiplier
+func TestRewards_SetSplitsRecomputesPoints(t *testing.T) {
+	card := newRewardCard()
+	txn := &models.Transaction{ID: primitive.NewObjectID(), AccountID: card.accountID, Type: models.TransactionTypeDebit, Amount: 100, Category: "Shopping", PointsRewarded: 100}
+	var points float32
+	transactions := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			copied := *txn
+			return &copied, nil
+		},
+		UpdateSplitsFunc: func(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, pointsRewarded float32) (*models.Transaction, error) {
+			points = pointsRewarded
+			return txn, nil
+		},
+	}
+	service := services.NewTransactionService(transactions, card.accounts(), &MockBudgetRepository{}, &MockTxRunner{}, &AllowAllAccess{})
+	service.AddEnricher(newRewardService(card, nil))
+	handler := handlers.NewTransactionHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Put("/transactions/:id/splits", handler.SetSplits)
+
+	rec := userRequest(t, app, card.ownerID, "PUT", "/transactions/"+txn.ID.Hex()+"/splits", handlers.SplitsPayload{Splits: []handlers.SplitPayload{
+		{Amount: 75, Category: "Dining"},
+		{Amount: 25, Category: "Shopping"},
+	}})
+
+	if rec.Code != fiber.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	if points != 325 {
+		t.Errorf("Expected 325 points (75x4 + 25x1), got %v", points)
+	}
+}
+
+// Test Sync - synced card purchases earn points from the card's rules
+func TestRewards_SyncedPurchasesEarnPoints(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	user := &models.User{ID: primitive.NewObjectID()}
+	var transactions []*models.Transaction
+	service := newSyncService(t, connectionStore(map[primitive.ObjectID]*models.BankConnection{}), syncedAccountStore(map[string]*models.Account{}),
+		syncedTransactionStore(&transactions), profileAccounts(user), &now)
+	rewards := &MockRewardRepository{
+		ListRulesFunc: func(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error) {
+			return []models.RewardRule{{AccountID: accountIDs[0], Multiplier: 2}}, nil
+		},
+	}
+	service.AddEnricher(services.NewRewardService(rewards, &MockUserRepository{}, &MockAccountRepository{}, &MockTransactionRepository{}, &MockTxRunner{}, &AllowAllAccess{}))
+
+	connectFakeBank(t, service, user.ID)
+
+	debits := 0
+	for _, txn := range transactions {
+		if txn.Type != models.TransactionTypeDebit || txn.IsTransfer() {
+			continue
+		}
+		debits++
+		if txn.PointsRewarded != txn.Amount*2 {
+			t.Errorf("Expected %v to earn %v points, got %v", txn.Name, txn.Amount*2, txn.PointsRewarded)
+		}
+	}
+	if debits == 0 {
+		t.Fatalf("Expected the sync to create purchases")
+	}
+}
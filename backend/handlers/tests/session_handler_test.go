@@ -0,0 +1,237 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/routes"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var sessionSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// userAccounts keeps signed-up users in users, looked up by ID, username or email
+func userAccounts(users map[primitive.ObjectID]*models.User) *MockUserRepository {
+	return &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			if user, ok := users[id]; ok {
+				copied := *user
+				return &copied, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		ListUsersByLoginFunc: func(ctx context.Context, login string) ([]models.User, error) {
+			var out []models.User
+			for _, user := range users {
+				if user.Username == login || user.Email == login {
+					out = append(out, *user)
+				}
+			}
+			return out, nil
+		},
+		CreateUserFunc: func(ctx context.Context, user *models.User) error {
+			stored := *user
+			users[user.ID] = &stored
+			return nil
+		},
+	}
+}
+
+// member is a signed-up user with password "correct horse"
+func member(t *testing.T) *models.User {
+	t.Helper()
+	hash, err := services.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	return &models.User{ID: primitive.NewObjectID(), Username: "sam", Email: "sam@example.com", PasswordHash: hash}
+}
+
+// newSessionApp serves sign-up, sign-in and the profile routes behind session authentication
+func newSessionApp(users *MockUserRepository) (*fiber.App, *services.SessionService) {
+	sessions := services.NewSessionService(users, sessionSecret)
+	app := fiber.New()
+	app.Use(middleware.SessionMiddleware(sessions))
+	app.Use(middleware.AuthorizationMiddleware(services.NewAdminService(users, auditStore(nil))))
+	routes.SetupSessionRoutes(app, handlers.NewSessionHandler(sessions))
+	routes.SetupProductRoutes(app, handlers.NewUserHandler(services.NewUserService(users)))
+	return app, sessions
+}
+
+// bearerRequest sends a request authenticated with a session token
+func bearerRequest(t *testing.T, app *fiber.App, token, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var body io.Reader
+	if payload != nil {
+		data, _ := json.Marshal(payload)
+		body = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	recorder.Code = resp.StatusCode
+	respBody, _ := io.ReadAll(resp.Body)
+	recorder.Body.Write(respBody)
+	return recorder
+}
+
+func login(t *testing.T, app *fiber.App, login, password string) (*httptest.ResponseRecorder, services.IssuedLogin) {
+	t.Helper()
+	rec := bearerRequest(t, app, "", "POST", "/api/auth/login", handlers.LoginPayload{Login: login, Password: password})
+	var issued services.IssuedLogin
+	_ = json.Unmarshal(rec.Body.Bytes(), &issued)
+	return rec, issued
+}
+
+// Test Login - the session token authenticates the user
+func TestLogin_SessionAuthenticatesUser(t *testing.T) {
+	user := member(t)
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	rec, issued := login(t, app, "sam@example.com", "correct horse")
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, rec.Code, rec.Body.String())
+	}
+	if issued.Session.UserID != user.ID || issued.TwoFactorRequired {
+		t.Errorf("Expected a session for the user without two-factor, got %+v", issued)
+	}
+	if rec := bearerRequest(t, app, issued.SessionToken, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected the session to reach the user's profile, got %d", rec.Code)
+	}
+}
+
+// Test Login - a wrong password is refused
+func TestLogin_WrongPassword(t *testing.T) {
+	user := member(t)
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	if rec, _ := login(t, app, "sam", "wrong horse"); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, rec.Code)
+	}
+}
+
+// Test Login - a user sharing another user's username cannot take over their sign-in
+func TestLogin_SharedLoginChecksEachPassword(t *testing.T) {
+	user := member(t)
+	impostor := member(t)
+	impostor.ID = primitive.NewObjectID()
+	impostor.PasswordHash, _ = services.HashPassword("another horse")
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user, impostor.ID: impostor}))
+
+	rec, issued := login(t, app, "sam", "correct horse")
+	if rec.Code != fiber.StatusCreated || issued.Session.UserID != user.ID {
+		t.Errorf("Expected the password to pick the user, got %d for %s", rec.Code, issued.Session.UserID.Hex())
+	}
+}
+
+// Test Login - users without a password cannot sign in
+func TestLogin_NoPassword(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID(), Username: "legacy"}
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	if rec, _ := login(t, app, "legacy", ""); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, rec.Code)
+	}
+}
+
+// Test Login - disabled users get no session
+func TestLogin_DisabledUser(t *testing.T) {
+	user := member(t)
+	user.Disabled = true
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	if rec, _ := login(t, app, "sam", "correct horse"); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusForbidden, rec.Code)
+	}
+}
+
+// Test Login - users with two-factor authentication are told to verify a code
+func TestLogin_FlagsTwoFactor(t *testing.T) {
+	user := member(t)
+	user.TOTPEnabled = true
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	if _, issued := login(t, app, "sam", "correct horse"); !issued.TwoFactorRequired {
+		t.Errorf("Expected two-factor verification to be required")
+	}
+}
+
+// Test CreateUser - a password given at sign-up signs the user in
+func TestCreateUser_PasswordSignsIn(t *testing.T) {
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{}))
+
+	rec := bearerRequest(t, app, "", "POST", "/api/products/", handlers.Payload{Username: "newuser", Password: "hunter2hunter2"})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected sign-up to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := login(t, app, "newuser", "hunter2hunter2"); rec.Code != fiber.StatusCreated {
+		t.Errorf("Expected the new user to sign in, got %d", rec.Code)
+	}
+}
+
+// Test CreateUser - short passwords are refused
+func TestCreateUser_WeakPassword(t *testing.T) {
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{}))
+
+	rec := bearerRequest(t, app, "", "POST", "/api/products/", handlers.Payload{Username: "newuser", Password: "short"})
+	if rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusBadRequest, rec.Code)
+	}
+}
+
+// Test SessionMiddleware - the X-User-ID header is refused
+func TestSessionMiddleware_RejectsUserIDHeader(t *testing.T) {
+	user := member(t)
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+
+	if rec := userRequest(t, app, user.ID, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, rec.Code)
+	}
+}
+
+// Test SessionMiddleware - a token signed with another secret is refused
+func TestSessionMiddleware_RejectsForgedToken(t *testing.T) {
+	user := member(t)
+	app, _ := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+	forger := services.NewSessionService(&MockUserRepository{}, []byte("not-the-server-secret-not-the-se"))
+	token, _, err := forger.SignSession(user.ID, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("Failed to sign session: %v", err)
+	}
+
+	if rec := bearerRequest(t, app, token, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, rec.Code)
+	}
+}
+
+// Test SessionMiddleware - an expired token is refused
+func TestSessionMiddleware_RejectsExpiredToken(t *testing.T) {
+	user := member(t)
+	app, sessions := newSessionApp(userAccounts(map[primitive.ObjectID]*models.User{user.ID: user}))
+	token, _, err := sessions.SignSession(user.ID, time.Now().UTC().Add(-services.SessionTTL-time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to sign session: %v", err)
+	}
+
+	if rec := bearerRequest(t, app, token, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, rec.Code)
+	}
+}
@@ -0,0 +1,404 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/routes"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockTokenRepository is a mock implementation of repository.TokenRepository for testing
+type MockTokenRepository struct {
+	CreateTokenFunc    func(ctx context.Context, token *models.PersonalAccessToken) error
+	GetTokenByHashFunc func(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	ListByUserFunc     func(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error)
+	RevokeTokenFunc    func(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error
+	TouchTokenFunc     func(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+func (m *MockTokenRepository) CreateToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	if m.CreateTokenFunc != nil {
+		return m.CreateTokenFunc(ctx, token)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	if m.GetTokenByHashFunc != nil {
+		return m.GetTokenByHashFunc(ctx, tokenHash)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTokenRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error) {
+	if m.ListByUserFunc != nil {
+		return m.ListByUserFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTokenRepository) RevokeToken(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+	if m.RevokeTokenFunc != nil {
+		return m.RevokeTokenFunc(ctx, id, userID, at)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTokenRepository) TouchToken(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if m.TouchTokenFunc != nil {
+		return m.TouchTokenFunc(ctx, id, at)
+	}
+	return errors.New("not implemented")
+}
+
+// tokenStore keeps the access tokens the service issues in tokens
+func tokenStore(tokens map[primitive.ObjectID]*models.PersonalAccessToken) *MockTokenRepository {
+	return &MockTokenRepository{
+		CreateTokenFunc: func(ctx context.Context, token *models.PersonalAccessToken) error {
+			token.ID = primitive.NewObjectID()
+			stored := *token
+			tokens[token.ID] = &stored
+			return nil
+		},
+		GetTokenByHashFunc: func(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+			for _, token := range tokens {
+				if token.TokenHash == tokenHash {
+					copied := *token
+					return &copied, nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		ListByUserFunc: func(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error) {
+			var out []models.PersonalAccessToken
+			for _, token := range tokens {
+				if token.UserID == userID {
+					out = append(out, *token)
+				}
+			}
+			return out, nil
+		},
+		RevokeTokenFunc: func(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+			token, ok := tokens[id]
+			if !ok || token.UserID != userID || !token.RevokedAt.IsZero() {
+				return mongo.ErrNoDocuments
+			}
+			token.RevokedAt = at
+			return nil
+		},
+		TouchTokenFunc: func(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+			tokens[id].LastUsedAt = at
+			return nil
+		},
+	}
+}
+
+type tokenFixture struct {
+	app     *fiber.App
+	tokens  map[primitive.ObjectID]*models.PersonalAccessToken
+	users   map[primitive.ObjectID]*models.User
+	userID  primitive.ObjectID
+	adminID primitive.ObjectID
+}
+
+// newTokenFixture serves the token, user, admin and transaction routes behind token authentication
+func newTokenFixture() *tokenFixture {
+	f := &tokenFixture{
+		tokens:  map[primitive.ObjectID]*models.PersonalAccessToken{},
+		userID:  primitive.NewObjectID(),
+		adminID: primitive.NewObjectID(),
+	}
+	f.users = map[primitive.ObjectID]*models.User{
+		f.userID:  {ID: f.userID, Username: "script-owner"},
+		f.adminID: {ID: f.adminID, Username: "admin", Role: models.RoleAdmin},
+	}
+	userRepo := &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			if user, ok := f.users[id]; ok {
+				return user, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		GetAllUsersFunc: func(ctx context.Context) ([]models.User, error) {
+			return []models.User{*f.users[f.userID], *f.users[f.adminID]}, nil
+		},
+		UpdateUserFunc: func(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error) {
+			return update, nil
+		},
+		DeleteUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			return nil
+		},
+	}
+
+	tokenService := services.NewTokenService(tokenStore(f.tokens), userRepo)
+	adminService := services.NewAdminService(userRepo, auditStore(nil))
+	f.app = fiber.New()
+	f.app.Use(middleware.UserContextMiddleware())
+	f.app.Use(middleware.TokenAuthMiddleware(tokenService))
+	f.app.Use(middleware.AuthorizationMiddleware(adminService))
+	routes.SetupTokenRoutes(f.app, handlers.NewTokenHandler(tokenService))
+	routes.SetupProductRoutes(f.app, handlers.NewUserHandler(services.NewUserService(userRepo)))
+	routes.SetupAdminRoutes(f.app, handlers.NewAdminHandler(adminService))
+	transactionRepo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+	routes.SetupTransactionRoutes(f.app, handlers.NewTransactionHandler(services.NewTransactionService(transactionRepo, &MockAccountRepository{}, &MockBudgetRepository{}, &MockTxRunner{}, &AllowAllAccess{})))
+	return f
+}
+
+func (f *tokenFixture) issue(t *testing.T, userID primitive.ObjectID, scopes ...string) services.IssuedToken {
+	t.Helper()
+	rec := userRequest(t, f.app, userID, "POST", "/api/tokens", handlers.TokenPayload{Name: "import script", Scopes: scopes})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected token to be issued, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var issued services.IssuedToken
+	_ = json.Unmarshal(rec.Body.Bytes(), &issued)
+	return issued
+}
+
+// bearer sends a request authenticated only by secret
+func (f *tokenFixture) bearer(t *testing.T, secret, method, path string) int {
+	t.Helper()
+	req := testRequest(method, path, nil)
+	req.Header.Del("X-User-ID")
+	req.Header.Set("Authorization", "Bearer "+secret)
+	resp, err := f.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// demote takes the admin role away from userID
+func (f *tokenFixture) demote(userID primitive.ObjectID) {
+	f.users[userID].Role = models.RoleUser
+}
+
+// asImpersonator sends payload as the admin while impersonating the user
+func (f *tokenFixture) asImpersonator(t *testing.T, method, path string, payload any) int {
+	t.Helper()
+	rec := userRequest(t, f.app, f.adminID, "POST", "/api/admin/users/"+f.userID.Hex()+"/impersonate", handlers.ImpersonatePayload{Reason: "support ticket 12"})
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected impersonation to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var impersonation models.Impersonation
+	_ = json.Unmarshal(rec.Body.Bytes(), &impersonation)
+
+	body, _ := json.Marshal(payload)
+	req := testRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", f.adminID.Hex())
+	req.Header.Set("X-Impersonation-ID", impersonation.ID.Hex())
+	resp, err := f.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// voidPath is a transaction write route; the transaction does not exist, so a request
+// that gets past authorization is answered 404
+func voidPath() string {
+	return "/api/transactions/" + primitive.NewObjectID().Hex() + "/void"
+}
+
+// Test CreateToken - the secret is prefixed and only its hash is stored
+func TestTokens_StoresHashOnly(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeReadTransactions)
+
+	if len(issued.AccessToken) < 40 || issued.AccessToken[:len(models.TokenPrefix)] != models.TokenPrefix {
+		t.Fatalf("Expected a %s token, got %q", models.TokenPrefix, issued.AccessToken)
+	}
+	if stored := f.tokens[issued.Token.ID]; stored.TokenHash == "" || stored.TokenHash == issued.AccessToken {
+		t.Errorf("Expected only a hash of the token to be stored")
+	}
+}
+
+// Test CreateToken - unknown scopes are rejected
+func TestTokens_UnknownScope(t *testing.T) {
+	f := newTokenFixture()
+
+	rec := userRequest(t, f.app, f.userID, "POST", "/api/tokens", handlers.TokenPayload{Name: "x", Scopes: []string{"write:everything"}})
+	if rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusBadRequest, rec.Code)
+	}
+}
+
+// Test CreateToken - only admins may be granted the admin scope
+func TestTokens_AdminScopeNeedsAdmin(t *testing.T) {
+	f := newTokenFixture()
+
+	rec := userRequest(t, f.app, f.userID, "POST", "/api/tokens", handlers.TokenPayload{Name: "x", Scopes: []string{models.ScopeAdmin}})
+	if rec.Code != fiber.StatusForbidden || len(f.tokens) != 0 {
+		t.Errorf("Expected status code %d and no token, got %d", fiber.StatusForbidden, rec.Code)
+	}
+}
+
+// Test CreateToken - tokens cannot mint tokens
+func TestTokens_TokenCannotMintTokens(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeWriteTransactions)
+
+	if status := f.bearer(t, issued.AccessToken, "POST", "/api/tokens"); status != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusForbidden, status)
+	}
+}
+
+// Test CreateToken - an admin impersonating a user cannot mint tokens as them
+func TestTokens_RefusedWhileImpersonating(t *testing.T) {
+	f := newTokenFixture()
+
+	status := f.asImpersonator(t, "POST", "/api/tokens", handlers.TokenPayload{Name: "x", Scopes: []string{models.ScopeReadTransactions}})
+	if status != fiber.StatusForbidden || len(f.tokens) != 0 {
+		t.Errorf("Expected status code %d and no token, got %d", fiber.StatusForbidden, status)
+	}
+}
+
+// Test TokenAuthMiddleware - a read token reads transactions and its use is recorded
+func TestTokens_ReadScopeReads(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeReadTransactions)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/transactions"); status != fiber.StatusOK {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusOK, status)
+	}
+	if f.tokens[issued.Token.ID].LastUsedAt.IsZero() {
+		t.Errorf("Expected last use to be recorded")
+	}
+}
+
+// Test TokenAuthMiddleware - a read token is refused transaction writes
+func TestTokens_ReadScopeCannotWrite(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeReadTransactions)
+
+	if status := f.bearer(t, issued.AccessToken, "POST", voidPath()); status != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusForbidden, status)
+	}
+}
+
+// Test TokenAuthMiddleware - a write token reads and writes transactions
+func TestTokens_WriteScopeWrites(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeWriteTransactions)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/transactions"); status != fiber.StatusOK {
+		t.Errorf("Expected a write token to read, got %d", status)
+	}
+	if status := f.bearer(t, issued.AccessToken, "POST", voidPath()); status != fiber.StatusNotFound {
+		t.Errorf("Expected a write token to reach the write, got %d", status)
+	}
+}
+
+// Test TokenAuthMiddleware - transaction scopes stay on transaction routes
+func TestTokens_ScopesStayOnTheirRoutes(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeWriteTransactions)
+	self := "/api/products/" + f.userID.Hex()
+
+	for _, req := range []struct{ method, path string }{
+		{"GET", self},
+		{"PUT", self},
+		{"DELETE", self},
+		{"GET", "/api/tokens"},
+	} {
+		if status := f.bearer(t, issued.AccessToken, req.method, req.path); status != fiber.StatusForbidden {
+			t.Errorf("Expected %s %s to be refused, got %d", req.method, req.path, status)
+		}
+	}
+}
+
+// Test TokenAuthMiddleware - an admin's transaction token is kept out of admin routes
+func TestTokens_AdminsTransactionTokenIsNotAdmin(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.adminID, models.ScopeWriteTransactions)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/admin/users"); status != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusForbidden, status)
+	}
+}
+
+// Test TokenAuthMiddleware - an admin token reaches admin routes but not transactions
+func TestTokens_AdminScopeReachesAdminRoutes(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.adminID, models.ScopeAdmin)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/admin/users"); status != fiber.StatusOK {
+		t.Errorf("Expected an admin token to reach admin routes, got %d", status)
+	}
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/transactions"); status != fiber.StatusForbidden {
+		t.Errorf("Expected an admin token to be refused transactions, got %d", status)
+	}
+}
+
+// Test TokenAuthMiddleware - the admin scope follows the owner's current role
+func TestTokens_AdminScopeFollowsRole(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.adminID, models.ScopeAdmin)
+	f.demote(f.adminID)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/admin/users"); status != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusForbidden, status)
+	}
+}
+
+// Test RevokeToken - a revoked token is unauthorized
+func TestTokens_RevokedIsUnauthorized(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeReadTransactions)
+
+	if rec := userRequest(t, f.app, f.userID, "DELETE", "/api/tokens/"+issued.Token.ID.Hex(), nil); rec.Code != fiber.StatusNoContent {
+		t.Fatalf("Expected token to be revoked, got %d", rec.Code)
+	}
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/transactions"); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, status)
+	}
+}
+
+// Test RevokeToken - users cannot revoke someone else's token
+func TestTokens_RevokeOthersToken(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.adminID, models.ScopeReadTransactions)
+
+	if rec := userRequest(t, f.app, f.userID, "DELETE", "/api/tokens/"+issued.Token.ID.Hex(), nil); rec.Code != fiber.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusNotFound, rec.Code)
+	}
+	if !f.tokens[issued.Token.ID].RevokedAt.IsZero() {
+		t.Errorf("Expected the token to stay usable")
+	}
+}
+
+// Test TokenAuthMiddleware - an expired token is unauthorized
+func TestTokens_ExpiredIsUnauthorized(t *testing.T) {
+	f := newTokenFixture()
+	issued := f.issue(t, f.userID, models.ScopeReadTransactions)
+	f.tokens[issued.Token.ID].ExpiresAt = time.Now().UTC().Add(-time.Minute)
+
+	if status := f.bearer(t, issued.AccessToken, "GET", "/api/transactions"); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, status)
+	}
+}
+
+// Test TokenAuthMiddleware - an unknown token is unauthorized
+func TestTokens_UnknownIsUnauthorized(t *testing.T) {
+	f := newTokenFixture()
+
+	if status := f.bearer(t, models.TokenPrefix+"not-a-real-token", "GET", "/api/transactions"); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, status)
+	}
+}
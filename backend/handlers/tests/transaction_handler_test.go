@@ -0,0 +1,476 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func newTransactionApp(repo *MockTransactionRepository) *fiber.App {
+	return newTransactionAppWithAccounts(repo, &MockAccountRepository{})
+}
+
+func newTransactionAppWithAccounts(repo *MockTransactionRepository, accounts *MockAccountRepository) *fiber.App {
+	budgets := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return &models.Budget{ID: id, UserID: testUserID}, nil
+		},
+	}
+	access := &AllowAllAccess{Accounts: []primitive.ObjectID{primitive.NewObjectID()}}
+	return newTransactionAppWithAccess(repo, accounts, budgets, access)
+}
+
+func newTransactionAppWithAccess(repo *MockTransactionRepository, accounts *MockAccountRepository, budgets *MockBudgetRepository, access services.AccessPolicy) *fiber.App {
+	handler := handlers.NewTransactionHandler(services.NewTransactionService(repo, accounts, budgets, &MockTxRunner{}, access))
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Get("/transactions", handler.ListTransactions)
+	app.Get("/transactions/summary", handler.GetSummary)
+	app.Get("/transactions/categories", handler.GetCategoryReport)
+	app.Put("/transactions/:id/splits", handler.SetSplits)
+	app.Put("/transactions/:id/tags", handler.SetTags)
+	app.Post("/transactions/:id/void", handler.VoidTransaction)
+	app.Post("/accounts/:id/transactions/import", handler.ImportTransactions)
+	return app
+}
+
+func putSplits(t *testing.T, app *fiber.App, id primitive.ObjectID, splits []handlers.SplitPayload) int {
+	payloadJSON, _ := json.Marshal(handlers.SplitsPayload{Splits: splits})
+	req := testRequest("PUT", "/transactions/"+id.Hex()+"/splits", bytes.NewBuffer(payloadJSON))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// Test SetSplits - Success when allocations add up to the parent amount
+func TestSetSplits_Success(t *testing.T) {
+	txn := &models.Transaction{ID: primitive.NewObjectID(), Name: "Costco", Type: models.TransactionTypeDebit, Amount: 120}
+	var saved []models.TransactionSplit
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+		UpdateSplitsFunc: func(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error) {
+			saved = splits
+			txn.Splits = splits
+			return txn, nil
+		},
+	}
+
+	status := putSplits(t, newTransactionApp(repo), txn.ID, []handlers.SplitPayload{
+		{Amount: 80, Category: "Groceries", BudgetID: primitive.NewObjectID().Hex()},
+		{Amount: 40, Category: "Household"},
+	})
+
+	if status != fiber.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusAccepted, status)
+	}
+	if len(saved) != 2 || saved[0].BudgetID.IsZero() {
+		t.Errorf("Expected 2 splits with the first linked to a budget, got %+v", saved)
+	}
+}
+
+// Test SetSplits - Forbidden when a split is charged to a budget the caller can't write
+func TestSetSplits_ForeignBudget(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), OwnerID: testUserID}
+	txn := &models.Transaction{ID: primitive.NewObjectID(), AccountID: account.ID, Type: models.TransactionTypeDebit, Amount: 120}
+	updated := false
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+		UpdateSplitsFunc: func(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error) {
+			updated = true
+			return txn, nil
+		},
+	}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+	}
+	budgets := &MockBudgetRepository{
+		GetBudgetByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+			return &models.Budget{ID: id, UserID: primitive.NewObjectID()}, nil
+		},
+	}
+	access := services.NewAccessService(accounts, noHouseholds())
+
+	status := putSplits(t, newTransactionAppWithAccess(repo, accounts, budgets, access), txn.ID, []handlers.SplitPayload{
+		{Amount: 80, Category: "Groceries", BudgetID: primitive.NewObjectID().Hex()},
+		{Amount: 40, Category: "Household"},
+	})
+
+	if status != fiber.StatusForbidden {
+		t.Errorf("Expected status code %d (Forbidden), got %d", fiber.StatusForbidden, status)
+	}
+	if updated {
+		t.Errorf("Expected the splits not to be saved")
+	}
+}
+
+// Test SetSplits - Rejected when allocations do not sum to the parent amount
+func TestSetSplits_SumMismatch(t *testing.T) {
+	txn := &models.Transaction{ID: primitive.NewObjectID(), Type: models.TransactionTypeDebit, Amount: 120}
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+	}
+
+	status := putSplits(t, newTransactionApp(repo), txn.ID, []handlers.SplitPayload{
+		{Amount: 80, Category: "Groceries"},
+		{Amount: 30, Category: "Household"},
+	})
+
+	if status != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d (Bad Request), got %d", fiber.StatusBadRequest, status)
+	}
+}
+
+// Test SetTags - tags are trimmed, lowercased and deduplicated, and overlong tags are refused
+func TestSetTags_Normalized(t *testing.T) {
+	txn := &models.Transaction{ID: primitive.NewObjectID(), Name: "Delta", Type: models.TransactionTypeDebit, Amount: 420}
+	var saved []string
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+		UpdateTagsFunc: func(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Transaction, error) {
+			saved = tags
+			txn.Tags = tags
+			return txn, nil
+		},
+	}
+	app := newTransactionApp(repo)
+
+	rec := userRequest(t, app, testUserID, "PUT", "/transactions/"+txn.ID.Hex()+"/tags", handlers.TagsPayload{Tags: []string{" Vacation ", "work", "vacation", ""}})
+	if rec.Code != fiber.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	if len(saved) != 2 || saved[0] != "vacation" || saved[1] != "work" {
+		t.Errorf("Expected tags [vacation work], got %v", saved)
+	}
+
+	long := handlers.TagsPayload{Tags: []string{"a-tag-that-is-far-too-long-to-be-useful-anywhere"}}
+	if rec := userRequest(t, app, testUserID, "PUT", "/transactions/"+txn.ID.Hex()+"/tags", long); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d for an overlong tag, got %d", fiber.StatusBadRequest, rec.Code)
+	}
+}
+
+// Test GetCategoryReport - split allocations land in their own categories and transfers are skipped
+func TestGetCategoryReport_RespectsSplits(t *testing.T) {
+	repo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{
+				{Type: models.TransactionTypeDebit, Amount: 120, Category: "Shopping", Splits: []models.TransactionSplit{
+					{Amount: 80, Category: "Groceries"},
+					{Amount: 40, Category: "Household"},
+				}},
+				{Type: models.TransactionTypeDebit, Amount: 20, Category: "Groceries"},
+			}, nil
+		},
+	}
+
+	resp, err := newTransactionApp(repo).Test(testRequest("GET", "/transactions/categories", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var totals []services.CategoryTotal
+	if err := json.Unmarshal(body, &totals); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	spending := map[string]float64{}
+	for _, total := range totals {
+		spending[total.Category] = total.Spending
+	}
+	if spending["Groceries"] != 100 || spending["Household"] != 40 || spending["Shopping"] != 0 {
+		t.Errorf("Expected Groceries 100 and Household 40, got %v", spending)
+	}
+}
+
+// Test SetSplits - reconciled transactions are locked
+func TestSetSplits_Locked(t *testing.T) {
+	txn := &models.Transaction{ID: primitive.NewObjectID(), Amount: 120, ReconciliationID: primitive.NewObjectID()}
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+	}
+
+	status := putSplits(t, newTransactionApp(repo), txn.ID, []handlers.SplitPayload{
+		{Amount: 60, Category: "Groceries"},
+		{Amount: 60, Category: "Household"},
+	})
+
+	if status != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, status)
+	}
+}
+
+// Test ImportTransactions - a posted charge with a tip settles its pending authorization
+func TestImportTransactions_PostsPendingWithTip(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 500}
+	day := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+	pending := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: account.ID, Name: "SQ *BLUE BOTTLE", Type: models.TransactionTypeDebit, Status: models.TransactionStatusPending, Amount: 40, TransactionDate: day},
+		{ID: primitive.NewObjectID(), AccountID: account.ID, Name: "SHELL OIL", Type: models.TransactionTypeDebit, Status: models.TransactionStatusPending, Amount: 25, TransactionDate: day},
+	}
+
+	var postedID primitive.ObjectID
+	var created []*models.Transaction
+	var available float64
+	repo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			var open []models.Transaction
+			for _, txn := range pending {
+				if txn.ID != postedID {
+					open = append(open, txn)
+				}
+			}
+			return open, nil
+		},
+		PostPendingFunc: func(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error) {
+			postedID = id
+			return &models.Transaction{ID: id, AccountID: account.ID, Type: posted.Type, Status: models.TransactionStatusPosted, Amount: posted.Amount}, nil
+		},
+		CreateTransactionFunc: func(ctx context.Context, txn *models.Transaction) error {
+			created = append(created, txn)
+			return nil
+		},
+	}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+		SetAvailableFunc: func(ctx context.Context, id primitive.ObjectID, value float64) error {
+			available = value
+			return nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			account.CurrentBalance += delta
+			return nil
+		},
+	}
+
+	payloadJSON, _ := json.Marshal(handlers.ImportPayload{Transactions: []handlers.ImportTransactionPayload{
+		{Name: "SQ *BLUE BOTTLE #112", Type: models.TransactionTypeDebit, Amount: 48, TransactionDate: day.AddDate(0, 0, 2)},
+		{Name: "PAYROLL", Type: models.TransactionTypeCredit, Amount: 2000, TransactionDate: day.AddDate(0, 0, 2)},
+	}})
+	req := testRequest("POST", "/accounts/"+account.ID.Hex()+"/transactions/import", bytes.NewBuffer(payloadJSON))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := newTransactionAppWithAccounts(repo, accounts).Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusCreated, resp.StatusCode)
+	}
+	if postedID != pending[0].ID {
+		t.Errorf("Expected the coffee authorization to be posted, got %v", postedID)
+	}
+	if len(created) != 1 || created[0].Name != "PAYROLL" || created[0].Status != models.TransactionStatusPosted {
+		t.Errorf("Expected only payroll to be created as posted, got %+v", created)
+	}
+	if account.CurrentBalance != 2452 {
+		t.Errorf("Expected current balance 2452 (500 less the posted 48, plus payroll), got %v", account.CurrentBalance)
+	}
+	if available != 2427 {
+		t.Errorf("Expected available balance 2427 (2452 less the 25 still pending), got %v", available)
+	}
+}
+
+// Test ListTransactions - unknown status filter
+func TestListTransactions_InvalidStatus(t *testing.T) {
+	resp, err := newTransactionApp(&MockTransactionRepository{}).Test(testRequest("GET", "/transactions?status=cleared", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d (Bad Request), got %d", fiber.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+// Test VoidTransaction - voiding a posted purchase gives its amount back to the account
+func TestVoidTransaction_ReversesPostedBalance(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 400}
+	txn := &models.Transaction{ID: primitive.NewObjectID(), AccountID: account.ID, Name: "Duplicate charge", Type: models.TransactionTypeDebit,
+		Status: models.TransactionStatusPosted, Amount: 60}
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return txn, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id primitive.ObjectID, status string) error {
+			return nil
+		},
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return nil, nil
+		},
+	}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			account.CurrentBalance += delta
+			return nil
+		},
+		SetAvailableFunc: func(ctx context.Context, id primitive.ObjectID, value float64) error {
+			return nil
+		},
+	}
+
+	resp, err := newTransactionAppWithAccounts(repo, accounts).Test(testRequest("POST", "/transactions/"+txn.ID.Hex()+"/void", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusAccepted, resp.StatusCode)
+	}
+	if account.CurrentBalance != 460 {
+		t.Errorf("Expected current balance 460 after the void, got %v", account.CurrentBalance)
+	}
+}
+
+// Test ImportTransactions - a posted charge can't settle an authorization locked in a reconciliation
+func TestImportTransactions_LockedPending(t *testing.T) {
+	account := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 500}
+	day := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+	pending := models.Transaction{ID: primitive.NewObjectID(), AccountID: account.ID, Name: "SHELL OIL", Type: models.TransactionTypeDebit,
+		Status: models.TransactionStatusPending, Amount: 25, TransactionDate: day, ReconciliationID: primitive.NewObjectID()}
+	repo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return []models.Transaction{pending}, nil
+		},
+		PostPendingFunc: func(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return account, nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			account.CurrentBalance += delta
+			return nil
+		},
+	}
+
+	rec := userRequest(t, newTransactionAppWithAccounts(repo, accounts), testUserID, "POST", "/accounts/"+account.ID.Hex()+"/transactions/import", handlers.ImportPayload{
+		Transactions: []handlers.ImportTransactionPayload{{Name: "SHELL OIL", Type: models.TransactionTypeDebit, Amount: 25, TransactionDate: day.AddDate(0, 0, 1)}},
+	})
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+	if account.CurrentBalance != 500 {
+		t.Errorf("Expected the balance to stay at 500, got %v", account.CurrentBalance)
+	}
+}
+
+// Test VoidTransaction - voiding one leg of a transfer voids both and restores both balances
+func TestVoidTransaction_VoidsBothTransferLegs(t *testing.T) {
+	checking := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 300}
+	savings := &models.Account{ID: primitive.NewObjectID(), CurrentBalance: 1200}
+	transferID := primitive.NewObjectID()
+	legs := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: checking.ID, TransferID: transferID, Type: models.TransactionTypeDebit, Status: models.TransactionStatusPosted, Amount: 200},
+		{ID: primitive.NewObjectID(), AccountID: savings.ID, TransferID: transferID, Type: models.TransactionTypeCredit, Status: models.TransactionStatusPosted, Amount: 200},
+	}
+	voided := map[primitive.ObjectID]bool{}
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return &legs[0], nil
+		},
+		GetTransactionsByTransferIDFunc: func(ctx context.Context, id primitive.ObjectID) ([]models.Transaction, error) {
+			return legs, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id primitive.ObjectID, status string) error {
+			voided[id] = status == models.TransactionStatusVoid
+			return nil
+		},
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return nil, nil
+		},
+	}
+	balances := map[primitive.ObjectID]*models.Account{checking.ID: checking, savings.ID: savings}
+	accounts := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return balances[id], nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			balances[id].CurrentBalance += delta
+			return nil
+		},
+		SetAvailableFunc: func(ctx context.Context, id primitive.ObjectID, value float64) error {
+			return nil
+		},
+	}
+
+	rec := userRequest(t, newTransactionAppWithAccounts(repo, accounts), testUserID, "POST", "/transactions/"+legs[0].ID.Hex()+"/void", nil)
+
+	if rec.Code != fiber.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	if !voided[legs[0].ID] || !voided[legs[1].ID] {
+		t.Errorf("Expected both legs voided, got %v", voided)
+	}
+	if checking.CurrentBalance != 500 || savings.CurrentBalance != 1000 {
+		t.Errorf("Expected balances 500 and 1000, got %v and %v", checking.CurrentBalance, savings.CurrentBalance)
+	}
+}
+
+// Test VoidTransaction - a transfer with a reconciled leg can't be voided
+func TestVoidTransaction_TransferLegLocked(t *testing.T) {
+	transferID := primitive.NewObjectID()
+	legs := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: primitive.NewObjectID(), TransferID: transferID, Type: models.TransactionTypeDebit, Status: models.TransactionStatusPosted, Amount: 200},
+		{ID: primitive.NewObjectID(), AccountID: primitive.NewObjectID(), TransferID: transferID, Type: models.TransactionTypeCredit, Status: models.TransactionStatusPosted, Amount: 200,
+			ReconciliationID: primitive.NewObjectID()},
+	}
+	updated := false
+	repo := &MockTransactionRepository{
+		GetTransactionByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+			return &legs[0], nil
+		},
+		GetTransactionsByTransferIDFunc: func(ctx context.Context, id primitive.ObjectID) ([]models.Transaction, error) {
+			return legs, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id primitive.ObjectID, status string) error {
+			updated = true
+			return nil
+		},
+	}
+
+	rec := userRequest(t, newTransactionApp(repo), testUserID, "POST", "/transactions/"+legs[0].ID.Hex()+"/void", nil)
+
+	if rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+	if updated {
+		t.Errorf("Expected neither leg to be voided")
+	}
+}
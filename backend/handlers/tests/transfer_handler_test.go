@@ -0,0 +1,524 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockAccountRepository is a mock implementation of repository.AccountRepository for testing
+type MockAccountRepository struct {
+	GetAccountByIDFunc func(ctx context.Context, id primitive.ObjectID) (*models.Account, error)
+	CreateAccountFunc  func(ctx context.Context, account *models.Account) error
+	ListOwnedIDsFunc   func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error)
+	ListSharedIDsFunc  func(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	ShareFunc          func(ctx context.Context, id, householdID primitive.ObjectID) error
+	UnshareFunc        func(ctx context.Context, id, householdID primitive.ObjectID) error
+	AdjustBalanceFunc  func(ctx context.Context, id primitive.ObjectID, delta float64) error
+	SetAvailableFunc   func(ctx context.Context, id primitive.ObjectID, available float64) error
+	ListInterestFunc   func(ctx context.Context) ([]models.Account, error)
+	UpdateInterestFunc func(ctx context.Context, id primitive.ObjectID, settings *models.Account) (*models.Account, error)
+	SaveInterestFunc   func(ctx context.Context, account *models.Account, previous time.Time) error
+	SetCreditLimitFunc func(ctx context.Context, id primitive.ObjectID, limit float64) error
+	SaveNumbersFunc    func(ctx context.Context, account, previous *models.Account) error
+	ListForEncryptFunc func(ctx context.Context, activeKeyID string) ([]models.Account, error)
+	UpsertExternalFunc func(ctx context.Context, account *models.Account) (*models.Account, error)
+	SetBalancesFunc    func(ctx context.Context, id primitive.ObjectID, current, available float64) error
+	SpendPointsFunc    func(ctx context.Context, id primitive.ObjectID, points, earned float64) error
+}
+
+func (m *MockAccountRepository) GetAccountByID(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+	if m.GetAccountByIDFunc != nil {
+		return m.GetAccountByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) AdjustBalance(ctx context.Context, id primitive.ObjectID, delta float64) error {
+	if m.AdjustBalanceFunc != nil {
+		return m.AdjustBalanceFunc(ctx, id, delta)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SetAvailableBalance(ctx context.Context, id primitive.ObjectID, available float64) error {
+	if m.SetAvailableFunc != nil {
+		return m.SetAvailableFunc(ctx, id, available)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) ListInterestBearing(ctx context.Context) ([]models.Account, error) {
+	if m.ListInterestFunc != nil {
+		return m.ListInterestFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) UpdateInterestSettings(ctx context.Context, id primitive.ObjectID, settings *models.Account) (*models.Account, error) {
+	if m.UpdateInterestFunc != nil {
+		return m.UpdateInterestFunc(ctx, id, settings)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SaveInterestState(ctx context.Context, account *models.Account, previous time.Time) error {
+	if m.SaveInterestFunc != nil {
+		return m.SaveInterestFunc(ctx, account, previous)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SetCreditLimit(ctx context.Context, id primitive.ObjectID, limit float64) error {
+	if m.SetCreditLimitFunc != nil {
+		return m.SetCreditLimitFunc(ctx, id, limit)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SaveAccountNumbers(ctx context.Context, account, previous *models.Account) error {
+	if m.SaveNumbersFunc != nil {
+		return m.SaveNumbersFunc(ctx, account, previous)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) ListForEncryption(ctx context.Context, activeKeyID string) ([]models.Account, error) {
+	if m.ListForEncryptFunc != nil {
+		return m.ListForEncryptFunc(ctx, activeKeyID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) UpsertExternalAccount(ctx context.Context, account *models.Account) (*models.Account, error) {
+	if m.UpsertExternalFunc != nil {
+		return m.UpsertExternalFunc(ctx, account)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SetBalances(ctx context.Context, id primitive.ObjectID, current, available float64) error {
+	if m.SetBalancesFunc != nil {
+		return m.SetBalancesFunc(ctx, id, current, available)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) SpendPoints(ctx context.Context, id primitive.ObjectID, points, earned float64) error {
+	if m.SpendPointsFunc != nil {
+		return m.SpendPointsFunc(ctx, id, points, earned)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	if m.CreateAccountFunc != nil {
+		return m.CreateAccountFunc(ctx, account)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) ListOwnedIDs(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if m.ListOwnedIDsFunc != nil {
+		return m.ListOwnedIDsFunc(ctx, ownerID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) ListSharedIDs(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if m.ListSharedIDsFunc != nil {
+		return m.ListSharedIDsFunc(ctx, householdIDs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) ShareWithHousehold(ctx context.Context, id, householdID primitive.ObjectID) error {
+	if m.ShareFunc != nil {
+		return m.ShareFunc(ctx, id, householdID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAccountRepository) UnshareFromHousehold(ctx context.Context, id, householdID primitive.ObjectID) error {
+	if m.UnshareFunc != nil {
+		return m.UnshareFunc(ctx, id, householdID)
+	}
+	return errors.New("not implemented")
+}
+
+// MockTransactionRepository is a mock implementation of repository.TransactionRepository for testing
+type MockTransactionRepository struct {
+	GetTransactionByIDFunc          func(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error)
+	GetTransactionsByTransferIDFunc func(ctx context.Context, transferID primitive.ObjectID) ([]models.Transaction, error)
+	ListTransactionsFunc            func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error)
+	StreamTransactionsFunc          func(ctx context.Context, filter repository.TransactionFilter, fn func(txn *models.Transaction) error) error
+	CreateTransactionFunc           func(ctx context.Context, txn *models.Transaction) error
+	CreateTransactionsFunc          func(ctx context.Context, txns []models.Transaction) error
+	DeleteByAccountsFunc            func(ctx context.Context, accountIDs []primitive.ObjectID) error
+	LinkTransferFunc                func(ctx context.Context, ids []primitive.ObjectID, transferID primitive.ObjectID) error
+	UpdateSplitsFunc                func(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error)
+	UpdateTagsFunc                  func(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Transaction, error)
+	SetClearedFunc                  func(ctx context.Context, accountID primitive.ObjectID, ids []primitive.ObjectID, cleared bool) (int64, error)
+	LockTransactionsFunc            func(ctx context.Context, ids []primitive.ObjectID, reconciliationID primitive.ObjectID) error
+	UpdateStatusFunc                func(ctx context.Context, id primitive.ObjectID, status string) error
+	PostPendingFunc                 func(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error)
+	GetExternalFunc                 func(ctx context.Context, accountID primitive.ObjectID, externalID string) (*models.Transaction, error)
+	UpsertExternalFunc              func(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error)
+}
+
+func (m *MockTransactionRepository) GetTransactionByID(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+	if m.GetTransactionByIDFunc != nil {
+		return m.GetTransactionByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) GetTransactionsByTransferID(ctx context.Context, transferID primitive.ObjectID) ([]models.Transaction, error) {
+	if m.GetTransactionsByTransferIDFunc != nil {
+		return m.GetTransactionsByTransferIDFunc(ctx, transferID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) ListTransactions(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+	if m.ListTransactionsFunc != nil {
+		return m.ListTransactionsFunc(ctx, filter)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) StreamTransactions(ctx context.Context, filter repository.TransactionFilter, fn func(txn *models.Transaction) error) error {
+	if m.StreamTransactionsFunc != nil {
+		return m.StreamTransactionsFunc(ctx, filter, fn)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, txn *models.Transaction) error {
+	if m.CreateTransactionFunc != nil {
+		return m.CreateTransactionFunc(ctx, txn)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) CreateTransactions(ctx context.Context, txns []models.Transaction) error {
+	if m.CreateTransactionsFunc != nil {
+		return m.CreateTransactionsFunc(ctx, txns)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) DeleteByAccounts(ctx context.Context, accountIDs []primitive.ObjectID) error {
+	if m.DeleteByAccountsFunc != nil {
+		return m.DeleteByAccountsFunc(ctx, accountIDs)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) LinkTransfer(ctx context.Context, ids []primitive.ObjectID, transferID primitive.ObjectID) error {
+	if m.LinkTransferFunc != nil {
+		return m.LinkTransferFunc(ctx, ids, transferID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) UpdateSplits(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error) {
+	if m.UpdateSplitsFunc != nil {
+		return m.UpdateSplitsFunc(ctx, id, splits, points)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) UpdateTags(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Transaction, error) {
+	if m.UpdateTagsFunc != nil {
+		return m.UpdateTagsFunc(ctx, id, tags)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) SetCleared(ctx context.Context, accountID primitive.ObjectID, ids []primitive.ObjectID, cleared bool) (int64, error) {
+	if m.SetClearedFunc != nil {
+		return m.SetClearedFunc(ctx, accountID, ids, cleared)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) LockTransactions(ctx context.Context, ids []primitive.ObjectID, reconciliationID primitive.ObjectID) error {
+	if m.LockTransactionsFunc != nil {
+		return m.LockTransactionsFunc(ctx, ids, reconciliationID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	if m.UpdateStatusFunc != nil {
+		return m.UpdateStatusFunc(ctx, id, status)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) PostPending(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error) {
+	if m.PostPendingFunc != nil {
+		return m.PostPendingFunc(ctx, id, posted)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) GetExternalTransaction(ctx context.Context, accountID primitive.ObjectID, externalID string) (*models.Transaction, error) {
+	if m.GetExternalFunc != nil {
+		return m.GetExternalFunc(ctx, accountID, externalID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransactionRepository) UpsertExternalTransaction(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error) {
+	if m.UpsertExternalFunc != nil {
+		return m.UpsertExternalFunc(ctx, txn, pendingExternalID)
+	}
+	return false, errors.New("not implemented")
+}
+
+// MockTxRunner runs the unit of work directly, without a database transaction
+type MockTxRunner struct{}
+
+func (m *MockTxRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// testUserID is the caller in tests that only need some signed-in user
+var testUserID = primitive.NewObjectID()
+
+// AllowAllAccess is an AccessPolicy that lets every user read and write everything.
+// AccountIDs reports Accounts as the caller's readable accounts.
+type AllowAllAccess struct {
+	Accounts []primitive.ObjectID
+}
+
+func (a *AllowAllAccess) AccountIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return a.Accounts, nil
+}
+
+func (a *AllowAllAccess) CheckAccount(ctx context.Context, userID, accountID primitive.ObjectID, access string) error {
+	return nil
+}
+
+func (a *AllowAllAccess) CheckBudget(ctx context.Context, userID primitive.ObjectID, budget *models.Budget, access string) error {
+	return nil
+}
+
+// testRequest builds a request made by testUserID
+func testRequest(method, path string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("X-User-ID", testUserID.Hex())
+	return req
+}
+
+func newTransferApp(accounts *MockAccountRepository, transactions *MockTransactionRepository) *fiber.App {
+	service := services.NewTransferService(accounts, transactions, &MockTxRunner{}, &AllowAllAccess{})
+	handler := handlers.NewTransferHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/transfers", handler.CreateTransfer)
+	app.Get("/transfers/:id", handler.GetTransfer)
+	return app
+}
+
+func postTransfer(t *testing.T, app *fiber.App, payload handlers.TransferPayload) int {
+	payloadJSON, _ := json.Marshal(payload)
+	req := testRequest("POST", "/transfers", bytes.NewBuffer(payloadJSON))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// Test CreateTransfer - Success writes both legs and moves both balances
+func TestCreateTransfer_Success(t *testing.T) {
+	checking := &models.Account{ID: primitive.NewObjectID(), AccountLabel: "Checking", CurrentBalance: 1000}
+	savings := &models.Account{ID: primitive.NewObjectID(), AccountLabel: "Savings", CurrentBalance: 500}
+	accounts := map[primitive.ObjectID]*models.Account{checking.ID: checking, savings.ID: savings}
+
+	var created []*models.Transaction
+	accountRepo := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			if account, ok := accounts[id]; ok {
+				return account, nil
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			accounts[id].CurrentBalance += delta
+			return nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		CreateTransactionFunc: func(ctx context.Context, txn *models.Transaction) error {
+			created = append(created, txn)
+			return nil
+		},
+	}
+
+	status := postTransfer(t, newTransferApp(accountRepo, transactionRepo), handlers.TransferPayload{
+		FromAccountID: checking.ID.Hex(),
+		ToAccountID:   savings.ID.Hex(),
+		Amount:        250,
+	})
+
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusCreated, status)
+	}
+	if len(created) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(created))
+	}
+	if created[0].TransferID.IsZero() || created[0].TransferID != created[1].TransferID {
+		t.Errorf("Expected both legs to share a transfer ID")
+	}
+	if created[0].Type != models.TransactionTypeDebit || created[1].Type != models.TransactionTypeCredit {
+		t.Errorf("Expected a debit and a credit leg, got %s and %s", created[0].Type, created[1].Type)
+	}
+	if checking.CurrentBalance != 750 || savings.CurrentBalance != 750 {
+		t.Errorf("Expected balances 750/750, got %v/%v", checking.CurrentBalance, savings.CurrentBalance)
+	}
+}
+
+// Test CreateTransfer - Both legs raise transaction.created and overdrawing raises balance.low
+func TestCreateTransfer_PublishesEvents(t *testing.T) {
+	checking := &models.Account{ID: primitive.NewObjectID(), AccountLabel: "Checking", AccountType: models.AccountTypeChecking, CurrentBalance: 100, AvailableBalance: 100}
+	savings := &models.Account{ID: primitive.NewObjectID(), AccountLabel: "Savings", AccountType: models.AccountTypeSavings}
+	accounts := map[primitive.ObjectID]*models.Account{checking.ID: checking, savings.ID: savings}
+	accountRepo := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			copied := *accounts[id]
+			return &copied, nil
+		},
+		AdjustBalanceFunc: func(ctx context.Context, id primitive.ObjectID, delta float64) error {
+			return nil
+		},
+	}
+	transactionRepo := &MockTransactionRepository{
+		CreateTransactionFunc: func(ctx context.Context, txn *models.Transaction) error {
+			return nil
+		},
+	}
+
+	recorder := &eventRecorder{}
+	bus := services.NewEventBus()
+	bus.Subscribe(recorder)
+	service := services.NewTransferService(accountRepo, transactionRepo, &MockTxRunner{}, &AllowAllAccess{})
+	service.SetEventBus(bus)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/transfers", handlers.NewTransferHandler(service).CreateTransfer)
+
+	status := postTransfer(t, app, handlers.TransferPayload{FromAccountID: checking.ID.Hex(), ToAccountID: savings.ID.Hex(), Amount: 150})
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusCreated, status)
+	}
+	if created := recorder.ofType(models.EventTransactionCreated); len(created) != 2 {
+		t.Errorf("Expected both legs to raise transaction.created, got %d", len(created))
+	}
+	low := recorder.ofType(models.EventBalanceLow)
+	if len(low) != 1 || low[0].Data.(services.LowBalance).AccountID != checking.ID || low[0].Data.(services.LowBalance).AvailableBalance != -50 {
+		t.Errorf("Expected balance.low for checking at -50, got %+v", low)
+	}
+}
+
+// Test CreateTransfer - Same account on both sides
+func TestCreateTransfer_SameAccount(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	status := postTransfer(t, newTransferApp(&MockAccountRepository{}, &MockTransactionRepository{}), handlers.TransferPayload{
+		FromAccountID: id,
+		ToAccountID:   id,
+		Amount:        10,
+	})
+
+	if status != fiber.StatusBadRequest {
+		t.Errorf("Expected status code %d (Bad Request), got %d", fiber.StatusBadRequest, status)
+	}
+}
+
+// Test CreateTransfer - Account Not Found
+func TestCreateTransfer_AccountNotFound(t *testing.T) {
+	accountRepo := &MockAccountRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+
+	status := postTransfer(t, newTransferApp(accountRepo, &MockTransactionRepository{}), handlers.TransferPayload{
+		FromAccountID: primitive.NewObjectID().Hex(),
+		ToAccountID:   primitive.NewObjectID().Hex(),
+		Amount:        10,
+	})
+
+	if status != fiber.StatusNotFound {
+		t.Errorf("Expected status code %d (Not Found), got %d", fiber.StatusNotFound, status)
+	}
+}
+
+// Test GetTransfer - Success
+func TestGetTransfer_Success(t *testing.T) {
+	transferID := primitive.NewObjectID()
+	transactionRepo := &MockTransactionRepository{
+		GetTransactionsByTransferIDFunc: func(ctx context.Context, id primitive.ObjectID) ([]models.Transaction, error) {
+			return []models.Transaction{
+				{ID: primitive.NewObjectID(), TransferID: id, Type: models.TransactionTypeDebit, Amount: 40},
+				{ID: primitive.NewObjectID(), TransferID: id, Type: models.TransactionTypeCredit, Amount: 40},
+			}, nil
+		},
+	}
+
+	app := newTransferApp(&MockAccountRepository{}, transactionRepo)
+	resp, err := app.Test(testRequest("GET", "/transfers/"+transferID.Hex(), nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var transfer models.Transfer
+	if err := json.Unmarshal(body, &transfer); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if transfer.Debit == nil || transfer.Credit == nil || transfer.Amount != 40 {
+		t.Errorf("Expected both legs with amount 40, got %+v", transfer)
+	}
+}
+
+// Test GetTransfer - Not Found when legs are missing
+func TestGetTransfer_NotFound(t *testing.T) {
+	transactionRepo := &MockTransactionRepository{
+		GetTransactionsByTransferIDFunc: func(ctx context.Context, id primitive.ObjectID) ([]models.Transaction, error) {
+			return nil, nil
+		},
+	}
+
+	app := newTransferApp(&MockAccountRepository{}, transactionRepo)
+	resp, err := app.Test(testRequest("GET", "/transfers/"+primitive.NewObjectID().Hex(), nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status code %d (Not Found), got %d", fiber.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,304 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockTransferMatchRepository is a mock implementation of repository.TransferMatchRepository for testing
+type MockTransferMatchRepository struct {
+	GetMatchByIDFunc                 func(ctx context.Context, id primitive.ObjectID) (*models.TransferMatch, error)
+	ListMatchesByUserFunc            func(ctx context.Context, userID primitive.ObjectID, status string) ([]models.TransferMatch, error)
+	MatchExistsFunc                  func(ctx context.Context, outflowID, inflowID primitive.ObjectID) (bool, error)
+	CreateMatchFunc                  func(ctx context.Context, match *models.TransferMatch) error
+	ResolveMatchFunc                 func(ctx context.Context, id primitive.ObjectID, status string, transferID primitive.ObjectID) error
+	RejectPendingForTransactionsFunc func(ctx context.Context, txnIDs []primitive.ObjectID, except primitive.ObjectID) error
+}
+
+func (m *MockTransferMatchRepository) GetMatchByID(ctx context.Context, id primitive.ObjectID) (*models.TransferMatch, error) {
+	if m.GetMatchByIDFunc != nil {
+		return m.GetMatchByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransferMatchRepository) ListMatchesByUser(ctx context.Context, userID primitive.ObjectID, status string) ([]models.TransferMatch, error) {
+	if m.ListMatchesByUserFunc != nil {
+		return m.ListMatchesByUserFunc(ctx, userID, status)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTransferMatchRepository) MatchExists(ctx context.Context, outflowID, inflowID primitive.ObjectID) (bool, error) {
+	if m.MatchExistsFunc != nil {
+		return m.MatchExistsFunc(ctx, outflowID, inflowID)
+	}
+	return false, errors.New("not implemented")
+}
+
+func (m *MockTransferMatchRepository) CreateMatch(ctx context.Context, match *models.TransferMatch) error {
+	if m.CreateMatchFunc != nil {
+		return m.CreateMatchFunc(ctx, match)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransferMatchRepository) ResolveMatch(ctx context.Context, id primitive.ObjectID, status string, transferID primitive.ObjectID) error {
+	if m.ResolveMatchFunc != nil {
+		return m.ResolveMatchFunc(ctx, id, status, transferID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTransferMatchRepository) RejectPendingForTransactions(ctx context.Context, txnIDs []primitive.ObjectID, except primitive.ObjectID) error {
+	if m.RejectPendingForTransactionsFunc != nil {
+		return m.RejectPendingForTransactionsFunc(ctx, txnIDs, except)
+	}
+	return errors.New("not implemented")
+}
+
+// matchStore keeps matches in memory. ResolveMatch only settles pending matches, as the
+// status filter of the Mongo update does.
+func matchStore(matches map[primitive.ObjectID]*models.TransferMatch) *MockTransferMatchRepository {
+	var mu sync.Mutex
+	return &MockTransferMatchRepository{
+		GetMatchByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.TransferMatch, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			match, ok := matches[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *match
+			return &copied, nil
+		},
+		MatchExistsFunc: func(ctx context.Context, outflowID, inflowID primitive.ObjectID) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, match := range matches {
+				if match.OutflowID == outflowID && match.InflowID == inflowID {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		CreateMatchFunc: func(ctx context.Context, match *models.TransferMatch) error {
+			mu.Lock()
+			defer mu.Unlock()
+			match.ID = primitive.NewObjectID()
+			copied := *match
+			matches[match.ID] = &copied
+			return nil
+		},
+		ResolveMatchFunc: func(ctx context.Context, id primitive.ObjectID, status string, transferID primitive.ObjectID) error {
+			mu.Lock()
+			defer mu.Unlock()
+			match, ok := matches[id]
+			if !ok || match.Status != models.TransferMatchPending {
+				return mongo.ErrNoDocuments
+			}
+			match.Status, match.TransferID = status, transferID
+			return nil
+		},
+		RejectPendingForTransactionsFunc: func(ctx context.Context, txnIDs []primitive.ObjectID, except primitive.ObjectID) error {
+			return nil
+		},
+	}
+}
+
+// matchedTransactions lists txns and records the IDs linked as a transfer
+func matchedTransactions(txns []models.Transaction, linked *[]primitive.ObjectID) *MockTransactionRepository {
+	return &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			return txns, nil
+		},
+		LinkTransferFunc: func(ctx context.Context, ids []primitive.ObjectID, transferID primitive.ObjectID) error {
+			*linked = append(*linked, ids...)
+			return nil
+		},
+	}
+}
+
+func newTransferMatchService(transactions *MockTransactionRepository, matches *MockTransferMatchRepository, accountIDs ...primitive.ObjectID) *services.TransferMatchService {
+	accounts := &MockAccountRepository{
+		ListOwnedIDsFunc: func(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return accountIDs, nil
+		},
+	}
+	return services.NewTransferMatchService(accounts, transactions, matches, &MockTxRunner{})
+}
+
+func newTransferMatchApp(service *services.TransferMatchService) *fiber.App {
+	handler := handlers.NewTransferMatchHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/matches", handler.FindMatches)
+	app.Post("/matches/:id/accept", handler.AcceptMatch)
+	app.Post("/matches/:id/reject", handler.RejectMatch)
+	return app
+}
+
+// pendingMatch stores a pending match of testUserID between two new transactions
+func pendingMatch(matches map[primitive.ObjectID]*models.TransferMatch) *models.TransferMatch {
+	match := &models.TransferMatch{ID: primitive.NewObjectID(), UserID: testUserID, OutflowID: primitive.NewObjectID(),
+		InflowID: primitive.NewObjectID(), Amount: 300, Status: models.TransferMatchPending}
+	matches[match.ID] = match
+	return match
+}
+
+// Test FindMatches - an outflow is paired with the closest inflow of the same amount
+func TestFindMatches_ProposesClosestInflow(t *testing.T) {
+	checking, savings := primitive.NewObjectID(), primitive.NewObjectID()
+	day := time.Now().UTC().AddDate(0, 0, -30)
+	txns := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: checking, Type: models.TransactionTypeDebit, Amount: 300, TransactionDate: day, Name: "ONLINE TRANSFER"},
+		{ID: primitive.NewObjectID(), AccountID: savings, Type: models.TransactionTypeCredit, Amount: 300, TransactionDate: day.AddDate(0, 0, 1)},
+		{ID: primitive.NewObjectID(), AccountID: savings, Type: models.TransactionTypeCredit, Amount: 300, TransactionDate: day.AddDate(0, 0, 20)},
+		{ID: primitive.NewObjectID(), AccountID: checking, Type: models.TransactionTypeDebit, Amount: 55, TransactionDate: day},
+	}
+	var linked []primitive.ObjectID
+	service := newTransferMatchService(matchedTransactions(txns, &linked), matchStore(map[primitive.ObjectID]*models.TransferMatch{}), checking, savings)
+
+	rec := userRequest(t, newTransferMatchApp(service), testUserID, "POST", "/matches", nil)
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var proposals []models.TransferMatch
+	_ = json.Unmarshal(rec.Body.Bytes(), &proposals)
+	if len(proposals) != 1 || proposals[0].OutflowID != txns[0].ID || proposals[0].InflowID != txns[1].ID || proposals[0].Confidence <= 0.9 {
+		t.Errorf("Expected one confident match with the next-day inflow, got %+v", proposals)
+	}
+}
+
+// Test FindMatches - only recent transactions are loaded
+func TestFindMatches_LoadsRecentTransactions(t *testing.T) {
+	var filters []repository.TransactionFilter
+	transactions := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter repository.TransactionFilter) ([]models.Transaction, error) {
+			filters = append(filters, filter)
+			return nil, nil
+		},
+	}
+	service := newTransferMatchService(transactions, matchStore(map[primitive.ObjectID]*models.TransferMatch{}), primitive.NewObjectID(), primitive.NewObjectID())
+
+	if _, err := service.FindMatches(context.Background(), testUserID, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(services.MatchLookbackDays + 5))
+	if len(filters) != 1 || !filters[0].ExcludeTransfers || filters[0].Start.Sub(since).Abs() > time.Minute {
+		t.Errorf("Expected unlinked transactions since %v, got %+v", since, filters)
+	}
+}
+
+// Test FindMatches - pairs that were proposed before are not proposed again
+func TestFindMatches_SkipsExisting(t *testing.T) {
+	checking, savings := primitive.NewObjectID(), primitive.NewObjectID()
+	day := time.Now().UTC().AddDate(0, 0, -3)
+	txns := []models.Transaction{
+		{ID: primitive.NewObjectID(), AccountID: checking, Type: models.TransactionTypeDebit, Amount: 120, TransactionDate: day},
+		{ID: primitive.NewObjectID(), AccountID: savings, Type: models.TransactionTypeCredit, Amount: 120, TransactionDate: day},
+	}
+	matches := map[primitive.ObjectID]*models.TransferMatch{}
+	matches[primitive.NewObjectID()] = &models.TransferMatch{OutflowID: txns[0].ID, InflowID: txns[1].ID, Status: models.TransferMatchRejected}
+	var linked []primitive.ObjectID
+	service := newTransferMatchService(matchedTransactions(txns, &linked), matchStore(matches), checking, savings)
+
+	proposals, err := service.FindMatches(context.Background(), testUserID, 5)
+	if err != nil || len(proposals) != 0 {
+		t.Errorf("Expected no new proposals, got %+v, %v", proposals, err)
+	}
+}
+
+// Test AcceptMatch - both transactions are linked under one transfer
+func TestAcceptMatch_LinksPair(t *testing.T) {
+	matches := map[primitive.ObjectID]*models.TransferMatch{}
+	match := pendingMatch(matches)
+	var linked []primitive.ObjectID
+	app := newTransferMatchApp(newTransferMatchService(matchedTransactions(nil, &linked), matchStore(matches)))
+
+	rec := userRequest(t, app, testUserID, "POST", "/matches/"+match.ID.Hex()+"/accept", nil)
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", fiber.StatusOK, rec.Code, rec.Body.String())
+	}
+	if len(linked) != 2 || matches[match.ID].Status != models.TransferMatchAccepted || matches[match.ID].TransferID.IsZero() {
+		t.Errorf("Expected both transactions linked and the match accepted, got %v and %+v", linked, matches[match.ID])
+	}
+}
+
+// Test AcceptMatch - a resolved match conflicts
+func TestAcceptMatch_AlreadyResolved(t *testing.T) {
+	matches := map[primitive.ObjectID]*models.TransferMatch{}
+	match := pendingMatch(matches)
+	match.Status = models.TransferMatchRejected
+	var linked []primitive.ObjectID
+	app := newTransferMatchApp(newTransferMatchService(matchedTransactions(nil, &linked), matchStore(matches)))
+
+	if rec := userRequest(t, app, testUserID, "POST", "/matches/"+match.ID.Hex()+"/accept", nil); rec.Code != fiber.StatusConflict {
+		t.Errorf("Expected status code %d (Conflict), got %d", fiber.StatusConflict, rec.Code)
+	}
+	if len(linked) != 0 {
+		t.Errorf("Expected nothing to be linked, got %v", linked)
+	}
+}
+
+// Test AcceptMatch and RejectMatch - when both race, only one resolves the match
+func TestResolveMatch_ConcurrentAcceptAndReject(t *testing.T) {
+	matches := map[primitive.ObjectID]*models.TransferMatch{}
+	match := pendingMatch(matches)
+	var linked []primitive.ObjectID
+	service := newTransferMatchService(matchedTransactions(nil, &linked), matchStore(matches))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	resolved := 0
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolve := service.RejectMatch
+			if i%2 == 0 {
+				resolve = service.AcceptMatch
+			}
+			_, err := resolve(context.Background(), testUserID, match.ID)
+			if err != nil && !errors.Is(err, services.ErrMatchResolved) {
+				t.Errorf("Expected the losers to see a resolved match, got %v", err)
+			}
+			if err == nil {
+				mu.Lock()
+				resolved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if resolved != 1 {
+		t.Errorf("Expected exactly one resolution, got %d", resolved)
+	}
+}
+
+// Test FindMatches - no user on the request
+func TestFindMatches_Unauthorized(t *testing.T) {
+	app := newTransferMatchApp(newTransferMatchService(&MockTransactionRepository{}, &MockTransferMatchRepository{}))
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/matches", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("Expected status code %d (Unauthorized), got %d", fiber.StatusUnauthorized, resp.StatusCode)
+	}
+}
@@ -0,0 +1,492 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/routes"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockTwoFactorRepository is a mock implementation of repository.TwoFactorRepository for testing
+type MockTwoFactorRepository struct {
+	CreateSessionFunc      func(ctx context.Context, session *models.TwoFactorSession) error
+	GetSessionByHashFunc   func(ctx context.Context, tokenHash string) (*models.TwoFactorSession, error)
+	StepUpFunc             func(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	DeleteUserSessionsFunc func(ctx context.Context, userID primitive.ObjectID) error
+}
+
+func (m *MockTwoFactorRepository) CreateSession(ctx context.Context, session *models.TwoFactorSession) error {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(ctx, session)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTwoFactorRepository) GetSessionByHash(ctx context.Context, tokenHash string) (*models.TwoFactorSession, error) {
+	if m.GetSessionByHashFunc != nil {
+		return m.GetSessionByHashFunc(ctx, tokenHash)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTwoFactorRepository) StepUp(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if m.StepUpFunc != nil {
+		return m.StepUpFunc(ctx, id, at)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockTwoFactorRepository) DeleteUserSessions(ctx context.Context, userID primitive.ObjectID) error {
+	if m.DeleteUserSessionsFunc != nil {
+		return m.DeleteUserSessionsFunc(ctx, userID)
+	}
+	return errors.New("not implemented")
+}
+
+// totpUserRepository serves user and keeps the two-factor fields the service updates
+func totpUserRepository(user *models.User) *MockUserRepository {
+	var mu sync.Mutex
+	return &MockUserRepository{
+		GetUserByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if id != user.ID {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *user
+			return &copied, nil
+		},
+		SaveTOTPFunc: func(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			user.TOTPSecret, user.TOTPEnabled, user.RecoveryCodes = secret, enabled, recoveryCodes
+			return nil
+		},
+		UseTOTPStepFunc: func(ctx context.Context, id primitive.ObjectID, step int64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if step <= user.TOTPLastStep {
+				return mongo.ErrNoDocuments
+			}
+			user.TOTPLastStep, user.TOTPAttempts = step, 0
+			return nil
+		},
+		UseRecoveryCodeFunc: func(ctx context.Context, id primitive.ObjectID, codeHash string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			i := slices.Index(user.RecoveryCodes, codeHash)
+			if i < 0 {
+				return mongo.ErrNoDocuments
+			}
+			user.RecoveryCodes, user.TOTPAttempts = slices.Delete(user.RecoveryCodes, i, i+1), 0
+			return nil
+		},
+		RecordTOTPAttemptFunc: func(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if user.TOTPLockedUntil.After(now) {
+				return 0, mongo.ErrNoDocuments
+			}
+			user.TOTPAttempts++
+			return user.TOTPAttempts, nil
+		},
+		LockTOTPFunc: func(ctx context.Context, id primitive.ObjectID, until time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			user.TOTPLockedUntil, user.TOTPAttempts = until, 0
+			return nil
+		},
+	}
+}
+
+// sessionRepository keeps the sessions the service issues in sessions
+func sessionRepository(sessions map[primitive.ObjectID]*models.TwoFactorSession) *MockTwoFactorRepository {
+	var mu sync.Mutex
+	return &MockTwoFactorRepository{
+		CreateSessionFunc: func(ctx context.Context, session *models.TwoFactorSession) error {
+			mu.Lock()
+			defer mu.Unlock()
+			session.ID = primitive.NewObjectID()
+			stored := *session
+			sessions[session.ID] = &stored
+			return nil
+		},
+		GetSessionByHashFunc: func(ctx context.Context, tokenHash string) (*models.TwoFactorSession, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, session := range sessions {
+				if session.TokenHash == tokenHash {
+					copied := *session
+					return &copied, nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		StepUpFunc: func(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			session, ok := sessions[id]
+			if !ok {
+				return mongo.ErrNoDocuments
+			}
+			session.StepUpAt = at
+			return nil
+		},
+	}
+}
+
+// newTwoFactorApp serves the two-factor and user routes behind the real middleware chain
+func newTwoFactorApp(t *testing.T, users *MockUserRepository, sessions *MockTwoFactorRepository) (*fiber.App, *services.TwoFactorService) {
+	t.Helper()
+	keys, err := services.NewLocalKeyProvider("2026-10", map[string][]byte{"2026-10": bytes.Repeat([]byte{3}, 32)})
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	service := services.NewTwoFactorService(users, sessions, services.NewFieldCipher(keys))
+
+	app := fiber.New()
+	app.Use(middleware.SessionMiddleware(services.NewSessionService(users, sessionSecret)))
+	app.Use(middleware.TwoFactorMiddleware(service))
+	app.Use(middleware.AuthorizationMiddleware(services.NewAdminService(users, auditStore(nil))))
+	routes.SetupTwoFactorRoutes(app, handlers.NewTwoFactorHandler(service))
+	routes.SetupProductRoutes(app, handlers.NewUserHandler(services.NewUserService(users)))
+	return app, service
+}
+
+// signIn issues userID a sign-in session, as a password login would
+func signIn(t *testing.T, userID primitive.ObjectID) (string, *services.Session) {
+	t.Helper()
+	token, session, err := services.NewSessionService(nil, sessionSecret).SignSession(userID, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("Failed to sign in: %v", err)
+	}
+	return token, session
+}
+
+// enableTwoFactor enrolls and confirms TOTP for userID within the sign-in session
+// loginSessionID, returning the decoded secret
+func enableTwoFactor(t *testing.T, service *services.TwoFactorService, userID primitive.ObjectID, loginSessionID string) ([]byte, *services.TwoFactorActivation) {
+	t.Helper()
+	enrollment, err := service.Enroll(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Failed to enroll: %v", err)
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("Expected a base32 secret, got %q", enrollment.Secret)
+	}
+	activation, err := service.Confirm(context.Background(), userID, loginSessionID, currentCode(secret))
+	if err != nil {
+		t.Fatalf("Failed to confirm: %v", err)
+	}
+	return secret, activation
+}
+
+// sessionRequest sends payload signed in with login, presenting a two-factor session when given
+func sessionRequest(t *testing.T, app *fiber.App, login, session, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+login)
+	if session != "" {
+		req.Header.Set("X-Two-Factor-Session", session)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	rec.Code = resp.StatusCode
+	_, _ = rec.Body.ReadFrom(resp.Body)
+	return rec
+}
+
+func currentCode(secret []byte) string {
+	return services.TOTPCode(secret, time.Now().Unix()/services.TOTPPeriod)
+}
+
+// Test Enroll - the provisioning URI names the user and carries the secret
+func TestEnroll_ProvisioningURI(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID(), Email: "saver@example.com"}
+	app, _ := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, _ := signIn(t, user.ID)
+
+	rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/enroll", nil)
+	if rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var enrollment services.TOTPEnrollment
+	_ = json.Unmarshal(rec.Body.Bytes(), &enrollment)
+	if !strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/TrackMe:saver@example.com?") || !strings.Contains(enrollment.ProvisioningURI, "secret="+enrollment.Secret) {
+		t.Errorf("Unexpected provisioning URI %q", enrollment.ProvisioningURI)
+	}
+	if user.TOTPEnabled {
+		t.Errorf("Expected two-factor to stay off until confirmed")
+	}
+}
+
+// Test Confirm - recovery codes are shown once and stored hashed
+func TestConfirm_HashesRecoveryCodes(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, _ := signIn(t, user.ID)
+	enrollment, _ := service.Enroll(context.Background(), user.ID)
+	secret, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+
+	rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/confirm", handlers.TwoFactorCodePayload{Code: currentCode(secret)})
+	if rec.Code != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var activation services.TwoFactorActivation
+	_ = json.Unmarshal(rec.Body.Bytes(), &activation)
+	if len(activation.RecoveryCodes) != services.RecoveryCodeCount || activation.Session == nil || !user.TOTPEnabled {
+		t.Fatalf("Expected two-factor on with %d recovery codes and a session, got %+v", services.RecoveryCodeCount, activation)
+	}
+	for _, stored := range user.RecoveryCodes {
+		if slices.Contains(activation.RecoveryCodes, stored) {
+			t.Errorf("Expected recovery codes to be stored hashed")
+		}
+	}
+}
+
+// Test TwoFactorMiddleware - users with two-factor need a verified session
+func TestTwoFactorMiddleware_RequiresSession(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	enableTwoFactor(t, service, user.ID, signedIn.ID)
+
+	if rec := sessionRequest(t, app, login, "", "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a session, got %d", rec.Code)
+	}
+}
+
+// Test TwoFactorMiddleware - a verified session gets in
+func TestTwoFactorMiddleware_AcceptsSession(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+
+	if rec := sessionRequest(t, app, login, activation.Session.SessionToken, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusOK {
+		t.Errorf("Expected status 200 with a verified session, got %d", rec.Code)
+	}
+}
+
+// Test Verify - a code that was already used is refused
+func TestVerify_RefusesUsedCode(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	secret, _ := enableTwoFactor(t, service, user.ID, signedIn.ID)
+
+	used := services.TOTPCode(secret, user.TOTPLastStep)
+	if rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", handlers.TwoFactorCodePayload{Code: used}); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a used code, got %d", rec.Code)
+	}
+}
+
+// Test Verify - recovery codes log in once, whatever their case
+func TestVerify_RecoveryCodeSingleUse(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	recovery := handlers.TwoFactorCodePayload{RecoveryCode: strings.ToUpper(activation.RecoveryCodes[0])}
+
+	if rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", recovery); rec.Code != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", recovery); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a spent recovery code, got %d", rec.Code)
+	}
+	if len(user.RecoveryCodes) != services.RecoveryCodeCount-1 {
+		t.Errorf("Expected one recovery code spent, got %d left", len(user.RecoveryCodes))
+	}
+}
+
+// Test DeleteUser - users without two-factor cannot step up, so cannot delete
+func TestDeleteUser_RequiresTwoFactor(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, _ := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, _ := signIn(t, user.ID)
+
+	if rec := sessionRequest(t, app, login, "", "DELETE", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rec.Code)
+	}
+}
+
+// Test DeleteUser - a step-up older than StepUpWindow is refused
+func TestDeleteUser_RequiresRecentStepUp(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	sessions := map[primitive.ObjectID]*models.TwoFactorSession{}
+	users := totpUserRepository(user)
+	deleted := false
+	users.DeleteUserByIDFunc = func(ctx context.Context, id primitive.ObjectID) error {
+		deleted = true
+		return nil
+	}
+	app, service := newTwoFactorApp(t, users, sessionRepository(sessions))
+	login, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	sessions[activation.Session.Session.ID].StepUpAt = time.Now().UTC().Add(-services.StepUpWindow - time.Minute)
+
+	rec := sessionRequest(t, app, login, activation.Session.SessionToken, "DELETE", "/api/products/"+user.ID.Hex(), nil)
+	if rec.Code != fiber.StatusForbidden || deleted {
+		t.Errorf("Expected status 403 and no delete, got %d", rec.Code)
+	}
+}
+
+// Test StepUp - a wrong code is refused
+func TestStepUp_WrongCode(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+
+	rec := sessionRequest(t, app, login, activation.Session.SessionToken, "POST", "/api/auth/2fa/step-up", handlers.TwoFactorCodePayload{Code: "000000"})
+	if rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+}
+
+// Test StepUp - stepping up opens sensitive operations again
+func TestStepUp_AllowsDelete(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	sessions := map[primitive.ObjectID]*models.TwoFactorSession{}
+	users := totpUserRepository(user)
+	deleted := false
+	users.DeleteUserByIDFunc = func(ctx context.Context, id primitive.ObjectID) error {
+		deleted = true
+		return nil
+	}
+	app, service := newTwoFactorApp(t, users, sessionRepository(sessions))
+	login, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	session := activation.Session.SessionToken
+	sessions[activation.Session.Session.ID].StepUpAt = time.Now().UTC().Add(-services.StepUpWindow - time.Minute)
+
+	rec := sessionRequest(t, app, login, session, "POST", "/api/auth/2fa/step-up", handlers.TwoFactorCodePayload{RecoveryCode: activation.RecoveryCodes[1]})
+	if rec.Code != fiber.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = sessionRequest(t, app, login, session, "DELETE", "/api/products/"+user.ID.Hex(), nil)
+	if rec.Code != fiber.StatusNoContent || !deleted {
+		t.Errorf("Expected the delete to go through after step-up, got %d", rec.Code)
+	}
+}
+
+// Test Verify - concurrent wrong codes only get TOTPMaxAttempts checks before the lockout
+func TestVerify_LocksAfterRepeatedFailures(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	enableTwoFactor(t, service, user.ID, signedIn.ID)
+
+	statuses := make([]int, 3*services.TOTPMaxAttempts)
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", handlers.TwoFactorCodePayload{Code: "000000"}).Code
+		}()
+	}
+	wg.Wait()
+
+	checked := 0
+	for _, status := range statuses {
+		switch status {
+		case fiber.StatusUnauthorized:
+			checked++
+		case fiber.StatusTooManyRequests:
+		default:
+			t.Errorf("Expected status 401 or 429, got %d", status)
+		}
+	}
+	if checked != services.TOTPMaxAttempts {
+		t.Errorf("Expected %d codes checked before the lockout, got %d", services.TOTPMaxAttempts, checked)
+	}
+}
+
+// Test Verify - a right code is refused while the user is locked out
+func TestVerify_RefusesWhileLocked(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	secret, _ := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	user.TOTPLockedUntil = time.Now().UTC().Add(services.TOTPLockout)
+
+	code := services.TOTPCode(secret, user.TOTPLastStep+1)
+	if rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", handlers.TwoFactorCodePayload{Code: code}); rec.Code != fiber.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", rec.Code)
+	}
+}
+
+// Test Verify - once the lockout ends a right code logs in and clears the attempts
+func TestVerify_AfterLockout(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	login, signedIn := signIn(t, user.ID)
+	secret, _ := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	user.TOTPLockedUntil, user.TOTPAttempts = time.Now().UTC().Add(-time.Second), 3
+
+	code := services.TOTPCode(secret, user.TOTPLastStep+1)
+	rec := sessionRequest(t, app, login, "", "POST", "/api/auth/2fa/verify", handlers.TwoFactorCodePayload{Code: code})
+	if rec.Code != fiber.StatusCreated || user.TOTPAttempts != 0 {
+		t.Errorf("Expected status 201 with attempts cleared, got %d with %d attempts", rec.Code, user.TOTPAttempts)
+	}
+}
+
+// Test TwoFactorMiddleware - a two-factor session does not carry over to another sign-in
+func TestTwoFactorMiddleware_SessionBoundToSignIn(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	_, signedIn := signIn(t, user.ID)
+	_, activation := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	other, _ := signIn(t, user.ID)
+
+	if rec := sessionRequest(t, app, other, activation.Session.SessionToken, "GET", "/api/products/"+user.ID.Hex(), nil); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 for another sign-in, got %d", rec.Code)
+	}
+}
+
+// Test Verify - a code is only checked within a sign-in session
+func TestVerify_RequiresSignIn(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	app, service := newTwoFactorApp(t, totpUserRepository(user), sessionRepository(map[primitive.ObjectID]*models.TwoFactorSession{}))
+	_, signedIn := signIn(t, user.ID)
+	secret, _ := enableTwoFactor(t, service, user.ID, signedIn.ID)
+	attempts := user.TOTPAttempts
+
+	code := services.TOTPCode(secret, user.TOTPLastStep+1)
+	if _, err := service.Verify(context.Background(), user.ID, "", code, ""); !errors.Is(err, services.ErrInvalidSession) {
+		t.Errorf("Expected ErrInvalidSession without a sign-in, got %v", err)
+	}
+	if rec := sessionRequest(t, app, "", "", "POST", "/api/auth/2fa/verify", handlers.TwoFactorCodePayload{Code: code}); rec.Code != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a sign-in, got %d", rec.Code)
+	}
+	if user.TOTPAttempts != attempts {
+		t.Errorf("Expected no code to be checked, got %d attempts", user.TOTPAttempts)
+	}
+}
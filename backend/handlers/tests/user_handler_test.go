@@ -25,6 +25,7 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/samuriot/track-me/handlers"
@@ -36,11 +37,23 @@ import (
 
 // MockUserRepository is a mock implementation of repository.UserRepository for testing
 type MockUserRepository struct {
-	GetUserByIDFunc    func(ctx context.Context, id primitive.ObjectID) (*models.User, error)
-	GetAllUsersFunc    func(ctx context.Context) ([]models.User, error)
-	CreateUserFunc     func(ctx context.Context, user *models.User) error
-	UpdateUserFunc     func(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error)
-	DeleteUserByIDFunc func(ctx context.Context, id primitive.ObjectID) error
+	GetUserByIDFunc       func(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	GetAllUsersFunc       func(ctx context.Context) ([]models.User, error)
+	ListUsersByLoginFunc  func(ctx context.Context, login string) ([]models.User, error)
+	CreateUserFunc        func(ctx context.Context, user *models.User) error
+	UpdateUserFunc        func(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error)
+	DeleteUserByIDFunc    func(ctx context.Context, id primitive.ObjectID) error
+	UpdateCreditScoreFunc func(ctx context.Context, id primitive.ObjectID, score int) error
+	SetRoleFunc           func(ctx context.Context, id primitive.ObjectID, role string) error
+	SetDisabledFunc       func(ctx context.Context, id primitive.ObjectID, disabled bool, at time.Time) error
+	SaveTOTPFunc          func(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error
+	UseTOTPStepFunc       func(ctx context.Context, id primitive.ObjectID, step int64) error
+	UseRecoveryCodeFunc   func(ctx context.Context, id primitive.ObjectID, codeHash string) error
+	RecordTOTPAttemptFunc func(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error)
+	LockTOTPFunc          func(ctx context.Context, id primitive.ObjectID, until time.Time) error
+	ListStaleTOTPFunc     func(ctx context.Context, activeKeyID string) ([]models.User, error)
+	ResealTOTPFunc        func(ctx context.Context, id primitive.ObjectID, previous, secret *models.EncryptedValue) error
+	AddAccountFunc        func(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error
 }
 
 func (m *MockUserRepository) GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
@@ -50,6 +63,13 @@ func (m *MockUserRepository) GetUserByID(ctx context.Context, id primitive.Objec
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockUserRepository) ListUsersByLogin(ctx context.Context, login string) ([]models.User, error) {
+	if m.ListUsersByLoginFunc != nil {
+		return m.ListUsersByLoginFunc(ctx, login)
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockUserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	if m.GetAllUsersFunc != nil {
 		return m.GetAllUsersFunc(ctx)
@@ -78,6 +98,83 @@ func (m *MockUserRepository) DeleteUserByID(ctx context.Context, id primitive.Ob
 	return errors.New("not implemented")
 }
 
+func (m *MockUserRepository) UpdateCreditScore(ctx context.Context, id primitive.ObjectID, score int) error {
+	if m.UpdateCreditScoreFunc != nil {
+		return m.UpdateCreditScoreFunc(ctx, id, score)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	if m.SetRoleFunc != nil {
+		return m.SetRoleFunc(ctx, id, role)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool, at time.Time) error {
+	if m.SetDisabledFunc != nil {
+		return m.SetDisabledFunc(ctx, id, disabled, at)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) SaveTOTP(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error {
+	if m.SaveTOTPFunc != nil {
+		return m.SaveTOTPFunc(ctx, id, secret, enabled, recoveryCodes)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error {
+	if m.UseTOTPStepFunc != nil {
+		return m.UseTOTPStepFunc(ctx, id, step)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) error {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(ctx, id, codeHash)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) RecordTOTPAttempt(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error) {
+	if m.RecordTOTPAttemptFunc != nil {
+		return m.RecordTOTPAttemptFunc(ctx, id, now)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockUserRepository) LockTOTP(ctx context.Context, id primitive.ObjectID, until time.Time) error {
+	if m.LockTOTPFunc != nil {
+		return m.LockTOTPFunc(ctx, id, until)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) ListStaleTOTP(ctx context.Context, activeKeyID string) ([]models.User, error) {
+	if m.ListStaleTOTPFunc != nil {
+		return m.ListStaleTOTPFunc(ctx, activeKeyID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockUserRepository) ResealTOTP(ctx context.Context, id primitive.ObjectID, previous, secret *models.EncryptedValue) error {
+	if m.ResealTOTPFunc != nil {
+		return m.ResealTOTPFunc(ctx, id, previous, secret)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockUserRepository) AddAccount(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error {
+	if m.AddAccountFunc != nil {
+		return m.AddAccountFunc(ctx, id, accountID)
+	}
+	return errors.New("not implemented")
+}
+
 // Test NewUserHandler
 func TestNewUserHandler(t *testing.T) {
 	mockRepo := &MockUserRepository{}
@@ -312,7 +409,7 @@ func TestGetAllUsers_InternalServerError(t *testing.T) {
 	// by checking if it properly handles non-ErrUserNotFound errors
 	// Actually, looking at the service code, GetAllUsers always returns ErrUserNotFound
 	// for errors, so this path might not be reachable. But we'll test it anyway.
-	
+
 	mockRepo := &MockUserRepository{
 		GetAllUsersFunc: func(ctx context.Context) ([]models.User, error) {
 			return nil, services.ErrUserNotFound
@@ -508,6 +605,48 @@ func TestUpdateUser_Success(t *testing.T) {
 	}
 }
 
+// Test UpdateUser - Accounts In The Body Are Ignored
+func TestUpdateUser_IgnoresAccounts(t *testing.T) {
+	testID := primitive.NewObjectID()
+	var received *models.User
+
+	mockRepo := &MockUserRepository{
+		UpdateUserFunc: func(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error) {
+			received = update
+			return update, nil
+		},
+	}
+
+	service := services.NewUserService(mockRepo)
+	handler := handlers.NewUserHandler(service)
+	app := fiber.New()
+	app.Put("/users/:id", handler.UpdateUser)
+
+	payload := handlers.Payload{
+		Username: "updateduser",
+		Email:    "updated@example.com",
+		Accounts: []string{primitive.NewObjectID().Hex()},
+	}
+
+	payloadJSON, _ := json.Marshal(payload)
+	req := httptest.NewRequest("PUT", "/users/"+testID.Hex(), bytes.NewBuffer(payloadJSON))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+
+	if err != nil {
+		t.Fatalf("Failed to perform request: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Errorf("Expected status code %d, got %d", fiber.StatusAccepted, resp.StatusCode)
+	}
+
+	if received == nil || len(received.Accounts) != 0 {
+		t.Errorf("Expected the update without accounts, got %+v", received)
+	}
+}
+
 // Test UpdateUser - Invalid ID
 func TestUpdateUser_InvalidID(t *testing.T) {
 	mockRepo := &MockUserRepository{}
@@ -0,0 +1,544 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockWebhookRepository is a mock implementation of repository.WebhookRepository for testing
+type MockWebhookRepository struct {
+	CreateEndpointFunc   func(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetEndpointByIDFunc  func(ctx context.Context, id primitive.ObjectID) (*models.WebhookEndpoint, error)
+	ListEndpointsFunc    func(ctx context.Context, userID primitive.ObjectID) ([]models.WebhookEndpoint, error)
+	ListSubscribedFunc   func(ctx context.Context, userID primitive.ObjectID, eventType string) ([]models.WebhookEndpoint, error)
+	DeleteEndpointFunc   func(ctx context.Context, id, userID primitive.ObjectID) error
+	CreateDeliveryFunc   func(ctx context.Context, delivery *models.WebhookDelivery) error
+	ClaimDeliveryFunc    func(ctx context.Context, now, until time.Time) (*models.WebhookDelivery, error)
+	SaveAttemptFunc      func(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveriesFunc   func(ctx context.Context, endpointID primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error)
+	ListStaleSecretsFunc func(ctx context.Context, activeKeyID string) ([]models.WebhookEndpoint, error)
+	ResealSecretFunc     func(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if m.CreateEndpointFunc != nil {
+		return m.CreateEndpointFunc(ctx, endpoint)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) GetEndpointByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookEndpoint, error) {
+	if m.GetEndpointByIDFunc != nil {
+		return m.GetEndpointByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListEndpoints(ctx context.Context, userID primitive.ObjectID) ([]models.WebhookEndpoint, error) {
+	if m.ListEndpointsFunc != nil {
+		return m.ListEndpointsFunc(ctx, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListSubscribed(ctx context.Context, userID primitive.ObjectID, eventType string) ([]models.WebhookEndpoint, error) {
+	if m.ListSubscribedFunc != nil {
+		return m.ListSubscribedFunc(ctx, userID, eventType)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(ctx context.Context, id, userID primitive.ObjectID) error {
+	if m.DeleteEndpointFunc != nil {
+		return m.DeleteEndpointFunc(ctx, id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if m.CreateDeliveryFunc != nil {
+		return m.CreateDeliveryFunc(ctx, delivery)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ClaimDelivery(ctx context.Context, now, until time.Time) (*models.WebhookDelivery, error) {
+	if m.ClaimDeliveryFunc != nil {
+		return m.ClaimDeliveryFunc(ctx, now, until)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if m.SaveAttemptFunc != nil {
+		return m.SaveAttemptFunc(ctx, delivery)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, endpointID primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error) {
+	if m.ListDeliveriesFunc != nil {
+		return m.ListDeliveriesFunc(ctx, endpointID, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListStaleSecrets(ctx context.Context, activeKeyID string) ([]models.WebhookEndpoint, error) {
+	if m.ListStaleSecretsFunc != nil {
+		return m.ListStaleSecretsFunc(ctx, activeKeyID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ResealSecret(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+	if m.ResealSecretFunc != nil {
+		return m.ResealSecretFunc(ctx, id, previous, sealed)
+	}
+	return errors.New("not implemented")
+}
+
+// webhookReceiver is a local endpoint that records what it is sent
+type webhookReceiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	status   int
+	received []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, receivedWebhook{header: req.Header.Clone(), body: body})
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *webhookReceiver) requests() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.received)
+}
+
+// verifyWebhook checks a received webhook's signature with secret and decodes its event
+func verifyWebhook(t *testing.T, received receivedWebhook, secret string) models.Event {
+	t.Helper()
+	signature := received.header.Get(services.WebhookSignatureHeader)
+	var timestamp int64
+	if _, err := fmt.Sscanf(signature, "t=%d,", &timestamp); err != nil {
+		t.Fatalf("Malformed signature header %q", signature)
+	}
+	if want := services.SignWebhook(secret, timestamp, received.body); signature != want {
+		t.Fatalf("Expected signature %s, got %s", want, signature)
+	}
+	var event models.Event
+	if err := json.Unmarshal(received.body, &event); err != nil {
+		t.Fatalf("Expected a JSON event, got %s", received.body)
+	}
+	if received.header.Get(services.WebhookEventHeader) != event.Type {
+		t.Errorf("Expected the event header to name %s", event.Type)
+	}
+	return event
+}
+
+// webhookStore keeps endpoints and deliveries in memory, claiming each due delivery
+// for one worker at a time as the Mongo lease does
+func webhookStore(endpoints map[primitive.ObjectID]*models.WebhookEndpoint, deliveries *[]*models.WebhookDelivery) *MockWebhookRepository {
+	var mu sync.Mutex
+	return &MockWebhookRepository{
+		CreateEndpointFunc: func(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored := *endpoint
+			endpoints[endpoint.ID] = &stored
+			return nil
+		},
+		GetEndpointByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*models.WebhookEndpoint, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			endpoint, ok := endpoints[id]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			copied := *endpoint
+			return &copied, nil
+		},
+		ListSubscribedFunc: func(ctx context.Context, userID primitive.ObjectID, eventType string) ([]models.WebhookEndpoint, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var subscribed []models.WebhookEndpoint
+			for _, endpoint := range endpoints {
+				if endpoint.UserID == userID && slices.Contains(endpoint.Events, eventType) {
+					subscribed = append(subscribed, *endpoint)
+				}
+			}
+			return subscribed, nil
+		},
+		ListStaleSecretsFunc: func(ctx context.Context, activeKeyID string) ([]models.WebhookEndpoint, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var stale []models.WebhookEndpoint
+			for _, endpoint := range endpoints {
+				if endpoint.SecretSealed != nil && endpoint.SecretSealed.KeyID != activeKeyID {
+					stale = append(stale, *endpoint)
+				}
+			}
+			return stale, nil
+		},
+		ResealSecretFunc: func(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+			mu.Lock()
+			defer mu.Unlock()
+			endpoint, ok := endpoints[id]
+			if !ok || !bytes.Equal(endpoint.SecretSealed.Nonce, previous.Nonce) {
+				return mongo.ErrNoDocuments
+			}
+			endpoint.SecretSealed = sealed
+			return nil
+		},
+		CreateDeliveryFunc: func(ctx context.Context, delivery *models.WebhookDelivery) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored := *delivery
+			*deliveries = append(*deliveries, &stored)
+			return nil
+		},
+		ClaimDeliveryFunc: func(ctx context.Context, now, until time.Time) (*models.WebhookDelivery, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, delivery := range *deliveries {
+				if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) && !delivery.LockedUntil.After(now) {
+					delivery.LockedUntil = until
+					copied := *delivery
+					return &copied, nil
+				}
+			}
+			return nil, mongo.ErrNoDocuments
+		},
+		SaveAttemptFunc: func(ctx context.Context, delivery *models.WebhookDelivery) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for i, stored := range *deliveries {
+				if stored.ID == delivery.ID {
+					saved := *delivery
+					saved.LockedUntil = time.Time{}
+					(*deliveries)[i] = &saved
+					return nil
+				}
+			}
+			return mongo.ErrNoDocuments
+		},
+		ListDeliveriesFunc: func(ctx context.Context, endpointID primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var logged []models.WebhookDelivery
+			for _, delivery := range slices.Backward(*deliveries) {
+				if delivery.EndpointID == endpointID {
+					logged = append(logged, *delivery)
+				}
+			}
+			return logged, nil
+		},
+	}
+}
+
+// newWebhookService signs with a fixed key; allowPrivate lets deliveries reach a local receiver
+func newWebhookService(t *testing.T, repo *MockWebhookRepository, allowPrivate bool) *services.WebhookService {
+	t.Helper()
+	keys, err := services.NewLocalKeyProvider("2026-10", map[string][]byte{"2026-10": bytes.Repeat([]byte{5}, 32)})
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	return services.NewWebhookService(repo, services.NewFieldCipher(keys), services.NewWebhookClient(allowPrivate))
+}
+
+func newWebhookApp(service *services.WebhookService) *fiber.App {
+	handler := handlers.NewWebhookHandler(service)
+	app := fiber.New()
+	app.Use(middleware.UserContextMiddleware())
+	app.Post("/webhooks", handler.CreateWebhook)
+	app.Get("/webhooks/:id/deliveries", handler.GetDeliveries)
+	app.Post("/webhooks/:id/test", handler.TestWebhook)
+	return app
+}
+
+func createWebhook(t *testing.T, service *services.WebhookService, userID primitive.ObjectID, url string, events ...string) *services.CreatedWebhook {
+	t.Helper()
+	created, err := service.CreateEndpoint(context.Background(), userID, url+"/hooks", "", events)
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	return created
+}
+
+// Test HandleEvent - events are queued for the user's subscribed endpoints only
+func TestHandleEvent_QueuesForSubscribers(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	subscribed := createWebhook(t, service, testUserID, receiver.server.URL, models.EventTransactionCreated, models.EventBalanceLow)
+	createWebhook(t, service, testUserID, receiver.server.URL, models.EventSyncFailed)
+	createWebhook(t, service, primitive.NewObjectID(), receiver.server.URL, models.EventTransactionCreated)
+
+	event := models.Event{ID: primitive.NewObjectID(), Type: models.EventTransactionCreated, UserID: testUserID, CreatedAt: time.Now().UTC()}
+	if err := service.HandleEvent(context.Background(), event); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].EndpointID != subscribed.Endpoint.ID || len(receiver.requests()) != 0 {
+		t.Errorf("Expected one queued delivery and nothing sent yet, got %+v", deliveries)
+	}
+}
+
+// Test DeliverDue - queued events reach the endpoint signed with its secret
+func TestDeliverDue_SignsEvents(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	created := createWebhook(t, service, testUserID, receiver.server.URL, models.EventTransactionCreated)
+	_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventTransactionCreated, UserID: testUserID})
+
+	result, err := service.DeliverDue(context.Background(), time.Now().UTC())
+	if err != nil || result.Delivered != 1 {
+		t.Fatalf("Expected one delivery, got %+v (%v)", result, err)
+	}
+	requests := receiver.requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected one request, got %d", len(requests))
+	}
+	if event := verifyWebhook(t, requests[0], created.Secret); event.UserID != testUserID || event.Type != models.EventTransactionCreated {
+		t.Errorf("Expected the user's transaction.created event, got %+v", event)
+	}
+}
+
+// Test DeliverDue - delivered events are not sent again
+func TestDeliverDue_SendsOnce(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	createWebhook(t, service, testUserID, receiver.server.URL, models.EventTransactionCreated)
+	_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventTransactionCreated, UserID: testUserID})
+
+	_, _ = service.DeliverDue(context.Background(), time.Now().UTC())
+	if result, _ := service.DeliverDue(context.Background(), time.Now().UTC()); result.Delivered != 0 || len(receiver.requests()) != 1 {
+		t.Errorf("Expected the delivered event not to be sent again, got %+v", result)
+	}
+}
+
+// Test DeliverDue - workers running together send each delivery once
+func TestDeliverDue_ConcurrentWorkers(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	createWebhook(t, service, testUserID, receiver.server.URL, models.EventTransactionCreated)
+	for range 6 {
+		_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventTransactionCreated, UserID: testUserID})
+	}
+
+	now := time.Now().UTC()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.DeliverDue(context.Background(), now); err != nil {
+				t.Errorf("DeliverDue failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]int{}
+	for _, received := range receiver.requests() {
+		seen[received.header.Get(services.WebhookDeliveryHeader)]++
+	}
+	if len(seen) != 6 {
+		t.Errorf("Expected six distinct deliveries, got %d", len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("Expected delivery %s once, got %d", id, count)
+		}
+	}
+}
+
+// Test DeliverDue - failing receivers are retried with backoff
+func TestDeliverDue_RetriesWithBackoff(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	receiver.status = http.StatusInternalServerError
+	createWebhook(t, service, testUserID, receiver.server.URL, models.EventSyncFailed)
+	_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventSyncFailed, UserID: testUserID})
+
+	now := time.Now().UTC()
+	for attempt := 1; attempt < services.WebhookMaxAttempts; attempt++ {
+		result, _ := service.DeliverDue(context.Background(), now)
+		delivery := deliveries[0]
+		if result.Retrying != 1 || delivery.Attempts != attempt || !delivery.NextAttemptAt.Equal(now.Add(services.WebhookBackoff(attempt))) {
+			t.Fatalf("Expected retry %d after backoff, got %+v", attempt, delivery)
+		}
+		if result, _ := service.DeliverDue(context.Background(), now.Add(time.Second)); result.Retrying+result.Failed+result.Delivered != 0 {
+			t.Fatalf("Expected nothing to be sent before the backoff passes")
+		}
+		now = delivery.NextAttemptAt
+	}
+}
+
+// Test DeliverDue - a delivery fails once its attempts run out
+func TestDeliverDue_FailsAfterLastAttempt(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	receiver.status = http.StatusInternalServerError
+	createWebhook(t, service, testUserID, receiver.server.URL, models.EventSyncFailed)
+	_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventSyncFailed, UserID: testUserID})
+	deliveries[0].Attempts = services.WebhookMaxAttempts - 1
+
+	result, _ := service.DeliverDue(context.Background(), time.Now().UTC())
+	if result.Failed != 1 || deliveries[0].Status != models.DeliveryFailed || deliveries[0].ResponseStatus != 500 {
+		t.Errorf("Expected the last attempt to fail the delivery, got %+v", deliveries[0])
+	}
+}
+
+// Test GetDeliveries - the owner sees the delivery log
+func TestGetDeliveries(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	created := createWebhook(t, service, testUserID, receiver.server.URL, models.EventSyncFailed)
+	_ = service.HandleEvent(context.Background(), models.Event{ID: primitive.NewObjectID(), Type: models.EventSyncFailed, UserID: testUserID})
+
+	rec := userRequest(t, newWebhookApp(service), testUserID, "GET", "/webhooks/"+created.Endpoint.ID.Hex()+"/deliveries", nil)
+	var log []models.WebhookDelivery
+	_ = json.Unmarshal(rec.Body.Bytes(), &log)
+	if rec.Code != fiber.StatusOK || len(log) != 1 || log[0].Status != models.DeliveryPending {
+		t.Errorf("Expected the pending delivery in the log, got %d %+v", rec.Code, log)
+	}
+}
+
+// Test GetDeliveries - other users are refused the log
+func TestGetDeliveries_Forbidden(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	created := createWebhook(t, service, testUserID, "https://hooks.example.com", models.EventSyncFailed)
+
+	rec := userRequest(t, newWebhookApp(service), primitive.NewObjectID(), "GET", "/webhooks/"+created.Endpoint.ID.Hex()+"/deliveries", nil)
+	if rec.Code != fiber.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rec.Code)
+	}
+}
+
+// Test CreateWebhook - only http and https URLs are accepted
+func TestCreateWebhook_InvalidURL(t *testing.T) {
+	repo := &MockWebhookRepository{
+		CreateEndpointFunc: func(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+			t.Error("Expected no endpoint to be stored")
+			return nil
+		},
+	}
+	app := newWebhookApp(newWebhookService(t, repo, false))
+
+	payload := handlers.WebhookPayload{URL: "ftp://example.com", Events: []string{models.EventBudgetExceeded}}
+	if rec := userRequest(t, app, testUserID, "POST", "/webhooks", payload); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+// Test CreateWebhook - test events cannot be subscribed to
+func TestCreateWebhook_TestEventRefused(t *testing.T) {
+	repo := &MockWebhookRepository{
+		CreateEndpointFunc: func(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+			t.Error("Expected no endpoint to be stored")
+			return nil
+		},
+	}
+	app := newWebhookApp(newWebhookService(t, repo, false))
+
+	payload := handlers.WebhookPayload{URL: "https://hooks.example.com", Events: []string{models.EventWebhookTest}}
+	if rec := userRequest(t, app, testUserID, "POST", "/webhooks", payload); rec.Code != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+// Test CreateWebhook - the secret is returned once and stored sealed
+func TestCreateWebhook_SealsSecret(t *testing.T) {
+	endpoints := map[primitive.ObjectID]*models.WebhookEndpoint{}
+	var deliveries []*models.WebhookDelivery
+	app := newWebhookApp(newWebhookService(t, webhookStore(endpoints, &deliveries), false))
+
+	payload := handlers.WebhookPayload{URL: "https://hooks.example.com", Events: []string{models.EventBudgetExceeded}}
+	rec := userRequest(t, app, testUserID, "POST", "/webhooks", payload)
+	var created services.CreatedWebhook
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != fiber.StatusCreated || created.Secret == "" {
+		t.Fatalf("Expected the webhook with its secret, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored := endpoints[created.Endpoint.ID]
+	if stored.SecretSealed == nil || bytes.Contains(stored.SecretSealed.Ciphertext, []byte(created.Secret)) {
+		t.Errorf("Expected the secret stored sealed, got %+v", stored.SecretSealed)
+	}
+}
+
+// Test TestWebhook - a test event is sent right away
+func TestTestWebhook_Delivers(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), true)
+	receiver := newWebhookReceiver(t)
+	created := createWebhook(t, service, testUserID, receiver.server.URL, models.EventBudgetExceeded)
+
+	rec := userRequest(t, newWebhookApp(service), testUserID, "POST", "/webhooks/"+created.Endpoint.ID.Hex()+"/test", nil)
+	var delivery models.WebhookDelivery
+	_ = json.Unmarshal(rec.Body.Bytes(), &delivery)
+	requests := receiver.requests()
+	if rec.Code != fiber.StatusOK || delivery.Status != models.DeliveryDelivered || delivery.ResponseStatus != 200 || len(requests) != 1 {
+		t.Fatalf("Expected the test event to be delivered, got %d %+v", rec.Code, delivery)
+	}
+	if event := verifyWebhook(t, requests[0], created.Secret); event.Type != models.EventWebhookTest {
+		t.Errorf("Expected a webhook.test event, got %s", event.Type)
+	}
+}
+
+// Test TestWebhook - by default deliveries never connect to private addresses
+func TestTestWebhook_RefusesPrivateAddresses(t *testing.T) {
+	var deliveries []*models.WebhookDelivery
+	service := newWebhookService(t, webhookStore(map[primitive.ObjectID]*models.WebhookEndpoint{}, &deliveries), false)
+	receiver := newWebhookReceiver(t)
+	created := createWebhook(t, service, testUserID, receiver.server.URL, models.EventBudgetExceeded)
+
+	rec := userRequest(t, newWebhookApp(service), testUserID, "POST", "/webhooks/"+created.Endpoint.ID.Hex()+"/test", nil)
+	var delivery models.WebhookDelivery
+	_ = json.Unmarshal(rec.Body.Bytes(), &delivery)
+	if delivery.Status == models.DeliveryDelivered || !strings.Contains(delivery.LastError, services.ErrPrivateWebhook.Error()) {
+		t.Errorf("Expected the loopback receiver to be refused, got %d %+v", rec.Code, delivery)
+	}
+	if len(receiver.requests()) != 0 {
+		t.Errorf("Expected nothing to reach the receiver, got %d requests", len(receiver.requests()))
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TokenPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// TokenHandler handles personal access token HTTP requests
+type TokenHandler struct {
+	service *services.TokenService
+}
+
+// NewTokenHandler creates a new TokenHandler
+func NewTokenHandler(service *services.TokenService) *TokenHandler {
+	return &TokenHandler{service: service}
+}
+
+func (h *TokenHandler) ListTokens(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, err := tokenOwner(c)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := h.service.ListTokens(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// CreateToken issues a token; its secret is only returned in this response
+func (h *TokenHandler) CreateToken(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, err := tokenOwner(c)
+	if err != nil {
+		return err
+	}
+
+	var payload TokenPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	issued, err := h.service.CreateToken(ctx, userID, payload.Name, payload.Scopes, payload.ExpiresInDays)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+func (h *TokenHandler) RevokeToken(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, err := tokenOwner(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Token ID")
+	}
+
+	if err := h.service.RevokeToken(ctx, userID, id); err != nil {
+		return tokenError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tokenOwner returns the calling user. Tokens are managed from the user's own session,
+// never with another token, so a leaked token cannot mint or extend itself, and never
+// while impersonating, so an admin cannot mint tokens that outlive the impersonation.
+func tokenOwner(c *fiber.Ctx) (primitive.ObjectID, error) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return userID, fiber.ErrUnauthorized
+	}
+	if _, isToken := middleware.TokenScopes(c); isToken {
+		return userID, fiber.NewError(fiber.StatusForbidden, "Error: Access Tokens Cannot Manage Tokens")
+	}
+	if _, impersonating := middleware.Impersonator(c); impersonating {
+		return userID, fiber.NewError(fiber.StatusForbidden, "Error: Tokens Cannot Be Managed While Impersonating")
+	}
+	return userID, nil
+}
+
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidScopes):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrTokenNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Access Token Not Found In DB")
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
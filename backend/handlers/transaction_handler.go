@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dateLayout is the format used for date query parameters
+const dateLayout = "2006-01-02"
+
+type SplitPayload struct {
+	Amount   float32 `json:"amount"`
+	Category string  `json:"category"`
+	BudgetID string  `json:"budget_id"`
+	Memo     string  `json:"memo"`
+}
+
+type SplitsPayload struct {
+	Splits []SplitPayload `json:"splits"`
+}
+
+type ImportTransactionPayload struct {
+	Name              string    `json:"name"`
+	Category          string    `json:"category"`
+	Type              string    `json:"type"`
+	Status            string    `json:"status"`
+	Amount            float32   `json:"amount"`
+	TransactionDate   time.Time `json:"transaction_date"`
+	TransactionPosted time.Time `json:"transaction_posted"`
+	Description       string    `json:"description"`
+	Tags              []string  `json:"tags"`
+}
+
+type TagsPayload struct {
+	Tags []string `json:"tags"`
+}
+
+type ImportPayload struct {
+	Transactions []ImportTransactionPayload `json:"transactions"`
+}
+
+// TransactionHandler handles transaction-related HTTP requests
+type TransactionHandler struct {
+	service *services.TransactionService
+}
+
+// NewTransactionHandler creates a new TransactionHandler
+func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+	return &TransactionHandler{service: service}
+}
+
+// ListTransactions returns transactions for the requested accounts, filtered by status and date
+func (h *TransactionHandler) ListTransactions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountIDs, err := parseObjectIDList(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	txns, err := h.service.ListTransactions(ctx, userID, accountIDs, c.Query("status"), start, end)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidStatus), errors.Is(err, services.ErrInvalidTags):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(txns)
+}
+
+// ImportTransactions records a batch of pending and posted transactions for an account
+func (h *TransactionHandler) ImportTransactions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	var payload ImportPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	incoming := make([]models.Transaction, 0, len(payload.Transactions))
+	for _, txn := range payload.Transactions {
+		if txn.Amount <= 0 || (txn.Type != models.TransactionTypeDebit && txn.Type != models.TransactionTypeCredit) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid Transaction Amount Or Type")
+		}
+		incoming = append(incoming, models.Transaction{
+			Name:              txn.Name,
+			Category:          txn.Category,
+			Type:              txn.Type,
+			Status:            txn.Status,
+			Amount:            txn.Amount,
+			TransactionDate:   txn.TransactionDate,
+			TransactionPosted: txn.TransactionPosted,
+			Description:       txn.Description,
+			Tags:              txn.Tags,
+		})
+	}
+
+	result, err := h.service.Import(ctx, userID, accountID, incoming)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAccountNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+		case errors.Is(err, services.ErrInvalidStatus):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrTransactionLocked):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// VoidTransaction cancels a transaction so it no longer counts toward balances or reports
+func (h *TransactionHandler) VoidTransaction(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	txn, err := h.service.Void(ctx, userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransactionNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transaction Not Found In DB")
+		case errors.Is(err, services.ErrTransactionLocked), errors.Is(err, services.ErrTransactionVoid):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(txn)
+}
+
+// GetSummary reports income and spending for the requested accounts, excluding transfers
+func (h *TransactionHandler) GetSummary(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountIDs, err := parseObjectIDList(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	summary, err := h.service.Summarize(ctx, userID, accountIDs, start, end)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(summary)
+}
+
+// GetCategoryReport totals income and spending per category, respecting split allocations
+func (h *TransactionHandler) GetCategoryReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	accountIDs, err := parseObjectIDList(c.Query("account_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Account ID")
+	}
+	start, end, err := parseDateRange(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Date Range")
+	}
+
+	totals, err := h.service.CategoryTotals(ctx, userID, accountIDs, start, end)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(totals)
+}
+
+// SetSplits divides a transaction across several categories and budgets
+func (h *TransactionHandler) SetSplits(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	var payload SplitsPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	splits := make([]models.TransactionSplit, 0, len(payload.Splits))
+	for _, split := range payload.Splits {
+		var budgetID primitive.ObjectID
+		if split.BudgetID != "" {
+			if budgetID, err = primitive.ObjectIDFromHex(split.BudgetID); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid Budget ID")
+			}
+		}
+		splits = append(splits, models.TransactionSplit{
+			Amount:   split.Amount,
+			Category: split.Category,
+			BudgetID: budgetID,
+			Memo:     split.Memo,
+		})
+	}
+
+	txn, err := h.service.SetSplits(ctx, userID, id, splits)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransactionNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transaction Not Found In DB")
+		case errors.Is(err, services.ErrInvalidSplit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrTransactionLocked):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(txn)
+}
+
+// SetTags replaces the tags on a transaction, which reports can group by
+func (h *TransactionHandler) SetTags(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	var payload TagsPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	txn, err := h.service.SetTags(ctx, userID, id, payload.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransactionNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transaction Not Found In DB")
+		case errors.Is(err, services.ErrInvalidTags):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(txn)
+}
+
+// parseObjectIDList parses a comma separated list of hex IDs
+func parseObjectIDList(raw string) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseDateRange reads the optional start and end query parameters.
+// The end date is inclusive of the whole day.
+func parseDateRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+
+	if raw := c.Query("start"); raw != "" {
+		if start, err = time.Parse(dateLayout, raw); err != nil {
+			return start, end, err
+		}
+	}
+	if raw := c.Query("end"); raw != "" {
+		if end, err = time.Parse(dateLayout, raw); err != nil {
+			return start, end, err
+		}
+		end = end.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return start, end, nil
+}
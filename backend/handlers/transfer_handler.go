@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TransferPayload struct {
+	FromAccountID string    `json:"from_account_id"`
+	ToAccountID   string    `json:"to_account_id"`
+	Amount        float32   `json:"amount"`
+	Date          time.Time `json:"date"`
+	Description   string    `json:"description"`
+}
+
+// TransferHandler handles transfer-related HTTP requests
+type TransferHandler struct {
+	service *services.TransferService
+}
+
+// NewTransferHandler creates a new TransferHandler
+func NewTransferHandler(service *services.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// CreateTransfer moves money between two accounts
+func (h *TransferHandler) CreateTransfer(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload TransferPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	from, err := primitive.ObjectIDFromHex(payload.FromAccountID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid From Account")
+	}
+	to, err := primitive.ObjectIDFromHex(payload.ToAccountID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid To Account")
+	}
+
+	transfer, err := h.service.CreateTransfer(ctx, userID, services.TransferRequest{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        payload.Amount,
+		Date:          payload.Date,
+		Description:   payload.Description,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTransfer):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrAccountNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Account Not Found In DB")
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+// GetTransfer retrieves both legs of a transfer by transfer ID
+func (h *TransferHandler) GetTransfer(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	transfer, err := h.service.GetTransfer(ctx, userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransferNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transfer Not Found In DB")
+		case errors.Is(err, services.ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(transfer)
+}
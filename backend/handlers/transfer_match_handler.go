@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TransferMatchPayload struct {
+	WindowDays int `json:"window_days"`
+}
+
+// TransferMatchHandler handles transfer matching HTTP requests
+type TransferMatchHandler struct {
+	service *services.TransferMatchService
+}
+
+// NewTransferMatchHandler creates a new TransferMatchHandler
+func NewTransferMatchHandler(service *services.TransferMatchService) *TransferMatchHandler {
+	return &TransferMatchHandler{service: service}
+}
+
+// FindMatches proposes links between the current user's outflows and inflows
+func (h *TransferMatchHandler) FindMatches(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload TransferMatchPayload
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return fiber.ErrBadRequest
+		}
+	}
+
+	matches, err := h.service.FindMatches(ctx, userID, payload.WindowDays)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(matches)
+}
+
+// ListMatches returns the current user's proposals, optionally filtered by status
+func (h *TransferMatchHandler) ListMatches(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	matches, err := h.service.ListMatches(ctx, userID, c.Query("status"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(matches)
+}
+
+// AcceptMatch links the proposed pair as a transfer
+func (h *TransferMatchHandler) AcceptMatch(c *fiber.Ctx) error {
+	return h.resolve(c, h.service.AcceptMatch)
+}
+
+// RejectMatch dismisses the proposed pair
+func (h *TransferMatchHandler) RejectMatch(c *fiber.Ctx) error {
+	return h.resolve(c, h.service.RejectMatch)
+}
+
+func (h *TransferMatchHandler) resolve(c *fiber.Ctx, action func(ctx context.Context, userID, matchID primitive.ObjectID) (*models.TransferMatch, error)) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	match, err := action(ctx, userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMatchNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Transfer Match Not Found In DB")
+		case errors.Is(err, services.ErrMatchResolved), errors.Is(err, services.ErrAlreadyTransfer):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(match)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TwoFactorCodePayload struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorHandler handles TOTP enrollment, verification and step-up HTTP requests
+type TwoFactorHandler struct {
+	service *services.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler
+func NewTwoFactorHandler(service *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service}
+}
+
+// Enroll returns a new TOTP secret and its otpauth:// provisioning URI for a QR code
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, err := twoFactorOwner(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.service.Enroll(ctx, userID)
+	if err != nil {
+		return twoFactorError(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(enrollment)
+}
+
+// Confirm enables two-factor authentication and returns the one-time recovery codes
+func (h *TwoFactorHandler) Confirm(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, session, err := twoFactorLogin(c)
+	if err != nil {
+		return err
+	}
+
+	var payload TwoFactorCodePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	activation, err := h.service.Confirm(ctx, userID, session.ID, payload.Code)
+	if err != nil {
+		return twoFactorError(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(activation)
+}
+
+// Verify is the login step for users with two-factor authentication. It completes the
+// sign-in session of the request; send the returned session token as X-Two-Factor-Session
+// alongside it.
+func (h *TwoFactorHandler) Verify(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, session, err := twoFactorLogin(c)
+	if err != nil {
+		return err
+	}
+
+	var payload TwoFactorCodePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	issued, err := h.service.Verify(ctx, userID, session.ID, payload.Code, payload.RecoveryCode)
+	if err != nil {
+		return twoFactorError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+// StepUp re-enters a code so the session may perform sensitive operations for a few minutes
+func (h *TwoFactorHandler) StepUp(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, session, err := twoFactorLogin(c)
+	if err != nil {
+		return err
+	}
+
+	var payload TwoFactorCodePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.service.StepUp(ctx, userID, session.ID, c.Get("X-Two-Factor-Session"), payload.Code, payload.RecoveryCode); err != nil {
+		return twoFactorError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, err := twoFactorOwner(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.Disable(ctx, userID); err != nil {
+		return twoFactorError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// twoFactorOwner returns the calling user, refusing access tokens and impersonating
+// admins, who must not change another user's second factor
+func twoFactorOwner(c *fiber.Ctx) (primitive.ObjectID, error) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return userID, fiber.ErrUnauthorized
+	}
+	if _, isToken := middleware.TokenScopes(c); isToken {
+		return userID, fiber.NewError(fiber.StatusForbidden, "Error: Access Tokens Cannot Manage Two-Factor Authentication")
+	}
+	if _, impersonating := middleware.Impersonator(c); impersonating {
+		return userID, fiber.NewError(fiber.StatusForbidden, "Error: Two-Factor Authentication Cannot Be Managed While Impersonating")
+	}
+	return userID, nil
+}
+
+// twoFactorLogin returns the calling user and the sign-in session the request came with;
+// codes are only checked within a signed-in session
+func twoFactorLogin(c *fiber.Ctx) (primitive.ObjectID, *services.Session, error) {
+	userID, err := twoFactorOwner(c)
+	if err != nil {
+		return userID, nil, err
+	}
+	session, ok := middleware.CurrentSession(c)
+	if !ok {
+		return userID, nil, fiber.NewError(fiber.StatusUnauthorized, "Error: Sign In Before Entering A Two-Factor Code")
+	}
+	return userID, session, nil
+}
+
+func twoFactorError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidTOTPCode), errors.Is(err, services.ErrTwoFactorRequired), errors.Is(err, services.ErrInvalidSession):
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrTOTPLocked):
+		return fiber.NewError(fiber.StatusTooManyRequests, err.Error())
+	case errors.Is(err, services.ErrTOTPNotEnrolled), errors.Is(err, services.ErrTOTPAlreadyEnabled):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -14,9 +14,12 @@ type Payload struct {
 		Username    string   `json:"username"`
 		Email       string   `json:"email"`
 		NetWorth    float64  `json:"net_worth"`
+		// Accounts is ignored; accounts join a profile when they are linked or imported
 		Accounts    []string `json:"accounts"`
 		CreditScore int      `json:"credit_score"`
 		Budget      []string `json:"budget"`
+		// Password is only read on sign-up; it is stored hashed
+		Password    string   `json:"password"`
 }
 
 // UserHandler handles user-related HTTP requests
@@ -74,10 +77,16 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		Username:    payload.Username,
 		Email:       payload.Email,
 		NetWorth:    payload.NetWorth,
-		Accounts:    payload.Accounts,
 		CreditScore: payload.CreditScore,
 		Budget:      payload.Budget,
 	}
+	if payload.Password != "" {
+		hash, err := services.HashPassword(payload.Password)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		user.PasswordHash = hash
+	}
 
 	err := h.service.CreateUser(ctx, &user)
 	if err != nil {
@@ -108,7 +117,6 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		Username:    payload.Username,
 		Email:       payload.Email,
 		NetWorth:    payload.NetWorth,
-		Accounts:    payload.Accounts,
 		CreditScore: payload.CreditScore,
 		Budget:      payload.Budget,
 	}
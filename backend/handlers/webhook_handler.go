@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookPayload struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+}
+
+// WebhookHandler handles webhook endpoint HTTP requests
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// CreateWebhook registers an endpoint; the response holds its signing secret, shown only once
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var payload WebhookPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	created, err := h.service.CreateEndpoint(ctx, userID, payload.URL, payload.Description, payload.Events)
+	if err != nil {
+		return webhookError(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	endpoints, err := h.service.ListEndpoints(ctx, userID)
+	if err != nil {
+		return webhookError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(endpoints)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	if err := h.service.DeleteEndpoint(ctx, userID, id); err != nil {
+		return webhookError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDeliveries returns a webhook's delivery log, newest first
+func (h *WebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	deliveries, err := h.service.Deliveries(ctx, userID, id, int64(c.QueryInt("limit")))
+	if err != nil {
+		return webhookError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+// TestWebhook sends a webhook.test event right away and returns how the delivery went
+func (h *WebhookHandler) TestWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	delivery, err := h.service.TestFire(ctx, userID, id)
+	if err != nil {
+		return webhookError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(delivery)
+}
+
+func webhookError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhook):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Webhook Not Found In DB")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/fs"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -25,14 +30,265 @@ func main() {
 	// Defer close to ensure MongoDB disconnects on app exit
 	defer db.Close()
 
-	app := fiber.New()
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), time.Minute)
+	if err := repository.Migrate(migrateCtx, mongodb); err != nil {
+		log.Fatalf("err: migrations: %v", err)
+	}
+	if err := repository.EnsureIndexes(migrateCtx, mongodb); err != nil {
+		log.Fatalf("err: indexes: %v", err)
+	}
+	cancelMigrate()
+
+	// bodies past the default limit are streamed so archive imports can be spooled to disk;
+	// BodyLimitMiddleware keeps the default limit everywhere else
+	app := fiber.New(fiber.Config{StreamRequestBody: true, DisablePreParseMultipartForm: true})
+	app.Use(middleware.BodyLimitMiddleware(fiber.DefaultBodyLimit, "/api/archive/import"))
 	app.Use(middleware.MongoContextMiddleware(5 * time.Second))
 
 	UserRepository := repository.NewMongoUserRepository(mongodb)
 	userService := services.NewUserService(UserRepository)
 	userHandler := handlers.NewUserHandler(userService)
 
+	// SESSION_SECRET signs session tokens. DEV_MODE lets development start without one,
+	// signing with a random secret, so sessions end when the server stops.
+	devMode := os.Getenv("DEV_MODE") != ""
+	sessionSecret := []byte(os.Getenv("SESSION_SECRET"))
+	if len(sessionSecret) == 0 && devMode {
+		sessionSecret = make([]byte, services.MinSessionSecret)
+		if _, err := rand.Read(sessionSecret); err != nil {
+			log.Fatalf("err: session secret: %v", err)
+		}
+		log.Printf("signing sessions with a random development secret; set SESSION_SECRET outside development")
+	}
+	if len(sessionSecret) < services.MinSessionSecret {
+		log.Fatalf("err: SESSION_SECRET must be set to at least %d bytes", services.MinSessionSecret)
+	}
+	sessionService := services.NewSessionService(UserRepository, sessionSecret)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	app.Use(middleware.SessionMiddleware(sessionService))
+
+	tokenRepository := repository.NewMongoTokenRepository(mongodb)
+	tokenService := services.NewTokenService(tokenRepository, UserRepository)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	app.Use(middleware.TokenAuthMiddleware(tokenService))
+
+	// FIELD_KEYS_FILE names the master key file. DEV_MODE lets development start without
+	// one, generating a key file once in the working directory.
+	fieldKeysFile := os.Getenv("FIELD_KEYS_FILE")
+	if fieldKeysFile == "" {
+		if !devMode {
+			log.Fatalf("err: FIELD_KEYS_FILE must name the field encryption key file")
+		}
+		fieldKeysFile = "field_keys.json"
+		if err := services.GenerateLocalKeyFile(fieldKeysFile, time.Now()); err == nil {
+			log.Printf("generated development field encryption keys in %s; set FIELD_KEYS_FILE outside development", fieldKeysFile)
+		} else if !errors.Is(err, fs.ErrExist) {
+			log.Fatalf("err: field encryption keys: %v", err)
+		}
+	}
+	fieldKeys, err := services.LoadLocalKeyProvider(fieldKeysFile)
+	if err != nil {
+		log.Fatalf("err: field encryption keys: %v", err)
+	}
+	fieldCipher := services.NewFieldCipher(fieldKeys)
+
+	twoFactorRepository := repository.NewMongoTwoFactorRepository(mongodb)
+	twoFactorService := services.NewTwoFactorService(UserRepository, twoFactorRepository, fieldCipher)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	app.Use(middleware.TwoFactorMiddleware(twoFactorService))
+
+	auditRepository := repository.NewMongoAuditRepository(mongodb)
+	adminService := services.NewAdminService(UserRepository, auditRepository)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	app.Use(middleware.AuthorizationMiddleware(adminService))
+
+	accountRepository := repository.NewMongoAccountRepository(mongodb)
+	transactionRepository := repository.NewMongoTransactionRepository(mongodb)
+	txRunner := repository.NewMongoTxRunner(mongodb)
+
+	householdRepository := repository.NewMongoHouseholdRepository(mongodb)
+	accessService := services.NewAccessService(accountRepository, householdRepository)
+
+	transferService := services.NewTransferService(accountRepository, transactionRepository, txRunner, accessService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+
+	budgetRepository := repository.NewMongoBudgetRepository(mongodb)
+
+	transactionService := services.NewTransactionService(transactionRepository, accountRepository, budgetRepository, txRunner, accessService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	budgetService := services.NewBudgetService(budgetRepository, transactionRepository, accessService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+
+	budgetTemplateRepository := repository.NewMongoBudgetTemplateRepository(mongodb)
+	budgetTemplateService := services.NewBudgetTemplateService(budgetTemplateRepository, budgetRepository, accountRepository, transactionRepository)
+	budgetTemplateHandler := handlers.NewBudgetTemplateHandler(budgetTemplateService)
+
+	reconciliationRepository := repository.NewMongoReconciliationRepository(mongodb)
+	reconciliationService := services.NewReconciliationService(accountRepository, transactionRepository, reconciliationRepository, txRunner, accessService)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+
+	accountNumberService := services.NewAccountNumberService(accountRepository, fieldCipher, accessService, auditRepository)
+	accountNumberHandler := handlers.NewAccountNumberHandler(accountNumberService)
+
+	connectionRepository := repository.NewMongoConnectionRepository(mongodb)
+	syncService := services.NewSyncService(connectionRepository, accountRepository, transactionRepository, UserRepository, fieldCipher)
+	if os.Getenv("FAKE_BANK") != "" {
+		syncService.RegisterProvider(services.NewFakeBankProvider(nil))
+	}
+	connectionHandler := handlers.NewConnectionHandler(syncService)
+
+	interestService := services.NewInterestService(accountRepository, transactionRepository, txRunner, accessService)
+	interestHandler := handlers.NewInterestHandler(interestService)
+
+	rewardRepository := repository.NewMongoRewardRepository(mongodb)
+	rewardService := services.NewRewardService(rewardRepository, UserRepository, accountRepository, transactionRepository, txRunner, accessService)
+	rewardHandler := handlers.NewRewardHandler(rewardService)
+	transactionService.AddEnricher(rewardService)
+	syncService.AddEnricher(rewardService)
+
+	creditRepository := repository.NewMongoCreditRepository(mongodb)
+	creditService := services.NewCreditService(creditRepository, UserRepository, accountRepository, accessService)
+	creditHandler := handlers.NewCreditHandler(creditService)
+
+	goalRepository := repository.NewMongoGoalRepository(mongodb)
+	goalService := services.NewGoalService(goalRepository, accountRepository, transactionRepository)
+	goalHandler := handlers.NewGoalHandler(goalService)
+
+	recurringRepository := repository.NewMongoRecurringRepository(mongodb)
+	forecastService := services.NewForecastService(recurringRepository, UserRepository, accountRepository)
+	forecastHandler := handlers.NewForecastHandler(forecastService)
+
+	envelopeRepository := repository.NewMongoEnvelopeRepository(mongodb)
+	envelopeService := services.NewEnvelopeService(envelopeRepository, accountRepository, transactionRepository)
+	envelopeHandler := handlers.NewEnvelopeHandler(envelopeService)
+
+	householdService := services.NewHouseholdService(householdRepository, UserRepository, accountRepository, budgetRepository, txRunner)
+	householdHandler := handlers.NewHouseholdHandler(householdService)
+
+	transferMatchRepository := repository.NewMongoTransferMatchRepository(mongodb)
+	transferMatchService := services.NewTransferMatchService(accountRepository, transactionRepository, transferMatchRepository, txRunner)
+	transferMatchHandler := handlers.NewTransferMatchHandler(transferMatchService)
+
+	// WEBHOOK_ALLOW_PRIVATE lets webhooks reach local receivers in development
+	webhookClient := services.NewWebhookClient(os.Getenv("WEBHOOK_ALLOW_PRIVATE") != "")
+	webhookService := services.NewWebhookService(repository.NewMongoWebhookRepository(mongodb), fieldCipher, webhookClient)
+	accountNumberService.AddRotator(twoFactorService)
+	accountNumberService.AddRotator(syncService)
+	accountNumberService.AddRotator(webhookService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	billRepository := repository.NewMongoBillRepository(mongodb)
+	billService := services.NewBillService(billRepository, accessService)
+	billHandler := handlers.NewBillHandler(billService)
+
+	calendarService := services.NewCalendarService(billRepository, recurringRepository, repository.NewMongoGrantRepository(mongodb))
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+
+	reportService := services.NewReportService(repository.NewMongoReportRepository(mongodb), accountRepository, accessService)
+	reportHandler := handlers.NewReportHandler(reportService)
+
+	alertRepository := repository.NewMongoAlertRepository(mongodb)
+	alertService := services.NewAlertService(alertRepository, UserRepository, accountRepository, transactionRepository, budgetRepository, recurringRepository, billRepository, accessService)
+	alertService.RegisterChannel(services.NewInAppChannel(alertRepository))
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil {
+			port = 587
+		}
+		alertService.RegisterChannel(services.NewSMTPChannel(host, port, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD")))
+	}
+	alertHandler := handlers.NewAlertHandler(alertService)
+
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe(webhookService)
+	eventBus.Subscribe(billService)
+	eventBus.Subscribe(alertService)
+	transactionService.SetEventBus(eventBus)
+	budgetService.SetEventBus(eventBus)
+	syncService.SetEventBus(eventBus)
+	transferService.SetEventBus(eventBus)
+	interestService.SetEventBus(eventBus)
+
+	netWorthRepository := repository.NewMongoNetWorthRepository(mongodb)
+	netWorthService := services.NewNetWorthService(UserRepository, accountRepository, netWorthRepository)
+
+	exportService := services.NewExportService(transactionRepository, accountRepository, budgetRepository, netWorthRepository, accessService)
+	exportHandler := handlers.NewExportHandler(exportService)
+
+	archiveService := services.NewArchiveService(UserRepository, accountRepository, transactionRepository, budgetRepository,
+		budgetTemplateRepository, goalRepository, alertRepository, txRunner)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+
+	scheduler := services.NewScheduler(repository.NewMongoJobRepository(mongodb))
+	jobs := []struct {
+		name, schedule string
+		run            services.JobFunc
+	}{
+		{"interest_accrual", "0 2 * * *", func(ctx context.Context, now time.Time) (any, error) {
+			return interestService.RunAccruals(ctx, now)
+		}},
+		{"budget_periods", "@hourly", func(ctx context.Context, now time.Time) (any, error) {
+			periods, err := budgetTemplateService.MaterializeAll(ctx, now)
+			if err != nil {
+				return periods, err
+			}
+			statuses, err := budgetService.RefreshStatuses(ctx, now)
+			return fiber.Map{"periods": periods, "statuses": statuses}, err
+		}},
+		{"bank_sync", "0 */6 * * *", func(ctx context.Context, now time.Time) (any, error) {
+			return syncService.SyncAllConnections(ctx)
+		}},
+		{"webhook_deliveries", "* * * * *", func(ctx context.Context, now time.Time) (any, error) {
+			return webhookService.DeliverDue(ctx, now)
+		}},
+		{"alerts", "*/15 * * * *", func(ctx context.Context, now time.Time) (any, error) {
+			return alertService.Evaluate(ctx, now)
+		}},
+		{"net_worth_snapshots", "55 23 * * *", func(ctx context.Context, now time.Time) (any, error) {
+			return netWorthService.TakeSnapshots(ctx, now)
+		}},
+	}
+	for _, job := range jobs {
+		if err := scheduler.Register(job.name, job.schedule, job.run); err != nil {
+			log.Fatalf("err: job %s: %v", job.name, err)
+		}
+	}
+	jobHandler := handlers.NewJobHandler(scheduler)
+
+	routes.SetupSessionRoutes(app, sessionHandler)
 	routes.SetupProductRoutes(app, userHandler)
+	routes.SetupTransferRoutes(app, transferHandler)
+	routes.SetupTransactionRoutes(app, transactionHandler)
+	routes.SetupTransferMatchRoutes(app, transferMatchHandler)
+	routes.SetupBudgetRoutes(app, budgetHandler)
+	routes.SetupBudgetTemplateRoutes(app, budgetTemplateHandler)
+	routes.SetupReconciliationRoutes(app, reconciliationHandler)
+	routes.SetupInterestRoutes(app, interestHandler)
+	routes.SetupAccountNumberRoutes(app, accountNumberHandler)
+	routes.SetupConnectionRoutes(app, connectionHandler)
+	routes.SetupRewardRoutes(app, rewardHandler)
+	routes.SetupCreditRoutes(app, creditHandler)
+	routes.SetupGoalRoutes(app, goalHandler)
+	routes.SetupForecastRoutes(app, forecastHandler)
+	routes.SetupEnvelopeRoutes(app, envelopeHandler)
+	routes.SetupHouseholdRoutes(app, householdHandler)
+	routes.SetupAdminRoutes(app, adminHandler)
+	routes.SetupTokenRoutes(app, tokenHandler)
+	routes.SetupTwoFactorRoutes(app, twoFactorHandler)
+	routes.SetupJobRoutes(app, jobHandler)
+	routes.SetupWebhookRoutes(app, webhookHandler)
+	routes.SetupAlertRoutes(app, alertHandler)
+	routes.SetupBillRoutes(app, billHandler)
+	routes.SetupCalendarRoutes(app, calendarHandler)
+	routes.SetupReportRoutes(app, reportHandler)
+	routes.SetupExportRoutes(app, exportHandler)
+	routes.SetupArchiveRoutes(app, archiveHandler)
+
+	// Periodic background work, run by whichever instance takes each job's lock
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go scheduler.Run(jobCtx, 30*time.Second)
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Hello World!")
@@ -44,6 +300,7 @@ func main() {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 		log.Println("Shutting down server...")
+		stopJobs()
 		if err := app.Shutdown(); err != nil {
 			log.Printf("Error shutting down server: %v", err)
 		}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	roleKey         string = "role"
+	impersonatorKey string = "impersonatorID"
+)
+
+// CallerResolver applies roles, disabled accounts and impersonation to the calling user
+type CallerResolver interface {
+	ResolveCaller(ctx context.Context, userID, impersonationID primitive.ObjectID) (*services.Caller, error)
+}
+
+// AuthorizationMiddleware resolves the role of the user set by UserContextMiddleware.
+// Admins may send X-Impersonation-ID to act as the impersonated user.
+// Anonymous requests pass through; RequirePermission turns them away.
+func AuthorizationMiddleware(resolver CallerResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := CurrentUserID(c)
+		if !ok {
+			return c.Next()
+		}
+
+		var impersonationID primitive.ObjectID
+		if raw := c.Get("X-Impersonation-ID"); raw != "" {
+			if _, isToken := TokenScopes(c); isToken {
+				return fiber.NewError(fiber.StatusForbidden, "Error: Access Tokens Cannot Impersonate")
+			}
+			id, err := primitive.ObjectIDFromHex(raw)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid Impersonation ID")
+			}
+			impersonationID = id
+		}
+
+		caller, err := resolver.ResolveCaller(c.Context(), userID, impersonationID)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrUserNotFound):
+				return fiber.ErrUnauthorized
+			case errors.Is(err, services.ErrAccountDisabled),
+				errors.Is(err, services.ErrImpersonationInvalid),
+				errors.Is(err, services.ErrForbidden):
+				return fiber.NewError(fiber.StatusForbidden, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		c.Locals(userIDKey, caller.UserID)
+		c.Locals(roleKey, caller.Role)
+		if !caller.ImpersonatorID.IsZero() {
+			c.Locals(impersonatorKey, caller.ImpersonatorID)
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermission declares the permission a route needs from the caller's role.
+// Requests made with an access token also need one of scopes, the token scopes the
+// route accepts; routes naming none refuse tokens. Users with two-factor
+// authentication need a verified session.
+func RequirePermission(permission string, scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := CurrentRole(c)
+		if !ok {
+			return fiber.ErrUnauthorized
+		}
+		if twoFactorPending(c) {
+			return fiber.NewError(fiber.StatusUnauthorized, services.ErrTwoFactorRequired.Error())
+		}
+		if !models.RoleAllows(role, permission) {
+			return fiber.NewError(fiber.StatusForbidden, "Error: Missing Permission "+permission)
+		}
+		if tokenScopes, isToken := TokenScopes(c); isToken && !models.ScopesInclude(tokenScopes, scopes) {
+			return fiber.NewError(fiber.StatusForbidden, "Error: Access Token Scopes Do Not Allow This Route")
+		}
+		return c.Next()
+	}
+}
+
+// RequireSelfOr lets callers reach routes about themselves, where the route parameter
+// param is their user ID, and otherwise requires permission
+func RequireSelfOr(permission, param string) fiber.Handler {
+	requirePermission := RequirePermission(permission)
+	return func(c *fiber.Ctx) error {
+		if userID, ok := CurrentUserID(c); ok && c.Params(param) == userID.Hex() {
+			return c.Next()
+		}
+		return requirePermission(c)
+	}
+}
+
+// CurrentRole returns the caller's role once AuthorizationMiddleware has resolved it
+func CurrentRole(c *fiber.Ctx) (string, bool) {
+	role, ok := c.Locals(roleKey).(string)
+	return role, ok && role != ""
+}
+
+// Impersonator returns the admin behind an impersonated request
+func Impersonator(c *fiber.Ctx) (primitive.ObjectID, bool) {
+	id, ok := c.Locals(impersonatorKey).(primitive.ObjectID)
+	return id, ok && !id.IsZero()
+}
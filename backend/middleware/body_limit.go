@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"io"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimitMiddleware caps request bodies at limit bytes on every path but the exempt ones.
+// The server runs with request body streaming, so bodies past its own limit reach the
+// handlers unread instead of being refused; this reads them here and refuses the ones that
+// are too long, leaving exempt routes to read their uploads as they see fit.
+func BodyLimitMiddleware(limit int, exempt ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !c.Request().IsBodyStream() || slices.Contains(exempt, c.Path()) {
+			return c.Next()
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request().BodyStream(), int64(limit)+1))
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+		if len(body) > limit {
+			// the rest of the body is still on the connection, so it can't carry another request
+			c.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+		c.Request().SetBody(body)
+		return c.Next()
+	}
+}
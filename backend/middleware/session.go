@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+const sessionKey string = "session"
+
+// SessionAuthenticator verifies the session tokens issued at login
+type SessionAuthenticator interface {
+	AuthenticateSession(ctx context.Context, token string) (*services.Session, error)
+}
+
+// SessionMiddleware authenticates session tokens sent as "Authorization: Bearer ...".
+// Personal access tokens are left to TokenAuthMiddleware. The X-User-ID header that
+// UserContextMiddleware reads in tests is refused, so no caller can name themselves.
+func SessionMiddleware(authenticator SessionAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("X-User-ID") != "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "Error: X-User-ID Is Not Accepted, Sign In Instead")
+		}
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || strings.HasPrefix(token, models.TokenPrefix) {
+			return c.Next()
+		}
+
+		session, err := authenticator.AuthenticateSession(c.Context(), token)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidSession) {
+				return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		c.Locals(userIDKey, session.UserID)
+		c.Locals(sessionKey, session)
+		return c.Next()
+	}
+}
+
+// CurrentSession returns the session that authenticated the request
+func CurrentSession(c *fiber.Ctx) (*services.Session, bool) {
+	session, ok := c.Locals(sessionKey).(*services.Session)
+	return session, ok && session != nil
+}
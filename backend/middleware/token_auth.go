@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+const tokenScopesKey string = "tokenScopes"
+
+// TokenAuthenticator resolves a personal access token to its stored record
+type TokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, secret string) (*models.PersonalAccessToken, error)
+}
+
+// TokenAuthMiddleware authenticates personal access tokens sent as
+// "Authorization: Bearer tm_pat_...". Other bearer credentials are left to
+// SessionMiddleware.
+func TokenAuthMiddleware(authenticator TokenAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		secret, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || !strings.HasPrefix(secret, models.TokenPrefix) {
+			return c.Next()
+		}
+
+		token, err := authenticator.AuthenticateToken(c.Context(), secret)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidToken) {
+				return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		c.Locals(userIDKey, token.UserID)
+		c.Locals(tokenScopesKey, token.Scopes)
+		return c.Next()
+	}
+}
+
+// TokenScopes returns the scopes of the access token that authenticated the request.
+// ok is false for requests not made with a token.
+func TokenScopes(c *fiber.Ctx) ([]string, bool) {
+	scopes, ok := c.Locals(tokenScopesKey).([]string)
+	return scopes, ok
+}
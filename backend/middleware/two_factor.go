@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	twoFactorSessionKey string = "twoFactorSession"
+	twoFactorPendingKey string = "twoFactorPending"
+)
+
+// TwoFactorChecker resolves the two-factor session presented with a request
+type TwoFactorChecker interface {
+	CheckSession(ctx context.Context, userID primitive.ObjectID, loginSessionID, sessionToken string) (*models.TwoFactorSession, error)
+}
+
+// TwoFactorMiddleware checks the X-Two-Factor-Session header of users with two-factor
+// authentication against the sign-in session of the request. Until they verify a code for
+// that sign-in, RequirePermission refuses them every route except the verification one. It must run before AuthorizationMiddleware so an
+// impersonating admin's own session is the one checked. Access tokens are exempt.
+func TwoFactorMiddleware(checker TwoFactorChecker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := CurrentUserID(c)
+		if !ok {
+			return c.Next()
+		}
+		if _, isToken := TokenScopes(c); isToken {
+			return c.Next()
+		}
+
+		session, err := checker.CheckSession(c.Context(), userID, loginSessionID(c), c.Get("X-Two-Factor-Session"))
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrTwoFactorRequired):
+				c.Locals(twoFactorPendingKey, true)
+				return c.Next()
+			case errors.Is(err, services.ErrUserNotFound):
+				return c.Next()
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if session != nil {
+			c.Locals(twoFactorSessionKey, session)
+		}
+		return c.Next()
+	}
+}
+
+// RequireStepUp guards sensitive operations: the caller must have two-factor
+// authentication and have entered a code within services.StepUpWindow
+func RequireStepUp() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session, ok := TwoFactorSession(c)
+		if !ok {
+			return fiber.NewError(fiber.StatusForbidden, "Error: This Operation Requires Two-Factor Authentication")
+		}
+		if !session.SteppedUp(time.Now().UTC(), services.StepUpWindow) {
+			return fiber.NewError(fiber.StatusForbidden, services.ErrStepUpRequired.Error())
+		}
+		return c.Next()
+	}
+}
+
+// TwoFactorSession returns the verified two-factor session of the request
+func TwoFactorSession(c *fiber.Ctx) (*models.TwoFactorSession, bool) {
+	session, ok := c.Locals(twoFactorSessionKey).(*models.TwoFactorSession)
+	return session, ok && session != nil
+}
+
+// loginSessionID is the ID of the sign-in session of the request, if it has one
+func loginSessionID(c *fiber.Ctx) string {
+	if session, ok := CurrentSession(c); ok {
+		return session.ID
+	}
+	return ""
+}
+
+// twoFactorPending reports whether the caller still has to verify a two-factor code
+func twoFactorPending(c *fiber.Ctx) bool {
+	pending, _ := c.Locals(twoFactorPendingKey).(bool)
+	return pending
+}
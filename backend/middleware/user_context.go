@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userIDKey string = "userID"
+
+// UserContextMiddleware attaches the calling user's ID from the X-User-ID header. It
+// trusts the header, so only tests use it to stand in for a signed-in user; the server
+// authenticates with SessionMiddleware, which refuses the header.
+func UserContextMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if id, err := primitive.ObjectIDFromHex(c.Get("X-User-ID")); err == nil {
+			c.Locals(userIDKey, id)
+		}
+		return c.Next()
+	}
+}
+
+// CurrentUserID returns the ID of the user making the request, if known
+func CurrentUserID(c *fiber.Ctx) (primitive.ObjectID, bool) {
+	id, ok := c.Locals(userIDKey).(primitive.ObjectID)
+	return id, ok && !id.IsZero()
+}
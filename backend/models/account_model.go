@@ -1,15 +1,79 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
+// Account types. Liability balances (credit cards, loans) are stored as negative amounts.
+const (
+	AccountTypeChecking   = "checking"
+	AccountTypeSavings    = "savings"
+	AccountTypeCreditCard = "credit_card"
+	AccountTypeLoan       = "loan"
+)
+
+// Interest settings
+const (
+	InterestMethodSimple   = "simple"
+	InterestMethodCompound = "compound"
+
+	CompoundingDaily     = "daily"
+	CompoundingMonthly   = "monthly"
+	CompoundingQuarterly = "quarterly"
+	CompoundingAnnually  = "annually"
+
+	DayCountActual365    = "actual/365"
+	DayCountActual360    = "actual/360"
+	DayCountActualActual = "actual/actual"
+	DayCount30360        = "30/360"
+)
+
+// Account is a bank, card or loan account. OwnerID and HouseholdIDs decide who may use
+// it; both are set by the server, never from a request body.
 type Account struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	AccountLabel     string             `json:"account_label" bson:"account_label"`
-	AccountType      string             `json:"account_type" bson:"account_type"`
-	AccountNumber    string             `json:"account_number" bson:"account_number"`
-	RoutingNumber    string             `json:"routing_number" bson:"routing_number"`
-	CurrentBalance   float64            `json:"current_balance" bson:"current_balance"`
-	AvailableBalance float64            `json:"available_balance" bson:"available_balance"`
-	InterestRate     float64            `json:"interest_rate" bson:"interest_rate"`
-	AcquiredInterest float64            `json:"acquired_interest" bson:"acquired_interest"`
+	ID                   primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	OwnerID              primitive.ObjectID   `json:"owner_id" bson:"owner_id,omitempty"`
+	HouseholdIDs         []primitive.ObjectID `json:"household_ids,omitempty" bson:"household_ids,omitempty"`
+	AccountLabel         string               `json:"account_label" bson:"account_label"`
+	AccountType          string               `json:"account_type" bson:"account_type"`
+	AccountNumber        string               `json:"-" bson:"account_number,omitempty"`
+	RoutingNumber        string               `json:"-" bson:"routing_number,omitempty"`
+	AccountNumberSealed  *EncryptedValue      `json:"-" bson:"account_number_sealed,omitempty"`
+	RoutingNumberSealed  *EncryptedValue      `json:"-" bson:"routing_number_sealed,omitempty"`
+	AccountNumberLast4   string               `json:"account_number_last4,omitempty" bson:"account_number_last4,omitempty"`
+	RoutingNumberLast4   string               `json:"routing_number_last4,omitempty" bson:"routing_number_last4,omitempty"`
+	CurrentBalance       float64              `json:"current_balance" bson:"current_balance"`
+	AvailableBalance     float64              `json:"available_balance" bson:"available_balance"`
+	CreditLimit          float64              `json:"credit_limit,omitempty" bson:"credit_limit,omitempty"`
+	PointsRedeemed       float64              `json:"-" bson:"points_redeemed,omitempty"`
+	InterestRate         float64              `json:"interest_rate" bson:"interest_rate"`
+	AcquiredInterest     float64              `json:"acquired_interest" bson:"acquired_interest"`
+	InterestMethod       string               `json:"interest_method,omitempty" bson:"interest_method,omitempty"`
+	CompoundingFrequency string               `json:"compounding_frequency,omitempty" bson:"compounding_frequency,omitempty"`
+	DayCountConvention   string               `json:"day_count_convention,omitempty" bson:"day_count_convention,omitempty"`
+	AccruedInterest      float64              `json:"accrued_interest" bson:"accrued_interest"`
+	UncompoundedInterest float64              `json:"-" bson:"uncompounded_interest"`
+	LastAccrualDate      time.Time            `json:"last_accrual_date,omitempty" bson:"last_accrual_date,omitempty"`
+	InterestYear         int                  `json:"interest_year,omitempty" bson:"interest_year,omitempty"`
+	ConnectionID         primitive.ObjectID   `json:"connection_id,omitempty" bson:"connection_id,omitempty"`
+	ExternalID           string               `json:"external_id,omitempty" bson:"external_id,omitempty"`
+}
+
+// MaskedAccountNumber shows only the last four digits of the account number.
+// AccountNumber itself is only set on accounts stored before numbers were encrypted.
+func (a *Account) MaskedAccountNumber() string {
+	last4 := a.AccountNumberLast4
+	if last4 == "" {
+		last4 = LastFour(a.AccountNumber)
+	}
+	if last4 == "" {
+		return ""
+	}
+	return "****" + last4
+}
+
+// IsLiability reports whether the account's balance is money owed
+func (a *Account) IsLiability() bool {
+	return a.AccountType == AccountTypeCreditCard || a.AccountType == AccountTypeLoan
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Alert kinds
+const (
+	// AlertLargeTransaction fires for a transaction of at least Threshold
+	AlertLargeTransaction = "large_transaction"
+	// AlertBudgetThreshold fires once a budget's spending reaches Threshold percent of its maximum
+	AlertBudgetThreshold = "budget_threshold"
+	// AlertBalanceFloor fires while an account's available balance is under Threshold
+	AlertBalanceFloor = "balance_floor"
+	// AlertRecurringCharge fires the first time a merchant charges the same amount a month apart
+	AlertRecurringCharge = "new_recurring_charge"
+	// AlertBillDue fires DaysBefore days ahead of a bill's due date
+	AlertBillDue = "bill_due"
+)
+
+// Notification channels
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// AlertRule is a condition the user wants to hear about and the channels to tell them on.
+// AccountID and BudgetID narrow a rule to one account or budget; unset, it covers all of
+// the user's own.
+type AlertRule struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name       string             `json:"name" bson:"name"`
+	Kind       string             `json:"kind" bson:"kind"`
+	Threshold  float64            `json:"threshold" bson:"threshold"`
+	AccountID  primitive.ObjectID `json:"account_id,omitempty" bson:"account_id,omitempty"`
+	BudgetID   primitive.ObjectID `json:"budget_id,omitempty" bson:"budget_id,omitempty"`
+	DaysBefore int                `json:"days_before,omitempty" bson:"days_before,omitempty"`
+	Channels   []string           `json:"channels" bson:"channels"`
+	Enabled    bool               `json:"enabled" bson:"enabled"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Notification is an alert that fired, as shown in the user's in-app inbox
+type Notification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	RuleID    primitive.ObjectID `json:"rule_id" bson:"rule_id"`
+	Kind      string             `json:"kind" bson:"kind"`
+	Title     string             `json:"title" bson:"title"`
+	Body      string             `json:"body" bson:"body"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ReadAt    time.Time          `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditUserRoleChanged        = "user.role_changed"
+	AuditUserDisabled           = "user.disabled"
+	AuditUserEnabled            = "user.enabled"
+	AuditImpersonationStarted   = "impersonation.started"
+	AuditImpersonationEnded     = "impersonation.ended"
+	AuditAccountNumbersRevealed = "account.numbers_revealed"
+)
+
+// AuditEntry records a privileged action: who did it, to whom or what, and when
+type AuditEntry struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ActorID        primitive.ObjectID `json:"actor_id" bson:"actor_id"`
+	Action         string             `json:"action" bson:"action"`
+	TargetUserID   primitive.ObjectID `json:"target_user_id,omitempty" bson:"target_user_id,omitempty"`
+	ResourceID     primitive.ObjectID `json:"resource_id,omitempty" bson:"resource_id,omitempty"`
+	ImpersonatorID primitive.ObjectID `json:"impersonator_id,omitempty" bson:"impersonator_id,omitempty"`
+	Detail         string             `json:"detail,omitempty" bson:"detail,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Impersonation lets an admin act as another user until it ends or expires
+type Impersonation struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AdminID   primitive.ObjectID `json:"admin_id" bson:"admin_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Reason    string             `json:"reason" bson:"reason"`
+	StartedAt time.Time          `json:"started_at" bson:"started_at"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	EndedAt   time.Time          `json:"ended_at,omitempty" bson:"ended_at,omitempty"`
+}
+
+// Active reports whether the impersonation can still be used at now
+func (i *Impersonation) Active(now time.Time) bool {
+	return i.EndedAt.IsZero() && now.Before(i.ExpiresAt)
+}
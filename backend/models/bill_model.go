@@ -0,0 +1,40 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Bill is a payment the user owes on a schedule, paid from AccountID. Estimated bills,
+// such as utilities, vary from period to period around Amount.
+type Bill struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Payee     string             `json:"payee" bson:"payee"`
+	Amount    float64            `json:"amount" bson:"amount"`
+	Estimated bool               `json:"estimated" bson:"estimated"`
+	AccountID primitive.ObjectID `json:"account_id" bson:"account_id"`
+	Category  string             `json:"category,omitempty" bson:"category,omitempty"`
+	Schedule  Recurrence         `json:"schedule" bson:"schedule"`
+	Active    bool               `json:"active" bson:"active"`
+	Payments  []BillPayment      `json:"payments" bson:"payments"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// BillPayment records the transaction that paid the bill due on DueDate
+type BillPayment struct {
+	DueDate       time.Time          `json:"due_date" bson:"due_date"`
+	TransactionID primitive.ObjectID `json:"transaction_id" bson:"transaction_id"`
+	Amount        float64            `json:"amount" bson:"amount"`
+	PaidAt        time.Time          `json:"paid_at" bson:"paid_at"`
+}
+
+// IsPaid reports whether the bill due on due has been paid
+func (b *Bill) IsPaid(due time.Time) bool {
+	for _, payment := range b.Payments {
+		if payment.DueDate.Equal(due) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,8 @@ import (
 
 type Budget struct {
 	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID          primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	TemplateID      primitive.ObjectID `json:"template_id,omitempty" bson:"template_id,omitempty"`
 	MinimumSpending float64            `json:"minimum_spending" bson:"minimum_spending"`
 	MaximumSpending float64            `json:"maximum_spending" bson:"maximum_spending"`
 	TargetGoal      float64            `json:"target_goal" bson:"target_goal"`
@@ -14,3 +16,21 @@ type Budget struct {
 	EndDate         time.Time          `json:"end_date" bson:"end_date"`
 	IsMeetingBudget bool               `json:"is_meeting_budget" bson:"is_meeting_budget"`
 }
+
+// BudgetTemplate generates a Budget for every period of its schedule. Periods run from
+// one occurrence to the next; edits only shape periods that have not been generated yet.
+// Spending in a period counts transactions tagged with that period's budget or, when
+// Categories is set, any transaction in one of those categories on the user's accounts.
+type BudgetTemplate struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name             string             `json:"name" bson:"name"`
+	Categories       []string           `json:"categories,omitempty" bson:"categories,omitempty"`
+	MinimumSpending  float64            `json:"minimum_spending" bson:"minimum_spending"`
+	MaximumSpending  float64            `json:"maximum_spending" bson:"maximum_spending"`
+	TargetGoal       float64            `json:"target_goal" bson:"target_goal"`
+	Schedule         Recurrence         `json:"schedule" bson:"schedule"`
+	Active           bool               `json:"active" bson:"active"`
+	GeneratedThrough time.Time          `json:"generated_through,omitempty" bson:"generated_through,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BankConnection links a user to their accounts at an institution through an
+// aggregation provider. Accounts and transactions synced through it carry the
+// provider's IDs as ExternalID so repeated syncs update rather than duplicate them.
+type BankConnection struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID            primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Provider          string             `json:"provider" bson:"provider"`
+	AccessTokenSealed *EncryptedValue    `json:"-" bson:"access_token_sealed"`
+	Cursor            string             `json:"-" bson:"cursor,omitempty"`
+	LastSyncedAt      time.Time          `json:"last_synced_at,omitempty" bson:"last_synced_at,omitempty"`
+	LastError         string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" bson:"created_at"`
+}
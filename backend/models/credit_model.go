@@ -0,0 +1,32 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Credit bureaus
+const (
+	BureauEquifax    = "equifax"
+	BureauExperian   = "experian"
+	BureauTransUnion = "transunion"
+)
+
+// CreditScoreReading is a dated score from one bureau
+type CreditScoreReading struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Bureau     string             `json:"bureau" bson:"bureau"`
+	Score      int                `json:"score" bson:"score"`
+	Source     string             `json:"source" bson:"source"`
+	ReportedAt time.Time          `json:"reported_at" bson:"reported_at"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CreditSettings holds a user's credit utilization alert thresholds, as fractions of the limit
+type CreditSettings struct {
+	ID                    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID                primitive.ObjectID `json:"user_id" bson:"user_id"`
+	UtilizationThresholds []float64          `json:"utilization_thresholds" bson:"utilization_thresholds"`
+	UpdatedAt             time.Time          `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// EncryptedValue is a field sealed with envelope encryption. The value is encrypted
+// with its own data key, and that data key is stored wrapped by the master key KeyID.
+type EncryptedValue struct {
+	KeyID      string `bson:"key_id"`
+	WrappedKey []byte `bson:"wrapped_key"`
+	Nonce      []byte `bson:"nonce"`
+	Ciphertext []byte `bson:"ciphertext"`
+}
+
+// LastFour returns the last four characters of value, or all of it when shorter
+func LastFour(value string) string {
+	if len(value) <= 4 {
+		return value
+	}
+	return value[len(value)-4:]
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// MonthLayout formats the budgeting month an envelope entry belongs to
+const MonthLayout = "2006-01"
+
+// Envelope entry kinds
+const (
+	EnvelopeEntryAssign = "assign"
+	EnvelopeEntryMove   = "move"
+)
+
+// Envelope holds money assigned for spending in one transaction category
+type Envelope struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name      string             `json:"name" bson:"name"`
+	Category  string             `json:"category" bson:"category"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// EnvelopeEntry changes the amount assigned to an envelope in a month. Assignments
+// draw on income that is ready to assign; a move is a pair of entries sharing MoveID.
+type EnvelopeEntry struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	EnvelopeID primitive.ObjectID `json:"envelope_id" bson:"envelope_id"`
+	Month      string             `json:"month" bson:"month"`
+	Kind       string             `json:"kind" bson:"kind"`
+	Amount     float64            `json:"amount" bson:"amount"`
+	MoveID     primitive.ObjectID `json:"move_id,omitempty" bson:"move_id,omitempty"`
+	Memo       string             `json:"memo,omitempty" bson:"memo,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
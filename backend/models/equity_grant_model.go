@@ -0,0 +1,58 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// EquityGrant is a grant of stock or options that vests over VestingMonths from
+// VestingStart. Nothing vests before the cliff; at the cliff everything accrued so far
+// vests at once, then shares vest every IntervalMonths.
+type EquityGrant struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name           string             `json:"name" bson:"name"`
+	Shares         int                `json:"shares" bson:"shares"`
+	VestingStart   time.Time          `json:"vesting_start" bson:"vesting_start"`
+	VestingMonths  int                `json:"vesting_months" bson:"vesting_months"`
+	CliffMonths    int                `json:"cliff_months" bson:"cliff_months"`
+	IntervalMonths int                `json:"interval_months" bson:"interval_months"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Vest is the number of shares that vest on Date
+type Vest struct {
+	Date   time.Time `json:"date"`
+	Shares int       `json:"shares"`
+}
+
+// Valid reports whether the grant has a vesting schedule
+func (g *EquityGrant) Valid() bool {
+	return g.Shares > 0 && !g.VestingStart.IsZero() && g.VestingMonths > 0 &&
+		g.CliffMonths >= 0 && g.CliffMonths <= g.VestingMonths && g.IntervalMonths > 0
+}
+
+// Vests returns every vest of the grant in order. Shares accrue evenly by month and are
+// rounded down, with the last vest taking the remainder.
+func (g *EquityGrant) Vests() []Vest {
+	if !g.Valid() {
+		return nil
+	}
+
+	var vests []Vest
+	vested := 0
+	for month := max(g.CliffMonths, g.IntervalMonths); ; month += g.IntervalMonths {
+		month = min(month, g.VestingMonths)
+		accrued := g.Shares * month / g.VestingMonths
+		if accrued > vested {
+			vests = append(vests, Vest{
+				Date:   addMonthsClamped(g.VestingStart, month, g.VestingStart.Day()),
+				Shares: accrued - vested,
+			})
+			vested = accrued
+		}
+		if month == g.VestingMonths {
+			return vests
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types published when something happens to a user's money
+const (
+	EventTransactionCreated = "transaction.created"
+	EventBudgetExceeded     = "budget.exceeded"
+	EventBalanceLow         = "balance.low"
+	EventSyncFailed         = "sync.failed"
+	// EventWebhookTest is only ever sent by a webhook's test-fire
+	EventWebhookTest = "webhook.test"
+)
+
+// EventTypes are the events webhooks may subscribe to
+var EventTypes = []string{EventTransactionCreated, EventBudgetExceeded, EventBalanceLow, EventSyncFailed}
+
+// ValidEventType reports whether eventType can be subscribed to
+func ValidEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// Event is one occurrence of an event type for a user. Data is the affected resource,
+// such as the created transaction.
+type Event struct {
+	ID        primitive.ObjectID `json:"id"`
+	Type      string             `json:"type"`
+	UserID    primitive.ObjectID `json:"user_id"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      any                `json:"data"`
+}
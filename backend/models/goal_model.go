@@ -0,0 +1,21 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// SavingsGoal is a target amount to build up across one or more accounts by a date.
+// Contributions are the net flow of the linked accounts' transactions since StartDate,
+// on top of StartingAmount.
+type SavingsGoal struct {
+	ID             primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	Name           string               `json:"name" bson:"name"`
+	AccountIDs     []primitive.ObjectID `json:"account_ids" bson:"account_ids"`
+	TargetAmount   float64              `json:"target_amount" bson:"target_amount"`
+	TargetDate     time.Time            `json:"target_date" bson:"target_date"`
+	StartingAmount float64              `json:"starting_amount" bson:"starting_amount"`
+	StartDate      time.Time            `json:"start_date" bson:"start_date"`
+	CreatedAt      time.Time            `json:"created_at" bson:"created_at"`
+}
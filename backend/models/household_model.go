@@ -0,0 +1,63 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Household member roles. Owners manage membership; editors may change shared
+// accounts and budgets; viewers may only read them.
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleEditor = "editor"
+	HouseholdRoleViewer = "viewer"
+)
+
+// Household groups users who share accounts and budgets. Version counts saves, so a
+// save made from a stale read is refused instead of undoing someone else's change.
+type Household struct {
+	ID        primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name      string               `json:"name" bson:"name"`
+	Members   []HouseholdMember    `json:"members" bson:"members"`
+	Accounts  []primitive.ObjectID `json:"accounts" bson:"accounts"`
+	Budgets   []primitive.ObjectID `json:"budgets" bson:"budgets"`
+	CreatedAt time.Time            `json:"created_at" bson:"created_at"`
+	Version   int64                `json:"version" bson:"version"`
+}
+
+// HouseholdMember is a user's membership in a household
+type HouseholdMember struct {
+	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Role     string             `json:"role" bson:"role"`
+	JoinedAt time.Time          `json:"joined_at" bson:"joined_at"`
+}
+
+// HouseholdInvitation lets the holder of a one-time token join a household. Only a
+// hash of the token is stored; the token itself is sent to Email.
+type HouseholdInvitation struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	HouseholdID primitive.ObjectID `json:"household_id" bson:"household_id"`
+	Email       string             `json:"email" bson:"email"`
+	Role        string             `json:"role" bson:"role"`
+	TokenHash   string             `json:"-" bson:"token_hash"`
+	InvitedBy   primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
+	AcceptedBy  primitive.ObjectID `json:"accepted_by,omitempty" bson:"accepted_by,omitempty"`
+	AcceptedAt  time.Time          `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Member returns userID's membership, if any
+func (h *Household) Member(userID primitive.ObjectID) (*HouseholdMember, bool) {
+	for i := range h.Members {
+		if h.Members[i].UserID == userID {
+			return &h.Members[i], true
+		}
+	}
+	return nil, false
+}
+
+// ValidHouseholdRole reports whether role can be granted to a member
+func ValidHouseholdRole(role string) bool {
+	return role == HouseholdRoleOwner || role == HouseholdRoleEditor || role == HouseholdRoleViewer
+}
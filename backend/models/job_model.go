@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job run outcomes
+const (
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobState is the schedule and lock of one background job, shared by every server
+// instance. An instance holds the lock until LockedUntil; a crashed instance's lock
+// simply expires.
+type JobState struct {
+	Name        string    `json:"name" bson:"_id"`
+	Schedule    string    `json:"schedule" bson:"schedule"`
+	NextRunAt   time.Time `json:"next_run_at" bson:"next_run_at"`
+	Attempt     int       `json:"attempt" bson:"attempt"`
+	LockedBy    string    `json:"locked_by,omitempty" bson:"locked_by,omitempty"`
+	LockedUntil time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	LastRunAt   time.Time `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastStatus  string    `json:"last_status,omitempty" bson:"last_status,omitempty"`
+	LastError   string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+}
+
+// JobRun records one execution of a job. Attempt counts retries after failures, so the
+// first try of a scheduled run is attempt 0.
+type JobRun struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Job        string             `json:"job" bson:"job"`
+	Instance   string             `json:"instance" bson:"instance"`
+	Attempt    int                `json:"attempt" bson:"attempt"`
+	Status     string             `json:"status" bson:"status"`
+	Error      string             `json:"error,omitempty" bson:"error,omitempty"`
+	Result     map[string]any     `json:"result,omitempty" bson:"result,omitempty"`
+	StartedAt  time.Time          `json:"started_at" bson:"started_at"`
+	FinishedAt time.Time          `json:"finished_at" bson:"finished_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NetWorthSnapshot is a user's assets and liabilities at the end of a day. Liabilities
+// are negative, as liability balances are.
+type NetWorthSnapshot struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Date        time.Time          `json:"date" bson:"date"`
+	Assets      float64            `json:"assets" bson:"assets"`
+	Liabilities float64            `json:"liabilities" bson:"liabilities"`
+	NetWorth    float64            `json:"net_worth" bson:"net_worth"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Reconciliation statuses
+const (
+	ReconciliationInProgress = "in_progress"
+	ReconciliationCompleted  = "completed"
+)
+
+// Reconciliation records matching an account's cleared transactions against a bank statement
+type Reconciliation struct {
+	ID                 primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	AccountID          primitive.ObjectID   `json:"account_id" bson:"account_id"`
+	StatementDate      time.Time            `json:"statement_date" bson:"statement_date"`
+	StatementBalance   float64              `json:"statement_balance" bson:"statement_balance"`
+	OpeningBalance     float64              `json:"opening_balance" bson:"opening_balance"`
+	ClearedTotal       float64              `json:"cleared_total" bson:"cleared_total"`
+	Difference         float64              `json:"difference" bson:"difference"`
+	AccountBalance     float64              `json:"account_balance" bson:"account_balance"`
+	BalanceDiscrepancy float64              `json:"balance_discrepancy" bson:"balance_discrepancy"`
+	TransactionIDs     []primitive.ObjectID `json:"transaction_ids" bson:"transaction_ids"`
+	Status             string               `json:"status" bson:"status"`
+	StartedAt          time.Time            `json:"started_at" bson:"started_at"`
+	CompletedAt        time.Time            `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+}
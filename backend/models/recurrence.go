@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// Recurrence frequencies
+const (
+	FrequencyDaily     = "daily"
+	FrequencyWeekly    = "weekly"
+	FrequencyBiweekly  = "biweekly"
+	FrequencyMonthly   = "monthly"
+	FrequencyQuarterly = "quarterly"
+	FrequencyYearly    = "yearly"
+)
+
+// Recurrence is a repeating schedule anchored on StartDate. Interval multiplies the
+// frequency (every 2 months, every 3 weeks) and defaults to 1. Monthly, quarterly and
+// yearly schedules fall on DayOfMonth, or StartDate's day when it is unset, using the
+// last day in shorter months.
+type Recurrence struct {
+	Frequency  string    `json:"frequency" bson:"frequency"`
+	Interval   int       `json:"interval,omitempty" bson:"interval,omitempty"`
+	DayOfMonth int       `json:"day_of_month,omitempty" bson:"day_of_month,omitempty"`
+	StartDate  time.Time `json:"start_date" bson:"start_date"`
+	EndDate    time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"`
+}
+
+// Valid reports whether the schedule can produce occurrences
+func (r Recurrence) Valid() bool {
+	if r.StartDate.IsZero() || r.Interval < 0 || r.DayOfMonth < 0 || r.DayOfMonth > 31 ||
+		(!r.EndDate.IsZero() && r.EndDate.Before(r.StartDate)) {
+		return false
+	}
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyBiweekly, FrequencyMonthly, FrequencyQuarterly, FrequencyYearly:
+		return true
+	}
+	return false
+}
+
+// Occurrences returns every scheduled date in [from, to], in order
+func (r Recurrence) Occurrences(from, to time.Time) []time.Time {
+	if !r.Valid() {
+		return nil
+	}
+	if !r.EndDate.IsZero() && r.EndDate.Before(to) {
+		to = r.EndDate
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date := r.nth(n)
+		if date.After(to) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// Next returns the first occurrence strictly after t, or false once the schedule has ended
+func (r Recurrence) Next(t time.Time) (time.Time, bool) {
+	if !r.Valid() {
+		return time.Time{}, false
+	}
+	for n := 0; ; n++ {
+		date := r.nth(n)
+		if !r.EndDate.IsZero() && date.After(r.EndDate) {
+			return time.Time{}, false
+		}
+		if date.After(t) {
+			return date, true
+		}
+	}
+}
+
+// nth returns the nth occurrence, counting the first on or after StartDate as the zeroth
+func (r Recurrence) nth(n int) time.Time {
+	step := n * max(r.Interval, 1)
+	switch r.Frequency {
+	case FrequencyDaily:
+		return r.StartDate.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		return r.StartDate.AddDate(0, 0, 7*step)
+	case FrequencyBiweekly:
+		return r.StartDate.AddDate(0, 0, 14*step)
+	case FrequencyQuarterly:
+		step *= 3
+	case FrequencyYearly:
+		step *= 12
+	}
+
+	day := r.StartDate.Day()
+	if r.DayOfMonth > 0 {
+		day = r.DayOfMonth
+		if addMonthsClamped(r.StartDate, 0, day).Before(r.StartDate) {
+			step++
+		}
+	}
+	return addMonthsClamped(r.StartDate, step, day)
+}
+
+// addMonthsClamped moves t by months onto day without overflowing into the following
+// month, so Jan 31 + 1 month is Feb 28 rather than Mar 3
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
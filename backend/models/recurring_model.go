@@ -0,0 +1,35 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Recurring item kinds
+const (
+	RecurringIncome      = "income"
+	RecurringBill        = "bill"
+	RecurringTransfer    = "transfer"
+	RecurringDebtPayment = "debt_payment"
+)
+
+// RecurringItem is a known future cash flow. Income lands in AccountID, bills leave it,
+// and transfers and debt payments move money from AccountID to ToAccountID.
+type RecurringItem struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name        string             `json:"name" bson:"name"`
+	Kind        string             `json:"kind" bson:"kind"`
+	AccountID   primitive.ObjectID `json:"account_id" bson:"account_id"`
+	ToAccountID primitive.ObjectID `json:"to_account_id,omitempty" bson:"to_account_id,omitempty"`
+	Amount      float64            `json:"amount" bson:"amount"`
+	Category    string             `json:"category,omitempty" bson:"category,omitempty"`
+	Schedule    Recurrence         `json:"schedule" bson:"schedule"`
+	Active      bool               `json:"active" bson:"active"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// MovesMoney reports whether the item debits one account and credits another
+func (r *RecurringItem) MovesMoney() bool {
+	return r.Kind == RecurringTransfer || r.Kind == RecurringDebtPayment
+}
@@ -0,0 +1,17 @@
+package models
+
+// Report groupings: what each spending report total is keyed by
+const (
+	ReportByCategory = "category"
+	ReportByMerchant = "merchant"
+	ReportByAccount  = "account"
+	ReportByTag      = "tag"
+)
+
+// Report intervals: the time buckets a spending report is split into. Weeks start on Monday.
+const (
+	ReportDaily   = "day"
+	ReportWeekly  = "week"
+	ReportMonthly = "month"
+	ReportYearly  = "year"
+)
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// RewardProgram is a points currency, valued in dollars per point
+type RewardProgram struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name       string             `json:"name" bson:"name"`
+	PointValue float64            `json:"point_value" bson:"point_value"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// RewardRule is a card's earn rate for a category. A rule without a category is the card's base rate.
+type RewardRule struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID  primitive.ObjectID `json:"account_id" bson:"account_id"`
+	ProgramID  primitive.ObjectID `json:"program_id" bson:"program_id"`
+	Category   string             `json:"category" bson:"category"`
+	Multiplier float64            `json:"multiplier" bson:"multiplier"`
+}
+
+// RewardRedemption records points spent from a card's balance
+type RewardRedemption struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID   primitive.ObjectID `json:"account_id" bson:"account_id"`
+	ProgramID   primitive.ObjectID `json:"program_id" bson:"program_id"`
+	Points      float64            `json:"points" bson:"points"`
+	Value       float64            `json:"value" bson:"value"`
+	Description string             `json:"description" bson:"description"`
+	RedeemedAt  time.Time          `json:"redeemed_at" bson:"redeemed_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenPrefix starts every personal access token so they are recognisable in headers and leaks
+const TokenPrefix = "tm_pat_"
+
+// Personal access token scopes
+const (
+	ScopeReadTransactions  = "read:transactions"
+	ScopeWriteTransactions = "write:transactions"
+	ScopeAdmin             = "admin"
+)
+
+// scopes are every scope a token may carry. A scope does not grant role permissions;
+// each route names the scopes that may reach it.
+var scopes = []string{ScopeReadTransactions, ScopeWriteTransactions, ScopeAdmin}
+
+// PersonalAccessToken lets scripts call the API as a user. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name       string             `json:"name" bson:"name"`
+	Hint       string             `json:"hint" bson:"hint"`
+	TokenHash  string             `json:"-" bson:"token_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	ExpiresAt  time.Time          `json:"expires_at" bson:"expires_at"`
+	LastUsedAt time.Time          `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  time.Time          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Usable reports whether the token is neither revoked nor expired at now
+func (t *PersonalAccessToken) Usable(now time.Time) bool {
+	return t.RevokedAt.IsZero() && now.Before(t.ExpiresAt)
+}
+
+func ValidScope(scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// ScopesInclude reports whether any of scopes is one of accepted
+func ScopesInclude(scopes []string, accepted []string) bool {
+	return slices.ContainsFunc(scopes, func(scope string) bool {
+		return slices.Contains(accepted, scope)
+	})
+}
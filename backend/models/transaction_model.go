@@ -5,16 +5,82 @@ import (
 	"time"
 )
 
+// Transaction statuses. Pending authorizations become posted once the bank settles them;
+// void transactions are kept for history but never count toward balances or reports.
+const (
+	TransactionStatusPending = "pending"
+	TransactionStatusPosted  = "posted"
+	TransactionStatusVoid    = "void"
+)
+
+// Transaction types describe the direction money moves relative to the account
+const (
+	TransactionTypeDebit  = "debit"
+	TransactionTypeCredit = "credit"
+)
+
 type Transaction struct {
 		ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 		Name string `json:"name" bson:"name"`
+		AccountID primitive.ObjectID `json:"account_id" bson:"account_id,omitempty"`
 		AccountNumber string `json:"account_number" bson:"account_number"`
 		Category string `json:"category" bson:"category"`
 		Type string `json:"type" bson:"type"`
+		Status string `json:"status" bson:"status,omitempty"`
 		BudgetID primitive.ObjectID `json:"budget_id" bson:"budget_id,omitempty"` 
+		TransferID primitive.ObjectID `json:"transfer_id,omitempty" bson:"transfer_id,omitempty"`
 		Amount float32 `json:"amount" bson:"amount"`
 		PointsRewarded float32 `json:"points_rewarded" bson:"points_rewarded"`
 		TransactionDate time.Time `json:"transaction_date" bson:"transaction_date"`
 		TransactionPosted time.Time `json:"transaction_posted" bson:"transaction_posted"`
 		Description string `json:"description" bson:"description"`
-}
\ No newline at end of file
+		Splits []TransactionSplit `json:"splits,omitempty" bson:"splits,omitempty"`
+		Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+		Cleared bool `json:"cleared" bson:"cleared"`
+		ReconciliationID primitive.ObjectID `json:"reconciliation_id,omitempty" bson:"reconciliation_id,omitempty"`
+		CreatedBy primitive.ObjectID `json:"created_by,omitempty" bson:"created_by,omitempty"`
+		ExternalID string `json:"external_id,omitempty" bson:"external_id,omitempty"`
+}
+
+// TransactionSplit allocates part of a transaction's amount to its own category and budget
+type TransactionSplit struct {
+	Amount   float32            `json:"amount" bson:"amount"`
+	Category string             `json:"category" bson:"category"`
+	BudgetID primitive.ObjectID `json:"budget_id" bson:"budget_id,omitempty"`
+	Memo     string             `json:"memo,omitempty" bson:"memo,omitempty"`
+}
+
+// IsTransfer reports whether the transaction is one leg of a transfer between accounts
+func (t *Transaction) IsTransfer() bool {
+	return !t.TransferID.IsZero()
+}
+
+// EffectiveStatus returns the lifecycle status, treating transactions stored before statuses existed as posted
+func (t *Transaction) EffectiveStatus() string {
+	if t.Status == "" {
+		return TransactionStatusPosted
+	}
+	return t.Status
+}
+
+// IsLocked reports whether the transaction belongs to a completed reconciliation and may no longer be edited
+func (t *Transaction) IsLocked() bool {
+	return !t.ReconciliationID.IsZero()
+}
+
+// Allocations returns the splits of the transaction, or a single allocation
+// covering the whole amount when it has not been split
+func (t *Transaction) Allocations() []TransactionSplit {
+	if len(t.Splits) > 0 {
+		return t.Splits
+	}
+	return []TransactionSplit{{Amount: t.Amount, Category: t.Category, BudgetID: t.BudgetID}}
+}
+
+// SignedAmount returns the amount as it affects the account balance (debits are negative)
+func (t *Transaction) SignedAmount() float64 {
+	if t.Type == TransactionTypeDebit {
+		return -float64(t.Amount)
+	}
+	return float64(t.Amount)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Transfer match statuses
+const (
+	TransferMatchPending  = "pending"
+	TransferMatchAccepted = "accepted"
+	TransferMatchRejected = "rejected"
+)
+
+// TransferMatch is a proposed link between an outflow and an inflow that look like the same transfer
+type TransferMatch struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	OutflowID  primitive.ObjectID `json:"outflow_id" bson:"outflow_id"`
+	InflowID   primitive.ObjectID `json:"inflow_id" bson:"inflow_id"`
+	TransferID primitive.ObjectID `json:"transfer_id,omitempty" bson:"transfer_id,omitempty"`
+	Amount     float32            `json:"amount" bson:"amount"`
+	DaysApart  int                `json:"days_apart" bson:"days_apart"`
+	Confidence float64            `json:"confidence" bson:"confidence"`
+	Status     string             `json:"status" bson:"status"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ResolvedAt time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Transfer groups the two linked transactions created when money moves between accounts
+type Transfer struct {
+	ID            primitive.ObjectID `json:"id"`
+	FromAccountID primitive.ObjectID `json:"from_account_id"`
+	ToAccountID   primitive.ObjectID `json:"to_account_id"`
+	Amount        float32            `json:"amount"`
+	TransferDate  time.Time          `json:"transfer_date"`
+	Debit         *Transaction       `json:"debit"`
+	Credit        *Transaction       `json:"credit"`
+}
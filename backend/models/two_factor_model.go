@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwoFactorSessionPrefix starts every two-factor session token
+const TwoFactorSessionPrefix = "tm_2fa_"
+
+// TwoFactorSession proves a user with two-factor authentication passed a TOTP check at
+// login. It is only accepted alongside the sign-in session LoginSessionID it was verified
+// for. StepUpAt moves forward each time they re-enter a code for a sensitive operation.
+// Only a hash of the session token is stored.
+type TwoFactorSession struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	LoginSessionID string             `json:"-" bson:"login_session_id"`
+	TokenHash      string             `json:"-" bson:"token_hash"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	StepUpAt       time.Time          `json:"step_up_at" bson:"step_up_at"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Usable reports whether the session has not expired at now
+func (s *TwoFactorSession) Usable(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
+
+// SteppedUp reports whether a code was entered within window before now
+func (s *TwoFactorSession) SteppedUp(now time.Time, window time.Duration) bool {
+	return !s.StepUpAt.IsZero() && now.Sub(s.StepUpAt) <= window
+}
@@ -1,9 +1,33 @@
 package models
 
 import (
+	"slices"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// User roles
+const (
+	RoleAdmin    = "admin"
+	RoleUser     = "user"
+	RoleReadOnly = "read_only"
+)
+
+// Permissions declared by routes and granted through roles
+const (
+	PermissionRead        = "read"
+	PermissionWrite       = "write"
+	PermissionManageUsers = "users:manage"
+	PermissionRunJobs     = "jobs:run"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin:    {PermissionRead, PermissionWrite, PermissionManageUsers, PermissionRunJobs},
+	RoleUser:     {PermissionRead, PermissionWrite},
+	RoleReadOnly: {PermissionRead},
+}
+
 type User struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Username    string             `json:"username" bson:"username"`
@@ -12,4 +36,38 @@ type User struct {
 	Accounts    []string           `json:"accounts" bson:"accounts"`
 	CreditScore int                `json:"credit_score" bson:"credit_score"`
 	Budget      []string           `json:"budget" bson:"budget"`
+	Role        string             `json:"role" bson:"role,omitempty"`
+	Disabled    bool               `json:"disabled" bson:"disabled,omitempty"`
+	DisabledAt  time.Time          `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"`
+	// PasswordHash is set at sign-up; users without one cannot sign in
+	PasswordHash string `json:"-" bson:"password_hash,omitempty"`
+
+	// TOTPSecret is set from enrollment on; TOTPEnabled once a first code confirms it.
+	// TOTPLastStep is the newest time step used, so a code cannot be replayed.
+	// TOTPAttempts counts codes tried since the last one accepted; too many lock
+	// two-factor checks until TOTPLockedUntil.
+	TOTPSecret      *EncryptedValue `json:"-" bson:"totp_secret,omitempty"`
+	TOTPEnabled     bool            `json:"totp_enabled" bson:"totp_enabled,omitempty"`
+	TOTPLastStep    int64           `json:"-" bson:"totp_last_step,omitempty"`
+	TOTPAttempts    int             `json:"-" bson:"totp_attempts,omitempty"`
+	TOTPLockedUntil time.Time       `json:"-" bson:"totp_locked_until,omitempty"`
+	RecoveryCodes   []string        `json:"-" bson:"recovery_codes,omitempty"`
+}
+
+// EffectiveRole is the user's role; users created before roles existed are plain users
+func (u *User) EffectiveRole() string {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants permission
+func RoleAllows(role, permission string) bool {
+	return slices.Contains(rolePermissions[role], permission)
 }
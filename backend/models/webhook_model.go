@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookSecretPrefix starts every webhook signing secret
+const WebhookSecretPrefix = "tm_whsec_"
+
+// Webhook delivery statuses. Pending deliveries are retried until they are delivered
+// or run out of attempts and fail.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is a URL that receives a user's events, signed with a secret shown
+// only when the endpoint is created
+type WebhookEndpoint struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	URL          string             `json:"url" bson:"url"`
+	Description  string             `json:"description,omitempty" bson:"description,omitempty"`
+	Events       []string           `json:"events" bson:"events"`
+	SecretSealed *EncryptedValue    `json:"-" bson:"secret_sealed"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// WebhookDelivery is one event queued for one endpoint, and the log of trying to send it
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EndpointID     primitive.ObjectID `json:"endpoint_id" bson:"endpoint_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	EventID        primitive.ObjectID `json:"event_id" bson:"event_id"`
+	EventType      string             `json:"event_type" bson:"event_type"`
+	Payload        string             `json:"payload" bson:"payload"`
+	Status         string             `json:"status" bson:"status"`
+	Attempts       int                `json:"attempts" bson:"attempts"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	LockedUntil    time.Time          `json:"-" bson:"locked_until,omitempty"`
+	ResponseStatus int                `json:"response_status,omitempty" bson:"response_status,omitempty"`
+	LastError      string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	DeliveredAt    time.Time          `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AccountRepository defines the interface for account database operations
+type AccountRepository interface {
+	GetAccountByID(ctx context.Context, id primitive.ObjectID) (*models.Account, error)
+	CreateAccount(ctx context.Context, account *models.Account) error
+	ListOwnedIDs(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error)
+	ListSharedIDs(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	ShareWithHousehold(ctx context.Context, id, householdID primitive.ObjectID) error
+	UnshareFromHousehold(ctx context.Context, id, householdID primitive.ObjectID) error
+	AdjustBalance(ctx context.Context, id primitive.ObjectID, delta float64) error
+	SetAvailableBalance(ctx context.Context, id primitive.ObjectID, available float64) error
+	ListInterestBearing(ctx context.Context) ([]models.Account, error)
+	UpdateInterestSettings(ctx context.Context, id primitive.ObjectID, settings *models.Account) (*models.Account, error)
+	SaveInterestState(ctx context.Context, account *models.Account, previous time.Time) error
+	SetCreditLimit(ctx context.Context, id primitive.ObjectID, limit float64) error
+	SaveAccountNumbers(ctx context.Context, account, previous *models.Account) error
+	ListForEncryption(ctx context.Context, activeKeyID string) ([]models.Account, error)
+	UpsertExternalAccount(ctx context.Context, account *models.Account) (*models.Account, error)
+	SetBalances(ctx context.Context, id primitive.ObjectID, current, available float64) error
+	SpendPoints(ctx context.Context, id primitive.ObjectID, points, earned float64) error
+}
+
+// MongoAccountRepository defines the specific MongoDB operations
+type MongoAccountRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoAccountRepository Factory
+func NewMongoAccountRepository(db *mongo.Database) AccountRepository {
+	return &MongoAccountRepository{
+		collection: db.Collection("accounts"),
+	}
+}
+
+func (r *MongoAccountRepository) GetAccountByID(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+	var account models.Account
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *MongoAccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	if account.ID.IsZero() {
+		account.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, account)
+
+	return err
+}
+
+// ListOwnedIDs returns the IDs of the accounts ownerID owns, oldest first
+func (r *MongoAccountRepository) ListOwnedIDs(ctx context.Context, ownerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.listIDs(ctx, bson.M{"owner_id": ownerID})
+}
+
+// ListSharedIDs returns the IDs of the accounts shared with any of householdIDs, oldest first
+func (r *MongoAccountRepository) ListSharedIDs(ctx context.Context, householdIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(householdIDs) == 0 {
+		return nil, nil
+	}
+	return r.listIDs(ctx, bson.M{"household_ids": bson.M{"$in": householdIDs}})
+}
+
+func (r *MongoAccountRepository) listIDs(ctx context.Context, query bson.M) ([]primitive.ObjectID, error) {
+	var accounts []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	if err := findAll(ctx, r.collection, query, &accounts, opts); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(accounts))
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+	}
+	return ids, nil
+}
+
+func (r *MongoAccountRepository) ShareWithHousehold(ctx context.Context, id, householdID primitive.ObjectID) error {
+	return r.updateHouseholds(ctx, id, bson.M{"$addToSet": bson.M{"household_ids": householdID}})
+}
+
+func (r *MongoAccountRepository) UnshareFromHousehold(ctx context.Context, id, householdID primitive.ObjectID) error {
+	return r.updateHouseholds(ctx, id, bson.M{"$pull": bson.M{"household_ids": householdID}})
+}
+
+func (r *MongoAccountRepository) updateHouseholds(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// AdjustBalance moves both the current and available balance by delta
+func (r *MongoAccountRepository) AdjustBalance(ctx context.Context, id primitive.ObjectID, delta float64) error {
+	update := bson.M{
+		"$inc": bson.M{
+			"current_balance":   delta,
+			"available_balance": delta,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoAccountRepository) SetAvailableBalance(ctx context.Context, id primitive.ObjectID, available float64) error {
+	update := bson.M{"$set": bson.M{"available_balance": available}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoAccountRepository) ListInterestBearing(ctx context.Context) ([]models.Account, error) {
+	var accounts []models.Account
+	cursor, err := r.collection.Find(ctx, bson.M{"interest_rate": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var account models.Account
+		if err := cursor.Decode(&account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, cursor.Err()
+}
+
+func (r *MongoAccountRepository) UpdateInterestSettings(ctx context.Context, id primitive.ObjectID, settings *models.Account) (*models.Account, error) {
+	var account models.Account
+
+	update := bson.M{
+		"$set": bson.M{
+			"interest_rate":         settings.InterestRate,
+			"interest_method":       settings.InterestMethod,
+			"compounding_frequency": settings.CompoundingFrequency,
+			"day_count_convention":  settings.DayCountConvention,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// SaveInterestState stores the accrual bookkeeping; balances are moved separately with AdjustBalance.
+// It only matches while last_accrual_date is still previous, so an overlapping run that accrued
+// the same days first makes this one return mongo.ErrNoDocuments.
+func (r *MongoAccountRepository) SaveInterestState(ctx context.Context, account *models.Account, previous time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"accrued_interest":      account.AccruedInterest,
+			"uncompounded_interest": account.UncompoundedInterest,
+			"acquired_interest":     account.AcquiredInterest,
+			"last_accrual_date":     account.LastAccrualDate,
+			"interest_year":         account.InterestYear,
+		},
+	}
+
+	filter := bson.M{"_id": account.ID, "last_accrual_date": previous}
+	if previous.IsZero() {
+		filter["last_accrual_date"] = nil
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoAccountRepository) SetCreditLimit(ctx context.Context, id primitive.ObjectID, limit float64) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"credit_limit": limit}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SaveAccountNumbers stores the sealed account and routing numbers with their masks,
+// removing any plaintext numbers left from before encryption. With a previous account,
+// it only saves while the stored numbers are still the ones previous was read with,
+// failing with mongo.ErrNoDocuments otherwise.
+func (r *MongoAccountRepository) SaveAccountNumbers(ctx context.Context, account, previous *models.Account) error {
+	update := bson.M{
+		"$set": bson.M{
+			"account_number_sealed": account.AccountNumberSealed,
+			"routing_number_sealed": account.RoutingNumberSealed,
+			"account_number_last4":  account.AccountNumberLast4,
+			"routing_number_last4":  account.RoutingNumberLast4,
+		},
+		"$unset": bson.M{"account_number": "", "routing_number": ""},
+	}
+
+	filter := bson.M{"_id": account.ID}
+	if previous != nil {
+		filter["account_number"] = storedNumber(previous.AccountNumber)
+		filter["routing_number"] = storedNumber(previous.RoutingNumber)
+		filter["account_number_sealed.nonce"] = sealedNonce(previous.AccountNumberSealed)
+		filter["routing_number_sealed.nonce"] = sealedNonce(previous.RoutingNumberSealed)
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// storedNumber matches a plaintext number field holding number, or missing when empty
+func storedNumber(number string) any {
+	if number == "" {
+		return bson.M{"$in": bson.A{nil, ""}}
+	}
+	return number
+}
+
+// sealedNonce matches the nonce of a sealed field holding value, or missing when nil
+func sealedNonce(value *models.EncryptedValue) any {
+	if value == nil {
+		return nil
+	}
+	return value.Nonce
+}
+
+// ListForEncryption finds accounts with plaintext numbers or numbers sealed under a
+// master key other than activeKeyID
+func (r *MongoAccountRepository) ListForEncryption(ctx context.Context, activeKeyID string) ([]models.Account, error) {
+	query := bson.M{"$or": bson.A{
+		bson.M{"account_number": bson.M{"$exists": true, "$ne": ""}},
+		bson.M{"routing_number": bson.M{"$exists": true, "$ne": ""}},
+		bson.M{"account_number_sealed.key_id": bson.M{"$exists": true, "$ne": activeKeyID}},
+		bson.M{"routing_number_sealed.key_id": bson.M{"$exists": true, "$ne": activeKeyID}},
+	}}
+
+	var accounts []models.Account
+	err := findAll(ctx, r.collection, query, &accounts)
+	return accounts, err
+}
+
+// UpsertExternalAccount creates or updates the account a connection knows by
+// ExternalID. Balances and interest settings are left alone.
+func (r *MongoAccountRepository) UpsertExternalAccount(ctx context.Context, account *models.Account) (*models.Account, error) {
+	var stored models.Account
+
+	filter := bson.M{"connection_id": account.ConnectionID, "external_id": account.ExternalID}
+	update := bson.M{
+		"$set": bson.M{
+			"account_label":        account.AccountLabel,
+			"account_type":         account.AccountType,
+			"account_number_last4": account.AccountNumberLast4,
+		},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "owner_id": account.OwnerID},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stored)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+// SetBalances stores balances reported by the account's institution
+func (r *MongoAccountRepository) SetBalances(ctx context.Context, id primitive.ObjectID, current, available float64) error {
+	update := bson.M{"$set": bson.M{"current_balance": current, "available_balance": available}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SpendPoints adds points to the card's redeemed total in one update that only matches while
+// the total stays within earned, so concurrent redemptions cannot spend the same points twice.
+// It returns mongo.ErrNoDocuments when the card does not hold enough points.
+func (r *MongoAccountRepository) SpendPoints(ctx context.Context, id primitive.ObjectID, points, earned float64) error {
+	filter := bson.M{
+		"_id": id,
+		"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$points_redeemed", 0}}, points}},
+			earned + 1e-9,
+		}},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"points_redeemed": points}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AlertRepository defines the interface for alert rule and notification database operations
+type AlertRepository interface {
+	CreateRule(ctx context.Context, rule *models.AlertRule) error
+	GetRuleByID(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error)
+	ListRules(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error)
+	ListEnabledByKind(ctx context.Context, kinds []string) ([]models.AlertRule, error)
+	SaveRule(ctx context.Context, rule *models.AlertRule) error
+	DeleteRule(ctx context.Context, id, userID primitive.ObjectID) error
+	RecordFiring(ctx context.Context, ruleID primitive.ObjectID, key string, at time.Time) (bool, error)
+	ReleaseFiring(ctx context.Context, ruleID primitive.ObjectID, key string) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	ListNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error)
+	MarkRead(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error)
+}
+
+// MongoAlertRepository defines the specific MongoDB operations
+type MongoAlertRepository struct {
+	rules         *mongo.Collection
+	firings       *mongo.Collection
+	notifications *mongo.Collection
+}
+
+// MongoAlertRepository Factory
+func NewMongoAlertRepository(db *mongo.Database) AlertRepository {
+	return &MongoAlertRepository{
+		rules:         db.Collection("alert_rules"),
+		firings:       db.Collection("alert_firings"),
+		notifications: db.Collection("notifications"),
+	}
+}
+
+func (r *MongoAlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	if rule.ID.IsZero() {
+		rule.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.rules.InsertOne(ctx, rule)
+
+	return err
+}
+
+func (r *MongoAlertRepository) GetRuleByID(ctx context.Context, id primitive.ObjectID) (*models.AlertRule, error) {
+	var rule models.AlertRule
+
+	err := r.rules.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *MongoAlertRepository) ListRules(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	err := findAll(ctx, r.rules, bson.M{"user_id": userID}, &rules, opts)
+	return rules, err
+}
+
+// ListEnabledByKind returns every user's enabled rules of the given kinds
+func (r *MongoAlertRepository) ListEnabledByKind(ctx context.Context, kinds []string) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := findAll(ctx, r.rules, bson.M{"enabled": true, "kind": bson.M{"$in": kinds}}, &rules)
+	return rules, err
+}
+
+func (r *MongoAlertRepository) SaveRule(ctx context.Context, rule *models.AlertRule) error {
+	res, err := r.rules.ReplaceOne(ctx, bson.M{"_id": rule.ID, "user_id": rule.UserID}, rule)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoAlertRepository) DeleteRule(ctx context.Context, id, userID primitive.ObjectID) error {
+	res, err := r.rules.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RecordFiring remembers that a rule fired for key, such as a transaction or a budget.
+// It reports false when the rule already fired for key, so each condition alerts once
+// however many instances evaluate it.
+func (r *MongoAlertRepository) RecordFiring(ctx context.Context, ruleID primitive.ObjectID, key string, at time.Time) (bool, error) {
+	_, err := r.firings.InsertOne(ctx, bson.M{
+		"_id":      ruleID.Hex() + ":" + key,
+		"rule_id":  ruleID,
+		"fired_at": at,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReleaseFiring forgets a firing recorded for key, so the rule can fire for it again
+func (r *MongoAlertRepository) ReleaseFiring(ctx context.Context, ruleID primitive.ObjectID, key string) error {
+	_, err := r.firings.DeleteOne(ctx, bson.M{"_id": ruleID.Hex() + ":" + key})
+
+	return err
+}
+
+func (r *MongoAlertRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.notifications.InsertOne(ctx, notification)
+
+	return err
+}
+
+// ListNotifications returns the user's newest notifications first
+func (r *MongoAlertRepository) ListNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := bson.M{"user_id": userID}
+	if unreadOnly {
+		query["read_at"] = bson.M{"$exists": false}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	err := findAll(ctx, r.notifications, query, &notifications, opts)
+	return notifications, err
+}
+
+func (r *MongoAlertRepository) MarkRead(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+	res, err := r.notifications.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID, "read_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"read_at": at}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		// already read is not an error, a missing notification is
+		count, err := r.notifications.CountDocuments(ctx, bson.M{"_id": id, "user_id": userID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return mongo.ErrNoDocuments
+		}
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user read and returns how many there were
+func (r *MongoAlertRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+	res, err := r.notifications.UpdateMany(ctx,
+		bson.M{"user_id": userID, "read_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"read_at": at}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AuditRepository defines the interface for audit log and impersonation database operations
+type AuditRepository interface {
+	CreateEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListEntries(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error)
+	CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) error
+	GetImpersonation(ctx context.Context, id primitive.ObjectID) (*models.Impersonation, error)
+	EndImpersonation(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// MongoAuditRepository defines the specific MongoDB operations
+type MongoAuditRepository struct {
+	entries        *mongo.Collection
+	impersonations *mongo.Collection
+}
+
+// MongoAuditRepository Factory
+func NewMongoAuditRepository(db *mongo.Database) AuditRepository {
+	return &MongoAuditRepository{
+		entries:        db.Collection("audit_log"),
+		impersonations: db.Collection("impersonations"),
+	}
+}
+
+func (r *MongoAuditRepository) CreateEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.entries.InsertOne(ctx, entry)
+
+	return err
+}
+
+// ListEntries returns the newest entries first, optionally only those about targetUserID
+func (r *MongoAuditRepository) ListEntries(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error) {
+	query := bson.M{}
+	if !targetUserID.IsZero() {
+		query["target_user_id"] = targetUserID
+	}
+
+	var entries []models.AuditEntry
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	err := findAll(ctx, r.entries, query, &entries, opts)
+	return entries, err
+}
+
+func (r *MongoAuditRepository) CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) error {
+	if impersonation.ID.IsZero() {
+		impersonation.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.impersonations.InsertOne(ctx, impersonation)
+
+	return err
+}
+
+func (r *MongoAuditRepository) GetImpersonation(ctx context.Context, id primitive.ObjectID) (*models.Impersonation, error) {
+	var impersonation models.Impersonation
+
+	err := r.impersonations.FindOne(ctx, bson.M{"_id": id}).Decode(&impersonation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &impersonation, nil
+}
+
+// EndImpersonation closes an impersonation that has not already ended
+func (r *MongoAuditRepository) EndImpersonation(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "ended_at": bson.M{"$exists": false}}
+	res, err := r.impersonations.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"ended_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
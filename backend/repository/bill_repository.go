@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// BillRepository defines the interface for bill database operations
+type BillRepository interface {
+	CreateBill(ctx context.Context, bill *models.Bill) error
+	GetBillByID(ctx context.Context, id primitive.ObjectID) (*models.Bill, error)
+	ListBills(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error)
+	ListActiveByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error)
+	SaveBill(ctx context.Context, bill *models.Bill) error
+	DeleteBill(ctx context.Context, id, userID primitive.ObjectID) error
+	RecordPayment(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error
+}
+
+// MongoBillRepository defines the specific MongoDB operations
+type MongoBillRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoBillRepository Factory
+func NewMongoBillRepository(db *mongo.Database) BillRepository {
+	return &MongoBillRepository{
+		collection: db.Collection("bills"),
+	}
+}
+
+func (r *MongoBillRepository) CreateBill(ctx context.Context, bill *models.Bill) error {
+	if bill.ID.IsZero() {
+		bill.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, bill)
+
+	return err
+}
+
+func (r *MongoBillRepository) GetBillByID(ctx context.Context, id primitive.ObjectID) (*models.Bill, error) {
+	var bill models.Bill
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&bill)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bill, nil
+}
+
+func (r *MongoBillRepository) ListBills(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error) {
+	var bills []models.Bill
+	opts := options.Find().SetSort(bson.D{{Key: "payee", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &bills, opts)
+	return bills, err
+}
+
+// ListActiveByAccount returns the active bills paid from an account, whoever owns them
+func (r *MongoBillRepository) ListActiveByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Bill, error) {
+	var bills []models.Bill
+	err := findAll(ctx, r.collection, bson.M{"account_id": accountID, "active": true}, &bills)
+	return bills, err
+}
+
+// SaveBill replaces a bill's details, leaving its recorded payments alone
+func (r *MongoBillRepository) SaveBill(ctx context.Context, bill *models.Bill) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": bill.ID, "user_id": bill.UserID}, bson.M{"$set": bson.M{
+		"payee":      bill.Payee,
+		"amount":     bill.Amount,
+		"estimated":  bill.Estimated,
+		"account_id": bill.AccountID,
+		"category":   bill.Category,
+		"schedule":   bill.Schedule,
+		"active":     bill.Active,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoBillRepository) DeleteBill(ctx context.Context, id, userID primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RecordPayment marks the bill paid for payment's due date. It returns
+// mongo.ErrNoDocuments when the bill is gone or that due date was already paid.
+func (r *MongoBillRepository) RecordPayment(ctx context.Context, id primitive.ObjectID, payment models.BillPayment) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "payments.due_date": bson.M{"$ne": payment.DueDate}},
+		bson.M{"$push": bson.M{"payments": payment}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// BudgetRepository defines the interface for budget database operations
+type BudgetRepository interface {
+	GetBudgetByID(ctx context.Context, id primitive.ObjectID) (*models.Budget, error)
+	UpdateBudgetStatus(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error
+	CreateBudget(ctx context.Context, budget *models.Budget) error
+	ListBudgetsByTemplate(ctx context.Context, templateID primitive.ObjectID) ([]models.Budget, error)
+	ListActiveBudgets(ctx context.Context, userID primitive.ObjectID, at time.Time) ([]models.Budget, error)
+	ListBudgets(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error)
+	ListAllBudgets(ctx context.Context, start, end time.Time) ([]models.Budget, error)
+}
+
+// MongoBudgetRepository defines the specific MongoDB operations
+type MongoBudgetRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoBudgetRepository Factory
+func NewMongoBudgetRepository(db *mongo.Database) BudgetRepository {
+	return &MongoBudgetRepository{
+		collection: db.Collection("budgets"),
+	}
+}
+
+func (r *MongoBudgetRepository) GetBudgetByID(ctx context.Context, id primitive.ObjectID) (*models.Budget, error) {
+	var budget models.Budget
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+func (r *MongoBudgetRepository) UpdateBudgetStatus(ctx context.Context, id primitive.ObjectID, isMeetingBudget bool) error {
+	update := bson.M{"$set": bson.M{"is_meeting_budget": isMeetingBudget}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoBudgetRepository) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	if budget.ID.IsZero() {
+		budget.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, budget)
+
+	return err
+}
+
+// ListBudgetsByTemplate returns the periods generated from a template, oldest first
+func (r *MongoBudgetRepository) ListBudgetsByTemplate(ctx context.Context, templateID primitive.ObjectID) ([]models.Budget, error) {
+	var budgets []models.Budget
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"template_id": templateID}, &budgets, opts)
+	return budgets, err
+}
+
+// ListActiveBudgets returns the budgets the user owns whose period includes at
+func (r *MongoBudgetRepository) ListActiveBudgets(ctx context.Context, userID primitive.ObjectID, at time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := bson.M{"user_id": userID, "start_date": bson.M{"$lte": at}, "end_date": bson.M{"$gte": at}}
+	err := findAll(ctx, r.collection, query, &budgets)
+	return budgets, err
+}
+
+// ListBudgets returns the budgets the user owns whose period overlaps start through end,
+// by start date. Zero bounds are open.
+func (r *MongoBudgetRepository) ListBudgets(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := bson.M{"user_id": userID}
+	if !end.IsZero() {
+		query["start_date"] = bson.M{"$lte": end}
+	}
+	if !start.IsZero() {
+		query["end_date"] = bson.M{"$gte": start}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}})
+	err := findAll(ctx, r.collection, query, &budgets, opts)
+	return budgets, err
+}
+
+// ListAllBudgets returns every user's budgets whose period overlaps start through end
+func (r *MongoBudgetRepository) ListAllBudgets(ctx context.Context, start, end time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := bson.M{"start_date": bson.M{"$lte": end}, "end_date": bson.M{"$gte": start}}
+	err := findAll(ctx, r.collection, query, &budgets)
+	return budgets, err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// BudgetTemplateRepository defines the interface for budget template database operations
+type BudgetTemplateRepository interface {
+	GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*models.BudgetTemplate, error)
+	ListTemplates(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error)
+	ListActiveTemplates(ctx context.Context) ([]models.BudgetTemplate, error)
+	CreateTemplate(ctx context.Context, template *models.BudgetTemplate) error
+	SaveTemplate(ctx context.Context, template *models.BudgetTemplate) error
+	AdvanceGeneratedThrough(ctx context.Context, id primitive.ObjectID, from, to time.Time) error
+}
+
+// MongoBudgetTemplateRepository defines the specific MongoDB operations
+type MongoBudgetTemplateRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoBudgetTemplateRepository Factory
+func NewMongoBudgetTemplateRepository(db *mongo.Database) BudgetTemplateRepository {
+	return &MongoBudgetTemplateRepository{
+		collection: db.Collection("budget_templates"),
+	}
+}
+
+func (r *MongoBudgetTemplateRepository) GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*models.BudgetTemplate, error) {
+	var template models.BudgetTemplate
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (r *MongoBudgetTemplateRepository) ListTemplates(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error) {
+	var templates []models.BudgetTemplate
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &templates, opts)
+	return templates, err
+}
+
+func (r *MongoBudgetTemplateRepository) ListActiveTemplates(ctx context.Context) ([]models.BudgetTemplate, error) {
+	var templates []models.BudgetTemplate
+	err := findAll(ctx, r.collection, bson.M{"active": true}, &templates)
+	return templates, err
+}
+
+func (r *MongoBudgetTemplateRepository) CreateTemplate(ctx context.Context, template *models.BudgetTemplate) error {
+	if template.ID.IsZero() {
+		template.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, template)
+
+	return err
+}
+
+// SaveTemplate stores the fields of a template a user may edit. GeneratedThrough is left
+// alone; it only moves through AdvanceGeneratedThrough.
+func (r *MongoBudgetTemplateRepository) SaveTemplate(ctx context.Context, template *models.BudgetTemplate) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":             template.Name,
+			"categories":       template.Categories,
+			"minimum_spending": template.MinimumSpending,
+			"maximum_spending": template.MaximumSpending,
+			"target_goal":      template.TargetGoal,
+			"active":           template.Active,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": template.ID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// AdvanceGeneratedThrough moves a template's GeneratedThrough from one period start to the
+// next. It returns mongo.ErrNoDocuments when the stored value is no longer from, meaning
+// another run got there first.
+func (r *MongoBudgetTemplateRepository) AdvanceGeneratedThrough(ctx context.Context, id primitive.ObjectID, from, to time.Time) error {
+	query := bson.M{"_id": id, "generated_through": from}
+	if from.IsZero() {
+		// templates that haven't generated anything yet have no generated_through stored
+		query["generated_through"] = bson.M{"$in": bson.A{nil, from}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, query, bson.M{"$set": bson.M{"generated_through": to}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ConnectionRepository defines the interface for bank connection database operations
+type ConnectionRepository interface {
+	CreateConnection(ctx context.Context, connection *models.BankConnection) error
+	GetConnectionByID(ctx context.Context, id primitive.ObjectID) (*models.BankConnection, error)
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error)
+	ListAll(ctx context.Context) ([]models.BankConnection, error)
+	SaveSyncState(ctx context.Context, id primitive.ObjectID, cursor string, syncedAt time.Time, syncErr string) error
+	DeleteConnection(ctx context.Context, id, userID primitive.ObjectID) error
+	ListStaleTokens(ctx context.Context, activeKeyID string) ([]models.BankConnection, error)
+	ResealToken(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error
+}
+
+// MongoConnectionRepository defines the specific MongoDB operations
+type MongoConnectionRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoConnectionRepository Factory
+func NewMongoConnectionRepository(db *mongo.Database) ConnectionRepository {
+	return &MongoConnectionRepository{
+		collection: db.Collection("bank_connections"),
+	}
+}
+
+func (r *MongoConnectionRepository) CreateConnection(ctx context.Context, connection *models.BankConnection) error {
+	if connection.ID.IsZero() {
+		connection.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, connection)
+
+	return err
+}
+
+func (r *MongoConnectionRepository) GetConnectionByID(ctx context.Context, id primitive.ObjectID) (*models.BankConnection, error) {
+	var connection models.BankConnection
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&connection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection, nil
+}
+
+func (r *MongoConnectionRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error) {
+	var connections []models.BankConnection
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &connections, opts)
+	return connections, err
+}
+
+func (r *MongoConnectionRepository) ListAll(ctx context.Context) ([]models.BankConnection, error) {
+	var connections []models.BankConnection
+	err := findAll(ctx, r.collection, bson.M{}, &connections)
+	return connections, err
+}
+
+// SaveSyncState records the outcome of a sync. The cursor only moves on success, so
+// a failed sync is retried from where the last good one stopped.
+func (r *MongoConnectionRepository) SaveSyncState(ctx context.Context, id primitive.ObjectID, cursor string, syncedAt time.Time, syncErr string) error {
+	update := bson.M{
+		"$set":   bson.M{"cursor": cursor, "last_synced_at": syncedAt},
+		"$unset": bson.M{"last_error": ""},
+	}
+	if syncErr != "" {
+		update = bson.M{"$set": bson.M{"last_error": syncErr}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteConnection removes one of the user's connections; synced accounts and
+// transactions are kept
+func (r *MongoConnectionRepository) DeleteConnection(ctx context.Context, id, userID primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ListStaleTokens finds connections whose access token is sealed under a master key
+// other than activeKeyID
+func (r *MongoConnectionRepository) ListStaleTokens(ctx context.Context, activeKeyID string) ([]models.BankConnection, error) {
+	var connections []models.BankConnection
+	err := findAll(ctx, r.collection, bson.M{"access_token_sealed.key_id": bson.M{"$exists": true, "$ne": activeKeyID}}, &connections)
+	return connections, err
+}
+
+// ResealToken replaces an access token with the same token sealed again, failing with
+// mongo.ErrNoDocuments when the stored token is no longer previous
+func (r *MongoConnectionRepository) ResealToken(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+	filter := bson.M{"_id": id, "access_token_sealed.nonce": previous.Nonce}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"access_token_sealed": sealed}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CreditRepository defines the interface for credit score and credit settings database operations
+type CreditRepository interface {
+	ListReadings(ctx context.Context, userID primitive.ObjectID) ([]models.CreditScoreReading, error)
+	CreateReading(ctx context.Context, reading *models.CreditScoreReading) error
+	GetSettings(ctx context.Context, userID primitive.ObjectID) (*models.CreditSettings, error)
+	SaveSettings(ctx context.Context, settings *models.CreditSettings) error
+}
+
+// MongoCreditRepository defines the specific MongoDB operations
+type MongoCreditRepository struct {
+	readings *mongo.Collection
+	settings *mongo.Collection
+}
+
+// MongoCreditRepository Factory
+func NewMongoCreditRepository(db *mongo.Database) CreditRepository {
+	return &MongoCreditRepository{
+		readings: db.Collection("credit_scores"),
+		settings: db.Collection("credit_settings"),
+	}
+}
+
+// ListReadings returns the user's readings, oldest first
+func (r *MongoCreditRepository) ListReadings(ctx context.Context, userID primitive.ObjectID) ([]models.CreditScoreReading, error) {
+	var readings []models.CreditScoreReading
+	opts := options.Find().SetSort(bson.D{{Key: "reported_at", Value: 1}})
+	err := findAll(ctx, r.readings, bson.M{"user_id": userID}, &readings, opts)
+	return readings, err
+}
+
+func (r *MongoCreditRepository) CreateReading(ctx context.Context, reading *models.CreditScoreReading) error {
+	if reading.ID.IsZero() {
+		reading.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.readings.InsertOne(ctx, reading)
+
+	return err
+}
+
+func (r *MongoCreditRepository) GetSettings(ctx context.Context, userID primitive.ObjectID) (*models.CreditSettings, error) {
+	var settings models.CreditSettings
+
+	err := r.settings.FindOne(ctx, bson.M{"user_id": userID}).Decode(&settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (r *MongoCreditRepository) SaveSettings(ctx context.Context, settings *models.CreditSettings) error {
+	update := bson.M{
+		"$set": bson.M{
+			"utilization_thresholds": settings.UtilizationThresholds,
+			"updated_at":             settings.UpdatedAt,
+		},
+	}
+
+	opts := options.UpdateOne().SetUpsert(true)
+	_, err := r.settings.UpdateOne(ctx, bson.M{"user_id": settings.UserID}, update, opts)
+
+	return err
+}
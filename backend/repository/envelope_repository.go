@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnvelopeRepository defines the interface for envelope and envelope entry database operations
+type EnvelopeRepository interface {
+	GetEnvelopeByID(ctx context.Context, id primitive.ObjectID) (*models.Envelope, error)
+	ListEnvelopes(ctx context.Context, userID primitive.ObjectID) ([]models.Envelope, error)
+	CreateEnvelope(ctx context.Context, envelope *models.Envelope) error
+	ListEntries(ctx context.Context, userID primitive.ObjectID, throughMonth string) ([]models.EnvelopeEntry, error)
+	CreateEntries(ctx context.Context, entries []models.EnvelopeEntry) error
+}
+
+// MongoEnvelopeRepository defines the specific MongoDB operations
+type MongoEnvelopeRepository struct {
+	envelopes *mongo.Collection
+	entries   *mongo.Collection
+}
+
+// MongoEnvelopeRepository Factory
+func NewMongoEnvelopeRepository(db *mongo.Database) EnvelopeRepository {
+	return &MongoEnvelopeRepository{
+		envelopes: db.Collection("envelopes"),
+		entries:   db.Collection("envelope_entries"),
+	}
+}
+
+func (r *MongoEnvelopeRepository) GetEnvelopeByID(ctx context.Context, id primitive.ObjectID) (*models.Envelope, error) {
+	var envelope models.Envelope
+
+	err := r.envelopes.FindOne(ctx, bson.M{"_id": id}).Decode(&envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelope, nil
+}
+
+func (r *MongoEnvelopeRepository) ListEnvelopes(ctx context.Context, userID primitive.ObjectID) ([]models.Envelope, error) {
+	var envelopes []models.Envelope
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	err := findAll(ctx, r.envelopes, bson.M{"user_id": userID}, &envelopes, opts)
+	return envelopes, err
+}
+
+func (r *MongoEnvelopeRepository) CreateEnvelope(ctx context.Context, envelope *models.Envelope) error {
+	if envelope.ID.IsZero() {
+		envelope.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.envelopes.InsertOne(ctx, envelope)
+
+	return err
+}
+
+// ListEntries returns the user's entries up to and including throughMonth (YYYY-MM), oldest month first
+func (r *MongoEnvelopeRepository) ListEntries(ctx context.Context, userID primitive.ObjectID, throughMonth string) ([]models.EnvelopeEntry, error) {
+	var entries []models.EnvelopeEntry
+	opts := options.Find().SetSort(bson.D{{Key: "month", Value: 1}, {Key: "created_at", Value: 1}})
+	err := findAll(ctx, r.entries, bson.M{"user_id": userID, "month": bson.M{"$lte": throughMonth}}, &entries, opts)
+	return entries, err
+}
+
+func (r *MongoEnvelopeRepository) CreateEntries(ctx context.Context, entries []models.EnvelopeEntry) error {
+	docs := make([]any, len(entries))
+	for i := range entries {
+		if entries[i].ID.IsZero() {
+			entries[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = entries[i]
+	}
+
+	_, err := r.entries.InsertMany(ctx, docs)
+
+	return err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GoalRepository defines the interface for savings goal database operations
+type GoalRepository interface {
+	GetGoalByID(ctx context.Context, id primitive.ObjectID) (*models.SavingsGoal, error)
+	ListGoalsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.SavingsGoal, error)
+	CreateGoal(ctx context.Context, goal *models.SavingsGoal) error
+	SaveGoal(ctx context.Context, goal *models.SavingsGoal) error
+	DeleteGoal(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MongoGoalRepository defines the specific MongoDB operations
+type MongoGoalRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoGoalRepository Factory
+func NewMongoGoalRepository(db *mongo.Database) GoalRepository {
+	return &MongoGoalRepository{
+		collection: db.Collection("savings_goals"),
+	}
+}
+
+func (r *MongoGoalRepository) GetGoalByID(ctx context.Context, id primitive.ObjectID) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&goal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
+
+func (r *MongoGoalRepository) ListGoalsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.SavingsGoal, error) {
+	var goals []models.SavingsGoal
+	opts := options.Find().SetSort(bson.D{{Key: "target_date", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &goals, opts)
+	return goals, err
+}
+
+func (r *MongoGoalRepository) CreateGoal(ctx context.Context, goal *models.SavingsGoal) error {
+	if goal.ID.IsZero() {
+		goal.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, goal)
+
+	return err
+}
+
+func (r *MongoGoalRepository) SaveGoal(ctx context.Context, goal *models.SavingsGoal) error {
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": goal.ID}, goal)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoGoalRepository) DeleteGoal(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GrantRepository defines the interface for equity grant database operations
+type GrantRepository interface {
+	CreateGrant(ctx context.Context, grant *models.EquityGrant) error
+	ListGrants(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error)
+	DeleteGrant(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+// MongoGrantRepository defines the specific MongoDB operations
+type MongoGrantRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoGrantRepository Factory
+func NewMongoGrantRepository(db *mongo.Database) GrantRepository {
+	return &MongoGrantRepository{
+		collection: db.Collection("equity_grants"),
+	}
+}
+
+func (r *MongoGrantRepository) CreateGrant(ctx context.Context, grant *models.EquityGrant) error {
+	if grant.ID.IsZero() {
+		grant.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, grant)
+
+	return err
+}
+
+func (r *MongoGrantRepository) ListGrants(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error) {
+	var grants []models.EquityGrant
+	opts := options.Find().SetSort(bson.D{{Key: "vesting_start", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &grants, opts)
+	return grants, err
+}
+
+func (r *MongoGrantRepository) DeleteGrant(ctx context.Context, id, userID primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// findAll decodes every document matching query into results, which must be a pointer to a slice
+func findAll(ctx context.Context, collection *mongo.Collection, query any, results any, opts ...options.Lister[options.FindOptions]) error {
+	cursor, err := collection.Find(ctx, query, opts...)
+	if err != nil {
+		return err
+	}
+
+	return cursor.All(ctx, results)
+}
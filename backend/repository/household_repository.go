@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// HouseholdRepository defines the interface for household and invitation database operations
+type HouseholdRepository interface {
+	GetHouseholdByID(ctx context.Context, id primitive.ObjectID) (*models.Household, error)
+	ListByMember(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error)
+	CreateHousehold(ctx context.Context, household *models.Household) error
+	SaveHousehold(ctx context.Context, household *models.Household) error
+	CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.HouseholdInvitation, error)
+	ListInvitations(ctx context.Context, householdID primitive.ObjectID) ([]models.HouseholdInvitation, error)
+	AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+// MongoHouseholdRepository defines the specific MongoDB operations
+type MongoHouseholdRepository struct {
+	households  *mongo.Collection
+	invitations *mongo.Collection
+}
+
+// MongoHouseholdRepository Factory
+func NewMongoHouseholdRepository(db *mongo.Database) HouseholdRepository {
+	return &MongoHouseholdRepository{
+		households:  db.Collection("households"),
+		invitations: db.Collection("household_invitations"),
+	}
+}
+
+func (r *MongoHouseholdRepository) GetHouseholdByID(ctx context.Context, id primitive.ObjectID) (*models.Household, error) {
+	var household models.Household
+
+	err := r.households.FindOne(ctx, bson.M{"_id": id}).Decode(&household)
+	if err != nil {
+		return nil, err
+	}
+
+	return &household, nil
+}
+
+func (r *MongoHouseholdRepository) ListByMember(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error) {
+	var households []models.Household
+	err := findAll(ctx, r.households, bson.M{"members.user_id": userID}, &households)
+	return households, err
+}
+
+func (r *MongoHouseholdRepository) CreateHousehold(ctx context.Context, household *models.Household) error {
+	if household.ID.IsZero() {
+		household.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.households.InsertOne(ctx, household)
+
+	return err
+}
+
+// SaveHousehold replaces the household if it is still at the version it was read at
+// and bumps the version. A household saved by someone else in between is not matched.
+func (r *MongoHouseholdRepository) SaveHousehold(ctx context.Context, household *models.Household) error {
+	filter := bson.M{"_id": household.ID, "version": household.Version}
+	if household.Version == 0 {
+		// households from before versioning have no version field
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	saved := *household
+	saved.Version++
+	res, err := r.households.ReplaceOne(ctx, filter, &saved)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	household.Version = saved.Version
+	return nil
+}
+
+func (r *MongoHouseholdRepository) CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error {
+	if invitation.ID.IsZero() {
+		invitation.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.invitations.InsertOne(ctx, invitation)
+
+	return err
+}
+
+func (r *MongoHouseholdRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.HouseholdInvitation, error) {
+	var invitation models.HouseholdInvitation
+
+	err := r.invitations.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *MongoHouseholdRepository) ListInvitations(ctx context.Context, householdID primitive.ObjectID) ([]models.HouseholdInvitation, error) {
+	var invitations []models.HouseholdInvitation
+	err := findAll(ctx, r.invitations, bson.M{"household_id": householdID}, &invitations)
+	return invitations, err
+}
+
+// AcceptInvitation marks an invitation used. It only matches invitations that are still
+// open, so a token cannot be redeemed twice.
+func (r *MongoHouseholdRepository) AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "accepted_by": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"accepted_by": userID, "accepted_at": time.Now().UTC()}}
+
+	res, err := r.invitations.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
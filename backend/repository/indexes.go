@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnsureIndexes creates the unique indexes the repositories rely on to turn concurrent
+// writers of the same record into a duplicate key error. Creating an index that already
+// exists does nothing, so it runs on every startup, after Migrate.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := []struct {
+		collection string
+		model      mongo.IndexModel
+	}{
+		// one budget per template period; hand-made budgets have no template_id
+		{"budgets", mongo.IndexModel{
+			Keys:    bson.D{{Key: "template_id", Value: 1}, {Key: "start_date", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"template_id": bson.M{"$exists": true}}),
+		}},
+		// synced records are keyed by the id their institution gives them
+		{"accounts", mongo.IndexModel{
+			Keys:    bson.D{{Key: "connection_id", Value: 1}, {Key: "external_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		}},
+		{"transactions", mongo.IndexModel{
+			Keys:    bson.D{{Key: "account_id", Value: 1}, {Key: "external_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		}},
+		// one reconciliation in progress per account
+		{"reconciliations", mongo.IndexModel{
+			Keys:    bson.D{{Key: "account_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": models.ReconciliationInProgress}),
+		}},
+	}
+	for _, index := range indexes {
+		if _, err := db.Collection(index.collection).Indexes().CreateOne(ctx, index.model); err != nil {
+			return fmt.Errorf("%s index: %w", index.collection, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// JobRunFilter narrows a job run listing; empty fields match everything
+type JobRunFilter struct {
+	Job    string
+	Status string
+	Limit  int64
+}
+
+// JobRepository defines the interface for background job state and run history
+type JobRepository interface {
+	EnsureJob(ctx context.Context, name, schedule string, nextRunAt time.Time) error
+	AcquireLock(ctx context.Context, name, instance string, now, until time.Time) (*models.JobState, error)
+	FinishRun(ctx context.Context, name, instance string, state *models.JobState) error
+	ListJobs(ctx context.Context) ([]models.JobState, error)
+	CreateRun(ctx context.Context, run *models.JobRun) error
+	ListRuns(ctx context.Context, filter JobRunFilter) ([]models.JobRun, error)
+}
+
+// MongoJobRepository defines the specific MongoDB operations
+type MongoJobRepository struct {
+	jobs *mongo.Collection
+	runs *mongo.Collection
+}
+
+// MongoJobRepository Factory
+func NewMongoJobRepository(db *mongo.Database) JobRepository {
+	return &MongoJobRepository{
+		jobs: db.Collection("jobs"),
+		runs: db.Collection("job_runs"),
+	}
+}
+
+// EnsureJob creates a job's state the first time an instance registers it, and
+// reschedules it when its schedule has changed
+func (r *MongoJobRepository) EnsureJob(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+	filter := bson.M{"_id": name, "schedule": bson.M{"$ne": schedule}}
+	update := bson.M{"$set": bson.M{"schedule": schedule, "next_run_at": nextRunAt, "attempt": 0}}
+
+	_, err := r.jobs.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the job exists with this schedule already
+		return nil
+	}
+
+	return err
+}
+
+// AcquireLock takes the job's lock until the given time if the job is due and no other
+// instance holds an unexpired lock. It returns mongo.ErrNoDocuments otherwise.
+func (r *MongoJobRepository) AcquireLock(ctx context.Context, name, instance string, now, until time.Time) (*models.JobState, error) {
+	var state models.JobState
+
+	filter := bson.M{
+		"_id":         name,
+		"next_run_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_by": instance, "locked_until": until}}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.jobs.FindOneAndUpdate(ctx, filter, update, opts).Decode(&state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// FinishRun records a run's outcome and next run time and releases the lock, provided
+// instance still holds it
+func (r *MongoJobRepository) FinishRun(ctx context.Context, name, instance string, state *models.JobState) error {
+	update := bson.M{
+		"$set": bson.M{
+			"next_run_at": state.NextRunAt,
+			"attempt":     state.Attempt,
+			"last_run_at": state.LastRunAt,
+			"last_status": state.LastStatus,
+			"last_error":  state.LastError,
+		},
+		"$unset": bson.M{"locked_by": "", "locked_until": ""},
+	}
+
+	res, err := r.jobs.UpdateOne(ctx, bson.M{"_id": name, "locked_by": instance}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoJobRepository) ListJobs(ctx context.Context) ([]models.JobState, error) {
+	var jobs []models.JobState
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	err := findAll(ctx, r.jobs, bson.M{}, &jobs, opts)
+	return jobs, err
+}
+
+func (r *MongoJobRepository) CreateRun(ctx context.Context, run *models.JobRun) error {
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.runs.InsertOne(ctx, run)
+
+	return err
+}
+
+// ListRuns returns the newest runs first
+func (r *MongoJobRepository) ListRuns(ctx context.Context, filter JobRunFilter) ([]models.JobRun, error) {
+	query := bson.M{}
+	if filter.Job != "" {
+		query["job"] = filter.Job
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	var runs []models.JobRun
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(filter.Limit)
+	err := findAll(ctx, r.runs, query, &runs, opts)
+	return runs, err
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Migrate brings documents written by earlier versions up to date. Each step only
+// touches documents it hasn't fixed yet, so Migrate runs on every startup.
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	steps := []struct {
+		name string
+		run  func(ctx context.Context, db *mongo.Database) error
+	}{
+		{"account owners", backfillAccountOwners},
+		{"budget owners", backfillBudgetOwners},
+		{"duplicate template periods", mergeDuplicatePeriods},
+		{"redeemed points", backfillPointsRedeemed},
+	}
+	for _, step := range steps {
+		if err := step.run(ctx, db); err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// backfillAccountOwners gives each account stored before accounts had owners the one user
+// whose profile lists it, and records the households it is shared with. Accounts listed on
+// several profiles keep no owner, and so stay out of everyone's reach until an admin settles them.
+func backfillAccountOwners(ctx context.Context, db *mongo.Database) error {
+	claims, err := profileClaims(ctx, db, "accounts")
+	if err != nil {
+		return err
+	}
+
+	accounts := db.Collection("accounts")
+	if err := setOwners(ctx, accounts, "account", "owner_id", claims); err != nil {
+		return err
+	}
+
+	var households []models.Household
+	if err := findAll(ctx, db.Collection("households"), bson.M{"accounts.0": bson.M{"$exists": true}}, &households); err != nil {
+		return err
+	}
+	for _, household := range households {
+		filter := bson.M{"_id": bson.M{"$in": household.Accounts}}
+		if _, err := accounts.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"household_ids": household.ID}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillBudgetOwners gives each budget stored before budgets had owners the one user whose
+// profile lists it. Budgets nobody or several users list keep no owner and are denied to everyone.
+func backfillBudgetOwners(ctx context.Context, db *mongo.Database) error {
+	claims, err := profileClaims(ctx, db, "budget")
+	if err != nil {
+		return err
+	}
+	return setOwners(ctx, db.Collection("budgets"), "budget", "user_id", claims)
+}
+
+// profileClaims maps each ID listed in the users' accounts or budget field to the users
+// listing it
+func profileClaims(ctx context.Context, db *mongo.Database, field string) (map[primitive.ObjectID][]primitive.ObjectID, error) {
+	var users []struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Accounts []string           `bson:"accounts"`
+		Budget   []string           `bson:"budget"`
+	}
+	opts := options.Find().SetProjection(bson.M{field: 1})
+	if err := findAll(ctx, db.Collection("users"), bson.M{field + ".0": bson.M{"$exists": true}}, &users, opts); err != nil {
+		return nil, err
+	}
+
+	claims := map[primitive.ObjectID][]primitive.ObjectID{}
+	for _, user := range users {
+		for _, hex := range slices.Concat(user.Accounts, user.Budget) {
+			id, err := primitive.ObjectIDFromHex(hex)
+			if err == nil && !slices.Contains(claims[id], user.ID) {
+				claims[id] = append(claims[id], user.ID)
+			}
+		}
+	}
+	return claims, nil
+}
+
+// setOwners stores the claiming user in ownerField of each document that has no owner yet,
+// as long as exactly one user claims it
+func setOwners(ctx context.Context, coll *mongo.Collection, kind, ownerField string, claims map[primitive.ObjectID][]primitive.ObjectID) error {
+	for id, owners := range claims {
+		filter := bson.M{"_id": id, ownerField: bson.M{"$exists": false}}
+		if len(owners) > 1 {
+			if n, err := coll.CountDocuments(ctx, filter); err == nil && n > 0 {
+				log.Printf("%s %s is listed by %d users and was left without an owner", kind, id.Hex(), len(owners))
+			}
+			continue
+		}
+		if _, err := coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{ownerField: owners[0]}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDuplicatePeriods keeps the oldest of any budgets generated twice for the same template
+// period, so the unique index EnsureIndexes creates can be built. Transactions, splits,
+// alert rules and household shares pointing at a duplicate move to the budget that is kept.
+func mergeDuplicatePeriods(ctx context.Context, db *mongo.Database) error {
+	budgets := db.Collection("budgets")
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"template_id": bson.M{"$exists": true}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"template_id": "$template_id", "start_date": "$start_date"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+	cursor, err := budgets.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	transactions, rules, households := db.Collection("transactions"), db.Collection("alert_rules"), db.Collection("households")
+	for _, group := range groups {
+		keep, duplicates := group.IDs[0], group.IDs[1:]
+		moved := bson.M{"$in": duplicates}
+		if _, err := transactions.UpdateMany(ctx, bson.M{"budget_id": moved}, bson.M{"$set": bson.M{"budget_id": keep}}); err != nil {
+			return err
+		}
+		splitOpts := options.UpdateMany().SetArrayFilters([]any{bson.M{"split.budget_id": moved}})
+		if _, err := transactions.UpdateMany(ctx, bson.M{"splits.budget_id": moved}, bson.M{"$set": bson.M{"splits.$[split].budget_id": keep}}, splitOpts); err != nil {
+			return err
+		}
+		if _, err := rules.UpdateMany(ctx, bson.M{"budget_id": moved}, bson.M{"$set": bson.M{"budget_id": keep}}); err != nil {
+			return err
+		}
+		sharedOpts := options.UpdateMany().SetArrayFilters([]any{bson.M{"budget": moved}})
+		if _, err := households.UpdateMany(ctx, bson.M{"budgets": moved}, bson.M{"$set": bson.M{"budgets.$[budget]": keep}}, sharedOpts); err != nil {
+			return err
+		}
+		if _, err := budgets.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicates}}); err != nil {
+			return err
+		}
+		log.Printf("merged %d duplicate periods into budget %s", len(duplicates), keep.Hex())
+	}
+	return nil
+}
+
+// backfillPointsRedeemed totals the redemptions of each card that has no points_redeemed yet,
+// the running total SpendPoints guards redemptions with
+func backfillPointsRedeemed(ctx context.Context, db *mongo.Database) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$account_id", "points": bson.M{"$sum": "$points"}}}},
+	}
+	cursor, err := db.Collection("reward_redemptions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var totals []struct {
+		AccountID primitive.ObjectID `bson:"_id"`
+		Points    float64            `bson:"points"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return err
+	}
+
+	accounts := db.Collection("accounts")
+	for _, total := range totals {
+		filter := bson.M{"_id": total.AccountID, "points_redeemed": bson.M{"$exists": false}}
+		if _, err := accounts.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"points_redeemed": total.Points}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// NetWorthRepository defines the interface for net worth snapshot database operations
+type NetWorthRepository interface {
+	SaveSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error
+	ListSnapshots(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.NetWorthSnapshot, error)
+}
+
+// MongoNetWorthRepository defines the specific MongoDB operations
+type MongoNetWorthRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoNetWorthRepository Factory
+func NewMongoNetWorthRepository(db *mongo.Database) NetWorthRepository {
+	return &MongoNetWorthRepository{
+		collection: db.Collection("net_worth_snapshots"),
+	}
+}
+
+// SaveSnapshot stores the user's snapshot for its date, replacing one taken earlier
+// that day
+func (r *MongoNetWorthRepository) SaveSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error {
+	filter := bson.M{"user_id": snapshot.UserID, "date": snapshot.Date}
+	update := bson.M{
+		"$set": bson.M{
+			"assets":      snapshot.Assets,
+			"liabilities": snapshot.Liabilities,
+			"net_worth":   snapshot.NetWorth,
+			"created_at":  snapshot.CreatedAt,
+		},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+
+	return err
+}
+
+// ListSnapshots returns the user's snapshots dated start through end, oldest first.
+// Zero bounds are open.
+func (r *MongoNetWorthRepository) ListSnapshots(ctx context.Context, userID primitive.ObjectID, start, end time.Time) ([]models.NetWorthSnapshot, error) {
+	var snapshots []models.NetWorthSnapshot
+	query := bson.M{"user_id": userID}
+	date := bson.M{}
+	if !start.IsZero() {
+		date["$gte"] = start
+	}
+	if !end.IsZero() {
+		date["$lte"] = end
+	}
+	if len(date) > 0 {
+		query["date"] = date
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+	err := findAll(ctx, r.collection, query, &snapshots, opts)
+	return snapshots, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ReconciliationRepository defines the interface for reconciliation database operations
+type ReconciliationRepository interface {
+	GetReconciliationByID(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error)
+	GetInProgress(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error)
+	GetLastCompleted(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error)
+	ListByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Reconciliation, error)
+	CreateReconciliation(ctx context.Context, rec *models.Reconciliation) error
+	SaveReconciliation(ctx context.Context, rec *models.Reconciliation) error
+}
+
+// MongoReconciliationRepository defines the specific MongoDB operations
+type MongoReconciliationRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoReconciliationRepository Factory
+func NewMongoReconciliationRepository(db *mongo.Database) ReconciliationRepository {
+	return &MongoReconciliationRepository{
+		collection: db.Collection("reconciliations"),
+	}
+}
+
+func (r *MongoReconciliationRepository) GetReconciliationByID(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoReconciliationRepository) GetInProgress(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+	return r.findOne(ctx, bson.M{"account_id": accountID, "status": models.ReconciliationInProgress})
+}
+
+func (r *MongoReconciliationRepository) GetLastCompleted(ctx context.Context, accountID primitive.ObjectID) (*models.Reconciliation, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "statement_date", Value: -1}})
+	return r.findOne(ctx, bson.M{"account_id": accountID, "status": models.ReconciliationCompleted}, opts)
+}
+
+func (r *MongoReconciliationRepository) ListByAccount(ctx context.Context, accountID primitive.ObjectID) ([]models.Reconciliation, error) {
+	var recs []models.Reconciliation
+
+	opts := options.Find().SetSort(bson.D{{Key: "statement_date", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"account_id": accountID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var rec models.Reconciliation
+		if err := cursor.Decode(&rec); err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+
+	return recs, cursor.Err()
+}
+
+func (r *MongoReconciliationRepository) CreateReconciliation(ctx context.Context, rec *models.Reconciliation) error {
+	if rec.ID.IsZero() {
+		rec.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, rec)
+
+	return err
+}
+
+func (r *MongoReconciliationRepository) SaveReconciliation(ctx context.Context, rec *models.Reconciliation) error {
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": rec.ID}, rec)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoReconciliationRepository) findOne(ctx context.Context, query bson.M, opts ...options.Lister[options.FindOneOptions]) (*models.Reconciliation, error) {
+	var rec models.Reconciliation
+
+	err := r.collection.FindOne(ctx, query, opts...).Decode(&rec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RecurringRepository defines the interface for recurring item database operations
+type RecurringRepository interface {
+	GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringItem, error)
+	ListItemsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error)
+	CreateItem(ctx context.Context, item *models.RecurringItem) error
+	SaveItem(ctx context.Context, item *models.RecurringItem) error
+	DeleteItem(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MongoRecurringRepository defines the specific MongoDB operations
+type MongoRecurringRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoRecurringRepository Factory
+func NewMongoRecurringRepository(db *mongo.Database) RecurringRepository {
+	return &MongoRecurringRepository{
+		collection: db.Collection("recurring_items"),
+	}
+}
+
+func (r *MongoRecurringRepository) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringItem, error) {
+	var item models.RecurringItem
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *MongoRecurringRepository) ListItemsByUser(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error) {
+	var items []models.RecurringItem
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &items, opts)
+	return items, err
+}
+
+func (r *MongoRecurringRepository) CreateItem(ctx context.Context, item *models.RecurringItem) error {
+	if item.ID.IsZero() {
+		item.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, item)
+
+	return err
+}
+
+func (r *MongoRecurringRepository) SaveItem(ctx context.Context, item *models.RecurringItem) error {
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": item.ID}, item)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoRecurringRepository) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
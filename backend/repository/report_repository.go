@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ReportQuery selects the transactions a spending report totals and how they are grouped.
+// Transfers and void transactions never count.
+type ReportQuery struct {
+	AccountIDs []primitive.ObjectID
+	Start      time.Time
+	End        time.Time
+	GroupBy    string
+	Interval   string
+}
+
+// ReportRow is the money moved for one group key within one time bucket
+type ReportRow struct {
+	Bucket   time.Time `bson:"bucket"`
+	Key      string    `bson:"key"`
+	Label    string    `bson:"label"`
+	Income   float64   `bson:"income"`
+	Spending float64   `bson:"spending"`
+	Count    int       `bson:"count"`
+}
+
+// ReportRepository defines the interface for aggregated transaction reports
+type ReportRepository interface {
+	SpendingTotals(ctx context.Context, query ReportQuery) ([]ReportRow, error)
+}
+
+// MongoReportRepository defines the specific MongoDB operations
+type MongoReportRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoReportRepository Factory
+func NewMongoReportRepository(db *mongo.Database) ReportRepository {
+	return &MongoReportRepository{
+		collection: db.Collection("transactions"),
+	}
+}
+
+// SpendingTotals sums income and spending per group key and time bucket, ordered by bucket
+// and key. Category reports count each split allocation under its own category; tag
+// reports count a transaction once per tag, and untagged transactions under "". Buckets
+// are truncated in UTC with $dateTrunc, which needs MongoDB 5.0 or later.
+func (r *MongoReportRepository) SpendingTotals(ctx context.Context, query ReportQuery) ([]ReportRow, error) {
+	var rows []ReportRow
+
+	filter := TransactionFilter{
+		AccountIDs:       query.AccountIDs,
+		Start:            query.Start,
+		End:              query.End,
+		ExcludeTransfers: true,
+	}
+	pipeline := bson.A{bson.M{"$match": filter.toBSON()}}
+
+	amount, key, label := any("$amount"), any(""), any(nil)
+	switch query.GroupBy {
+	case models.ReportByCategory:
+		pipeline = append(pipeline,
+			bson.M{"$set": bson.M{"allocation": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$splits", bson.A{}}}}, 0}},
+				"$splits",
+				bson.A{bson.M{"amount": "$amount", "category": "$category"}},
+			}}}},
+			bson.M{"$unwind": "$allocation"},
+		)
+		amount, key = "$allocation.amount", bson.M{"$ifNull": bson.A{"$allocation.category", ""}}
+	case models.ReportByMerchant:
+		// the services' merchant key: the name's letters, lowercased. $toLower only folds ASCII,
+		// so names differing just in the case of accented letters still group apart.
+		key = bson.M{"$reduce": bson.M{
+			"input":        bson.M{"$regexFindAll": bson.M{"input": bson.M{"$toLower": "$name"}, "regex": `\p{L}+`}},
+			"initialValue": "",
+			"in":           bson.M{"$concat": bson.A{"$$value", "$$this.match"}},
+		}}
+		// label each merchant with its most recent name
+		pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: "transaction_date", Value: -1}, {Key: "_id", Value: -1}}})
+		label = "$name"
+	case models.ReportByAccount:
+		key = bson.M{"$toString": "$account_id"}
+	case models.ReportByTag:
+		pipeline = append(pipeline, bson.M{"$unwind": bson.M{"path": "$tags", "preserveNullAndEmptyArrays": true}})
+		key = bson.M{"$ifNull": bson.A{"$tags", ""}}
+	}
+
+	truncate := bson.M{"date": "$transaction_date", "unit": query.Interval, "timezone": "UTC"}
+	if query.Interval == models.ReportWeekly {
+		truncate["startOfWeek"] = "monday"
+	}
+	debit := bson.M{"$eq": bson.A{"$type", models.TransactionTypeDebit}}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":      bson.M{"bucket": bson.M{"$dateTrunc": truncate}, "key": key},
+			"label":    bson.M{"$first": label},
+			"income":   bson.M{"$sum": bson.M{"$cond": bson.A{debit, 0, amount}}},
+			"spending": bson.M{"$sum": bson.M{"$cond": bson.A{debit, amount, 0}}},
+			"count":    bson.M{"$sum": 1},
+		}},
+		bson.M{"$project": bson.M{
+			"_id":      0,
+			"bucket":   "$_id.bucket",
+			"key":      "$_id.key",
+			"label":    bson.M{"$ifNull": bson.A{"$label", "$_id.key"}},
+			"income":   1,
+			"spending": 1,
+			"count":    1,
+		}},
+		bson.M{"$sort": bson.D{{Key: "bucket", Value: 1}, {Key: "key", Value: 1}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// RewardRepository defines the interface for reward programs, earn rules and redemptions
+type RewardRepository interface {
+	GetProgramByID(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error)
+	ListPrograms(ctx context.Context) ([]models.RewardProgram, error)
+	CreateProgram(ctx context.Context, program *models.RewardProgram) error
+	ListRules(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error)
+	CreateRule(ctx context.Context, rule *models.RewardRule) error
+	ListRedemptions(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRedemption, error)
+	CreateRedemption(ctx context.Context, redemption *models.RewardRedemption) error
+}
+
+// MongoRewardRepository defines the specific MongoDB operations
+type MongoRewardRepository struct {
+	programs    *mongo.Collection
+	rules       *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+// MongoRewardRepository Factory
+func NewMongoRewardRepository(db *mongo.Database) RewardRepository {
+	return &MongoRewardRepository{
+		programs:    db.Collection("reward_programs"),
+		rules:       db.Collection("reward_rules"),
+		redemptions: db.Collection("reward_redemptions"),
+	}
+}
+
+func (r *MongoRewardRepository) GetProgramByID(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error) {
+	var program models.RewardProgram
+
+	err := r.programs.FindOne(ctx, bson.M{"_id": id}).Decode(&program)
+	if err != nil {
+		return nil, err
+	}
+
+	return &program, nil
+}
+
+func (r *MongoRewardRepository) ListPrograms(ctx context.Context) ([]models.RewardProgram, error) {
+	var programs []models.RewardProgram
+	err := findAll(ctx, r.programs, bson.M{}, &programs)
+	return programs, err
+}
+
+func (r *MongoRewardRepository) CreateProgram(ctx context.Context, program *models.RewardProgram) error {
+	if program.ID.IsZero() {
+		program.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.programs.InsertOne(ctx, program)
+
+	return err
+}
+
+func (r *MongoRewardRepository) ListRules(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRule, error) {
+	var rules []models.RewardRule
+	err := findAll(ctx, r.rules, bson.M{"account_id": bson.M{"$in": accountIDs}}, &rules)
+	return rules, err
+}
+
+func (r *MongoRewardRepository) CreateRule(ctx context.Context, rule *models.RewardRule) error {
+	if rule.ID.IsZero() {
+		rule.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.rules.InsertOne(ctx, rule)
+
+	return err
+}
+
+func (r *MongoRewardRepository) ListRedemptions(ctx context.Context, accountIDs []primitive.ObjectID) ([]models.RewardRedemption, error) {
+	var redemptions []models.RewardRedemption
+	err := findAll(ctx, r.redemptions, bson.M{"account_id": bson.M{"$in": accountIDs}}, &redemptions)
+	return redemptions, err
+}
+
+func (r *MongoRewardRepository) CreateRedemption(ctx context.Context, redemption *models.RewardRedemption) error {
+	if redemption.ID.IsZero() {
+		redemption.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.redemptions.InsertOne(ctx, redemption)
+
+	return err
+}
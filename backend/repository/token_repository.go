@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TokenRepository defines the interface for personal access token database operations
+type TokenRepository interface {
+	CreateToken(ctx context.Context, token *models.PersonalAccessToken) error
+	GetTokenByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error
+	TouchToken(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// MongoTokenRepository defines the specific MongoDB operations
+type MongoTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoTokenRepository Factory
+func NewMongoTokenRepository(db *mongo.Database) TokenRepository {
+	return &MongoTokenRepository{
+		collection: db.Collection("access_tokens"),
+	}
+}
+
+func (r *MongoTokenRepository) CreateToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, token)
+
+	return err
+}
+
+func (r *MongoTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ListByUser returns the user's tokens, newest first
+func (r *MongoTokenRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	err := findAll(ctx, r.collection, bson.M{"user_id": userID}, &tokens, opts)
+	return tokens, err
+}
+
+// RevokeToken revokes one of userID's tokens that is not already revoked
+func (r *MongoTokenRepository) RevokeToken(ctx context.Context, id, userID primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoTokenRepository) TouchToken(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": at}})
+	return err
+}
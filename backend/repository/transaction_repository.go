@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TransactionFilter narrows transaction queries. Zero values are ignored,
+// except that void transactions are left out unless Status asks for them
+// or IncludeVoid is set.
+type TransactionFilter struct {
+	AccountIDs       []primitive.ObjectID
+	Start            time.Time
+	End              time.Time
+	BudgetID         primitive.ObjectID
+	ExcludeTransfers bool
+	Unreconciled     bool
+	Status           string
+	IncludeVoid      bool
+}
+
+// TransactionRepository defines the interface for transaction database operations
+type TransactionRepository interface {
+	GetTransactionByID(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error)
+	GetTransactionsByTransferID(ctx context.Context, transferID primitive.ObjectID) ([]models.Transaction, error)
+	ListTransactions(ctx context.Context, filter TransactionFilter) ([]models.Transaction, error)
+	StreamTransactions(ctx context.Context, filter TransactionFilter, fn func(txn *models.Transaction) error) error
+	CreateTransaction(ctx context.Context, txn *models.Transaction) error
+	CreateTransactions(ctx context.Context, txns []models.Transaction) error
+	DeleteByAccounts(ctx context.Context, accountIDs []primitive.ObjectID) error
+	LinkTransfer(ctx context.Context, ids []primitive.ObjectID, transferID primitive.ObjectID) error
+	UpdateSplits(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error)
+	UpdateTags(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Transaction, error)
+	SetCleared(ctx context.Context, accountID primitive.ObjectID, ids []primitive.ObjectID, cleared bool) (int64, error)
+	LockTransactions(ctx context.Context, ids []primitive.ObjectID, reconciliationID primitive.ObjectID) error
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	PostPending(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error)
+	GetExternalTransaction(ctx context.Context, accountID primitive.ObjectID, externalID string) (*models.Transaction, error)
+	UpsertExternalTransaction(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error)
+}
+
+// MongoTransactionRepository defines the specific MongoDB operations
+type MongoTransactionRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoTransactionRepository Factory
+func NewMongoTransactionRepository(db *mongo.Database) TransactionRepository {
+	return &MongoTransactionRepository{
+		collection: db.Collection("transactions"),
+	}
+}
+
+func (r *MongoTransactionRepository) GetTransactionByID(ctx context.Context, id primitive.ObjectID) (*models.Transaction, error) {
+	var txn models.Transaction
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&txn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+func (r *MongoTransactionRepository) GetTransactionsByTransferID(ctx context.Context, transferID primitive.ObjectID) ([]models.Transaction, error) {
+	return r.find(ctx, bson.M{"transfer_id": transferID})
+}
+
+func (r *MongoTransactionRepository) ListTransactions(ctx context.Context, filter TransactionFilter) ([]models.Transaction, error) {
+	return r.find(ctx, filter.toBSON())
+}
+
+// StreamTransactions calls fn for each matching transaction in date order, decoding one
+// document at a time rather than loading the result set. An error from fn stops the stream
+// and is returned.
+func (r *MongoTransactionRepository) StreamTransactions(ctx context.Context, filter TransactionFilter, fn func(txn *models.Transaction) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "transaction_date", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter.toBSON(), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var txn models.Transaction
+		if err := cursor.Decode(&txn); err != nil {
+			return err
+		}
+		if err := fn(&txn); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (r *MongoTransactionRepository) CreateTransaction(ctx context.Context, txn *models.Transaction) error {
+	if txn.ID.IsZero() {
+		txn.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, txn)
+
+	return err
+}
+
+// CreateTransactions inserts txns in one round trip, giving an ID to each that has none
+func (r *MongoTransactionRepository) CreateTransactions(ctx context.Context, txns []models.Transaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+	docs := make([]any, len(txns))
+	for i := range txns {
+		if txns[i].ID.IsZero() {
+			txns[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = txns[i]
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs)
+
+	return err
+}
+
+// DeleteByAccounts removes every transaction on the given accounts
+func (r *MongoTransactionRepository) DeleteByAccounts(ctx context.Context, accountIDs []primitive.ObjectID) error {
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"account_id": bson.M{"$in": accountIDs}})
+
+	return err
+}
+
+// LinkTransfer marks not-yet-linked transactions as legs of the same transfer
+func (r *MongoTransactionRepository) LinkTransfer(ctx context.Context, ids []primitive.ObjectID, transferID primitive.ObjectID) error {
+	query := bson.M{
+		"_id":         bson.M{"$in": ids},
+		"transfer_id": bson.M{"$exists": false},
+	}
+
+	res, err := r.collection.UpdateMany(ctx, query, bson.M{"$set": bson.M{"transfer_id": transferID}})
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount != int64(len(ids)) {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UpdateSplits replaces the split allocations of a transaction; an empty slice removes the split
+func (r *MongoTransactionRepository) UpdateSplits(ctx context.Context, id primitive.ObjectID, splits []models.TransactionSplit, points float32) (*models.Transaction, error) {
+	var txn models.Transaction
+
+	update := bson.M{"$set": bson.M{"splits": splits, "points_rewarded": points}}
+	if len(splits) == 0 {
+		update = bson.M{"$set": bson.M{"points_rewarded": points}, "$unset": bson.M{"splits": ""}}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&txn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// UpdateTags replaces the tags of a transaction; an empty slice removes them
+func (r *MongoTransactionRepository) UpdateTags(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Transaction, error) {
+	var txn models.Transaction
+
+	update := bson.M{"$set": bson.M{"tags": tags}}
+	if len(tags) == 0 {
+		update = bson.M{"$unset": bson.M{"tags": ""}}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&txn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// SetCleared flags unreconciled posted transactions on the account as cleared (or not) and returns how many matched
+func (r *MongoTransactionRepository) SetCleared(ctx context.Context, accountID primitive.ObjectID, ids []primitive.ObjectID, cleared bool) (int64, error) {
+	query := bson.M{
+		"_id":               bson.M{"$in": ids},
+		"account_id":        accountID,
+		"reconciliation_id": bson.M{"$exists": false},
+		"status":            bson.M{"$in": bson.A{models.TransactionStatusPosted, nil}},
+	}
+
+	res, err := r.collection.UpdateMany(ctx, query, bson.M{"$set": bson.M{"cleared": cleared}})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+// LockTransactions attaches transactions to a completed reconciliation so they can no longer be edited
+func (r *MongoTransactionRepository) LockTransactions(ctx context.Context, ids []primitive.ObjectID, reconciliationID primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := bson.M{
+		"_id":               bson.M{"$in": ids},
+		"reconciliation_id": bson.M{"$exists": false},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, query, bson.M{"$set": bson.M{"reconciliation_id": reconciliationID}})
+
+	return err
+}
+
+func (r *MongoTransactionRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// PostPending settles a pending authorization with the amount and dates of its posted version.
+// Authorizations locked in a reconciliation are left alone and return mongo.ErrNoDocuments.
+func (r *MongoTransactionRepository) PostPending(ctx context.Context, id primitive.ObjectID, posted *models.Transaction) (*models.Transaction, error) {
+	var txn models.Transaction
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":             models.TransactionStatusPosted,
+			"name":               posted.Name,
+			"amount":             posted.Amount,
+			"points_rewarded":    posted.PointsRewarded,
+			"transaction_posted": posted.TransactionPosted,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	query := bson.M{
+		"_id":               id,
+		"status":            models.TransactionStatusPending,
+		"reconciliation_id": bson.M{"$exists": false},
+	}
+	err := r.collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&txn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// GetExternalTransaction finds the transaction synced onto accountID as externalID
+func (r *MongoTransactionRepository) GetExternalTransaction(ctx context.Context, accountID primitive.ObjectID, externalID string) (*models.Transaction, error) {
+	var txn models.Transaction
+
+	err := r.collection.FindOne(ctx, bson.M{"account_id": accountID, "external_id": externalID}).Decode(&txn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// UpsertExternalTransaction stores a transaction synced from the account's institution,
+// keyed by ExternalID. A posted transaction naming the pending one it settles with
+// pendingExternalID takes that transaction over. It reports whether a transaction was created;
+// a created transaction gets txn.ID, which is set first when empty.
+// Reconciled and void transactions are never changed; the unique index on
+// (account_id, external_id) stops the upsert from adding a second copy beside them.
+func (r *MongoTransactionRepository) UpsertExternalTransaction(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error) {
+	externalIDs := bson.A{txn.ExternalID}
+	if pendingExternalID != "" {
+		externalIDs = append(externalIDs, pendingExternalID)
+	}
+	if txn.ID.IsZero() {
+		txn.ID = primitive.NewObjectID()
+	}
+	filter := bson.M{
+		"account_id":        txn.AccountID,
+		"external_id":       bson.M{"$in": externalIDs},
+		"reconciliation_id": bson.M{"$exists": false},
+		"status":            bson.M{"$ne": models.TransactionStatusVoid},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"external_id":        txn.ExternalID,
+			"name":               txn.Name,
+			"status":             txn.Status,
+			"amount":             txn.Amount,
+			"type":               txn.Type,
+			"transaction_posted": txn.TransactionPosted,
+		},
+		"$setOnInsert": bson.M{
+			"_id":              txn.ID,
+			"account_number":   txn.AccountNumber,
+			"category":         txn.Category,
+			"transaction_date": txn.TransactionDate,
+			"description":      txn.Description,
+			"points_rewarded":  txn.PointsRewarded,
+			"cleared":          false,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the transaction is locked or void, or a concurrent sync stored it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *MongoTransactionRepository) find(ctx context.Context, query bson.M) ([]models.Transaction, error) {
+	var txns []models.Transaction
+
+	opts := options.Find().SetSort(bson.D{{Key: "transaction_date", Value: 1}})
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var txn models.Transaction
+		if err := cursor.Decode(&txn); err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, cursor.Err()
+}
+
+func (f TransactionFilter) toBSON() bson.M {
+	query := bson.M{}
+	if len(f.AccountIDs) > 0 {
+		query["account_id"] = bson.M{"$in": f.AccountIDs}
+	}
+
+	if !f.BudgetID.IsZero() {
+		query["$or"] = bson.A{
+			bson.M{"budget_id": f.BudgetID},
+			bson.M{"splits.budget_id": f.BudgetID},
+		}
+	}
+	if f.ExcludeTransfers {
+		query["transfer_id"] = bson.M{"$exists": false}
+	}
+	if f.Unreconciled {
+		query["reconciliation_id"] = bson.M{"$exists": false}
+	}
+
+	switch f.Status {
+	case "":
+		if !f.IncludeVoid {
+			query["status"] = bson.M{"$ne": models.TransactionStatusVoid}
+		}
+	case models.TransactionStatusPosted:
+		query["status"] = bson.M{"$in": bson.A{models.TransactionStatusPosted, nil}}
+	default:
+		query["status"] = f.Status
+	}
+
+	date := bson.M{}
+	if !f.Start.IsZero() {
+		date["$gte"] = f.Start
+	}
+	if !f.End.IsZero() {
+		date["$lte"] = f.End
+	}
+	if len(date) > 0 {
+		query["transaction_date"] = date
+	}
+
+	return query
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TxRunner runs a unit of work inside a database transaction
+type TxRunner interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// MongoTxRunner runs work inside a MongoDB multi-document transaction
+type MongoTxRunner struct {
+	client *mongo.Client
+}
+
+// MongoTxRunner Factory
+func NewMongoTxRunner(db *mongo.Database) TxRunner {
+	return &MongoTxRunner{
+		client: db.Client(),
+	}
+}
+
+// WithTransaction starts a session and commits fn's writes atomically.
+// fn may be retried by the driver on transient errors, so it must be idempotent.
+func (r *MongoTxRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := r.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx context.Context) (any, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
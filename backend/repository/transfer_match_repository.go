@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TransferMatchRepository defines the interface for transfer match database operations
+type TransferMatchRepository interface {
+	GetMatchByID(ctx context.Context, id primitive.ObjectID) (*models.TransferMatch, error)
+	ListMatchesByUser(ctx context.Context, userID primitive.ObjectID, status string) ([]models.TransferMatch, error)
+	MatchExists(ctx context.Context, outflowID, inflowID primitive.ObjectID) (bool, error)
+	CreateMatch(ctx context.Context, match *models.TransferMatch) error
+	ResolveMatch(ctx context.Context, id primitive.ObjectID, status string, transferID primitive.ObjectID) error
+	RejectPendingForTransactions(ctx context.Context, txnIDs []primitive.ObjectID, except primitive.ObjectID) error
+}
+
+// MongoTransferMatchRepository defines the specific MongoDB operations
+type MongoTransferMatchRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoTransferMatchRepository Factory
+func NewMongoTransferMatchRepository(db *mongo.Database) TransferMatchRepository {
+	return &MongoTransferMatchRepository{
+		collection: db.Collection("transfer_matches"),
+	}
+}
+
+func (r *MongoTransferMatchRepository) GetMatchByID(ctx context.Context, id primitive.ObjectID) (*models.TransferMatch, error) {
+	var match models.TransferMatch
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&match)
+	if err != nil {
+		return nil, err
+	}
+
+	return &match, nil
+}
+
+func (r *MongoTransferMatchRepository) ListMatchesByUser(ctx context.Context, userID primitive.ObjectID, status string) ([]models.TransferMatch, error) {
+	var matches []models.TransferMatch
+
+	query := bson.M{"user_id": userID}
+	if status != "" {
+		query["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "confidence", Value: -1}})
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var match models.TransferMatch
+		if err := cursor.Decode(&match); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, cursor.Err()
+}
+
+func (r *MongoTransferMatchRepository) MatchExists(ctx context.Context, outflowID, inflowID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"outflow_id": outflowID, "inflow_id": inflowID})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MongoTransferMatchRepository) CreateMatch(ctx context.Context, match *models.TransferMatch) error {
+	if match.ID.IsZero() {
+		match.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, match)
+
+	return err
+}
+
+// ResolveMatch settles a pending proposal. It returns mongo.ErrNoDocuments when the
+// proposal is no longer pending, so a concurrent accept and reject can't both win.
+func (r *MongoTransferMatchRepository) ResolveMatch(ctx context.Context, id primitive.ObjectID, status string, transferID primitive.ObjectID) error {
+	set := bson.M{
+		"status":      status,
+		"resolved_at": time.Now().UTC(),
+	}
+	if !transferID.IsZero() {
+		set["transfer_id"] = transferID
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": models.TransferMatchPending}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RejectPendingForTransactions rejects every other pending proposal that uses one of the given transactions
+func (r *MongoTransferMatchRepository) RejectPendingForTransactions(ctx context.Context, txnIDs []primitive.ObjectID, except primitive.ObjectID) error {
+	query := bson.M{
+		"_id":    bson.M{"$ne": except},
+		"status": models.TransferMatchPending,
+		"$or": bson.A{
+			bson.M{"outflow_id": bson.M{"$in": txnIDs}},
+			bson.M{"inflow_id": bson.M{"$in": txnIDs}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      models.TransferMatchRejected,
+			"resolved_at": time.Now().UTC(),
+		},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, query, update)
+
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TwoFactorRepository defines the interface for two-factor session database operations
+type TwoFactorRepository interface {
+	CreateSession(ctx context.Context, session *models.TwoFactorSession) error
+	GetSessionByHash(ctx context.Context, tokenHash string) (*models.TwoFactorSession, error)
+	StepUp(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	DeleteUserSessions(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// MongoTwoFactorRepository defines the specific MongoDB operations
+type MongoTwoFactorRepository struct {
+	collection *mongo.Collection
+}
+
+// MongoTwoFactorRepository Factory
+func NewMongoTwoFactorRepository(db *mongo.Database) TwoFactorRepository {
+	return &MongoTwoFactorRepository{
+		collection: db.Collection("two_factor_sessions"),
+	}
+}
+
+func (r *MongoTwoFactorRepository) CreateSession(ctx context.Context, session *models.TwoFactorSession) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, session)
+
+	return err
+}
+
+func (r *MongoTwoFactorRepository) GetSessionByHash(ctx context.Context, tokenHash string) (*models.TwoFactorSession, error) {
+	var session models.TwoFactorSession
+
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *MongoTwoFactorRepository) StepUp(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"step_up_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteUserSessions signs every device of the user out of two-factor verification
+func (r *MongoTwoFactorRepository) DeleteUserSessions(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/samuriot/track-me/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,10 +14,22 @@ import (
 // UserRepository defines the interface for user database operations
 type UserRepository interface {
 	GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	ListUsersByLogin(ctx context.Context, login string) ([]models.User, error)
 	GetAllUsers(ctx context.Context) ([]models.User, error)
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUser(ctx context.Context, id primitive.ObjectID, update *models.User) (*models.User, error)
 	DeleteUserByID(ctx context.Context, id primitive.ObjectID) error
+	UpdateCreditScore(ctx context.Context, id primitive.ObjectID, score int) error
+	SetRole(ctx context.Context, id primitive.ObjectID, role string) error
+	SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool, at time.Time) error
+	SaveTOTP(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error
+	UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) error
+	RecordTOTPAttempt(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error)
+	LockTOTP(ctx context.Context, id primitive.ObjectID, until time.Time) error
+	ListStaleTOTP(ctx context.Context, activeKeyID string) ([]models.User, error)
+	ResealTOTP(ctx context.Context, id primitive.ObjectID, previous, secret *models.EncryptedValue) error
+	AddAccount(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error
 }
 
 // MongoUserRepository defines the specific MongoDB operations
@@ -42,6 +55,15 @@ func (r *MongoUserRepository) GetUserByID(ctx context.Context, id primitive.Obje
     return &user, nil
 }
 
+// ListUsersByLogin returns the users whose username or email is login. Neither is
+// unique, so sign-in checks the password against each.
+func (r *MongoUserRepository) ListUsersByLogin(ctx context.Context, login string) ([]models.User, error) {
+	var users []models.User
+	filter := bson.M{"$or": bson.A{bson.M{"username": login}, bson.M{"email": login}}}
+	err := findAll(ctx, r.collection, filter, &users)
+	return users, err
+}
+
 func (r *MongoUserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 	cursor, err := r.collection.Find(ctx, bson.M{})
@@ -79,7 +101,6 @@ func (r *MongoUserRepository) UpdateUser(ctx context.Context, id primitive.Objec
 			"username":     update.Username,
 			"email":        update.Email,
 			"net_worth":    update.NetWorth,
-			"accounts":     update.Accounts,
 			"credit_score": update.CreditScore,
 			"budget":       update.Budget,
 		},
@@ -106,4 +127,180 @@ func (r *MongoUserRepository) DeleteUserByID(ctx context.Context, id primitive.O
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// UpdateCreditScore keeps the profile's CreditScore in step with the latest bureau reading
+func (r *MongoUserRepository) UpdateCreditScore(ctx context.Context, id primitive.ObjectID, score int) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"credit_score": score}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoUserRepository) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetDisabled blocks or restores a user's access; at is recorded when disabling
+func (r *MongoUserRepository) SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool, at time.Time) error {
+	update := bson.M{"$set": bson.M{"disabled": true, "disabled_at": at}}
+	if !disabled {
+		update = bson.M{"$unset": bson.M{"disabled": "", "disabled_at": ""}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SaveTOTP stores a user's TOTP secret and hashed recovery codes; a nil secret removes
+// two-factor authentication altogether
+func (r *MongoUserRepository) SaveTOTP(ctx context.Context, id primitive.ObjectID, secret *models.EncryptedValue, enabled bool, recoveryCodes []string) error {
+	update := bson.M{"$set": bson.M{"totp_secret": secret, "totp_enabled": enabled, "recovery_codes": recoveryCodes}}
+	if secret == nil {
+		update = bson.M{"$unset": bson.M{"totp_secret": "", "totp_enabled": "", "totp_last_step": "", "totp_attempts": "", "totp_locked_until": "", "recovery_codes": ""}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UseTOTPStep records step as used and clears the attempt count, failing with
+// mongo.ErrNoDocuments when it is not newer than the last step used
+func (r *MongoUserRepository) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"totp_last_step": bson.M{"$exists": false}},
+			bson.M{"totp_last_step": bson.M{"$lt": step}},
+		},
+	}
+
+	update := bson.M{"$set": bson.M{"totp_last_step": step}, "$unset": bson.M{"totp_attempts": ""}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UseRecoveryCode removes a recovery code and clears the attempt count, failing with
+// mongo.ErrNoDocuments when the user does not have it
+func (r *MongoUserRepository) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) error {
+	update := bson.M{"$pull": bson.M{"recovery_codes": codeHash}, "$unset": bson.M{"totp_attempts": ""}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "recovery_codes": codeHash}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RecordTOTPAttempt counts a two-factor code about to be checked and returns the
+// attempts since the last accepted code. It fails with mongo.ErrNoDocuments while the
+// user is locked out.
+func (r *MongoUserRepository) RecordTOTPAttempt(ctx context.Context, id primitive.ObjectID, now time.Time) (int, error) {
+	var user models.User
+
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"totp_locked_until": bson.M{"$exists": false}},
+			bson.M{"totp_locked_until": bson.M{"$lte": now}},
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"totp_attempts": 1})
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"totp_attempts": 1}}, opts).Decode(&user)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.TOTPAttempts, nil
+}
+
+// LockTOTP refuses two-factor codes until until, counting attempts afresh after it
+func (r *MongoUserRepository) LockTOTP(ctx context.Context, id primitive.ObjectID, until time.Time) error {
+	update := bson.M{"$set": bson.M{"totp_locked_until": until}, "$unset": bson.M{"totp_attempts": ""}}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ListStaleTOTP finds users whose TOTP secret is sealed under a master key other than
+// activeKeyID
+func (r *MongoUserRepository) ListStaleTOTP(ctx context.Context, activeKeyID string) ([]models.User, error) {
+	var users []models.User
+	err := findAll(ctx, r.collection, bson.M{"totp_secret.key_id": bson.M{"$exists": true, "$ne": activeKeyID}}, &users)
+	return users, err
+}
+
+// ResealTOTP replaces a TOTP secret with the same secret sealed again, failing with
+// mongo.ErrNoDocuments when the stored secret is no longer previous
+func (r *MongoUserRepository) ResealTOTP(ctx context.Context, id primitive.ObjectID, previous, secret *models.EncryptedValue) error {
+	filter := bson.M{"_id": id, "totp_secret.nonce": previous.Nonce}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"totp_secret": secret}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// AddAccount puts an account on the user's profile, once
+func (r *MongoUserRepository) AddAccount(ctx context.Context, id primitive.ObjectID, accountID primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$addToSet": bson.M{"accounts": accountID.Hex()}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
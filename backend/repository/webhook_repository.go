@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// WebhookRepository defines the interface for webhook endpoint and delivery database operations
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetEndpointByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, userID primitive.ObjectID) ([]models.WebhookEndpoint, error)
+	ListSubscribed(ctx context.Context, userID primitive.ObjectID, eventType string) ([]models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id, userID primitive.ObjectID) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ClaimDelivery(ctx context.Context, now, until time.Time) (*models.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, endpointID primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error)
+	ListStaleSecrets(ctx context.Context, activeKeyID string) ([]models.WebhookEndpoint, error)
+	ResealSecret(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error
+}
+
+// MongoWebhookRepository defines the specific MongoDB operations
+type MongoWebhookRepository struct {
+	endpoints  *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+// MongoWebhookRepository Factory
+func NewMongoWebhookRepository(db *mongo.Database) WebhookRepository {
+	return &MongoWebhookRepository{
+		endpoints:  db.Collection("webhook_endpoints"),
+		deliveries: db.Collection("webhook_deliveries"),
+	}
+}
+
+func (r *MongoWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if endpoint.ID.IsZero() {
+		endpoint.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.endpoints.InsertOne(ctx, endpoint)
+
+	return err
+}
+
+func (r *MongoWebhookRepository) GetEndpointByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+
+	err := r.endpoints.FindOne(ctx, bson.M{"_id": id}).Decode(&endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *MongoWebhookRepository) ListEndpoints(ctx context.Context, userID primitive.ObjectID) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	err := findAll(ctx, r.endpoints, bson.M{"user_id": userID}, &endpoints, opts)
+	return endpoints, err
+}
+
+// ListSubscribed returns the user's endpoints that receive eventType
+func (r *MongoWebhookRepository) ListSubscribed(ctx context.Context, userID primitive.ObjectID, eventType string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := findAll(ctx, r.endpoints, bson.M{"user_id": userID, "events": eventType}, &endpoints)
+	return endpoints, err
+}
+
+// DeleteEndpoint removes one of the user's endpoints; deliveries still queued for it fail
+// when their turn comes
+func (r *MongoWebhookRepository) DeleteEndpoint(ctx context.Context, id, userID primitive.ObjectID) error {
+	res, err := r.endpoints.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *MongoWebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.deliveries.InsertOne(ctx, delivery)
+
+	return err
+}
+
+// ClaimDelivery locks the pending delivery that has been due longest until the given
+// time, so concurrent workers never send it twice. It returns mongo.ErrNoDocuments when
+// nothing is due.
+func (r *MongoWebhookRepository) ClaimDelivery(ctx context.Context, now, until time.Time) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	filter := bson.M{
+		"status":          models.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": until}}
+
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetReturnDocument(options.After)
+	err := r.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// SaveAttempt records the outcome of sending a delivery and releases its lock
+func (r *MongoWebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+
+	res, err := r.deliveries.UpdateOne(ctx, bson.M{"_id": delivery.ID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ListDeliveries returns an endpoint's newest deliveries first
+func (r *MongoWebhookRepository) ListDeliveries(ctx context.Context, endpointID primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	err := findAll(ctx, r.deliveries, bson.M{"endpoint_id": endpointID}, &deliveries, opts)
+	return deliveries, err
+}
+
+// ListStaleSecrets finds endpoints whose signing secret is sealed under a master key
+// other than activeKeyID
+func (r *MongoWebhookRepository) ListStaleSecrets(ctx context.Context, activeKeyID string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := findAll(ctx, r.endpoints, bson.M{"secret_sealed.key_id": bson.M{"$exists": true, "$ne": activeKeyID}}, &endpoints)
+	return endpoints, err
+}
+
+// ResealSecret replaces a signing secret with the same secret sealed again, failing with
+// mongo.ErrNoDocuments when the stored secret is no longer previous
+func (r *MongoWebhookRepository) ResealSecret(ctx context.Context, id primitive.ObjectID, previous, sealed *models.EncryptedValue) error {
+	filter := bson.M{"_id": id, "secret_sealed.nonce": previous.Nonce}
+
+	res, err := r.endpoints.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"secret_sealed": sealed}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
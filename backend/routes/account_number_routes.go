@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupAccountNumberRoutes configures all encrypted account number routes
+func SetupAccountNumberRoutes(app *fiber.App, handler *handlers.AccountNumberHandler) {
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	accountGroup := app.Group("/api/accounts")
+	accountGroup.Put("/:id/numbers", write, handler.SetNumbers)
+	accountGroup.Post("/:id/numbers/reveal", write, middleware.RequireStepUp(), handler.RevealNumbers)
+
+	encryptionGroup := app.Group("/api/encryption")
+	encryptionGroup.Post("/rotations", middleware.RequirePermission(models.PermissionRunJobs), handler.RotateKeys)
+}
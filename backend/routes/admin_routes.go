@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupAdminRoutes configures the admin-only user management routes
+func SetupAdminRoutes(app *fiber.App, handler *handlers.AdminHandler) {
+	adminGroup := app.Group("/api/admin", middleware.RequirePermission(models.PermissionManageUsers, models.ScopeAdmin))
+	adminGroup.Get("/users", handler.ListUsers)
+	adminGroup.Put("/users/:id/role", handler.SetRole)
+	adminGroup.Post("/users/:id/disable", handler.DisableUser)
+	adminGroup.Post("/users/:id/enable", handler.EnableUser)
+	adminGroup.Post("/users/:id/impersonate", handler.Impersonate)
+	adminGroup.Delete("/impersonations/:id", handler.EndImpersonation)
+	adminGroup.Get("/audit", handler.GetAuditLog)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupAlertRoutes configures alert rule and notification inbox routes
+func SetupAlertRoutes(app *fiber.App, handler *handlers.AlertHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	alertGroup := app.Group("/api/alerts")
+	alertGroup.Get("/", read, handler.GetAlerts)
+	alertGroup.Post("/", write, handler.CreateAlert)
+	alertGroup.Put("/:id", write, handler.UpdateAlert)
+	alertGroup.Delete("/:id", write, handler.DeleteAlert)
+
+	notificationGroup := app.Group("/api/notifications")
+	notificationGroup.Get("/", read, handler.GetNotifications)
+	notificationGroup.Post("/read", write, handler.ReadAllNotifications)
+	notificationGroup.Post("/:id/read", write, handler.ReadNotification)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupArchiveRoutes configures the account archive export and import routes
+func SetupArchiveRoutes(app *fiber.App, handler *handlers.ArchiveHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	archiveGroup := app.Group("/api/archive")
+	archiveGroup.Get("/", read, handler.ExportArchive)
+	archiveGroup.Post("/import", write, handler.ImportArchive)
+}
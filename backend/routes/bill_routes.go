@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupBillRoutes configures all bill routes
+func SetupBillRoutes(app *fiber.App, handler *handlers.BillHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	billGroup := app.Group("/api/bills")
+	billGroup.Get("/", read, handler.GetBills)
+	billGroup.Post("/", write, handler.CreateBill)
+	billGroup.Put("/:id", write, handler.UpdateBill)
+	billGroup.Delete("/:id", write, handler.DeleteBill)
+}
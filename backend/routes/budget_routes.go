@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupBudgetRoutes configures all budget-related routes
+func SetupBudgetRoutes(app *fiber.App, handler *handlers.BudgetHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+
+	budgetGroup := app.Group("/api/budgets")
+	budgetGroup.Get("/:id/evaluation", read, handler.EvaluateBudget)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupBudgetTemplateRoutes configures recurring budget template routes
+func SetupBudgetTemplateRoutes(app *fiber.App, handler *handlers.BudgetTemplateHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	templateGroup := app.Group("/api/budget-templates")
+	templateGroup.Get("/", read, handler.ListTemplates)
+	templateGroup.Post("/", write, handler.CreateTemplate)
+	templateGroup.Post("/generate", middleware.RequirePermission(models.PermissionRunJobs), handler.GeneratePeriods)
+	templateGroup.Put("/:id", write, handler.UpdateTemplate)
+	templateGroup.Get("/:id/periods", read, handler.ListPeriods)
+	templateGroup.Get("/:id/performance", read, handler.GetPerformance)
+}
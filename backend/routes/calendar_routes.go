@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupCalendarRoutes configures the money calendar and equity grant routes
+func SetupCalendarRoutes(app *fiber.App, handler *handlers.CalendarHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	app.Get("/api/calendar", read, handler.GetCalendar)
+
+	grantGroup := app.Group("/api/grants")
+	grantGroup.Get("/", read, handler.GetGrants)
+	grantGroup.Post("/", write, handler.CreateGrant)
+	grantGroup.Delete("/:id", write, handler.DeleteGrant)
+}
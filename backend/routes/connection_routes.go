@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupConnectionRoutes configures all bank connection and sync routes
+func SetupConnectionRoutes(app *fiber.App, handler *handlers.ConnectionHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	connectionGroup := app.Group("/api/connections")
+	connectionGroup.Get("/", read, handler.GetConnections)
+	connectionGroup.Post("/", write, handler.CreateConnection)
+	connectionGroup.Post("/sync", write, handler.SyncAll)
+	connectionGroup.Post("/:id/sync", write, handler.SyncConnection)
+	connectionGroup.Delete("/:id", write, handler.DeleteConnection)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupCreditRoutes configures all credit score and utilization routes
+func SetupCreditRoutes(app *fiber.App, handler *handlers.CreditHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	creditGroup := app.Group("/api/credit")
+	creditGroup.Get("/", read, handler.GetOverview)
+	creditGroup.Post("/scores", write, handler.RecordScore)
+	creditGroup.Get("/utilization", read, handler.GetUtilization)
+	creditGroup.Put("/thresholds", write, handler.SetThresholds)
+
+	accountGroup := app.Group("/api/accounts")
+	accountGroup.Put("/:id/credit-limit", write, handler.SetCreditLimit)
+}
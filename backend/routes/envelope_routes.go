@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupEnvelopeRoutes configures all envelope budgeting routes
+func SetupEnvelopeRoutes(app *fiber.App, handler *handlers.EnvelopeHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	envelopeGroup := app.Group("/api/envelopes")
+	envelopeGroup.Get("/", read, handler.GetMonth)
+	envelopeGroup.Post("/", write, handler.CreateEnvelope)
+	envelopeGroup.Post("/moves", write, handler.Move)
+	envelopeGroup.Post("/:id/assign", write, handler.Assign)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupExportRoutes configures the transaction and report export routes
+func SetupExportRoutes(app *fiber.App, handler *handlers.ExportHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+
+	exportGroup := app.Group("/api/exports")
+	exportGroup.Get("/transactions", read, handler.ExportTransactions)
+	exportGroup.Get("/budgets", read, handler.ExportBudgetReport)
+	exportGroup.Get("/net-worth", read, handler.ExportNetWorthReport)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupForecastRoutes configures recurring item and cash flow forecast routes
+func SetupForecastRoutes(app *fiber.App, handler *handlers.ForecastHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	app.Get("/api/forecast", read, handler.GetForecast)
+
+	recurringGroup := app.Group("/api/recurring")
+	recurringGroup.Get("/", read, handler.ListRecurring)
+	recurringGroup.Post("/", write, handler.CreateRecurring)
+	recurringGroup.Put("/:id", write, handler.UpdateRecurring)
+	recurringGroup.Delete("/:id", write, handler.DeleteRecurring)
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupGoalRoutes configures all savings goal routes
+func SetupGoalRoutes(app *fiber.App, handler *handlers.GoalHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	goalGroup := app.Group("/api/goals")
+	goalGroup.Get("/", read, handler.ListGoals)
+	goalGroup.Post("/", write, handler.CreateGoal)
+	goalGroup.Get("/:id", read, handler.GetGoal)
+	goalGroup.Put("/:id", write, handler.UpdateGoal)
+	goalGroup.Delete("/:id", write, handler.DeleteGoal)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupHouseholdRoutes configures all household membership and sharing routes
+func SetupHouseholdRoutes(app *fiber.App, handler *handlers.HouseholdHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	householdGroup := app.Group("/api/households")
+	householdGroup.Get("/", read, handler.ListHouseholds)
+	householdGroup.Post("/", write, handler.CreateHousehold)
+	householdGroup.Post("/invitations/accept", write, handler.AcceptInvitation)
+	householdGroup.Get("/:id", read, handler.GetHousehold)
+	householdGroup.Get("/:id/invitations", read, handler.ListInvitations)
+	householdGroup.Post("/:id/invitations", write, handler.Invite)
+	householdGroup.Put("/:id/members/:memberId", write, handler.SetMemberRole)
+	householdGroup.Delete("/:id/members/:memberId", write, handler.RemoveMember)
+	householdGroup.Put("/:id/accounts/:accountId", write, handler.ShareAccount)
+	householdGroup.Delete("/:id/accounts/:accountId", write, handler.UnshareAccount)
+	householdGroup.Put("/:id/budgets/:budgetId", write, handler.ShareBudget)
+	householdGroup.Delete("/:id/budgets/:budgetId", write, handler.UnshareBudget)
+}
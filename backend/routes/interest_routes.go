@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupInterestRoutes configures all interest accrual routes
+func SetupInterestRoutes(app *fiber.App, handler *handlers.InterestHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	accountGroup := app.Group("/api/accounts")
+	accountGroup.Get("/:id/interest", read, handler.GetInterest)
+	accountGroup.Put("/:id/interest", write, handler.UpdateInterestSettings)
+
+	interestGroup := app.Group("/api/interest")
+	interestGroup.Post("/accruals", middleware.RequirePermission(models.PermissionRunJobs), handler.RunAccruals)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupJobRoutes configures the admin routes for inspecting background jobs
+func SetupJobRoutes(app *fiber.App, handler *handlers.JobHandler) {
+	jobGroup := app.Group("/api/admin/jobs", middleware.RequirePermission(models.PermissionRunJobs, models.ScopeAdmin))
+	jobGroup.Get("/", handler.ListJobs)
+	jobGroup.Get("/runs", handler.ListRuns)
+}
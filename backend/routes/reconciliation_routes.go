@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupReconciliationRoutes configures all account reconciliation routes
+func SetupReconciliationRoutes(app *fiber.App, handler *handlers.ReconciliationHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	accountGroup := app.Group("/api/accounts")
+	accountGroup.Get("/:id/reconciliations", read, handler.GetHistory)
+	accountGroup.Post("/:id/reconciliations", write, handler.StartReconciliation)
+
+	reconciliationGroup := app.Group("/api/reconciliations")
+	reconciliationGroup.Get("/:id", read, handler.GetReconciliation)
+	reconciliationGroup.Post("/:id/clear", write, handler.ClearTransactions)
+	reconciliationGroup.Post("/:id/complete", write, handler.CompleteReconciliation)
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupReportRoutes configures the aggregated report routes
+func SetupReportRoutes(app *fiber.App, handler *handlers.ReportHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+
+	reportGroup := app.Group("/api/reports")
+	reportGroup.Get("/spending", read, handler.GetSpendingReport)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupRewardRoutes configures all rewards-related routes
+func SetupRewardRoutes(app *fiber.App, handler *handlers.RewardHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+	manage := middleware.RequirePermission(models.PermissionManageUsers)
+
+	rewardGroup := app.Group("/api/rewards")
+	rewardGroup.Get("/", read, handler.GetSummary)
+	rewardGroup.Get("/programs", read, handler.ListPrograms)
+	rewardGroup.Post("/programs", manage, handler.CreateProgram)
+	rewardGroup.Get("/rules", read, handler.ListRules)
+	rewardGroup.Post("/rules", write, handler.CreateRule)
+	rewardGroup.Post("/redemptions", write, handler.Redeem)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+)
+
+// SetupSessionRoutes configures sign-in routes
+func SetupSessionRoutes(app *fiber.App, handler *handlers.SessionHandler) {
+	sessionGroup := app.Group("/api/auth")
+	sessionGroup.Post("/login", handler.Login)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupTokenRoutes configures personal access token routes
+func SetupTokenRoutes(app *fiber.App, handler *handlers.TokenHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+
+	tokenGroup := app.Group("/api/tokens")
+	tokenGroup.Get("/", read, handler.ListTokens)
+	tokenGroup.Post("/", read, handler.CreateToken)
+	tokenGroup.Delete("/:id", read, handler.RevokeToken)
+}
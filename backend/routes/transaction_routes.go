@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupTransactionRoutes configures all transaction-related routes
+func SetupTransactionRoutes(app *fiber.App, handler *handlers.TransactionHandler) {
+	read := middleware.RequirePermission(models.PermissionRead, models.ScopeReadTransactions, models.ScopeWriteTransactions)
+	write := middleware.RequirePermission(models.PermissionWrite, models.ScopeWriteTransactions)
+
+	transactionGroup := app.Group("/api/transactions")
+	transactionGroup.Get("/", read, handler.ListTransactions)
+	transactionGroup.Get("/summary", read, handler.GetSummary)
+	transactionGroup.Get("/categories", read, handler.GetCategoryReport)
+	transactionGroup.Put("/:id/splits", write, handler.SetSplits)
+	transactionGroup.Put("/:id/tags", write, handler.SetTags)
+	transactionGroup.Post("/:id/void", write, handler.VoidTransaction)
+
+	accountGroup := app.Group("/api/accounts")
+	accountGroup.Post("/:id/transactions/import", write, handler.ImportTransactions)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupTransferMatchRoutes configures all transfer matching routes
+func SetupTransferMatchRoutes(app *fiber.App, handler *handlers.TransferMatchHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	matchGroup := app.Group("/api/transfer-matches")
+	matchGroup.Get("/", read, handler.ListMatches)
+	matchGroup.Post("/", write, handler.FindMatches)
+	matchGroup.Post("/:id/accept", write, handler.AcceptMatch)
+	matchGroup.Post("/:id/reject", write, handler.RejectMatch)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupTransferRoutes configures all transfer-related routes
+func SetupTransferRoutes(app *fiber.App, handler *handlers.TransferHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	transferGroup := app.Group("/api/transfers")
+	transferGroup.Post("/", write, handler.CreateTransfer)
+	transferGroup.Get("/:id", read, handler.GetTransfer)
+}
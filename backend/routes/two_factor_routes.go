@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupTwoFactorRoutes configures all two-factor authentication routes
+func SetupTwoFactorRoutes(app *fiber.App, handler *handlers.TwoFactorHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+
+	twoFactorGroup := app.Group("/api/auth/2fa")
+	twoFactorGroup.Post("/enroll", read, handler.Enroll)
+	twoFactorGroup.Post("/confirm", read, handler.Confirm)
+	// verify is the login step, so it is open to callers who have not verified yet
+	twoFactorGroup.Post("/verify", handler.Verify)
+	twoFactorGroup.Post("/step-up", read, handler.StepUp)
+	twoFactorGroup.Delete("/", read, middleware.RequireStepUp(), handler.Disable)
+}
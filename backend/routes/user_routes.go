@@ -3,15 +3,21 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
 )
 
 // SetupProductRoutes configures all product-related routes
 func SetupProductRoutes(app *fiber.App, handler *handlers.UserHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+	selfOrAdmin := middleware.RequireSelfOr(models.PermissionManageUsers, "id")
+
 	// Create a route group for products
 	productGroup := app.Group("/api/products")
-	productGroup.Get("/", handler.GetAllUsers)
+	productGroup.Get("/", middleware.RequirePermission(models.PermissionManageUsers), handler.GetAllUsers)
 	productGroup.Post("/", handler.CreateUser)
-	productGroup.Get("/:id", handler.GetUser)
-	productGroup.Put("/:id", handler.UpdateUser)
-	productGroup.Delete("/:id", handler.DeleteUser)
-}
\ No newline at end of file
+	productGroup.Get("/:id", read, selfOrAdmin, handler.GetUser)
+	productGroup.Put("/:id", write, selfOrAdmin, handler.UpdateUser)
+	productGroup.Delete("/:id", write, selfOrAdmin, middleware.RequireStepUp(), handler.DeleteUser)
+}
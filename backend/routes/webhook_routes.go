@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/samuriot/track-me/handlers"
+	"github.com/samuriot/track-me/middleware"
+	"github.com/samuriot/track-me/models"
+)
+
+// SetupWebhookRoutes configures all webhook endpoint routes
+func SetupWebhookRoutes(app *fiber.App, handler *handlers.WebhookHandler) {
+	read := middleware.RequirePermission(models.PermissionRead)
+	write := middleware.RequirePermission(models.PermissionWrite)
+
+	webhookGroup := app.Group("/api/webhooks")
+	webhookGroup.Get("/", read, handler.GetWebhooks)
+	webhookGroup.Post("/", write, handler.CreateWebhook)
+	webhookGroup.Delete("/:id", write, handler.DeleteWebhook)
+	webhookGroup.Get("/:id/deliveries", read, handler.GetDeliveries)
+	webhookGroup.Post("/:id/test", write, handler.TestWebhook)
+}
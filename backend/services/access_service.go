@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Access levels checked by an AccessPolicy
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+var ErrForbidden = errors.New("Error: You Do Not Have Access To This Resource")
+
+// AccessPolicy decides what a user may do with accounts and budgets, whether they
+// own them or they are shared with them through a household
+type AccessPolicy interface {
+	AccountIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	CheckAccount(ctx context.Context, userID, accountID primitive.ObjectID, access string) error
+	CheckBudget(ctx context.Context, userID primitive.ObjectID, budget *models.Budget, access string) error
+}
+
+// AccessService is the AccessPolicy backed by account ownership and household memberships.
+// Users have full access to the accounts they own and the budgets they own.
+// Household members reach shared accounts and budgets with their member role:
+// owners and editors may write, viewers may only read.
+type AccessService struct {
+	accounts   repository.AccountRepository
+	households repository.HouseholdRepository
+}
+
+func NewAccessService(accounts repository.AccountRepository, households repository.HouseholdRepository) *AccessService {
+	return &AccessService{accounts: accounts, households: households}
+}
+
+// AccountIDs returns every account userID can read: their own, then shared ones
+func (s *AccessService) AccountIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	households, err := s.households.ListByMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	householdIDs := make([]primitive.ObjectID, 0, len(households))
+	for _, household := range households {
+		householdIDs = append(householdIDs, household.ID)
+	}
+	shared, err := s.accounts.ListSharedIDs(ctx, householdIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range shared {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// CheckAccount allows the account's owner and members of households it is shared with.
+// Accounts that don't exist are forbidden rather than not found, so they can't be probed.
+func (s *AccessService) CheckAccount(ctx context.Context, userID, accountID primitive.ObjectID, access string) error {
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !account.OwnerID.IsZero() && account.OwnerID == userID {
+		return nil
+	}
+
+	return s.checkShared(ctx, userID, access, func(household *models.Household) bool {
+		return slices.Contains(account.HouseholdIDs, household.ID)
+	})
+}
+
+// CheckBudget allows the budget's owner and members of households it is shared with.
+// Budgets without an owner are denied to everyone; Migrate assigns owners to old ones.
+func (s *AccessService) CheckBudget(ctx context.Context, userID primitive.ObjectID, budget *models.Budget, access string) error {
+	if !budget.UserID.IsZero() && budget.UserID == userID {
+		return nil
+	}
+
+	return s.checkShared(ctx, userID, access, func(household *models.Household) bool {
+		return slices.Contains(household.Budgets, budget.ID)
+	})
+}
+
+// checkShared looks for a household of userID that shares the resource with a role
+// allowing access
+func (s *AccessService) checkShared(ctx context.Context, userID primitive.ObjectID, access string, shares func(*models.Household) bool) error {
+	households, err := s.households.ListByMember(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := range households {
+		if !shares(&households[i]) {
+			continue
+		}
+		member, ok := households[i].Member(userID)
+		if ok && (access == AccessRead || member.Role != models.HouseholdRoleViewer) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// userAccountIDs returns the accounts userID owns. Ownership is the server-set
+// Account.OwnerID; the account list on a user's profile grants nothing.
+func userAccountIDs(ctx context.Context, accounts repository.AccountRepository, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return accounts.ListOwnedIDs(ctx, userID)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrInvalidAccountNumbers = errors.New("Error: Invalid Account Or Routing Number")
+	ErrRevealReason          = errors.New("Error: Revealing Account Numbers Requires A Reason")
+)
+
+// AccountNumbers are the full, decrypted numbers of an account
+type AccountNumbers struct {
+	AccountID     primitive.ObjectID `json:"account_id"`
+	AccountNumber string             `json:"account_number"`
+	RoutingNumber string             `json:"routing_number,omitempty"`
+}
+
+// KeyRotationResult reports what one re-encryption run did. Secrets counts the other
+// sealed fields found stale, such as TOTP secrets and access tokens.
+type KeyRotationResult struct {
+	ActiveKeyID string `json:"active_key_id"`
+	Accounts    int    `json:"accounts"`
+	Secrets     int    `json:"secrets"`
+	Reencrypted int    `json:"reencrypted"`
+	Failures    int    `json:"failures"`
+}
+
+// record counts the outcome of re-encrypting one secret of kind. A secret replaced
+// while it was being re-encrypted (mongo.ErrNoDocuments) no longer needs it.
+func (r *KeyRotationResult) record(kind string, id primitive.ObjectID, err error) {
+	r.Secrets++
+	switch {
+	case err == nil:
+		r.Reencrypted++
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		log.Printf("re-encryption failed for %s %s: %v", kind, id.Hex(), err)
+		r.Failures++
+	}
+}
+
+// SecretRotator re-encrypts the secrets a service keeps sealed under retired master
+// keys, counting them into result
+type SecretRotator interface {
+	RotateSecrets(ctx context.Context, result *KeyRotationResult) error
+}
+
+// AccountNumberService keeps account and routing numbers encrypted at rest. Responses
+// only ever carry the last four digits, except through an audited Reveal.
+type AccountNumberService struct {
+	accounts repository.AccountRepository
+	cipher   *FieldCipher
+	access   AccessPolicy
+	audit    repository.AuditRepository
+	rotators []SecretRotator
+}
+
+func NewAccountNumberService(accounts repository.AccountRepository, cipher *FieldCipher, access AccessPolicy, audit repository.AuditRepository) *AccountNumberService {
+	return &AccountNumberService{accounts: accounts, cipher: cipher, access: access, audit: audit}
+}
+
+// AddRotator makes RotateKeys re-encrypt the secrets of another service as well
+func (s *AccountNumberService) AddRotator(rotator SecretRotator) {
+	s.rotators = append(s.rotators, rotator)
+}
+
+// SetNumbers encrypts and stores the numbers of an account. The routing number may be
+// left empty for accounts without one, such as credit cards.
+func (s *AccountNumberService) SetNumbers(ctx context.Context, userID, accountID primitive.ObjectID, accountNumber, routingNumber string) (*models.Account, error) {
+	accountNumber = strings.TrimSpace(accountNumber)
+	routingNumber = strings.TrimSpace(routingNumber)
+	if !ValidAccountNumber(accountNumber) || (routingNumber != "" && !ValidRoutingNumber(routingNumber)) {
+		return nil, ErrInvalidAccountNumbers
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	if err := s.seal(ctx, account, accountNumber, routingNumber); err != nil {
+		return nil, err
+	}
+	if err := s.accounts.SaveAccountNumbers(ctx, account, nil); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// Reveal decrypts the full numbers of an account for a user who may edit it, recording
+// who asked, why, and any admin impersonating them
+func (s *AccountNumberService) Reveal(ctx context.Context, userID, impersonatorID, accountID primitive.ObjectID, reason string) (*AccountNumbers, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRevealReason
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	accountNumber, routingNumber, err := s.open(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.CreateEntry(ctx, &models.AuditEntry{
+		ActorID:        userID,
+		Action:         models.AuditAccountNumbersRevealed,
+		TargetUserID:   userID,
+		ResourceID:     accountID,
+		ImpersonatorID: impersonatorID,
+		Detail:         reason,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountNumbers{AccountID: accountID, AccountNumber: accountNumber, RoutingNumber: routingNumber}, nil
+}
+
+// RotateKeys re-encrypts every number still in plaintext or sealed under a retired
+// master key with the active one, then the secrets of every added rotator
+func (s *AccountNumberService) RotateKeys(ctx context.Context) (*KeyRotationResult, error) {
+	activeKeyID := s.cipher.keys.ActiveKeyID()
+	accounts, err := s.accounts.ListForEncryption(ctx, activeKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeyRotationResult{ActiveKeyID: activeKeyID, Accounts: len(accounts)}
+	for i := range accounts {
+		switch err := s.reencrypt(ctx, &accounts[i]); {
+		case err == nil:
+			result.Reencrypted++
+		case !errors.Is(err, mongo.ErrNoDocuments):
+			log.Printf("re-encryption failed for account %s: %v", accounts[i].ID.Hex(), err)
+			result.Failures++
+		}
+	}
+	for _, rotator := range s.rotators {
+		if err := rotator.RotateSecrets(ctx, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// reencrypt seals the numbers of account again under the active key. Numbers changed
+// since account was read are left alone (mongo.ErrNoDocuments), since whoever changed
+// them sealed them under the active key already.
+func (s *AccountNumberService) reencrypt(ctx context.Context, account *models.Account) error {
+	previous := *account
+	accountNumber, routingNumber, err := s.open(ctx, account)
+	if err != nil {
+		return err
+	}
+	if err := s.seal(ctx, account, accountNumber, routingNumber); err != nil {
+		return err
+	}
+	return s.accounts.SaveAccountNumbers(ctx, account, &previous)
+}
+
+// seal encrypts both numbers onto account, binding each to the account and field
+func (s *AccountNumberService) seal(ctx context.Context, account *models.Account, accountNumber, routingNumber string) error {
+	account.AccountNumber, account.RoutingNumber = "", ""
+	account.AccountNumberSealed, account.RoutingNumberSealed = nil, nil
+	account.AccountNumberLast4, account.RoutingNumberLast4 = "", ""
+
+	if accountNumber != "" {
+		sealed, err := s.cipher.Encrypt(ctx, accountNumber, numberBinding(account.ID, "account_number"))
+		if err != nil {
+			return err
+		}
+		account.AccountNumberSealed, account.AccountNumberLast4 = sealed, models.LastFour(accountNumber)
+	}
+	if routingNumber != "" {
+		sealed, err := s.cipher.Encrypt(ctx, routingNumber, numberBinding(account.ID, "routing_number"))
+		if err != nil {
+			return err
+		}
+		account.RoutingNumberSealed, account.RoutingNumberLast4 = sealed, models.LastFour(routingNumber)
+	}
+	return nil
+}
+
+// open decrypts both numbers of account, falling back to plaintext stored before
+// numbers were encrypted
+func (s *AccountNumberService) open(ctx context.Context, account *models.Account) (string, string, error) {
+	accountNumber, routingNumber := account.AccountNumber, account.RoutingNumber
+
+	if account.AccountNumberSealed != nil {
+		value, err := s.cipher.Decrypt(ctx, account.AccountNumberSealed, numberBinding(account.ID, "account_number"))
+		if err != nil {
+			return "", "", err
+		}
+		accountNumber = value
+	}
+	if account.RoutingNumberSealed != nil {
+		value, err := s.cipher.Decrypt(ctx, account.RoutingNumberSealed, numberBinding(account.ID, "routing_number"))
+		if err != nil {
+			return "", "", err
+		}
+		routingNumber = value
+	}
+	return accountNumber, routingNumber, nil
+}
+
+func (s *AccountNumberService) getAccount(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+	account, err := s.accounts.GetAccountByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func numberBinding(accountID primitive.ObjectID, field string) string {
+	return accountID.Hex() + ":" + field
+}
+
+// ValidAccountNumber accepts the 4 to 17 digits US account numbers may have
+func ValidAccountNumber(number string) bool {
+	return len(number) >= 4 && len(number) <= 17 && allDigits(number)
+}
+
+// ValidRoutingNumber checks a nine digit ABA routing number against its check digit
+func ValidRoutingNumber(number string) bool {
+	if len(number) != 9 || !allDigits(number) {
+		return false
+	}
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i, r := range number {
+		sum += int(r-'0') * weights[i%3]
+	}
+	return sum%10 == 0
+}
+
+func allDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ImpersonationTTL is how long an impersonation stays usable unless ended sooner
+const ImpersonationTTL = time.Hour
+
+var (
+	ErrAccountDisabled      = errors.New("Error: This Account Is Disabled")
+	ErrInvalidRole          = errors.New("Error: Invalid Role")
+	ErrSelfAdminChange      = errors.New("Error: Admins Cannot Change, Disable Or Impersonate Themselves")
+	ErrImpersonationInvalid = errors.New("Error: Impersonation Is Invalid, Ended Or Expired")
+	ErrImpersonationReason  = errors.New("Error: Impersonation Requires A Reason")
+)
+
+// Caller is who a request runs as once roles, disabled accounts and impersonation are applied
+type Caller struct {
+	UserID          primitive.ObjectID
+	Role            string
+	ImpersonatorID  primitive.ObjectID
+	ImpersonationID primitive.ObjectID
+}
+
+type AdminService struct {
+	users repository.UserRepository
+	audit repository.AuditRepository
+}
+
+func NewAdminService(users repository.UserRepository, audit repository.AuditRepository) *AdminService {
+	return &AdminService{users: users, audit: audit}
+}
+
+// ResolveCaller loads the calling user's role. With an impersonationID, an admin who
+// started that impersonation acts as its target user, with the target's role.
+func (s *AdminService) ResolveCaller(ctx context.Context, userID, impersonationID primitive.ObjectID) (*Caller, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	caller := &Caller{UserID: user.ID, Role: user.EffectiveRole()}
+	if impersonationID.IsZero() {
+		return caller, nil
+	}
+
+	if !models.RoleAllows(caller.Role, models.PermissionManageUsers) {
+		return nil, ErrForbidden
+	}
+	impersonation, err := s.audit.GetImpersonation(ctx, impersonationID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrImpersonationInvalid
+		}
+		return nil, err
+	}
+	if impersonation.AdminID != userID || !impersonation.Active(time.Now().UTC()) {
+		return nil, ErrImpersonationInvalid
+	}
+
+	target, err := s.activeUser(ctx, impersonation.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &Caller{
+		UserID:          target.ID,
+		Role:            target.EffectiveRole(),
+		ImpersonatorID:  userID,
+		ImpersonationID: impersonation.ID,
+	}, nil
+}
+
+func (s *AdminService) ListUsers(ctx context.Context) ([]models.User, error) {
+	users, err := s.users.GetAllUsers(ctx)
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, err
+}
+
+// SetRole changes a user's role. Admins cannot change their own, so there is always
+// an admin left to undo a mistake.
+func (s *AdminService) SetRole(ctx context.Context, adminID, userID primitive.ObjectID, role string) (*models.User, error) {
+	if !models.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if adminID == userID {
+		return nil, ErrSelfAdminChange
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.EffectiveRole()
+	if err := s.users.SetRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	if err := s.record(ctx, adminID, models.AuditUserRoleChanged, userID, previous+" -> "+role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetDisabled blocks or restores every request made by or as the user
+func (s *AdminService) SetDisabled(ctx context.Context, adminID, userID primitive.ObjectID, disabled bool) (*models.User, error) {
+	if adminID == userID {
+		return nil, ErrSelfAdminChange
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.users.SetDisabled(ctx, userID, disabled, now); err != nil {
+		return nil, err
+	}
+	action := models.AuditUserEnabled
+	user.Disabled, user.DisabledAt = disabled, time.Time{}
+	if disabled {
+		action = models.AuditUserDisabled
+		user.DisabledAt = now
+	}
+	if err := s.record(ctx, adminID, action, userID, ""); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Impersonate starts an audited impersonation of userID for ImpersonationTTL
+func (s *AdminService) Impersonate(ctx context.Context, adminID, userID primitive.ObjectID, reason string) (*models.Impersonation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrImpersonationReason
+	}
+	if adminID == userID {
+		return nil, ErrSelfAdminChange
+	}
+	if _, err := s.activeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	impersonation := &models.Impersonation{
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		StartedAt: now,
+		ExpiresAt: now.Add(ImpersonationTTL),
+	}
+	if err := s.audit.CreateImpersonation(ctx, impersonation); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, models.AuditImpersonationStarted, userID, reason); err != nil {
+		return nil, err
+	}
+	return impersonation, nil
+}
+
+// EndImpersonation closes one of the admin's own impersonations
+func (s *AdminService) EndImpersonation(ctx context.Context, adminID, id primitive.ObjectID) error {
+	impersonation, err := s.audit.GetImpersonation(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrImpersonationInvalid
+		}
+		return err
+	}
+	if impersonation.AdminID != adminID {
+		return ErrImpersonationInvalid
+	}
+
+	if err := s.audit.EndImpersonation(ctx, id, time.Now().UTC()); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrImpersonationInvalid
+		}
+		return err
+	}
+	return s.record(ctx, adminID, models.AuditImpersonationEnded, impersonation.UserID, "")
+}
+
+// AuditLog returns the newest audit entries, optionally only those about targetUserID
+func (s *AdminService) AuditLog(ctx context.Context, targetUserID primitive.ObjectID, limit int64) ([]models.AuditEntry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	entries, err := s.audit.ListEntries(ctx, targetUserID, limit)
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	return entries, err
+}
+
+func (s *AdminService) record(ctx context.Context, actorID primitive.ObjectID, action string, targetUserID primitive.ObjectID, detail string) error {
+	return s.audit.CreateEntry(ctx, &models.AuditEntry{
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Detail:       detail,
+		CreatedAt:    time.Now().UTC(),
+	})
+}
+
+func (s *AdminService) getUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.users.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AdminService) activeUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	return user, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrProviderAuth = errors.New("Error: The Bank Connection Needs To Be Re-Authorized")
+
+// AggregationProvider reads accounts, balances and transactions from an account
+// aggregator. accessToken is the provider's credential for one connection.
+type AggregationProvider interface {
+	Name() string
+	ListAccounts(ctx context.Context, accessToken string) ([]ProviderAccount, error)
+	FetchBalances(ctx context.Context, accessToken string) ([]ProviderBalance, error)
+	// FetchTransactions returns transactions added or changed since cursor, an empty
+	// cursor meaning from the start of the available history. Callers keep asking with
+	// NextCursor while HasMore is set.
+	FetchTransactions(ctx context.Context, accessToken, cursor string) (*TransactionPage, error)
+}
+
+// ProviderAccount is an account as the provider reports it
+type ProviderAccount struct {
+	ExternalID string
+	Name       string
+	Type       string
+	Mask       string
+}
+
+type ProviderBalance struct {
+	AccountExternalID string
+	Current           float64
+	Available         float64
+}
+
+// ProviderTransaction is a transaction as the provider reports it. Amount is signed:
+// negative amounts leave the account. A posted transaction that settles a pending one
+// names it with PendingExternalID.
+type ProviderTransaction struct {
+	ExternalID        string
+	AccountExternalID string
+	PendingExternalID string
+	Name              string
+	Category          string
+	Amount            float64
+	Pending           bool
+	Date              time.Time
+	PostedDate        time.Time
+}
+
+type TransactionPage struct {
+	Transactions []ProviderTransaction
+	NextCursor   string
+	HasMore      bool
+}
@@ -0,0 +1,620 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrAlertNotFound        = errors.New("Error: Alert Rule Not Found")
+	ErrInvalidAlert         = errors.New("Error: Alert Rule Has An Unknown Kind Or An Invalid Threshold")
+	ErrUnknownChannel       = errors.New("Error: Unknown Notification Channel")
+	ErrNotificationNotFound = errors.New("Error: Notification Not Found")
+)
+
+const (
+	// AlertMaxBillDays is how far ahead a bill_due rule may look
+	AlertMaxBillDays = 60
+	// alertMaxBudgetPercent caps budget_threshold rules, which may alert past 100%
+	alertMaxBudgetPercent = 1000
+	// recurringChargeMinGap and recurringChargeMaxGap bound how far apart two charges
+	// from a merchant are to count as monthly
+	recurringChargeMinGap = 25 * 24 * time.Hour
+	recurringChargeMaxGap = 35 * 24 * time.Hour
+	// recurringChargeHistoryDays is how far back a charge is looked up to tell a new
+	// recurring charge from an established one
+	recurringChargeHistoryDays = 100
+	// recurringChargeTolerance is how far, as a fraction, a repeat charge's amount may drift
+	recurringChargeTolerance = 0.1
+	// notificationLimit is how many notifications the inbox returns by default and at most
+	notificationLimit = 100
+	alertDateLayout   = "Jan 2, 2006"
+	alertDayKeyLayout = "2006-01-02"
+)
+
+// scheduledAlertKinds are the rules whose conditions change with time as well as with
+// events, so the scheduled run evaluates them
+var scheduledAlertKinds = []string{models.AlertBudgetThreshold, models.AlertBalanceFloor, models.AlertBillDue}
+
+// AlertRunResult reports what one scheduled evaluation of alert rules did
+type AlertRunResult struct {
+	Rules    int `json:"rules"`
+	Fired    int `json:"fired"`
+	Failures int `json:"failures"`
+}
+
+// AlertService evaluates users' alert rules and sends what fires to the rules' channels.
+// Rules are checked as events arrive and on a schedule; each condition fires once, so a
+// budget crossing 80% or a bill coming due alerts a single time however often it is seen.
+// Balance floors alert at most once a day per account while the balance stays low.
+type AlertService struct {
+	alerts       repository.AlertRepository
+	users        repository.UserRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	budgets      repository.BudgetRepository
+	recurring    repository.RecurringRepository
+	bills        repository.BillRepository
+	access       AccessPolicy
+	channels     map[string]NotificationChannel
+}
+
+func NewAlertService(alerts repository.AlertRepository, users repository.UserRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository, budgets repository.BudgetRepository, recurring repository.RecurringRepository, bills repository.BillRepository, access AccessPolicy) *AlertService {
+	return &AlertService{
+		alerts:       alerts,
+		users:        users,
+		accounts:     accounts,
+		transactions: transactions,
+		budgets:      budgets,
+		recurring:    recurring,
+		bills:        bills,
+		access:       access,
+		channels:     map[string]NotificationChannel{},
+	}
+}
+
+// RegisterChannel makes a channel available to alert rules
+func (s *AlertService) RegisterChannel(channel NotificationChannel) {
+	s.channels[channel.Name()] = channel
+}
+
+func (s *AlertService) ListRules(ctx context.Context, userID primitive.ObjectID) ([]models.AlertRule, error) {
+	rules, err := s.alerts.ListRules(ctx, userID)
+	if rules == nil {
+		rules = []models.AlertRule{}
+	}
+	return rules, err
+}
+
+func (s *AlertService) CreateRule(ctx context.Context, userID primitive.ObjectID, rule *models.AlertRule) error {
+	rule.ID = primitive.NilObjectID
+	rule.UserID = userID
+	rule.CreatedAt = time.Now().UTC()
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+	return s.alerts.CreateRule(ctx, rule)
+}
+
+// UpdateRule replaces one of userID's rules, keeping its owner and creation time
+func (s *AlertService) UpdateRule(ctx context.Context, userID, id primitive.ObjectID, rule *models.AlertRule) error {
+	existing, err := s.getRule(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	rule.ID = existing.ID
+	rule.UserID = existing.UserID
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+
+	if err := s.alerts.SaveRule(ctx, rule); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAlertNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AlertService) DeleteRule(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.alerts.DeleteRule(ctx, id, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAlertNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Notifications returns the user's inbox, newest first
+func (s *AlertService) Notifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]models.Notification, error) {
+	if limit <= 0 || limit > notificationLimit {
+		limit = notificationLimit
+	}
+	notifications, err := s.alerts.ListNotifications(ctx, userID, unreadOnly, limit)
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+	return notifications, err
+}
+
+func (s *AlertService) MarkRead(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.alerts.MarkRead(ctx, id, userID, time.Now().UTC()); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// MarkAllRead empties the user's unread inbox and returns how many notifications it held
+func (s *AlertService) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return s.alerts.MarkAllRead(ctx, userID, time.Now().UTC())
+}
+
+// HandleEvent checks the rules of the event's user that the event can trigger
+func (s *AlertService) HandleEvent(ctx context.Context, event models.Event) error {
+	rules, err := s.alerts.ListRules(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	rules = slices.DeleteFunc(rules, func(rule models.AlertRule) bool { return !rule.Enabled })
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var errs []error
+	switch event.Type {
+	case models.EventTransactionCreated:
+		if txn, ok := event.Data.(models.Transaction); ok {
+			errs = append(errs, s.checkTransaction(ctx, rules, &txn, event.CreatedAt))
+		}
+	case models.EventBudgetExceeded:
+		if eval, ok := event.Data.(*BudgetEvaluation); ok {
+			errs = append(errs, s.checkBudgets(ctx, rules, []primitive.ObjectID{eval.BudgetID}, event.CreatedAt))
+		}
+	case models.EventBalanceLow:
+		if low, ok := event.Data.(LowBalance); ok {
+			errs = append(errs, s.checkAccount(ctx, rules, low.AccountID, event.CreatedAt))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Evaluate checks every enabled rule whose condition can change without an event, such
+// as a bill coming due
+func (s *AlertService) Evaluate(ctx context.Context, now time.Time) (*AlertRunResult, error) {
+	rules, err := s.alerts.ListEnabledByKind(ctx, scheduledAlertKinds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AlertRunResult{Rules: len(rules)}
+	for i := range rules {
+		fired, err := s.evaluateRule(ctx, &rules[i], now)
+		if err != nil {
+			log.Printf("alert rule %s could not be evaluated: %v", rules[i].ID.Hex(), err)
+			result.Failures++
+			continue
+		}
+		result.Fired += fired
+	}
+	return result, nil
+}
+
+func (s *AlertService) evaluateRule(ctx context.Context, rule *models.AlertRule, now time.Time) (int, error) {
+	switch rule.Kind {
+	case models.AlertBudgetThreshold:
+		budgets, err := s.ruleBudgets(ctx, rule, now)
+		if err != nil {
+			return 0, err
+		}
+		return s.checkBudgetRule(ctx, rule, budgets, now)
+	case models.AlertBalanceFloor:
+		accountIDs := []primitive.ObjectID{rule.AccountID}
+		if rule.AccountID.IsZero() {
+			owned, err := userAccountIDs(ctx, s.accounts, rule.UserID)
+			if err != nil {
+				return 0, err
+			}
+			accountIDs = owned
+		}
+		fired := 0
+		for _, accountID := range accountIDs {
+			n, err := s.checkBalanceFloor(ctx, rule, accountID, now)
+			if err != nil {
+				return fired, err
+			}
+			fired += n
+		}
+		return fired, nil
+	case models.AlertBillDue:
+		return s.checkBills(ctx, rule, now)
+	}
+	return 0, nil
+}
+
+// checkTransaction runs the rules a new transaction can trigger: its size, whether it
+// starts a recurring charge, and the account and budgets it lands on
+func (s *AlertService) checkTransaction(ctx context.Context, rules []models.AlertRule, txn *models.Transaction, now time.Time) error {
+	var budgetIDs []primitive.ObjectID
+	for _, alloc := range txn.Allocations() {
+		if !alloc.BudgetID.IsZero() && !slices.Contains(budgetIDs, alloc.BudgetID) {
+			budgetIDs = append(budgetIDs, alloc.BudgetID)
+		}
+	}
+
+	var errs []error
+	var recurring *bool
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.AccountID.IsZero() && rule.AccountID != txn.AccountID {
+			continue
+		}
+		switch rule.Kind {
+		case models.AlertLargeTransaction:
+			if float64(txn.Amount) < rule.Threshold {
+				continue
+			}
+			_, err := s.fire(ctx, rule, txn.ID.Hex(),
+				fmt.Sprintf("Large transaction: %s at %s", formatMoney(float64(txn.Amount)), txn.Name),
+				fmt.Sprintf("A %s of %s at %s on %s is over your alert amount of %s.",
+					txn.Type, formatMoney(float64(txn.Amount)), txn.Name, txn.TransactionDate.Format(alertDateLayout), formatMoney(rule.Threshold)),
+				now)
+			errs = append(errs, err)
+		case models.AlertRecurringCharge:
+			if recurring == nil {
+				history, err := s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+					AccountIDs: []primitive.ObjectID{txn.AccountID},
+					Start:      txn.TransactionDate.AddDate(0, 0, -recurringChargeHistoryDays),
+					End:        txn.TransactionDate,
+				})
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				isNew := NewRecurringCharge(txn, history)
+				recurring = &isNew
+			}
+			if !*recurring {
+				continue
+			}
+			_, err := s.fire(ctx, rule, "merchant:"+normalizeMerchant(txn.Name),
+				fmt.Sprintf("New recurring charge: %s", txn.Name),
+				fmt.Sprintf("%s charged %s on %s, about the same as a month ago. It looks like a new subscription or bill.",
+					txn.Name, formatMoney(float64(txn.Amount)), txn.TransactionDate.Format(alertDateLayout)),
+				now)
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, s.checkAccount(ctx, rules, txn.AccountID, now))
+	if len(budgetIDs) > 0 {
+		errs = append(errs, s.checkBudgets(ctx, rules, budgetIDs, now))
+	}
+	return errors.Join(errs...)
+}
+
+// checkAccount runs the balance floor rules that cover accountID
+func (s *AlertService) checkAccount(ctx context.Context, rules []models.AlertRule, accountID primitive.ObjectID, now time.Time) error {
+	var errs []error
+	for i := range rules {
+		if rules[i].Kind != models.AlertBalanceFloor {
+			continue
+		}
+		_, err := s.checkBalanceFloor(ctx, &rules[i], accountID, now)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// checkBudgets runs the budget threshold rules against the given budgets
+func (s *AlertService) checkBudgets(ctx context.Context, rules []models.AlertRule, budgetIDs []primitive.ObjectID, now time.Time) error {
+	var budgets []models.Budget
+	for i := range rules {
+		if rules[i].Kind != models.AlertBudgetThreshold {
+			continue
+		}
+		if budgets == nil {
+			for _, id := range budgetIDs {
+				budget, err := s.budgets.GetBudgetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				budgets = append(budgets, *budget)
+			}
+		}
+		if _, err := s.checkBudgetRule(ctx, &rules[i], budgets, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AlertService) checkBalanceFloor(ctx context.Context, rule *models.AlertRule, accountID primitive.ObjectID, now time.Time) (int, error) {
+	if !rule.AccountID.IsZero() && rule.AccountID != accountID {
+		return 0, nil
+	}
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	// a rule over every account only watches what the user holds, not what they owe
+	if rule.AccountID.IsZero() && account.IsLiability() {
+		return 0, nil
+	}
+	if account.AvailableBalance >= rule.Threshold {
+		return 0, nil
+	}
+
+	fired, err := s.fire(ctx, rule, accountID.Hex()+":"+now.UTC().Format(alertDayKeyLayout),
+		fmt.Sprintf("Low balance: %s", account.AccountLabel),
+		fmt.Sprintf("The available balance of %s is %s, under your floor of %s.",
+			account.AccountLabel, formatMoney(account.AvailableBalance), formatMoney(rule.Threshold)),
+		now)
+	return boolCount(fired), err
+}
+
+func (s *AlertService) checkBudgetRule(ctx context.Context, rule *models.AlertRule, budgets []models.Budget, now time.Time) (int, error) {
+	fired := 0
+	for i := range budgets {
+		budget := &budgets[i]
+		if (!rule.BudgetID.IsZero() && rule.BudgetID != budget.ID) || budget.MaximumSpending <= 0 {
+			continue
+		}
+		txns, err := budgetTransactions(ctx, s.access, s.transactions, budget)
+		if err != nil {
+			return fired, err
+		}
+		spent := budgetSpending(txns, budget.ID)
+		percent := spent / budget.MaximumSpending * 100
+		if percent < rule.Threshold {
+			continue
+		}
+
+		ok, err := s.fire(ctx, rule, budget.ID.Hex(),
+			fmt.Sprintf("Budget at %.0f%%", math.Floor(percent)),
+			fmt.Sprintf("You have spent %s of your %s budget for %s to %s.",
+				formatMoney(spent), formatMoney(budget.MaximumSpending), budget.StartDate.Format(alertDateLayout), budget.EndDate.Format(alertDateLayout)),
+			now)
+		if err != nil {
+			return fired, err
+		}
+		fired += boolCount(ok)
+	}
+	return fired, nil
+}
+
+// checkBills alerts for every unpaid bill, and every active recurring bill, due between
+// today and DaysBefore days from now
+func (s *AlertService) checkBills(ctx context.Context, rule *models.AlertRule, now time.Time) (int, error) {
+	bills, err := s.bills.ListBills(ctx, rule.UserID)
+	if err != nil {
+		return 0, err
+	}
+	items, err := s.recurring.ListItemsByUser(ctx, rule.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	today := truncateDay(now)
+	until := today.AddDate(0, 0, rule.DaysBefore)
+	fired := 0
+	due := func(id primitive.ObjectID, payee string, amount float64, date time.Time) error {
+		ok, err := s.fire(ctx, rule, id.Hex()+":"+date.Format(alertDayKeyLayout),
+			fmt.Sprintf("Bill due %s: %s", date.Format(alertDateLayout), payee),
+			fmt.Sprintf("%s of %s is due on %s.", payee, formatMoney(amount), date.Format(alertDateLayout)),
+			now)
+		fired += boolCount(ok)
+		return err
+	}
+
+	for i := range bills {
+		bill := &bills[i]
+		if !bill.Active || (!rule.AccountID.IsZero() && rule.AccountID != bill.AccountID) {
+			continue
+		}
+		for _, date := range bill.Schedule.Occurrences(today, until) {
+			if bill.IsPaid(date) {
+				continue
+			}
+			if err := due(bill.ID, bill.Payee, bill.Amount, date); err != nil {
+				return fired, err
+			}
+		}
+	}
+	for i := range items {
+		item := &items[i]
+		if item.Kind != models.RecurringBill || !item.Active || (!rule.AccountID.IsZero() && rule.AccountID != item.AccountID) {
+			continue
+		}
+		for _, date := range item.Schedule.Occurrences(today, until) {
+			if err := due(item.ID, item.Name, item.Amount, date); err != nil {
+				return fired, err
+			}
+		}
+	}
+	return fired, nil
+}
+
+// fire sends a notification for rule unless it already fired for key. The firing is
+// claimed before sending so concurrent instances alert once, and released again when no
+// channel delivers it, so the next evaluation retries. Failures of some channels are
+// logged rather than retried, so one broken channel cannot hold up the others.
+func (s *AlertService) fire(ctx context.Context, rule *models.AlertRule, key, title, body string, now time.Time) (bool, error) {
+	user, err := s.users.GetUserByID(ctx, rule.UserID)
+	if err != nil {
+		return false, err
+	}
+	fresh, err := s.alerts.RecordFiring(ctx, rule.ID, key, now)
+	if err != nil || !fresh {
+		return false, err
+	}
+
+	notification := &models.Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    rule.UserID,
+		RuleID:    rule.ID,
+		Kind:      rule.Kind,
+		Title:     title,
+		Body:      body,
+		CreatedAt: now.UTC(),
+	}
+	delivered := false
+	for _, name := range rule.Channels {
+		channel, ok := s.channels[name]
+		if !ok {
+			log.Printf("alert rule %s names unavailable channel %s", rule.ID.Hex(), name)
+			continue
+		}
+		if err := channel.Send(ctx, user, notification); err != nil {
+			log.Printf("alert rule %s could not notify by %s: %v", rule.ID.Hex(), name, err)
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		if err := s.alerts.ReleaseFiring(ctx, rule.ID, key); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *AlertService) ruleBudgets(ctx context.Context, rule *models.AlertRule, now time.Time) ([]models.Budget, error) {
+	if rule.BudgetID.IsZero() {
+		return s.budgets.ListActiveBudgets(ctx, rule.UserID, now)
+	}
+	budget, err := s.budgets.GetBudgetByID(ctx, rule.BudgetID)
+	if err != nil {
+		return nil, err
+	}
+	return []models.Budget{*budget}, nil
+}
+
+func (s *AlertService) getRule(ctx context.Context, userID, id primitive.ObjectID) (*models.AlertRule, error) {
+	rule, err := s.alerts.GetRuleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	if rule.UserID != userID {
+		return nil, ErrAlertNotFound
+	}
+	return rule, nil
+}
+
+func (s *AlertService) validate(ctx context.Context, rule *models.AlertRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	switch rule.Kind {
+	case models.AlertLargeTransaction:
+		if rule.Threshold <= 0 {
+			return ErrInvalidAlert
+		}
+	case models.AlertBudgetThreshold:
+		if rule.Threshold <= 0 || rule.Threshold > alertMaxBudgetPercent {
+			return ErrInvalidAlert
+		}
+	case models.AlertBillDue:
+		if rule.DaysBefore < 0 || rule.DaysBefore > AlertMaxBillDays {
+			return ErrInvalidAlert
+		}
+	case models.AlertBalanceFloor, models.AlertRecurringCharge:
+	default:
+		return ErrInvalidAlert
+	}
+	if rule.Name == "" {
+		rule.Name = rule.Kind
+	}
+
+	if len(rule.Channels) == 0 {
+		rule.Channels = []string{models.ChannelInApp}
+	}
+	rule.Channels = slices.Compact(slices.Sorted(slices.Values(rule.Channels)))
+	for _, name := range rule.Channels {
+		if _, ok := s.channels[name]; !ok {
+			return ErrUnknownChannel
+		}
+	}
+
+	if !rule.AccountID.IsZero() {
+		if err := s.access.CheckAccount(ctx, rule.UserID, rule.AccountID, AccessRead); err != nil {
+			return err
+		}
+	}
+	if !rule.BudgetID.IsZero() {
+		budget, err := s.budgets.GetBudgetByID(ctx, rule.BudgetID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrBudgetNotFound
+			}
+			return err
+		}
+		if err := s.access.CheckBudget(ctx, rule.UserID, budget, AccessRead); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewRecurringCharge reports whether txn is the second monthly charge from its merchant:
+// history, the account's recent transactions, holds a similar debit from the same
+// merchant about a month earlier and nothing from it before that
+func NewRecurringCharge(txn *models.Transaction, history []models.Transaction) bool {
+	if txn.Type != models.TransactionTypeDebit || txn.IsTransfer() {
+		return false
+	}
+	merchant := normalizeMerchant(txn.Name)
+	if merchant == "" {
+		return false
+	}
+
+	repeat := false
+	for i := range history {
+		prior := &history[i]
+		if prior.ID == txn.ID || prior.Type != models.TransactionTypeDebit ||
+			prior.EffectiveStatus() == models.TransactionStatusVoid || normalizeMerchant(prior.Name) != merchant {
+			continue
+		}
+		gap := txn.TransactionDate.Sub(prior.TransactionDate)
+		if gap > recurringChargeMaxGap {
+			return false
+		}
+		if gap >= recurringChargeMinGap && math.Abs(float64(prior.Amount-txn.Amount)) <= recurringChargeTolerance*float64(txn.Amount) {
+			repeat = true
+		}
+	}
+	return repeat
+}
+
+func formatMoney(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.2f", -amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,658 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrInvalidArchive      = errors.New("Error: Not A Valid TrackMe Archive")
+	ErrArchiveVersion      = errors.New("Error: Archive Version Is Not Supported")
+	ErrArchiveChecksum     = errors.New("Error: Archive Checksum Does Not Match")
+	ErrInvalidImportMode   = errors.New("Error: Import Mode Must Be restore Or merge")
+	ErrArchiveUserNotEmpty = errors.New("Error: Restore Needs A User Without Accounts, Budgets, Goals Or Rules")
+)
+
+// Archive import modes. Restore fills a user that has no data yet and brings back the
+// profile; merge adds the archive's records next to what the user already has.
+const (
+	ArchiveRestore = "restore"
+	ArchiveMerge   = "merge"
+)
+
+const (
+	// ArchiveFormat and ArchiveVersion identify the archive layout in its manifest.
+	// Version bumps are for changes older importers can't read.
+	ArchiveFormat  = "trackme-archive"
+	ArchiveVersion = 1
+
+	// ArchiveMaxBytes caps the size of an uploaded archive
+	ArchiveMaxBytes = 256 << 20
+
+	archiveManifest        = "manifest.json"
+	archiveProfile         = "profile.json"
+	archiveAccounts        = "accounts.json"
+	archiveBudgetTemplates = "budget_templates.json"
+	archiveBudgets         = "budgets.json"
+	archiveGoals           = "goals.json"
+	archiveAlertRules      = "alert_rules.json"
+	archiveTransactions    = "transactions.ndjson"
+
+	// archiveMaxEntryBytes caps how far any one entry may decompress
+	archiveMaxEntryBytes = 256 << 20
+	// archiveImportBatch is how many transactions are inserted per round trip
+	archiveImportBatch = 500
+)
+
+// archiveEntries are the files every version 1 archive holds besides its manifest
+var archiveEntries = []string{
+	archiveProfile, archiveAccounts, archiveBudgetTemplates, archiveBudgets, archiveGoals, archiveAlertRules, archiveTransactions,
+}
+
+// ArchiveManifest is written last into an archive and lists every other entry with its
+// record count and SHA-256, so an import can verify the archive before touching anything
+type ArchiveManifest struct {
+	Format    string             `json:"format"`
+	Version   int                `json:"version"`
+	CreatedAt time.Time          `json:"created_at"`
+	UserID    primitive.ObjectID `json:"user_id"`
+	Entries   []ArchiveEntry     `json:"entries"`
+}
+
+type ArchiveEntry struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// ArchiveProfile is the part of a user an archive carries. Role, two-factor settings and
+// the login identity belong to the instance and stay behind.
+type ArchiveProfile struct {
+	Username    string  `json:"username"`
+	Email       string  `json:"email"`
+	NetWorth    float64 `json:"net_worth"`
+	CreditScore int     `json:"credit_score"`
+}
+
+// ArchiveImportResult counts the records an import created
+type ArchiveImportResult struct {
+	Mode            string `json:"mode"`
+	Accounts        int    `json:"accounts"`
+	Transactions    int    `json:"transactions"`
+	Budgets         int    `json:"budgets"`
+	BudgetTemplates int    `json:"budget_templates"`
+	Goals           int    `json:"goals"`
+	AlertRules      int    `json:"alert_rules"`
+}
+
+// ArchiveService moves everything a user owns between TrackMe instances as one zip archive
+type ArchiveService struct {
+	users        repository.UserRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	budgets      repository.BudgetRepository
+	templates    repository.BudgetTemplateRepository
+	goals        repository.GoalRepository
+	alerts       repository.AlertRepository
+	txRunner     repository.TxRunner
+}
+
+func NewArchiveService(users repository.UserRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository,
+	budgets repository.BudgetRepository, templates repository.BudgetTemplateRepository, goals repository.GoalRepository,
+	alerts repository.AlertRepository, txRunner repository.TxRunner) *ArchiveService {
+	return &ArchiveService{
+		users:        users,
+		accounts:     accounts,
+		transactions: transactions,
+		budgets:      budgets,
+		templates:    templates,
+		goals:        goals,
+		alerts:       alerts,
+		txRunner:     txRunner,
+	}
+}
+
+// ArchiveExport writes one user's archive. Everything but the transactions is loaded when
+// the export is prepared; transactions are streamed while the archive is written.
+type ArchiveExport struct {
+	userID       primitive.ObjectID
+	profile      ArchiveProfile
+	accounts     []models.Account
+	templates    []models.BudgetTemplate
+	budgets      []models.Budget
+	goals        []models.SavingsGoal
+	rules        []models.AlertRule
+	transactions repository.TransactionRepository
+}
+
+// Export prepares an archive of the accounts userID owns and the transactions on them,
+// including void ones, and of their budgets, budget templates, goals and alert rules.
+// Accounts shared through a household stay with their owner.
+func (s *ArchiveService) Export(ctx context.Context, userID primitive.ObjectID) (*ArchiveExport, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	export := &ArchiveExport{
+		userID:       userID,
+		profile:      ArchiveProfile{Username: user.Username, Email: user.Email, NetWorth: user.NetWorth, CreditScore: user.CreditScore},
+		accounts:     []models.Account{},
+		transactions: s.transactions,
+	}
+
+	// only owned accounts leave with the archive; the profile's account list grants nothing
+	owned, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range owned {
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			return nil, err
+		}
+		if account.OwnerID != userID {
+			continue
+		}
+		// the institution link only means something on this instance
+		account.ConnectionID = primitive.NilObjectID
+		export.accounts = append(export.accounts, *account)
+	}
+
+	if export.templates, err = s.templates.ListTemplates(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.budgets, err = s.budgets.ListBudgets(ctx, userID, time.Time{}, time.Time{}); err != nil {
+		return nil, err
+	}
+	if export.goals, err = s.goals.ListGoalsByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.rules, err = s.alerts.ListRules(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Write writes the archive to w, hashing each entry on the way and finishing with the manifest
+func (e *ArchiveExport) Write(ctx context.Context, w io.Writer, now time.Time) error {
+	archive := zip.NewWriter(w)
+	manifest := ArchiveManifest{Format: ArchiveFormat, Version: ArchiveVersion, CreatedAt: now, UserID: e.userID}
+
+	// writeEntry creates name and hands fill a writer that also feeds the entry's checksum
+	writeEntry := func(name string, fill func(w io.Writer) (int, error)) error {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		records, err := fill(io.MultiWriter(entry, hash))
+		if err != nil {
+			return err
+		}
+		manifest.Entries = append(manifest.Entries, ArchiveEntry{Name: name, Records: records, SHA256: hex.EncodeToString(hash.Sum(nil))})
+		return nil
+	}
+	writeJSON := func(name string, records int, v any) error {
+		return writeEntry(name, func(w io.Writer) (int, error) {
+			return records, json.NewEncoder(w).Encode(v)
+		})
+	}
+
+	if err := writeJSON(archiveProfile, 1, e.profile); err != nil {
+		return err
+	}
+	if err := writeJSON(archiveAccounts, len(e.accounts), e.accounts); err != nil {
+		return err
+	}
+	if err := writeJSON(archiveBudgetTemplates, len(e.templates), e.templates); err != nil {
+		return err
+	}
+	if err := writeJSON(archiveBudgets, len(e.budgets), e.budgets); err != nil {
+		return err
+	}
+	if err := writeJSON(archiveGoals, len(e.goals), e.goals); err != nil {
+		return err
+	}
+	if err := writeJSON(archiveAlertRules, len(e.rules), e.rules); err != nil {
+		return err
+	}
+
+	err := writeEntry(archiveTransactions, func(w io.Writer) (int, error) {
+		count := 0
+		if len(e.accounts) == 0 {
+			return count, nil
+		}
+		accountIDs := make([]primitive.ObjectID, 0, len(e.accounts))
+		for _, account := range e.accounts {
+			accountIDs = append(accountIDs, account.ID)
+		}
+		encoder := json.NewEncoder(w)
+		filter := repository.TransactionFilter{AccountIDs: accountIDs, IncludeVoid: true}
+		err := e.transactions.StreamTransactions(ctx, filter, func(txn *models.Transaction) error {
+			count++
+			return encoder.Encode(txn)
+		})
+		return count, err
+	})
+	if err != nil {
+		return err
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: archiveManifest, Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// archiveContents is a verified archive with everything but its transactions decoded
+type archiveContents struct {
+	archive   *zip.Reader
+	manifest  ArchiveManifest
+	profile   ArchiveProfile
+	accounts  []models.Account
+	templates []models.BudgetTemplate
+	budgets   []models.Budget
+	goals     []models.SavingsGoal
+	rules     []models.AlertRule
+}
+
+// Import reads an archive of size bytes written by Export and adds its records to userID.
+// Every record gets a new ID and references between records are rewritten to match, so the
+// same archive can be merged into a user on the instance it came from. The whole archive is
+// verified against its manifest before anything is written.
+//
+// Transactions go in first, in batches, on new account IDs nobody can reach yet. The
+// accounts and every other record follow in one short transaction that makes the import
+// visible at once. When anything fails the transactions already written are deleted again,
+// so a large archive never has to fit in a single database transaction. Imported
+// transactions don't fire transaction events.
+func (s *ArchiveService) Import(ctx context.Context, userID primitive.ObjectID, mode string, archive io.ReaderAt, size int64) (*ArchiveImportResult, error) {
+	if mode != ArchiveRestore && mode != ArchiveMerge {
+		return nil, ErrInvalidImportMode
+	}
+
+	contents, err := readArchive(archive, size)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if mode == ArchiveRestore {
+		empty, err := s.isEmpty(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if !empty {
+			return nil, ErrArchiveUserNotEmpty
+		}
+	}
+
+	// ids maps every archived account, budget and template to its new ID; references to
+	// anything the archive doesn't hold are dropped
+	ids := map[primitive.ObjectID]primitive.ObjectID{}
+	for _, account := range contents.accounts {
+		ids[account.ID] = primitive.NewObjectID()
+	}
+	for _, template := range contents.templates {
+		ids[template.ID] = primitive.NewObjectID()
+	}
+	for _, budget := range contents.budgets {
+		ids[budget.ID] = primitive.NewObjectID()
+	}
+	ref := func(id primitive.ObjectID) primitive.ObjectID {
+		if id.IsZero() {
+			return id
+		}
+		return ids[id]
+	}
+
+	accounts := make([]models.Account, 0, len(contents.accounts))
+	accountIDs := make([]primitive.ObjectID, 0, len(contents.accounts))
+	for _, account := range contents.accounts {
+		account.ID = ids[account.ID]
+		account.OwnerID = userID
+		account.HouseholdIDs = nil
+		account.ConnectionID = primitive.NilObjectID
+		account.AccountNumber, account.RoutingNumber = "", ""
+		account.AccountNumberSealed, account.RoutingNumberSealed = nil, nil
+		accounts = append(accounts, account)
+		accountIDs = append(accountIDs, account.ID)
+	}
+	templates := make([]models.BudgetTemplate, 0, len(contents.templates))
+	for _, template := range contents.templates {
+		template.ID = ids[template.ID]
+		template.UserID = userID
+		templates = append(templates, template)
+	}
+	budgets := make([]models.Budget, 0, len(contents.budgets))
+	for _, budget := range contents.budgets {
+		budget.ID = ids[budget.ID]
+		budget.UserID = userID
+		budget.TemplateID = ref(budget.TemplateID)
+		budgets = append(budgets, budget)
+	}
+	goals := make([]models.SavingsGoal, 0, len(contents.goals))
+	for _, goal := range contents.goals {
+		goal.ID = primitive.NewObjectID()
+		goal.UserID = userID
+		accountIDs := []primitive.ObjectID{}
+		for _, id := range goal.AccountIDs {
+			if id = ref(id); !id.IsZero() {
+				accountIDs = append(accountIDs, id)
+			}
+		}
+		goal.AccountIDs = accountIDs
+		goals = append(goals, goal)
+	}
+	rules := make([]models.AlertRule, 0, len(contents.rules))
+	for _, rule := range contents.rules {
+		rule.ID = primitive.NewObjectID()
+		rule.UserID = userID
+		rule.AccountID = ref(rule.AccountID)
+		rule.BudgetID = ref(rule.BudgetID)
+		rules = append(rules, rule)
+	}
+
+	result := &ArchiveImportResult{
+		Mode:            mode,
+		Accounts:        len(accounts),
+		Budgets:         len(budgets),
+		BudgetTemplates: len(templates),
+		Goals:           len(goals),
+		AlertRules:      len(rules),
+	}
+
+	result.Transactions, err = s.importTransactions(ctx, userID, contents, ref)
+	if err == nil {
+		err = s.txRunner.WithTransaction(ctx, func(ctx context.Context) error {
+			return s.importRecords(ctx, userID, mode, user, contents.profile, accounts, templates, budgets, goals, rules)
+		})
+	}
+	if err != nil {
+		// the request may have been cancelled, which must not stop the clean-up
+		if cleanupErr := s.transactions.DeleteByAccounts(context.WithoutCancel(ctx), accountIDs); cleanupErr != nil {
+			log.Printf("archive import for user %s left transactions behind: %v", userID.Hex(), cleanupErr)
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// importRecords creates everything but the transactions. It runs inside a transaction and
+// may be retried, so it writes the prepared records as they are.
+func (s *ArchiveService) importRecords(ctx context.Context, userID primitive.ObjectID, mode string, user *models.User, profile ArchiveProfile,
+	accounts []models.Account, templates []models.BudgetTemplate, budgets []models.Budget, goals []models.SavingsGoal, rules []models.AlertRule) error {
+	for i := range accounts {
+		account := accounts[i]
+		if err := s.accounts.CreateAccount(ctx, &account); err != nil {
+			return err
+		}
+		if err := s.users.AddAccount(ctx, userID, account.ID); err != nil {
+			return err
+		}
+	}
+	if mode == ArchiveRestore {
+		restored := *user
+		restored.NetWorth = profile.NetWorth
+		restored.CreditScore = profile.CreditScore
+		if _, err := s.users.UpdateUser(ctx, userID, &restored); err != nil {
+			return err
+		}
+	}
+
+	for i := range templates {
+		template := templates[i]
+		if err := s.templates.CreateTemplate(ctx, &template); err != nil {
+			return err
+		}
+	}
+	for i := range budgets {
+		budget := budgets[i]
+		if err := s.budgets.CreateBudget(ctx, &budget); err != nil {
+			return err
+		}
+	}
+	for i := range goals {
+		goal := goals[i]
+		if err := s.goals.CreateGoal(ctx, &goal); err != nil {
+			return err
+		}
+	}
+	for i := range rules {
+		rule := rules[i]
+		if err := s.alerts.CreateRule(ctx, &rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importTransactions creates the archive's transactions on their new accounts. Transfer IDs
+// get new values too, shared by every transaction that shared the old one. Reconciliations
+// are not archived, so imported transactions start unreconciled rather than locked to one.
+// They are inserted archiveImportBatch at a time, outside any database transaction.
+func (s *ArchiveService) importTransactions(ctx context.Context, userID primitive.ObjectID, contents *archiveContents,
+	ref func(id primitive.ObjectID) primitive.ObjectID) (int, error) {
+	links := map[primitive.ObjectID]primitive.ObjectID{}
+	link := func(id primitive.ObjectID) primitive.ObjectID {
+		if id.IsZero() {
+			return id
+		}
+		if _, ok := links[id]; !ok {
+			links[id] = primitive.NewObjectID()
+		}
+		return links[id]
+	}
+
+	entry, err := contents.archive.Open(archiveTransactions)
+	if err != nil {
+		return 0, ErrInvalidArchive
+	}
+	defer entry.Close()
+
+	count := 0
+	batch := make([]models.Transaction, 0, archiveImportBatch)
+	decoder := json.NewDecoder(io.LimitReader(entry, archiveMaxEntryBytes))
+	for {
+		var txn models.Transaction
+		if err := decoder.Decode(&txn); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return count, ErrInvalidArchive
+		}
+
+		txn.ID = primitive.NewObjectID()
+		if txn.AccountID = ref(txn.AccountID); txn.AccountID.IsZero() {
+			return count, ErrInvalidArchive
+		}
+		txn.BudgetID = ref(txn.BudgetID)
+		for i := range txn.Splits {
+			txn.Splits[i].BudgetID = ref(txn.Splits[i].BudgetID)
+		}
+		txn.TransferID = link(txn.TransferID)
+		txn.ReconciliationID = primitive.NilObjectID
+		txn.CreatedBy = userID
+
+		batch = append(batch, txn)
+		if len(batch) == archiveImportBatch {
+			if err := s.transactions.CreateTransactions(ctx, batch); err != nil {
+				return count, err
+			}
+			count += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := s.transactions.CreateTransactions(ctx, batch); err != nil {
+		return count, err
+	}
+	count += len(batch)
+
+	if count != archiveRecords(contents.manifest, archiveTransactions) {
+		return count, ErrInvalidArchive
+	}
+	return count, nil
+}
+
+// isEmpty reports whether user has nothing a restore could collide with
+func (s *ArchiveService) isEmpty(ctx context.Context, user *models.User) (bool, error) {
+	owned, err := userAccountIDs(ctx, s.accounts, user.ID)
+	if err != nil || len(owned) > 0 {
+		return false, err
+	}
+	budgets, err := s.budgets.ListBudgets(ctx, user.ID, time.Time{}, time.Time{})
+	if err != nil || len(budgets) > 0 {
+		return false, err
+	}
+	templates, err := s.templates.ListTemplates(ctx, user.ID)
+	if err != nil || len(templates) > 0 {
+		return false, err
+	}
+	goals, err := s.goals.ListGoalsByUser(ctx, user.ID)
+	if err != nil || len(goals) > 0 {
+		return false, err
+	}
+	rules, err := s.alerts.ListRules(ctx, user.ID)
+	if err != nil || len(rules) > 0 {
+		return false, err
+	}
+	return true, nil
+}
+
+// readArchive opens the size bytes of r as an archive, checks its manifest and every
+// entry's checksum and decodes all entries but the transactions
+func readArchive(r io.ReaderAt, size int64) (*archiveContents, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	contents := &archiveContents{archive: archive}
+
+	raw, err := readArchiveEntry(archive, archiveManifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &contents.manifest); err != nil || contents.manifest.Format != ArchiveFormat {
+		return nil, ErrInvalidArchive
+	}
+	if contents.manifest.Version < 1 || contents.manifest.Version > ArchiveVersion {
+		return nil, ErrArchiveVersion
+	}
+
+	entries := map[string][]byte{}
+	for _, listed := range contents.manifest.Entries {
+		if !slices.Contains(archiveEntries, listed.Name) {
+			continue
+		}
+		// transactions are decoded again while importing, so they are only hashed here
+		raw, sum, err := verifyArchiveEntry(archive, listed.Name, listed.Name != archiveTransactions)
+		if err != nil {
+			return nil, err
+		}
+		if sum != listed.SHA256 {
+			return nil, ErrArchiveChecksum
+		}
+		entries[listed.Name] = raw
+	}
+	for _, name := range archiveEntries {
+		if _, ok := entries[name]; !ok {
+			return nil, ErrInvalidArchive
+		}
+	}
+
+	if json.Unmarshal(entries[archiveProfile], &contents.profile) != nil ||
+		json.Unmarshal(entries[archiveAccounts], &contents.accounts) != nil ||
+		json.Unmarshal(entries[archiveBudgetTemplates], &contents.templates) != nil ||
+		json.Unmarshal(entries[archiveBudgets], &contents.budgets) != nil ||
+		json.Unmarshal(entries[archiveGoals], &contents.goals) != nil ||
+		json.Unmarshal(entries[archiveAlertRules], &contents.rules) != nil {
+		return nil, ErrInvalidArchive
+	}
+	counts := map[string]int{
+		archiveAccounts:        len(contents.accounts),
+		archiveBudgetTemplates: len(contents.templates),
+		archiveBudgets:         len(contents.budgets),
+		archiveGoals:           len(contents.goals),
+		archiveAlertRules:      len(contents.rules),
+	}
+	for name, count := range counts {
+		if count != archiveRecords(contents.manifest, name) {
+			return nil, ErrInvalidArchive
+		}
+	}
+
+	return contents, nil
+}
+
+// readArchiveEntry reads one entry whole, refusing entries that decompress past the limit
+func readArchiveEntry(archive *zip.Reader, name string) ([]byte, error) {
+	raw, _, err := verifyArchiveEntry(archive, name, true)
+	return raw, err
+}
+
+// verifyArchiveEntry hashes one entry, returning its contents as well when keep is set
+func verifyArchiveEntry(archive *zip.Reader, name string, keep bool) ([]byte, string, error) {
+	entry, err := archive.Open(name)
+	if err != nil {
+		return nil, "", ErrInvalidArchive
+	}
+	defer entry.Close()
+
+	hash := sha256.New()
+	var raw bytes.Buffer
+	var w io.Writer = hash
+	if keep {
+		w = io.MultiWriter(hash, &raw)
+	}
+	n, err := io.Copy(w, io.LimitReader(entry, archiveMaxEntryBytes+1))
+	if err != nil || n > archiveMaxEntryBytes {
+		return nil, "", ErrInvalidArchive
+	}
+	return raw.Bytes(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// archiveRecords is the record count the manifest gives for name
+func archiveRecords(manifest ArchiveManifest, name string) int {
+	for _, entry := range manifest.Entries {
+		if entry.Name == name {
+			return entry.Records
+		}
+	}
+	return -1
+}
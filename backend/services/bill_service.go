@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrBillNotFound = errors.New("Error: Bill Not Found")
+	ErrInvalidBill  = errors.New("Error: Bill Needs A Payee, A Positive Amount And A Valid Schedule")
+)
+
+const (
+	// billMatchDays is how many days before or after a due date a payment may land and
+	// still pay that bill
+	billMatchDays = 10
+	// billEstimateTolerance is how far, as a fraction, a payment may be from an
+	// estimated bill's amount
+	billEstimateTolerance = 0.25
+)
+
+// BillService manages users' bills and marks them paid as matching transactions arrive
+type BillService struct {
+	bills  repository.BillRepository
+	access AccessPolicy
+}
+
+func NewBillService(bills repository.BillRepository, access AccessPolicy) *BillService {
+	return &BillService{bills: bills, access: access}
+}
+
+func (s *BillService) ListBills(ctx context.Context, userID primitive.ObjectID) ([]models.Bill, error) {
+	bills, err := s.bills.ListBills(ctx, userID)
+	if bills == nil {
+		bills = []models.Bill{}
+	}
+	return bills, err
+}
+
+func (s *BillService) CreateBill(ctx context.Context, userID primitive.ObjectID, bill *models.Bill) error {
+	bill.ID = primitive.NilObjectID
+	bill.UserID = userID
+	bill.Payments = []models.BillPayment{}
+	bill.CreatedAt = time.Now().UTC()
+	if err := s.validate(ctx, bill); err != nil {
+		return err
+	}
+	return s.bills.CreateBill(ctx, bill)
+}
+
+// UpdateBill changes one of userID's bills, keeping its owner, creation time and payments
+func (s *BillService) UpdateBill(ctx context.Context, userID, id primitive.ObjectID, bill *models.Bill) error {
+	existing, err := s.getBill(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	bill.ID = existing.ID
+	bill.UserID = existing.UserID
+	bill.Payments = existing.Payments
+	bill.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, bill); err != nil {
+		return err
+	}
+
+	if err := s.bills.SaveBill(ctx, bill); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrBillNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *BillService) DeleteBill(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.bills.DeleteBill(ctx, id, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrBillNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// HandleEvent marks bills paid by newly created transactions
+func (s *BillService) HandleEvent(ctx context.Context, event models.Event) error {
+	if event.Type != models.EventTransactionCreated {
+		return nil
+	}
+	txn, ok := event.Data.(models.Transaction)
+	if !ok || txn.Type != models.TransactionTypeDebit {
+		return nil
+	}
+
+	bills, err := s.bills.ListActiveByAccount(ctx, txn.AccountID)
+	if err != nil {
+		return err
+	}
+	for i := range bills {
+		due, ok := MatchBillPayment(&bills[i], &txn)
+		if !ok {
+			continue
+		}
+		err := s.bills.RecordPayment(ctx, bills[i].ID, models.BillPayment{
+			DueDate:       due,
+			TransactionID: txn.ID,
+			Amount:        float64(txn.Amount),
+			PaidAt:        event.CreatedAt,
+		})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// paid meanwhile by another transaction
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// one payment pays one bill
+		return nil
+	}
+	return nil
+}
+
+// MatchBillPayment reports which unpaid due date of bill txn pays: a debit from the
+// bill's account, naming the payee, for the bill's amount (or near it when estimated),
+// within billMatchDays of the due date. The closest due date wins.
+func MatchBillPayment(bill *models.Bill, txn *models.Transaction) (time.Time, bool) {
+	if !bill.Active || txn.Type != models.TransactionTypeDebit || txn.IsTransfer() ||
+		txn.EffectiveStatus() == models.TransactionStatusVoid || txn.AccountID != bill.AccountID {
+		return time.Time{}, false
+	}
+	payee := normalizeMerchant(bill.Payee)
+	if payee == "" || !strings.Contains(normalizeMerchant(txn.Name), payee) {
+		return time.Time{}, false
+	}
+	diff := math.Abs(float64(txn.Amount) - bill.Amount)
+	if (bill.Estimated && diff > billEstimateTolerance*bill.Amount) || (!bill.Estimated && diff >= 0.005) {
+		return time.Time{}, false
+	}
+
+	day := truncateDay(txn.TransactionDate)
+	var match time.Time
+	for _, due := range bill.Schedule.Occurrences(day.AddDate(0, 0, -billMatchDays), day.AddDate(0, 0, billMatchDays)) {
+		if bill.IsPaid(due) {
+			continue
+		}
+		if match.IsZero() || absDuration(due.Sub(day)) < absDuration(match.Sub(day)) {
+			match = due
+		}
+	}
+	return match, !match.IsZero()
+}
+
+func (s *BillService) getBill(ctx context.Context, userID, id primitive.ObjectID) (*models.Bill, error) {
+	bill, err := s.bills.GetBillByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrBillNotFound
+		}
+		return nil, err
+	}
+	if bill.UserID != userID {
+		return nil, ErrBillNotFound
+	}
+	return bill, nil
+}
+
+func (s *BillService) validate(ctx context.Context, bill *models.Bill) error {
+	bill.Payee = strings.TrimSpace(bill.Payee)
+	if bill.Payee == "" || bill.Amount <= 0 || !bill.Schedule.Valid() {
+		return ErrInvalidBill
+	}
+	return s.access.CheckAccount(ctx, bill.UserID, bill.AccountID, AccessRead)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var ErrBudgetNotFound = errors.New("Error: Budget Not Found")
+
+// budgetSettleWindow is how long after a budget ends its status keeps being refreshed,
+// so transactions that post late still count
+const budgetSettleWindow = 7 * 24 * time.Hour
+
+// BudgetEvaluation is the spending recorded against a budget over its period
+type BudgetEvaluation struct {
+	BudgetID        primitive.ObjectID `json:"budget_id"`
+	Spent           float64            `json:"spent"`
+	Remaining       float64            `json:"remaining"`
+	MinimumSpending float64            `json:"minimum_spending"`
+	MaximumSpending float64            `json:"maximum_spending"`
+	IsMeetingBudget bool               `json:"is_meeting_budget"`
+}
+
+// BudgetStatusResult summarizes one status refresh run
+type BudgetStatusResult struct {
+	Budgets  int `json:"budgets"`
+	Changed  int `json:"changed"`
+	Exceeded int `json:"exceeded"`
+}
+
+type BudgetService struct {
+	budgets      repository.BudgetRepository
+	transactions repository.TransactionRepository
+	access       AccessPolicy
+	events       *EventBus
+}
+
+func NewBudgetService(budgets repository.BudgetRepository, transactions repository.TransactionRepository, access AccessPolicy) *BudgetService {
+	return &BudgetService{budgets: budgets, transactions: transactions, access: access}
+}
+
+// SetEventBus publishes budget.exceeded events on bus
+func (s *BudgetService) SetEventBus(bus *EventBus) {
+	s.events = bus
+}
+
+// Evaluate totals the allocations charged to the budget within its dates and
+// reports whether spending is inside the budget's bounds. Split transactions
+// only count the portion allocated to this budget; refunds reduce spending.
+// It only reads; RefreshStatuses stores the status.
+func (s *BudgetService) Evaluate(ctx context.Context, userID, budgetID primitive.ObjectID) (*BudgetEvaluation, error) {
+	budget, err := s.budgets.GetBudgetByID(ctx, budgetID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.CheckBudget(ctx, userID, budget, AccessRead); err != nil {
+		return nil, err
+	}
+
+	txns, err := budgetTransactions(ctx, s.access, s.transactions, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateBudget(budget, txns), nil
+}
+
+// RefreshStatuses re-evaluates every budget running at now or ended within the settle
+// window, stores each IsMeetingBudget that changed and publishes budget.exceeded when
+// a budget goes over its maximum. The budget_periods job runs it.
+func (s *BudgetService) RefreshStatuses(ctx context.Context, now time.Time) (*BudgetStatusResult, error) {
+	budgets, err := s.budgets.ListAllBudgets(ctx, now.Add(-budgetSettleWindow), now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BudgetStatusResult{}
+	for i := range budgets {
+		budget := &budgets[i]
+		txns, err := budgetTransactions(ctx, s.access, s.transactions, budget)
+		if err != nil {
+			return result, err
+		}
+		result.Budgets++
+
+		eval := evaluateBudget(budget, txns)
+		if eval.IsMeetingBudget == budget.IsMeetingBudget {
+			continue
+		}
+		if err := s.budgets.UpdateBudgetStatus(ctx, budget.ID, eval.IsMeetingBudget); err != nil {
+			return result, err
+		}
+		result.Changed++
+		if budget.MaximumSpending > 0 && eval.Spent > budget.MaximumSpending {
+			s.events.Publish(ctx, budget.UserID, models.EventBudgetExceeded, eval)
+			result.Exceeded++
+		}
+	}
+
+	return result, nil
+}
+
+// budgetTransactions lists the transactions charged to budget within its dates. Only
+// accounts the budget's owner can read count, so nobody can move someone else's
+// budget by allocating their own transactions to it.
+func budgetTransactions(ctx context.Context, access AccessPolicy, transactions repository.TransactionRepository, budget *models.Budget) ([]models.Transaction, error) {
+	accountIDs, err := access.AccountIDs(ctx, budget.UserID)
+	if err != nil || len(accountIDs) == 0 {
+		return nil, err
+	}
+	return transactions.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs:       accountIDs,
+		BudgetID:         budget.ID,
+		Start:            budget.StartDate,
+		End:              budget.EndDate,
+		ExcludeTransfers: true,
+	})
+}
+
+// evaluateBudget measures the budget's transactions against its bounds
+func evaluateBudget(budget *models.Budget, txns []models.Transaction) *BudgetEvaluation {
+	eval := &BudgetEvaluation{
+		BudgetID:        budget.ID,
+		Spent:           budgetSpending(txns, budget.ID),
+		MinimumSpending: budget.MinimumSpending,
+		MaximumSpending: budget.MaximumSpending,
+	}
+	eval.Remaining = budget.MaximumSpending - eval.Spent
+	eval.IsMeetingBudget = eval.Spent >= budget.MinimumSpending &&
+		(budget.MaximumSpending <= 0 || eval.Spent <= budget.MaximumSpending)
+	return eval
+}
+
+func budgetSpending(txns []models.Transaction, budgetID primitive.ObjectID) float64 {
+	var spent float64
+	for i := range txns {
+		for _, alloc := range txns[i].Allocations() {
+			if alloc.BudgetID != budgetID {
+				continue
+			}
+			if txns[i].Type == models.TransactionTypeDebit {
+				spent += float64(alloc.Amount)
+			} else {
+				spent -= float64(alloc.Amount)
+			}
+		}
+	}
+	return spent
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("Error: Budget Template Not Found")
+	ErrInvalidTemplate  = errors.New("Error: Template Needs A Name, Valid Schedule And Spending Bounds")
+)
+
+// PeriodPerformance is the spending recorded in one generated budget period
+type PeriodPerformance struct {
+	BudgetID        primitive.ObjectID `json:"budget_id"`
+	StartDate       time.Time          `json:"start_date"`
+	EndDate         time.Time          `json:"end_date"`
+	Spent           float64            `json:"spent"`
+	MinimumSpending float64            `json:"minimum_spending"`
+	MaximumSpending float64            `json:"maximum_spending"`
+	Remaining       float64            `json:"remaining"`
+	IsMeetingBudget bool               `json:"is_meeting_budget"`
+	Current         bool               `json:"current"`
+}
+
+// TemplatePerformance summarizes every period of a template. The statistics only
+// cover completed periods; the period in progress is listed but not counted.
+type TemplatePerformance struct {
+	Template         models.BudgetTemplate `json:"template"`
+	Periods          []PeriodPerformance   `json:"periods"`
+	PeriodsCompleted int                   `json:"periods_completed"`
+	PeriodsMet       int                   `json:"periods_met"`
+	HitRate          float64               `json:"hit_rate"`
+	PeriodsOver      int                   `json:"periods_over"`
+	PeriodsUnder     int                   `json:"periods_under_minimum"`
+	TotalSpent       float64               `json:"total_spent"`
+	AverageSpent     float64               `json:"average_spent"`
+	CurrentStreak    int                   `json:"current_streak"`
+}
+
+// TemplateRunResult counts the budget periods generated by a run
+type TemplateRunResult struct {
+	Templates int `json:"templates"`
+	Budgets   int `json:"budgets"`
+}
+
+type BudgetTemplateService struct {
+	templates    repository.BudgetTemplateRepository
+	budgets      repository.BudgetRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewBudgetTemplateService(templates repository.BudgetTemplateRepository, budgets repository.BudgetRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository) *BudgetTemplateService {
+	return &BudgetTemplateService{templates: templates, budgets: budgets, accounts: accounts, transactions: transactions}
+}
+
+func (s *BudgetTemplateService) ListTemplates(ctx context.Context, userID primitive.ObjectID) ([]models.BudgetTemplate, error) {
+	templates, err := s.templates.ListTemplates(ctx, userID)
+	if templates == nil {
+		templates = []models.BudgetTemplate{}
+	}
+	return templates, err
+}
+
+// CreateTemplate stores a template and generates its periods up to today
+func (s *BudgetTemplateService) CreateTemplate(ctx context.Context, userID primitive.ObjectID, template *models.BudgetTemplate) error {
+	template.ID = primitive.NilObjectID
+	template.UserID = userID
+	template.Active = true
+	template.GeneratedThrough = time.Time{}
+	template.CreatedAt = time.Now().UTC()
+	if !validTemplate(template) {
+		return ErrInvalidTemplate
+	}
+
+	if err := s.templates.CreateTemplate(ctx, template); err != nil {
+		return err
+	}
+	_, err := s.Materialize(ctx, template, template.CreatedAt)
+	return err
+}
+
+// UpdateTemplate changes the name, categories, bounds or active flag of a template.
+// Periods already generated keep their bounds, so history is preserved. The schedule
+// cannot change; create a new template instead.
+func (s *BudgetTemplateService) UpdateTemplate(ctx context.Context, userID, id primitive.ObjectID, changes *models.BudgetTemplate) (*models.BudgetTemplate, error) {
+	template, err := s.getTemplate(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Name = changes.Name
+	template.Categories = changes.Categories
+	template.MinimumSpending = changes.MinimumSpending
+	template.MaximumSpending = changes.MaximumSpending
+	template.TargetGoal = changes.TargetGoal
+	template.Active = changes.Active
+	if !validTemplate(template) {
+		return nil, ErrInvalidTemplate
+	}
+
+	if err := s.templates.SaveTemplate(ctx, template); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// Periods returns every budget generated from a template so far, oldest first. Reading
+// never generates periods; that is left to template creation and the scheduled run.
+func (s *BudgetTemplateService) Periods(ctx context.Context, userID, id primitive.ObjectID) ([]models.Budget, error) {
+	template, err := s.getTemplate(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.budgets.ListBudgetsByTemplate(ctx, template.ID)
+	if budgets == nil {
+		budgets = []models.Budget{}
+	}
+	return budgets, err
+}
+
+// Materialize creates a Budget for every period of an active template that has
+// started by asOf and has not been generated yet. Runs on the same template may overlap:
+// the unique index on (template_id, start_date) lets only one of them create each period,
+// and GeneratedThrough only advances from the value this run last saw, so a run that
+// falls behind stops rather than writing over the other's progress.
+func (s *BudgetTemplateService) Materialize(ctx context.Context, template *models.BudgetTemplate, asOf time.Time) (int, error) {
+	if !template.Active {
+		return 0, nil
+	}
+
+	created := 0
+	after := template.GeneratedThrough
+	if after.IsZero() {
+		after = template.Schedule.StartDate.Add(-time.Nanosecond)
+	}
+	for {
+		start, ok := template.Schedule.Next(after)
+		if !ok || start.After(asOf) {
+			break
+		}
+
+		budget := &models.Budget{
+			UserID:          template.UserID,
+			TemplateID:      template.ID,
+			MinimumSpending: template.MinimumSpending,
+			MaximumSpending: template.MaximumSpending,
+			TargetGoal:      template.TargetGoal,
+			StartDate:       start,
+			EndDate:         periodEnd(template.Schedule, start),
+		}
+		err := s.budgets.CreateBudget(ctx, budget)
+		switch {
+		case err == nil:
+			created++
+		case !mongo.IsDuplicateKeyError(err):
+			return created, err
+		}
+
+		if err := s.templates.AdvanceGeneratedThrough(ctx, template.ID, template.GeneratedThrough, start); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return created, nil
+			}
+			return created, err
+		}
+		template.GeneratedThrough = start
+		after = start
+	}
+
+	return created, nil
+}
+
+// MaterializeAll generates due periods for every active template
+func (s *BudgetTemplateService) MaterializeAll(ctx context.Context, asOf time.Time) (*TemplateRunResult, error) {
+	templates, err := s.templates.ListActiveTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TemplateRunResult{}
+	for i := range templates {
+		created, err := s.Materialize(ctx, &templates[i], asOf)
+		if err != nil {
+			return result, err
+		}
+		result.Templates++
+		result.Budgets += created
+	}
+
+	return result, nil
+}
+
+// Performance evaluates every generated period of a template against its spending.
+// It only reads; the budget_periods job keeps each period's IsMeetingBudget current.
+func (s *BudgetTemplateService) Performance(ctx context.Context, userID, id primitive.ObjectID) (*TemplatePerformance, error) {
+	template, err := s.getTemplate(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	budgets, err := s.budgets.ListBudgetsByTemplate(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	var txns []models.Transaction
+	if len(budgets) > 0 {
+		accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(accountIDs) > 0 {
+			txns, err = s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+				AccountIDs:       accountIDs,
+				Start:            budgets[0].StartDate,
+				End:              budgets[len(budgets)-1].EndDate,
+				ExcludeTransfers: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return TemplateReport(template, budgets, txns, now), nil
+}
+
+// TemplateReport measures each budget period of a template. A transaction counts toward
+// a period when it falls inside the period's dates and is either tagged with that
+// period's budget or, split by split, in one of the template's categories.
+func TemplateReport(template *models.BudgetTemplate, budgets []models.Budget, txns []models.Transaction, now time.Time) *TemplatePerformance {
+	categories := map[string]bool{}
+	for _, category := range template.Categories {
+		categories[strings.ToLower(category)] = true
+	}
+
+	report := &TemplatePerformance{Template: *template, Periods: []PeriodPerformance{}}
+	for _, budget := range budgets {
+		var spent float64
+		for i := range txns {
+			txn := &txns[i]
+			if txn.TransactionDate.Before(budget.StartDate) || txn.TransactionDate.After(budget.EndDate) {
+				continue
+			}
+			for _, alloc := range txn.Allocations() {
+				if alloc.BudgetID != budget.ID && !categories[strings.ToLower(alloc.Category)] {
+					continue
+				}
+				if txn.Type == models.TransactionTypeDebit {
+					spent += float64(alloc.Amount)
+				} else {
+					spent -= float64(alloc.Amount)
+				}
+			}
+		}
+
+		period := PeriodPerformance{
+			BudgetID:        budget.ID,
+			StartDate:       budget.StartDate,
+			EndDate:         budget.EndDate,
+			Spent:           roundCents(spent),
+			MinimumSpending: budget.MinimumSpending,
+			MaximumSpending: budget.MaximumSpending,
+			Remaining:       roundCents(budget.MaximumSpending - spent),
+			Current:         !budget.EndDate.Before(now),
+		}
+		over := budget.MaximumSpending > 0 && period.Spent > budget.MaximumSpending
+		under := period.Spent < budget.MinimumSpending
+		period.IsMeetingBudget = !over && !under
+		report.Periods = append(report.Periods, period)
+
+		if period.Current {
+			continue
+		}
+		report.PeriodsCompleted++
+		report.TotalSpent += period.Spent
+		switch {
+		case period.IsMeetingBudget:
+			report.PeriodsMet++
+			report.CurrentStreak++
+		case over:
+			report.PeriodsOver++
+			report.CurrentStreak = 0
+		default:
+			report.PeriodsUnder++
+			report.CurrentStreak = 0
+		}
+	}
+
+	report.TotalSpent = roundCents(report.TotalSpent)
+	if report.PeriodsCompleted > 0 {
+		report.HitRate = math.Round(float64(report.PeriodsMet)/float64(report.PeriodsCompleted)*10000) / 10000
+		report.AverageSpent = roundCents(report.TotalSpent / float64(report.PeriodsCompleted))
+	}
+	return report
+}
+
+// periodEnd is the instant before the next period starts, or the end of the schedule's last day
+func periodEnd(schedule models.Recurrence, start time.Time) time.Time {
+	if next, ok := schedule.Next(start); ok {
+		return next.Add(-time.Nanosecond)
+	}
+	return truncateDay(schedule.EndDate).Add(24*time.Hour - time.Nanosecond)
+}
+
+func (s *BudgetTemplateService) getTemplate(ctx context.Context, userID, id primitive.ObjectID) (*models.BudgetTemplate, error) {
+	template, err := s.templates.GetTemplateByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func validTemplate(template *models.BudgetTemplate) bool {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" || !template.Schedule.Valid() || template.Schedule.Frequency == models.FrequencyDaily {
+		return false
+	}
+	return template.MinimumSpending >= 0 && template.MaximumSpending >= 0 &&
+		(template.MaximumSpending == 0 || template.MinimumSpending <= template.MaximumSpending)
+}
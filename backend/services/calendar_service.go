@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrGrantNotFound        = errors.New("Error: Equity Grant Not Found")
+	ErrInvalidGrant         = errors.New("Error: Grant Needs Shares And A Vesting Schedule")
+	ErrInvalidCalendarRange = errors.New("Error: Calendar Range Must End After It Starts And Span At Most 400 Days")
+)
+
+// Calendar event kinds
+const (
+	CalendarBill   = "bill"
+	CalendarPayday = "payday"
+	CalendarVest   = "vest"
+)
+
+const (
+	// calendarMaxDays bounds how long a range one calendar request may cover
+	calendarMaxDays = 400
+	// calendarPastDays and calendarFutureDays make up the default range around today
+	calendarPastDays   = 30
+	calendarFutureDays = 90
+	icsDateLayout      = "20060102"
+	icsStampLayout     = "20060102T150405Z"
+	// icsLineOctets is the longest content line RFC 5545 allows before folding
+	icsLineOctets = 75
+)
+
+// CalendarEvent is one dated money event: a bill coming due, a payday or a vest
+type CalendarEvent struct {
+	Date      time.Time          `json:"date"`
+	Kind      string             `json:"kind"`
+	Title     string             `json:"title"`
+	Amount    float64            `json:"amount,omitempty"`
+	Shares    int                `json:"shares,omitempty"`
+	Estimated bool               `json:"estimated,omitempty"`
+	Paid      bool               `json:"paid,omitempty"`
+	SourceID  primitive.ObjectID `json:"source_id"`
+}
+
+// Calendar is every event between Start and End, in date order
+type Calendar struct {
+	Start  time.Time       `json:"start"`
+	End    time.Time       `json:"end"`
+	Events []CalendarEvent `json:"events"`
+}
+
+// CalendarService merges bills, paydays from recurring income and equity vests into one
+// calendar, and keeps the user's equity grants
+type CalendarService struct {
+	bills     repository.BillRepository
+	recurring repository.RecurringRepository
+	grants    repository.GrantRepository
+}
+
+func NewCalendarService(bills repository.BillRepository, recurring repository.RecurringRepository, grants repository.GrantRepository) *CalendarService {
+	return &CalendarService{bills: bills, recurring: recurring, grants: grants}
+}
+
+func (s *CalendarService) ListGrants(ctx context.Context, userID primitive.ObjectID) ([]models.EquityGrant, error) {
+	grants, err := s.grants.ListGrants(ctx, userID)
+	if grants == nil {
+		grants = []models.EquityGrant{}
+	}
+	return grants, err
+}
+
+func (s *CalendarService) CreateGrant(ctx context.Context, userID primitive.ObjectID, grant *models.EquityGrant) error {
+	grant.ID = primitive.NilObjectID
+	grant.UserID = userID
+	grant.Name = strings.TrimSpace(grant.Name)
+	grant.CreatedAt = time.Now().UTC()
+	if grant.IntervalMonths == 0 {
+		grant.IntervalMonths = 1
+	}
+	if grant.Name == "" || !grant.Valid() {
+		return ErrInvalidGrant
+	}
+	return s.grants.CreateGrant(ctx, grant)
+}
+
+func (s *CalendarService) DeleteGrant(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.grants.DeleteGrant(ctx, id, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrGrantNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Calendar returns the user's events from start through end. Zero dates default to a
+// range from 30 days ago to 90 days ahead.
+func (s *CalendarService) Calendar(ctx context.Context, userID primitive.ObjectID, start, end, now time.Time) (*Calendar, error) {
+	today := truncateDay(now)
+	if start.IsZero() {
+		start = today.AddDate(0, 0, -calendarPastDays)
+	}
+	if end.IsZero() {
+		end = today.AddDate(0, 0, calendarFutureDays)
+	}
+	if !end.After(start) || end.Sub(start) > calendarMaxDays*24*time.Hour {
+		return nil, ErrInvalidCalendarRange
+	}
+
+	bills, err := s.bills.ListBills(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.recurring.ListItemsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.grants.ListGrants(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Calendar{Start: start, End: end, Events: BuildCalendar(bills, items, grants, start, end)}, nil
+}
+
+// BuildCalendar lists the events of active bills, active income items and grants that fall
+// between start and end, by date and then kind
+func BuildCalendar(bills []models.Bill, items []models.RecurringItem, grants []models.EquityGrant, start, end time.Time) []CalendarEvent {
+	events := []CalendarEvent{}
+	for i := range bills {
+		bill := &bills[i]
+		if !bill.Active {
+			continue
+		}
+		for _, due := range bill.Schedule.Occurrences(start, end) {
+			events = append(events, CalendarEvent{
+				Date:      due,
+				Kind:      CalendarBill,
+				Title:     bill.Payee + " due",
+				Amount:    bill.Amount,
+				Estimated: bill.Estimated,
+				Paid:      bill.IsPaid(due),
+				SourceID:  bill.ID,
+			})
+		}
+	}
+	for i := range items {
+		item := &items[i]
+		if item.Kind != models.RecurringIncome || !item.Active {
+			continue
+		}
+		for _, payday := range item.Schedule.Occurrences(start, end) {
+			events = append(events, CalendarEvent{Date: payday, Kind: CalendarPayday, Title: item.Name, Amount: item.Amount, SourceID: item.ID})
+		}
+	}
+	for i := range grants {
+		grant := &grants[i]
+		for _, vest := range grant.Vests() {
+			if vest.Date.Before(start) || vest.Date.After(end) {
+				continue
+			}
+			events = append(events, CalendarEvent{
+				Date:     vest.Date,
+				Kind:     CalendarVest,
+				Title:    fmt.Sprintf("%s: %d shares vest", grant.Name, vest.Shares),
+				Shares:   vest.Shares,
+				SourceID: grant.ID,
+			})
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b CalendarEvent) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Title, b.Title))
+	})
+	return events
+}
+
+// FormatICS renders events as an iCalendar feed of all-day events. UIDs are derived from
+// each event's source and date, so calendar apps update events rather than duplicate them.
+func FormatICS(events []CalendarEvent, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(content string) {
+		writeICSLine(&buf, content)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//TrackMe//Money Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:TrackMe")
+	for _, event := range events {
+		day := event.Date.Format(icsDateLayout)
+		line("BEGIN:VEVENT")
+		line("UID:" + event.Kind + "-" + event.SourceID.Hex() + "-" + day + "@trackme")
+		line("DTSTAMP:" + stamp.UTC().Format(icsStampLayout))
+		line("DTSTART;VALUE=DATE:" + day)
+		line("DTEND;VALUE=DATE:" + event.Date.AddDate(0, 0, 1).Format(icsDateLayout))
+		line("SUMMARY:" + icsText(event.Title))
+		if description := calendarDescription(event); description != "" {
+			line("DESCRIPTION:" + icsText(description))
+		}
+		line("CATEGORIES:" + strings.ToUpper(event.Kind))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+func calendarDescription(event CalendarEvent) string {
+	switch event.Kind {
+	case CalendarBill:
+		description := formatMoney(event.Amount)
+		if event.Estimated {
+			description = "About " + description
+		}
+		if event.Paid {
+			description += " (paid)"
+		}
+		return description
+	case CalendarPayday:
+		return formatMoney(event.Amount)
+	}
+	return ""
+}
+
+// icsText escapes a TEXT value
+func icsText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// writeICSLine writes a content line, folding it into 75-octet pieces without splitting
+// a UTF-8 character
+func writeICSLine(buf *bytes.Buffer, content string) {
+	limit := icsLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(content[:cut])
+		buf.WriteString("\r\n ")
+		content = content[cut:]
+		// continuation lines start with a space, which counts toward their length
+		limit = icsLineOctets - 1
+	}
+	buf.WriteString(content)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultUtilizationThresholds are used until a user configures their own
+var DefaultUtilizationThresholds = []float64{0.3, 0.5, 0.75}
+
+var (
+	ErrInvalidCreditReading = errors.New("Error: Credit Score Must Be 300-850 From A Known Bureau")
+	ErrInvalidThresholds    = errors.New("Error: Thresholds Must Be Between 0 And 1")
+	ErrInvalidCreditLimit   = errors.New("Error: Credit Limit Cannot Be Negative")
+	ErrNotCreditCard        = errors.New("Error: Account Is Not A Credit Card")
+)
+
+// BureauTrend summarizes how one bureau's score has moved
+type BureauTrend struct {
+	Bureau        string    `json:"bureau"`
+	Latest        int       `json:"latest"`
+	LatestAt      time.Time `json:"latest_at"`
+	Previous      int       `json:"previous,omitempty"`
+	Change        int       `json:"change"`
+	Change90Days  int       `json:"change_90_days"`
+	Change365Days int       `json:"change_365_days"`
+	Min           int       `json:"min"`
+	Max           int       `json:"max"`
+	Average       float64   `json:"average"`
+	Readings      int       `json:"readings"`
+}
+
+// CardUtilization is the balance owed on a credit card relative to its limit
+type CardUtilization struct {
+	AccountID    primitive.ObjectID `json:"account_id"`
+	AccountLabel string             `json:"account_label"`
+	Balance      float64            `json:"balance"`
+	CreditLimit  float64            `json:"credit_limit"`
+	Utilization  float64            `json:"utilization"`
+}
+
+// UtilizationReport covers every credit card of a user
+type UtilizationReport struct {
+	Cards       []CardUtilization `json:"cards"`
+	TotalOwed   float64           `json:"total_owed"`
+	TotalLimit  float64           `json:"total_limit"`
+	Utilization float64           `json:"utilization"`
+	Thresholds  []float64         `json:"thresholds"`
+	Alerts      []CreditAlert     `json:"alerts"`
+}
+
+// CreditAlert flags utilization at or above a configured threshold
+type CreditAlert struct {
+	AccountID   primitive.ObjectID `json:"account_id,omitempty"`
+	Scope       string             `json:"scope"`
+	Utilization float64            `json:"utilization"`
+	Threshold   float64            `json:"threshold"`
+	Message     string             `json:"message"`
+}
+
+// CreditOverview is everything shown on the credit page
+type CreditOverview struct {
+	CurrentScore int                         `json:"current_score"`
+	History      []models.CreditScoreReading `json:"history"`
+	Trends       []BureauTrend               `json:"trends"`
+	Utilization  *UtilizationReport          `json:"utilization"`
+}
+
+type CreditService struct {
+	credit   repository.CreditRepository
+	users    repository.UserRepository
+	accounts repository.AccountRepository
+	access   AccessPolicy
+}
+
+func NewCreditService(credit repository.CreditRepository, users repository.UserRepository, accounts repository.AccountRepository, access AccessPolicy) *CreditService {
+	return &CreditService{credit: credit, users: users, accounts: accounts, access: access}
+}
+
+// RecordReading stores a dated bureau score. When it is the newest reading the
+// profile's CreditScore is updated to match.
+func (s *CreditService) RecordReading(ctx context.Context, reading *models.CreditScoreReading) error {
+	reading.Bureau = strings.ToLower(strings.TrimSpace(reading.Bureau))
+	if reading.Score < 300 || reading.Score > 850 || !knownBureau(reading.Bureau) {
+		return ErrInvalidCreditReading
+	}
+	reading.CreatedAt = time.Now().UTC()
+	if reading.ReportedAt.IsZero() {
+		reading.ReportedAt = reading.CreatedAt
+	}
+
+	readings, err := s.credit.ListReadings(ctx, reading.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.credit.CreateReading(ctx, reading); err != nil {
+		return err
+	}
+
+	for _, existing := range readings {
+		if existing.ReportedAt.After(reading.ReportedAt) {
+			return nil
+		}
+	}
+	if err := s.users.UpdateCreditScore(ctx, reading.UserID, reading.Score); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Overview returns score history, per-bureau trends and utilization with alerts
+func (s *CreditService) Overview(ctx context.Context, userID primitive.ObjectID) (*CreditOverview, error) {
+	readings, err := s.credit.ListReadings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if readings == nil {
+		readings = []models.CreditScoreReading{}
+	}
+
+	utilization, err := s.Utilization(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &CreditOverview{
+		History:     readings,
+		Trends:      creditTrends(readings, time.Now().UTC()),
+		Utilization: utilization,
+	}
+	if len(readings) > 0 {
+		overview.CurrentScore = readings[len(readings)-1].Score
+	}
+	return overview, nil
+}
+
+// Utilization compares the balance owed on each credit card with its limit
+func (s *CreditService) Utilization(ctx context.Context, userID primitive.ObjectID) (*UtilizationReport, error) {
+	accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := s.thresholds(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UtilizationReport{Cards: []CardUtilization{}, Thresholds: thresholds, Alerts: []CreditAlert{}}
+	for _, id := range accountIDs {
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			return nil, err
+		}
+		if account.AccountType != models.AccountTypeCreditCard || account.CreditLimit <= 0 {
+			continue
+		}
+
+		card := CardUtilization{
+			AccountID:    account.ID,
+			AccountLabel: account.AccountLabel,
+			Balance:      math.Max(-account.CurrentBalance, 0),
+			CreditLimit:  account.CreditLimit,
+		}
+		card.Utilization = ratio(card.Balance, card.CreditLimit)
+		report.Cards = append(report.Cards, card)
+		report.TotalOwed += card.Balance
+		report.TotalLimit += card.CreditLimit
+
+		if threshold, crossed := highestCrossed(thresholds, card.Utilization); crossed {
+			report.Alerts = append(report.Alerts, CreditAlert{
+				AccountID:   card.AccountID,
+				Scope:       "card",
+				Utilization: card.Utilization,
+				Threshold:   threshold,
+				Message:     card.AccountLabel + " utilization is above " + percent(threshold),
+			})
+		}
+	}
+
+	report.Utilization = ratio(report.TotalOwed, report.TotalLimit)
+	if threshold, crossed := highestCrossed(thresholds, report.Utilization); crossed {
+		report.Alerts = append(report.Alerts, CreditAlert{
+			Scope:       "overall",
+			Utilization: report.Utilization,
+			Threshold:   threshold,
+			Message:     "Overall credit utilization is above " + percent(threshold),
+		})
+	}
+
+	return report, nil
+}
+
+// SetThresholds replaces the user's utilization alert thresholds
+func (s *CreditService) SetThresholds(ctx context.Context, userID primitive.ObjectID, thresholds []float64) (*models.CreditSettings, error) {
+	for _, threshold := range thresholds {
+		if threshold <= 0 || threshold > 1 {
+			return nil, ErrInvalidThresholds
+		}
+	}
+	sorted := append([]float64{}, thresholds...)
+	sort.Float64s(sorted)
+
+	settings := &models.CreditSettings{UserID: userID, UtilizationThresholds: sorted, UpdatedAt: time.Now().UTC()}
+	if err := s.credit.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SetCreditLimit records the limit of a credit card account userID may write to
+func (s *CreditService) SetCreditLimit(ctx context.Context, userID, accountID primitive.ObjectID, limit float64) error {
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessWrite); err != nil {
+		return err
+	}
+
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+	if account.AccountType != models.AccountTypeCreditCard {
+		return ErrNotCreditCard
+	}
+	if limit < 0 {
+		return ErrInvalidCreditLimit
+	}
+	return s.accounts.SetCreditLimit(ctx, accountID, limit)
+}
+
+func (s *CreditService) thresholds(ctx context.Context, userID primitive.ObjectID) ([]float64, error) {
+	settings, err := s.credit.GetSettings(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return DefaultUtilizationThresholds, nil
+		}
+		return nil, err
+	}
+	if len(settings.UtilizationThresholds) == 0 {
+		return DefaultUtilizationThresholds, nil
+	}
+	return settings.UtilizationThresholds, nil
+}
+
+// creditTrends groups readings (oldest first) by bureau and measures their movement
+func creditTrends(readings []models.CreditScoreReading, now time.Time) []BureauTrend {
+	byBureau := map[string][]models.CreditScoreReading{}
+	var bureaus []string
+	for _, reading := range readings {
+		if _, ok := byBureau[reading.Bureau]; !ok {
+			bureaus = append(bureaus, reading.Bureau)
+		}
+		byBureau[reading.Bureau] = append(byBureau[reading.Bureau], reading)
+	}
+	sort.Strings(bureaus)
+
+	trends := []BureauTrend{}
+	for _, bureau := range bureaus {
+		series := byBureau[bureau]
+		latest := series[len(series)-1]
+		trend := BureauTrend{
+			Bureau:   bureau,
+			Latest:   latest.Score,
+			LatestAt: latest.ReportedAt,
+			Min:      latest.Score,
+			Max:      latest.Score,
+			Readings: len(series),
+		}
+
+		var total int
+		for _, reading := range series {
+			total += reading.Score
+			trend.Min = min(trend.Min, reading.Score)
+			trend.Max = max(trend.Max, reading.Score)
+		}
+		trend.Average = math.Round(float64(total)/float64(len(series))*10) / 10
+
+		if len(series) > 1 {
+			trend.Previous = series[len(series)-2].Score
+			trend.Change = latest.Score - trend.Previous
+		}
+		trend.Change90Days = latest.Score - scoreAsOf(series, now.AddDate(0, 0, -90))
+		trend.Change365Days = latest.Score - scoreAsOf(series, now.AddDate(-1, 0, 0))
+
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// scoreAsOf returns the score in effect at t, or the earliest score when t predates the history
+func scoreAsOf(series []models.CreditScoreReading, t time.Time) int {
+	score := series[0].Score
+	for _, reading := range series {
+		if reading.ReportedAt.After(t) {
+			break
+		}
+		score = reading.Score
+	}
+	return score
+}
+
+func highestCrossed(thresholds []float64, utilization float64) (float64, bool) {
+	var crossed float64
+	for _, threshold := range thresholds {
+		if utilization >= threshold {
+			crossed = math.Max(crossed, threshold)
+		}
+	}
+	return crossed, crossed > 0
+}
+
+func knownBureau(bureau string) bool {
+	switch bureau {
+	case models.BureauEquifax, models.BureauExperian, models.BureauTransUnion:
+		return true
+	}
+	return false
+}
+
+func ratio(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*10000) / 10000
+}
+
+func percent(fraction float64) string {
+	return strconv.FormatFloat(fraction*100, 'f', -1, 64) + "%"
+}
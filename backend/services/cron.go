@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("Error: Invalid Cron Expression")
+
+// cronDescriptors are the shorthand schedules accepted in place of five fields
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSearchYears bounds the search for a schedule that never matches, like Feb 30
+const cronSearchYears = 5
+
+// CronSchedule is a parsed five-field cron expression: minute, hour, day of month, month
+// and day of week, evaluated in UTC. Fields accept *, numbers, ranges (1-5), lists (1,15)
+// and steps (*/15, 10-50/10), and day of week 7 is Sunday like 0. As in cron, when both
+// day fields are restricted a day matching either one runs.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// ParseCron parses a cron expression or one of @hourly, @daily, @weekly, @monthly
+// and @yearly
+func ParseCron(expr string) (CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return CronSchedule{}, ErrInvalidCron
+	}
+
+	var s CronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny, s.dowAny = fields[2] == "*", fields[4] == "*"
+	return s, nil
+}
+
+// Next returns the first time after after that the schedule fires, or the zero time
+// when it never does
+func (s CronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s CronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseCronField turns one comma-separated field into a bit set of the values it allows
+func parseCronField(field string, low, high int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, ErrInvalidCron
+			}
+			rangePart, step = part[:i], n
+		}
+
+		start, end := low, high
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil {
+				return 0, ErrInvalidCron
+			}
+			start, end = a, b
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, ErrInvalidCron
+			}
+			start = n
+			if step == 1 {
+				end = n
+			}
+		}
+		if start < low || end > high || start > end {
+			return 0, ErrInvalidCron
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrEnvelopeNotFound     = errors.New("Error: Envelope Not Found")
+	ErrInvalidEnvelope      = errors.New("Error: Envelope Needs A Name And An Unused Category")
+	ErrInvalidEnvelopeEntry = errors.New("Error: Invalid Envelope Assignment Or Move")
+	ErrInsufficientEnvelope = errors.New("Error: Envelope Does Not Have Enough Available")
+)
+
+// EnvelopeMonth is one envelope's position in a month
+type EnvelopeMonth struct {
+	EnvelopeID primitive.ObjectID `json:"envelope_id"`
+	Name       string             `json:"name"`
+	Category   string             `json:"category"`
+	Carryover  float64            `json:"carryover"`
+	Assigned   float64            `json:"assigned"`
+	Spent      float64            `json:"spent"`
+	Available  float64            `json:"available"`
+	Overspent  bool               `json:"overspent"`
+}
+
+// EnvelopeBudget is the envelope view of one month. Income is money received in
+// categories without an envelope; ReadyToAssign is all income so far less everything
+// assigned so far, so it carries across months too.
+type EnvelopeBudget struct {
+	Month          string          `json:"month"`
+	Income         float64         `json:"income"`
+	Assigned       float64         `json:"assigned"`
+	Spent          float64         `json:"spent"`
+	Unbudgeted     float64         `json:"unbudgeted_spending"`
+	ReadyToAssign  float64         `json:"ready_to_assign"`
+	TotalAvailable float64         `json:"total_available"`
+	Envelopes      []EnvelopeMonth `json:"envelopes"`
+}
+
+// EnvelopeMove moves assigned money from one envelope to another within a month
+type EnvelopeMove struct {
+	FromID primitive.ObjectID
+	ToID   primitive.ObjectID
+	Month  time.Time
+	Amount float64
+	Memo   string
+}
+
+type EnvelopeService struct {
+	envelopes    repository.EnvelopeRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewEnvelopeService(envelopes repository.EnvelopeRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository) *EnvelopeService {
+	return &EnvelopeService{envelopes: envelopes, accounts: accounts, transactions: transactions}
+}
+
+// CreateEnvelope adds an envelope for a category the user does not already budget
+func (s *EnvelopeService) CreateEnvelope(ctx context.Context, userID primitive.ObjectID, envelope *models.Envelope) error {
+	envelope.Name = strings.TrimSpace(envelope.Name)
+	envelope.Category = strings.TrimSpace(envelope.Category)
+	if envelope.Name == "" || envelope.Category == "" {
+		return ErrInvalidEnvelope
+	}
+
+	existing, err := s.envelopes.ListEnvelopes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if strings.EqualFold(other.Category, envelope.Category) {
+			return ErrInvalidEnvelope
+		}
+	}
+
+	envelope.ID = primitive.NilObjectID
+	envelope.UserID = userID
+	envelope.CreatedAt = time.Now().UTC()
+	return s.envelopes.CreateEnvelope(ctx, envelope)
+}
+
+// Month evaluates every envelope of userID for the month containing month
+func (s *EnvelopeService) Month(ctx context.Context, userID primitive.ObjectID, month time.Time) (*EnvelopeBudget, error) {
+	month = monthStart(month)
+	envelopes, err := s.envelopes.ListEnvelopes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.envelopes.ListEntries(ctx, userID, month.Format(models.MonthLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	first := month
+	for _, envelope := range envelopes {
+		if created := monthStart(envelope.CreatedAt); created.Before(first) {
+			first = created
+		}
+	}
+	for _, entry := range entries {
+		if start, err := time.Parse(models.MonthLayout, entry.Month); err == nil && start.Before(first) {
+			first = start
+		}
+	}
+
+	accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	var txns []models.Transaction
+	if len(accountIDs) > 0 {
+		txns, err = s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+			AccountIDs:       accountIDs,
+			Start:            first,
+			End:              month.AddDate(0, 1, 0).Add(-time.Nanosecond),
+			ExcludeTransfers: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return EvaluateEnvelopes(envelopes, entries, txns, first, month), nil
+}
+
+// Assign puts ready-to-assign money into an envelope, or takes it back out when amount is negative
+func (s *EnvelopeService) Assign(ctx context.Context, userID, envelopeID primitive.ObjectID, month time.Time, amount float64, memo string) (*EnvelopeBudget, error) {
+	if amount == 0 {
+		return nil, ErrInvalidEnvelopeEntry
+	}
+	if _, err := s.getEnvelope(ctx, userID, envelopeID); err != nil {
+		return nil, err
+	}
+	if amount < 0 {
+		if err := s.requireAvailable(ctx, userID, envelopeID, month, -amount); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := models.EnvelopeEntry{
+		UserID:     userID,
+		EnvelopeID: envelopeID,
+		Month:      month.Format(models.MonthLayout),
+		Kind:       models.EnvelopeEntryAssign,
+		Amount:     roundCents(amount),
+		Memo:       memo,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.envelopes.CreateEntries(ctx, []models.EnvelopeEntry{entry}); err != nil {
+		return nil, err
+	}
+	return s.Month(ctx, userID, month)
+}
+
+// Move shifts available money between two envelopes, for example to cover overspending.
+// The source may not be left with a negative balance.
+func (s *EnvelopeService) Move(ctx context.Context, userID primitive.ObjectID, move EnvelopeMove) (*EnvelopeBudget, error) {
+	if move.Amount <= 0 || move.FromID == move.ToID {
+		return nil, ErrInvalidEnvelopeEntry
+	}
+	for _, id := range []primitive.ObjectID{move.FromID, move.ToID} {
+		if _, err := s.getEnvelope(ctx, userID, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.requireAvailable(ctx, userID, move.FromID, move.Month, move.Amount); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	moveID := primitive.NewObjectID()
+	entry := func(envelopeID primitive.ObjectID, amount float64) models.EnvelopeEntry {
+		return models.EnvelopeEntry{
+			UserID:     userID,
+			EnvelopeID: envelopeID,
+			Month:      move.Month.Format(models.MonthLayout),
+			Kind:       models.EnvelopeEntryMove,
+			Amount:     roundCents(amount),
+			MoveID:     moveID,
+			Memo:       move.Memo,
+			CreatedAt:  now,
+		}
+	}
+	entries := []models.EnvelopeEntry{entry(move.FromID, -move.Amount), entry(move.ToID, move.Amount)}
+	if err := s.envelopes.CreateEntries(ctx, entries); err != nil {
+		return nil, err
+	}
+	return s.Month(ctx, userID, move.Month)
+}
+
+// EvaluateEnvelopes rolls every envelope forward month by month from first through month.
+// Whatever is left in an envelope at the end of a month, positive or overspent, carries
+// into the next. Spending is matched to envelopes by category, split by split, and
+// refunds in an envelope's category put money back into it.
+func EvaluateEnvelopes(envelopes []models.Envelope, entries []models.EnvelopeEntry, txns []models.Transaction, first, month time.Time) *EnvelopeBudget {
+	first, month = monthStart(first), monthStart(month)
+	key := month.Format(models.MonthLayout)
+
+	byCategory := map[string]int{}
+	for i, envelope := range envelopes {
+		byCategory[strings.ToLower(envelope.Category)] = i
+	}
+	// An envelope is tracked from its creation month, or its first entry if that is earlier
+	byID := map[primitive.ObjectID]int{}
+	starts := make([]time.Time, len(envelopes))
+	for i, envelope := range envelopes {
+		byID[envelope.ID] = i
+		starts[i] = monthStart(envelope.CreatedAt)
+	}
+
+	// Per-month totals keyed by YYYY-MM
+	assigned := map[string][]float64{}
+	spent := map[string][]float64{}
+	income := map[string]float64{}
+	unbudgeted := map[string]float64{}
+	var assignedTotal float64
+	for _, entry := range entries {
+		i, ok := byID[entry.EnvelopeID]
+		if !ok || entry.Month > key {
+			continue
+		}
+		if assigned[entry.Month] == nil {
+			assigned[entry.Month] = make([]float64, len(envelopes))
+		}
+		assigned[entry.Month][i] += entry.Amount
+		assignedTotal += entry.Amount
+		if start, err := time.Parse(models.MonthLayout, entry.Month); err == nil && start.Before(starts[i]) {
+			starts[i] = start
+		}
+	}
+	var incomeTotal float64
+	for i := range txns {
+		txn := &txns[i]
+		if txn.TransactionDate.Before(first) || txn.TransactionDate.Format(models.MonthLayout) > key {
+			continue
+		}
+		txnMonth := txn.TransactionDate.Format(models.MonthLayout)
+		for _, alloc := range txn.Allocations() {
+			amount := float64(alloc.Amount)
+			if txn.Type != models.TransactionTypeDebit {
+				amount = -amount
+			}
+			envelope, ok := byCategory[strings.ToLower(alloc.Category)]
+			switch {
+			case ok:
+				if spent[txnMonth] == nil {
+					spent[txnMonth] = make([]float64, len(envelopes))
+				}
+				spent[txnMonth][envelope] += amount
+			case amount < 0:
+				income[txnMonth] -= amount
+				incomeTotal -= amount
+			default:
+				unbudgeted[txnMonth] += amount
+			}
+		}
+	}
+
+	available := make([]float64, len(envelopes))
+	results := make([]EnvelopeMonth, len(envelopes))
+	for current := first; !current.After(month); current = current.AddDate(0, 1, 0) {
+		currentKey := current.Format(models.MonthLayout)
+		for i, envelope := range envelopes {
+			if starts[i].After(current) {
+				continue
+			}
+			result := EnvelopeMonth{EnvelopeID: envelope.ID, Name: envelope.Name, Category: envelope.Category, Carryover: roundCents(available[i])}
+			if assigned[currentKey] != nil {
+				result.Assigned = roundCents(assigned[currentKey][i])
+			}
+			if spent[currentKey] != nil {
+				result.Spent = roundCents(spent[currentKey][i])
+			}
+			available[i] += result.Assigned - result.Spent
+			result.Available = roundCents(available[i])
+			result.Overspent = result.Available < 0
+			results[i] = result
+		}
+	}
+
+	budget := &EnvelopeBudget{
+		Month:         key,
+		Income:        roundCents(income[key]),
+		Unbudgeted:    roundCents(unbudgeted[key]),
+		ReadyToAssign: roundCents(incomeTotal - assignedTotal),
+		Envelopes:     []EnvelopeMonth{},
+	}
+	for i, result := range results {
+		if result.EnvelopeID.IsZero() {
+			result = EnvelopeMonth{EnvelopeID: envelopes[i].ID, Name: envelopes[i].Name, Category: envelopes[i].Category}
+		}
+		budget.Assigned += result.Assigned
+		budget.Spent += result.Spent
+		budget.TotalAvailable += result.Available
+		budget.Envelopes = append(budget.Envelopes, result)
+	}
+	budget.Assigned = roundCents(budget.Assigned)
+	budget.Spent = roundCents(budget.Spent)
+	budget.TotalAvailable = roundCents(budget.TotalAvailable)
+	return budget
+}
+
+func (s *EnvelopeService) requireAvailable(ctx context.Context, userID, envelopeID primitive.ObjectID, month time.Time, amount float64) error {
+	budget, err := s.Month(ctx, userID, month)
+	if err != nil {
+		return err
+	}
+	for _, envelope := range budget.Envelopes {
+		if envelope.EnvelopeID == envelopeID && envelope.Available < amount {
+			return ErrInsufficientEnvelope
+		}
+	}
+	return nil
+}
+
+func (s *EnvelopeService) getEnvelope(ctx context.Context, userID, id primitive.ObjectID) (*models.Envelope, error) {
+	envelope, err := s.envelopes.GetEnvelopeByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrEnvelopeNotFound
+		}
+		return nil, err
+	}
+	if envelope.UserID != userID {
+		return nil, ErrEnvelopeNotFound
+	}
+	return envelope, nil
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
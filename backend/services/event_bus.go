@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventSubscriber receives every event published on the bus
+type EventSubscriber interface {
+	HandleEvent(ctx context.Context, event models.Event) error
+}
+
+// EventBus hands events raised by services to subscribers such as webhooks. Publishing
+// never fails the operation that raised the event: subscriber errors are only logged.
+// A nil bus drops events, so services work without one.
+type EventBus struct {
+	subscribers []EventSubscriber
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a subscriber for every event
+func (b *EventBus) Subscribe(subscriber EventSubscriber) {
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish raises an event of eventType for userID about data
+func (b *EventBus) Publish(ctx context.Context, userID primitive.ObjectID, eventType string, data any) {
+	if b == nil || len(b.subscribers) == 0 {
+		return
+	}
+	event := models.Event{
+		ID:        primitive.NewObjectID(),
+		Type:      eventType,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	for _, subscriber := range b.subscribers {
+		if err := subscriber.HandleEvent(ctx, event); err != nil {
+			log.Printf("event %s %s could not be handled: %v", eventType, event.ID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var ErrInvalidExportFormat = errors.New("Error: Export Format Must Be csv, ofx Or ndjson")
+
+// Export formats
+const (
+	ExportCSV    = "csv"
+	ExportOFX    = "ofx"
+	ExportNDJSON = "ndjson"
+)
+
+const (
+	exportDateLayout = "2006-01-02"
+	// ofxDateLayout is the OFX datetime format; exports are always in UTC
+	ofxDateLayout = "20060102150405.000"
+	// ofxNameLength and ofxMemoLength are the longest NAME and MEMO an OFX transaction may carry
+	ofxNameLength = 32
+	ofxMemoLength = 255
+	// ofxBankID stands in for the routing number, which exports never reveal
+	ofxBankID = "000000000"
+)
+
+var transactionCSVHeader = []string{
+	"id", "date", "posted", "account", "account_number", "name", "category", "type", "status", "amount", "tags", "description",
+}
+
+// ExportService gets transactions, budget reports and net worth history out in formats
+// accountants can load: CSV, OFX and newline-delimited JSON
+type ExportService struct {
+	transactions repository.TransactionRepository
+	accounts     repository.AccountRepository
+	budgets      repository.BudgetRepository
+	snapshots    repository.NetWorthRepository
+	access       AccessPolicy
+}
+
+func NewExportService(transactions repository.TransactionRepository, accounts repository.AccountRepository, budgets repository.BudgetRepository,
+	snapshots repository.NetWorthRepository, access AccessPolicy) *ExportService {
+	return &ExportService{transactions: transactions, accounts: accounts, budgets: budgets, snapshots: snapshots, access: access}
+}
+
+// TransactionExport writes a user's transactions in one export format. Access is checked
+// and accounts are loaded when the export is prepared, since writing starts after the
+// response status is sent and can then only fail on I/O.
+type TransactionExport struct {
+	Format       string
+	transactions repository.TransactionRepository
+	accounts     []models.Account
+	start, end   time.Time
+}
+
+// Transactions prepares an export of the transactions on the given accounts, or on every
+// account userID can read, dated start through end
+func (s *ExportService) Transactions(ctx context.Context, userID primitive.ObjectID, format string, accountIDs []primitive.ObjectID, start, end time.Time) (*TransactionExport, error) {
+	switch format {
+	case ExportCSV, ExportOFX, ExportNDJSON:
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+
+	accountIDs, err := readableAccountIDs(ctx, s.access, userID, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	export := &TransactionExport{Format: format, transactions: s.transactions, accounts: make([]models.Account, 0, len(accountIDs)), start: start, end: end}
+	for _, id := range accountIDs {
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrAccountNotFound
+			}
+			return nil, err
+		}
+		export.accounts = append(export.accounts, *account)
+	}
+	return export, nil
+}
+
+// ContentType is the media type of the export
+func (e *TransactionExport) ContentType() string {
+	switch e.Format {
+	case ExportOFX:
+		return "application/x-ofx"
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write streams the export to w one transaction at a time, in date order. OFX exports
+// hold one statement per account and only posted transactions.
+func (e *TransactionExport) Write(ctx context.Context, w io.Writer, now time.Time) error {
+	switch e.Format {
+	case ExportOFX:
+		return e.writeOFX(ctx, w, now)
+	case ExportNDJSON:
+		encoder := json.NewEncoder(w)
+		return e.stream(ctx, e.accountIDs(), "", func(txn *models.Transaction) error {
+			return encoder.Encode(txn)
+		})
+	}
+	return e.writeCSV(ctx, w)
+}
+
+func (e *TransactionExport) writeCSV(ctx context.Context, w io.Writer) error {
+	labels := make(map[primitive.ObjectID]string, len(e.accounts))
+	for i := range e.accounts {
+		labels[e.accounts[i].ID] = e.accounts[i].AccountLabel
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(transactionCSVHeader); err != nil {
+		return err
+	}
+	err := e.stream(ctx, e.accountIDs(), "", func(txn *models.Transaction) error {
+		var posted string
+		if !txn.TransactionPosted.IsZero() {
+			posted = txn.TransactionPosted.UTC().Format(exportDateLayout)
+		}
+		return out.Write([]string{
+			txn.ID.Hex(),
+			txn.TransactionDate.UTC().Format(exportDateLayout),
+			posted,
+			csvText(labels[txn.AccountID]),
+			txn.AccountNumber,
+			csvText(txn.Name),
+			csvText(txn.Category),
+			txn.Type,
+			txn.EffectiveStatus(),
+			csvMoney(txn.SignedAmount()),
+			csvText(strings.Join(txn.Tags, ";")),
+			csvText(txn.Description),
+		})
+	})
+	out.Flush()
+	if err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+func (e *TransactionExport) writeOFX(ctx context.Context, w io.Writer, now time.Time) error {
+	out := &ofxWriter{w: w}
+	out.line(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>`)
+	out.line(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`)
+	out.line("<OFX>")
+	out.line("<SIGNONMSGSRSV1><SONRS>")
+	out.line("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+	out.line("<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE>", ofxDate(now))
+	out.line("</SONRS></SIGNONMSGSRSV1>")
+
+	var banks, cards []*models.Account
+	for i := range e.accounts {
+		if e.accounts[i].AccountType == models.AccountTypeCreditCard {
+			cards = append(cards, &e.accounts[i])
+		} else {
+			banks = append(banks, &e.accounts[i])
+		}
+	}
+	// message sets come in the order the specification lists them
+	for _, set := range []struct {
+		name, response, statement string
+		accounts                  []*models.Account
+	}{
+		{"BANKMSGSRSV1", "STMTTRNRS", "STMTRS", banks},
+		{"CREDITCARDMSGSRSV1", "CCSTMTTRNRS", "CCSTMTRS", cards},
+	} {
+		if len(set.accounts) == 0 {
+			continue
+		}
+		out.line("<%s>", set.name)
+		for i, account := range set.accounts {
+			out.line("<%s><TRNUID>%d</TRNUID>", set.response, i+1)
+			out.line("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+			out.line("<%s><CURDEF>USD</CURDEF>", set.statement)
+			if account.AccountType == models.AccountTypeCreditCard {
+				out.line("<CCACCTFROM><ACCTID>%s</ACCTID></CCACCTFROM>", account.ID.Hex())
+			} else {
+				out.line("<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>",
+					ofxBankID, account.ID.Hex(), ofxAccountType(account))
+			}
+			if err := e.writeOFXTransactions(ctx, out, account, now); err != nil {
+				return err
+			}
+			out.line("<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>", csvMoney(account.CurrentBalance), ofxDate(now))
+			out.line("</%s></%s>", set.statement, set.response)
+		}
+		out.line("</%s>", set.name)
+	}
+	out.line("</OFX>")
+	return out.err
+}
+
+func (e *TransactionExport) writeOFXTransactions(ctx context.Context, out *ofxWriter, account *models.Account, now time.Time) error {
+	start, end := e.start, e.end
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+	if end.IsZero() {
+		end = now
+	}
+	out.line("<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>", ofxDate(start), ofxDate(end))
+	err := e.stream(ctx, []primitive.ObjectID{account.ID}, models.TransactionStatusPosted, func(txn *models.Transaction) error {
+		posted := txn.TransactionPosted
+		if posted.IsZero() {
+			posted = txn.TransactionDate
+		}
+		trnType := "CREDIT"
+		if txn.Type == models.TransactionTypeDebit {
+			trnType = "DEBIT"
+		}
+		out.line("<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><DTUSER>%s</DTUSER><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME>",
+			trnType, ofxDate(posted), ofxDate(txn.TransactionDate), csvMoney(txn.SignedAmount()), txn.ID.Hex(), ofxText(txn.Name, ofxNameLength))
+		if txn.Description != "" {
+			out.line("<MEMO>%s</MEMO>", ofxText(txn.Description, ofxMemoLength))
+		}
+		out.line("</STMTTRN>")
+		return out.err
+	})
+	if err != nil {
+		return err
+	}
+	out.line("</BANKTRANLIST>")
+	return out.err
+}
+
+func (e *TransactionExport) accountIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(e.accounts))
+	for i := range e.accounts {
+		ids[i] = e.accounts[i].ID
+	}
+	return ids
+}
+
+func (e *TransactionExport) stream(ctx context.Context, accountIDs []primitive.ObjectID, status string, fn func(txn *models.Transaction) error) error {
+	// the repository reads no account filter as every account
+	if len(accountIDs) == 0 {
+		return nil
+	}
+	return e.transactions.StreamTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: accountIDs,
+		Start:      e.start,
+		End:        e.end,
+		Status:     status,
+	}, fn)
+}
+
+// WriteBudgetReport writes a CSV of how each of userID's budgets overlapping start through
+// end is doing. Everything is computed before the first write.
+func (s *ExportService) WriteBudgetReport(ctx context.Context, userID primitive.ObjectID, start, end time.Time, w io.Writer) error {
+	budgets, err := s.budgets.ListBudgets(ctx, userID, start, end)
+	if err != nil {
+		return err
+	}
+
+	records := [][]string{{"budget_id", "start_date", "end_date", "minimum_spending", "maximum_spending", "target_goal", "spent", "remaining", "meeting_budget"}}
+	for i := range budgets {
+		budget := &budgets[i]
+		txns, err := budgetTransactions(ctx, s.access, s.transactions, budget)
+		if err != nil {
+			return err
+		}
+		eval := evaluateBudget(budget, txns)
+		records = append(records, []string{
+			budget.ID.Hex(),
+			budget.StartDate.UTC().Format(exportDateLayout),
+			budget.EndDate.UTC().Format(exportDateLayout),
+			csvMoney(budget.MinimumSpending),
+			csvMoney(budget.MaximumSpending),
+			csvMoney(budget.TargetGoal),
+			csvMoney(eval.Spent),
+			csvMoney(eval.Remaining),
+			strconv.FormatBool(eval.IsMeetingBudget),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// WriteNetWorthReport writes a CSV of userID's daily net worth snapshots dated start
+// through end, with the change since the previous snapshot
+func (s *ExportService) WriteNetWorthReport(ctx context.Context, userID primitive.ObjectID, start, end time.Time, w io.Writer) error {
+	snapshots, err := s.snapshots.ListSnapshots(ctx, userID, start, end)
+	if err != nil {
+		return err
+	}
+
+	records := [][]string{{"date", "assets", "liabilities", "net_worth", "change"}}
+	for i, snapshot := range snapshots {
+		var change string
+		if i > 0 {
+			change = csvMoney(snapshot.NetWorth - snapshots[i-1].NetWorth)
+		}
+		records = append(records, []string{
+			snapshot.Date.UTC().Format(exportDateLayout),
+			csvMoney(snapshot.Assets),
+			csvMoney(snapshot.Liabilities),
+			csvMoney(snapshot.NetWorth),
+			change,
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// ofxWriter writes OFX lines, keeping the first error so a statement can be written
+// without checking every line
+type ofxWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (o *ofxWriter) line(format string, args ...any) {
+	if o.err != nil {
+		return
+	}
+	_, o.err = fmt.Fprintf(o.w, format+"\n", args...)
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout) + "[0:GMT]"
+}
+
+func ofxAccountType(account *models.Account) string {
+	switch account.AccountType {
+	case models.AccountTypeSavings:
+		return "SAVINGS"
+	case models.AccountTypeLoan:
+		return "CREDITLINE"
+	}
+	return "CHECKING"
+}
+
+// ofxText shortens value to at most limit characters and escapes it for XML
+func ofxText(value string, limit int) string {
+	if runes := []rune(value); len(runes) > limit {
+		value = string(runes[:limit])
+	}
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// csvText keeps spreadsheet apps from running text that starts like a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvMoney(amount float64) string {
+	amount = roundCents(amount)
+	if amount == 0 {
+		// no "-0.00" for amounts that round away
+		amount = 0
+	}
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
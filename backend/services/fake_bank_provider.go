@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+)
+
+const (
+	// FakeBankProviderName registers the fake bank with the sync service
+	FakeBankProviderName = "fake_bank"
+	// fakeBankHistoryDays is how far back the first sync of a fake connection reaches
+	fakeBankHistoryDays = 90
+	// fakeBankPageDays is how many days of transactions one page covers
+	fakeBankPageDays   = 30
+	fakeBankDateLayout = "2006-01-02"
+)
+
+type fakeMerchant struct {
+	name     string
+	category string
+	low      float64
+	high     float64
+}
+
+var fakeMerchants = []fakeMerchant{
+	{"Corner Grocery", "groceries", 12, 140},
+	{"Bean There Coffee", "dining", 3, 9},
+	{"Metro Fuel", "transportation", 25, 70},
+	{"Noodle House", "dining", 11, 45},
+	{"City Transit", "transportation", 2.75, 2.75},
+	{"Pharma Plus", "health", 6, 60},
+	{"Bookshelf & Co", "shopping", 8, 55},
+}
+
+// FakeBankProvider is a fully local AggregationProvider for development and tests.
+// Every access token is its own bank customer with a checking, savings and credit card
+// account, and the same token always produces the same history. Transactions on the
+// current day are pending and post the next day.
+type FakeBankProvider struct {
+	clock func() time.Time
+}
+
+// NewFakeBankProvider creates a fake bank whose "today" comes from clock, or the real
+// time when clock is nil
+func NewFakeBankProvider(clock func() time.Time) *FakeBankProvider {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &FakeBankProvider{clock: clock}
+}
+
+func (p *FakeBankProvider) Name() string {
+	return FakeBankProviderName
+}
+
+func (p *FakeBankProvider) ListAccounts(ctx context.Context, accessToken string) ([]ProviderAccount, error) {
+	if strings.TrimSpace(accessToken) == "" {
+		return nil, ErrProviderAuth
+	}
+	prefix := "fake-" + fakeSeedHex(accessToken)
+	return []ProviderAccount{
+		{ExternalID: prefix + "-checking", Name: "Fake Bank Checking", Type: models.AccountTypeChecking, Mask: fakeMask(accessToken, "checking")},
+		{ExternalID: prefix + "-savings", Name: "Fake Bank Savings", Type: models.AccountTypeSavings, Mask: fakeMask(accessToken, "savings")},
+		{ExternalID: prefix + "-card", Name: "Fake Bank Rewards Card", Type: models.AccountTypeCreditCard, Mask: fakeMask(accessToken, "card")},
+	}, nil
+}
+
+// FetchBalances reports each account's opening balance plus its history. Current
+// balances count posted transactions; available balances also take out pending spending.
+func (p *FakeBankProvider) FetchBalances(ctx context.Context, accessToken string) ([]ProviderBalance, error) {
+	accounts, err := p.ListAccounts(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	today := truncateDay(p.clock().UTC())
+
+	balances := make([]ProviderBalance, 0, len(accounts))
+	for _, account := range accounts {
+		opening := fakeOpeningBalance(account)
+		balance := ProviderBalance{AccountExternalID: account.ExternalID, Current: opening, Available: opening}
+		for day := today.AddDate(0, 0, -fakeBankHistoryDays); !day.After(today); day = day.AddDate(0, 0, 1) {
+			for _, txn := range fakeDay(account, day, today) {
+				if !txn.Pending {
+					balance.Current += txn.Amount
+				}
+				if !txn.Pending || txn.Amount < 0 {
+					balance.Available += txn.Amount
+				}
+			}
+		}
+		balance.Current, balance.Available = roundCents(balance.Current), roundCents(balance.Available)
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// FetchTransactions returns posted transactions day by day after the cursor date, plus
+// today's pending ones on the last page. The cursor is the last posted day returned.
+func (p *FakeBankProvider) FetchTransactions(ctx context.Context, accessToken, cursor string) (*TransactionPage, error) {
+	accounts, err := p.ListAccounts(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	today := truncateDay(p.clock().UTC())
+
+	start := today.AddDate(0, 0, -fakeBankHistoryDays)
+	if cursor != "" {
+		last, err := time.Parse(fakeBankDateLayout, cursor)
+		if err != nil {
+			return nil, ErrProviderAuth
+		}
+		start = last.AddDate(0, 0, 1)
+	}
+	end := start.AddDate(0, 0, fakeBankPageDays)
+	hasMore := end.Before(today)
+	if !hasMore {
+		end = today
+	}
+
+	page := &TransactionPage{Transactions: []ProviderTransaction{}, NextCursor: cursor, HasMore: hasMore}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if day.Equal(today) {
+			break
+		}
+		for _, account := range accounts {
+			page.Transactions = append(page.Transactions, fakeDay(account, day, today)...)
+		}
+		page.NextCursor = day.Format(fakeBankDateLayout)
+	}
+	if !hasMore {
+		for _, account := range accounts {
+			page.Transactions = append(page.Transactions, fakeDay(account, today, today)...)
+		}
+	}
+	return page, nil
+}
+
+// fakeDay generates an account's transactions on day, pending when day is today
+func fakeDay(account ProviderAccount, day, today time.Time) []ProviderTransaction {
+	rng := fakeRand(account.ExternalID, day.Format(fakeBankDateLayout))
+	pending := day.Equal(today)
+
+	var txns []ProviderTransaction
+	add := func(n int, name, category string, amount float64) {
+		id := account.ExternalID + "-" + day.Format("20060102") + "-" + string(rune('a'+n))
+		txn := ProviderTransaction{
+			ExternalID:        id,
+			AccountExternalID: account.ExternalID,
+			Name:              name,
+			Category:          category,
+			Amount:            roundCents(amount),
+			Pending:           pending,
+			Date:              day,
+		}
+		if pending {
+			txn.ExternalID = id + "-pending"
+		} else {
+			txn.PendingExternalID = id + "-pending"
+			txn.PostedDate = day.AddDate(0, 0, 1)
+		}
+		txns = append(txns, txn)
+	}
+
+	switch account.Type {
+	case models.AccountTypeChecking:
+		if day.Day() == 1 || day.Day() == 15 {
+			add(0, "Acme Corp Payroll", "income", 2150)
+		}
+		if day.Day() == 3 {
+			add(1, "Maple Apartments Rent", "housing", -1450)
+		}
+		purchases := rng.IntN(4)
+		for n := 2; n < 2+purchases; n++ {
+			merchant := fakeMerchants[rng.IntN(len(fakeMerchants))]
+			add(n, merchant.name, merchant.category, -(merchant.low + rng.Float64()*(merchant.high-merchant.low)))
+		}
+	case models.AccountTypeSavings:
+		if day.Day() == 2 {
+			add(0, "Transfer From Checking", "savings", 300)
+		}
+	case models.AccountTypeCreditCard:
+		if rng.IntN(2) == 0 {
+			merchant := fakeMerchants[rng.IntN(len(fakeMerchants))]
+			add(0, merchant.name, merchant.category, -(merchant.low + rng.Float64()*(merchant.high-merchant.low)))
+		}
+	}
+	return txns
+}
+
+func fakeOpeningBalance(account ProviderAccount) float64 {
+	switch account.Type {
+	case models.AccountTypeCreditCard:
+		return -float64(fakeRand(account.ExternalID, "opening").IntN(900))
+	case models.AccountTypeSavings:
+		return float64(2000 + fakeRand(account.ExternalID, "opening").IntN(8000))
+	}
+	return float64(500 + fakeRand(account.ExternalID, "opening").IntN(3000))
+}
+
+// fakeRand is a random source determined entirely by parts
+func fakeRand(parts ...string) *rand.Rand {
+	hash := fnv.New64a()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	seed := hash.Sum64()
+	return rand.New(rand.NewPCG(seed, seed>>1|1))
+}
+
+func fakeSeedHex(accessToken string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(accessToken))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+func fakeMask(accessToken, account string) string {
+	return fmt.Sprintf("%04d", fakeRand(accessToken, account).IntN(10000))
+}
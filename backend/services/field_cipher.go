@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+)
+
+var ErrUnknownKey = errors.New("Error: Encryption Key Not Found")
+
+// KeyProvider wraps and unwraps data keys with named master keys, the way a KMS does.
+// Retired keys must stay available for unwrapping until RotateKeys has re-encrypted
+// everything sealed with them.
+type KeyProvider interface {
+	ActiveKeyID() string
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider is a KeyProvider holding AES-256 master keys in memory, standing in
+// for a KMS in development and single-host deployments
+type LocalKeyProvider struct {
+	active string
+	keys   map[string][]byte
+}
+
+// localKeyFile is the format of the file read by LoadLocalKeyProvider, with base64 keys:
+// {"active": "2026-10", "keys": {"2026-04": "...", "2026-10": "..."}}
+type localKeyFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+func NewLocalKeyProvider(active string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, ErrUnknownKey
+	}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes", id)
+		}
+	}
+	return &LocalKeyProvider{active: active, keys: keys}, nil
+}
+
+// LoadLocalKeyProvider reads master keys from a JSON key file
+func LoadLocalKeyProvider(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file localKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return NewLocalKeyProvider(file.Active, keys)
+}
+
+// GenerateLocalKeyFile writes a key file holding one random master key so development can
+// start without provisioning keys. It never replaces an existing file; losing the keys makes
+// every sealed value unreadable.
+func GenerateLocalKeyFile(path string, now time.Time) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	active := now.UTC().Format("2006-01")
+	data, err := json.MarshalIndent(localKeyFile{Active: active, Keys: map[string]string{active: base64.StdEncoding.EncodeToString(key)}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (p *LocalKeyProvider) ActiveKeyID() string {
+	return p.active
+}
+
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	nonce, ciphertext, err := seal(key, dataKey, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if len(wrapped) < gcmNonceSize {
+		return nil, errors.New("wrapped key too short")
+	}
+	return open(key, wrapped[:gcmNonceSize], wrapped[gcmNonceSize:], []byte(keyID))
+}
+
+// FieldCipher seals individual fields with envelope encryption: every value gets a fresh
+// AES-256-GCM data key, wrapped by the provider's active master key
+type FieldCipher struct {
+	keys KeyProvider
+}
+
+func NewFieldCipher(keys KeyProvider) *FieldCipher {
+	return &FieldCipher{keys: keys}
+}
+
+// Encrypt seals plaintext. binding ties the ciphertext to where it is stored, such as
+// an account and field, so it cannot be copied elsewhere and still decrypt.
+func (f *FieldCipher) Encrypt(ctx context.Context, plaintext, binding string) (*models.EncryptedValue, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := seal(dataKey, []byte(plaintext), []byte(binding))
+	if err != nil {
+		return nil, err
+	}
+	keyID := f.keys.ActiveKeyID()
+	wrapped, err := f.keys.WrapKey(ctx, keyID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EncryptedValue{KeyID: keyID, WrappedKey: wrapped, Nonce: nonce, Ciphertext: ciphertext}, nil
+}
+
+func (f *FieldCipher) Decrypt(ctx context.Context, value *models.EncryptedValue, binding string) (string, error) {
+	dataKey, err := f.keys.UnwrapKey(ctx, value.KeyID, value.WrappedKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, value.Nonce, value.Ciphertext, []byte(binding))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Stale reports whether value was sealed with a key other than the active one
+func (f *FieldCipher) Stale(value *models.EncryptedValue) bool {
+	return value != nil && value.KeyID != f.keys.ActiveKeyID()
+}
+
+// Reseal seals a stale value again under the active key, returning nil when value is
+// not stale
+func (f *FieldCipher) Reseal(ctx context.Context, value *models.EncryptedValue, binding string) (*models.EncryptedValue, error) {
+	if !f.Stale(value) {
+		return nil, nil
+	}
+	plaintext, err := f.Decrypt(ctx, value, binding)
+	if err != nil {
+		return nil, err
+	}
+	return f.Encrypt(ctx, plaintext, binding)
+}
+
+// gcmNonceSize is the standard AES-GCM nonce length
+const gcmNonceSize = 12
+
+// seal encrypts with AES-GCM under a random nonce
+func seal(key, plaintext, additional []byte) (nonce, ciphertext []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, additional), nil
+}
+
+func open(key, nonce, ciphertext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, ciphertext, additional)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
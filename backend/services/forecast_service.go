@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MaxForecastDays bounds how far ahead a forecast may project
+const MaxForecastDays = 366
+
+var (
+	ErrRecurringNotFound = errors.New("Error: Recurring Item Not Found")
+	ErrInvalidRecurring  = errors.New("Error: Recurring Item Needs A Name, Kind, Positive Amount, Valid Schedule And Accounts You Own")
+	ErrInvalidForecast   = errors.New("Error: Forecast Must Cover 1-366 Days")
+)
+
+// ForecastEvent is one recurring item landing on an account
+type ForecastEvent struct {
+	ItemID primitive.ObjectID `json:"item_id"`
+	Name   string             `json:"name"`
+	Kind   string             `json:"kind"`
+	Amount float64            `json:"amount"`
+}
+
+// ForecastDay is an account's projected end-of-day balance
+type ForecastDay struct {
+	Date    time.Time       `json:"date"`
+	Balance float64         `json:"balance"`
+	Events  []ForecastEvent `json:"events,omitempty"`
+}
+
+// AccountForecast is the day-by-day projection of one account
+type AccountForecast struct {
+	AccountID       primitive.ObjectID `json:"account_id"`
+	AccountLabel    string             `json:"account_label"`
+	AccountType     string             `json:"account_type"`
+	StartingBalance float64            `json:"starting_balance"`
+	EndingBalance   float64            `json:"ending_balance"`
+	LowestBalance   float64            `json:"lowest_balance"`
+	LowestDate      time.Time          `json:"lowest_date"`
+	Days            []ForecastDay      `json:"days"`
+}
+
+// ForecastAlert marks the first day of a run where an account sits below the floor
+type ForecastAlert struct {
+	Date          time.Time          `json:"date"`
+	AccountID     primitive.ObjectID `json:"account_id"`
+	AccountLabel  string             `json:"account_label"`
+	Balance       float64            `json:"balance"`
+	LowestBalance float64            `json:"lowest_balance"`
+	Threshold     float64            `json:"threshold"`
+}
+
+// Forecast projects every account of a user over the next Days days
+type Forecast struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Floor    float64           `json:"floor"`
+	Accounts []AccountForecast `json:"accounts"`
+	Alerts   []ForecastAlert   `json:"alerts"`
+}
+
+type ForecastService struct {
+	recurring repository.RecurringRepository
+	users     repository.UserRepository
+	accounts  repository.AccountRepository
+}
+
+func NewForecastService(recurring repository.RecurringRepository, users repository.UserRepository, accounts repository.AccountRepository) *ForecastService {
+	return &ForecastService{recurring: recurring, users: users, accounts: accounts}
+}
+
+func (s *ForecastService) ListItems(ctx context.Context, userID primitive.ObjectID) ([]models.RecurringItem, error) {
+	items, err := s.recurring.ListItemsByUser(ctx, userID)
+	if items == nil {
+		items = []models.RecurringItem{}
+	}
+	return items, err
+}
+
+func (s *ForecastService) CreateItem(ctx context.Context, userID primitive.ObjectID, item *models.RecurringItem) error {
+	item.ID = primitive.NilObjectID
+	item.UserID = userID
+	item.CreatedAt = time.Now().UTC()
+	if err := s.validate(ctx, item); err != nil {
+		return err
+	}
+	return s.recurring.CreateItem(ctx, item)
+}
+
+// UpdateItem replaces one of userID's recurring items, keeping its owner and creation time
+func (s *ForecastService) UpdateItem(ctx context.Context, userID, id primitive.ObjectID, item *models.RecurringItem) error {
+	existing, err := s.getItem(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	item.ID = existing.ID
+	item.UserID = existing.UserID
+	item.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, item); err != nil {
+		return err
+	}
+
+	if err := s.recurring.SaveItem(ctx, item); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrRecurringNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ForecastService) DeleteItem(ctx context.Context, userID, id primitive.ObjectID) error {
+	if _, err := s.getItem(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.recurring.DeleteItem(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrRecurringNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Forecast projects the balances of userID's accounts for the next days days,
+// alerting where a checking or savings account dips below floor
+func (s *ForecastService) Forecast(ctx context.Context, userID primitive.ObjectID, days int, floor float64) (*Forecast, error) {
+	if days < 1 || days > MaxForecastDays {
+		return nil, ErrInvalidForecast
+	}
+
+	accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []models.Account
+	for _, id := range accountIDs {
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	items, err := s.recurring.ListItemsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildForecast(accounts, items, truncateDay(time.Now().UTC()), days, floor), nil
+}
+
+// BuildForecast walks each day after today, applying the recurring items due that day.
+// Debt payments stop once the liability is paid off. Liability balances are negative
+// by convention, so only asset accounts are checked against the floor.
+func BuildForecast(accounts []models.Account, items []models.RecurringItem, today time.Time, days int, floor float64) *Forecast {
+	from := today.AddDate(0, 0, 1)
+	to := today.AddDate(0, 0, days)
+	forecast := &Forecast{From: from, To: to, Floor: floor, Accounts: []AccountForecast{}, Alerts: []ForecastAlert{}}
+
+	index := map[primitive.ObjectID]int{}
+	balances := make([]float64, len(accounts))
+	for i, account := range accounts {
+		index[account.ID] = i
+		balances[i] = account.CurrentBalance
+		forecast.Accounts = append(forecast.Accounts, AccountForecast{
+			AccountID:       account.ID,
+			AccountLabel:    account.AccountLabel,
+			AccountType:     account.AccountType,
+			StartingBalance: roundCents(account.CurrentBalance),
+			LowestBalance:   roundCents(account.CurrentBalance),
+			LowestDate:      today,
+			Days:            make([]ForecastDay, 0, days),
+		})
+	}
+
+	// Bucket every occurrence by day offset so the walk below stays linear
+	due := make([][]*models.RecurringItem, days)
+	for i := range items {
+		item := &items[i]
+		if !item.Active {
+			continue
+		}
+		for _, date := range item.Schedule.Occurrences(from, to) {
+			offset := int(truncateDay(date).Sub(from).Hours() / 24)
+			if offset >= 0 && offset < days {
+				due[offset] = append(due[offset], item)
+			}
+		}
+	}
+
+	// belowFloor holds the index of each account's open alert, or -1
+	belowFloor := make([]int, len(accounts))
+	for i := range belowFloor {
+		belowFloor[i] = -1
+	}
+	for offset := 0; offset < days; offset++ {
+		date := from.AddDate(0, 0, offset)
+		events := make([][]ForecastEvent, len(accounts))
+
+		for _, item := range due[offset] {
+			source, ok := index[item.AccountID]
+			if !ok {
+				continue
+			}
+			amount := item.Amount
+			switch item.Kind {
+			case models.RecurringIncome:
+				balances[source] += amount
+				events[source] = append(events[source], forecastEvent(item, amount))
+				continue
+			case models.RecurringBill:
+				balances[source] -= amount
+				events[source] = append(events[source], forecastEvent(item, -amount))
+				continue
+			}
+
+			target, ok := index[item.ToAccountID]
+			if !ok {
+				continue
+			}
+			if item.Kind == models.RecurringDebtPayment {
+				amount = math.Min(amount, math.Max(-balances[target], 0))
+				if amount == 0 {
+					continue
+				}
+			}
+			balances[source] -= amount
+			balances[target] += amount
+			events[source] = append(events[source], forecastEvent(item, -amount))
+			events[target] = append(events[target], forecastEvent(item, amount))
+		}
+
+		for i, account := range accounts {
+			balance := roundCents(balances[i])
+			projection := &forecast.Accounts[i]
+			projection.Days = append(projection.Days, ForecastDay{Date: date, Balance: balance, Events: events[i]})
+			if balance < projection.LowestBalance {
+				projection.LowestBalance = balance
+				projection.LowestDate = date
+			}
+
+			if account.IsLiability() {
+				continue
+			}
+			switch {
+			case balance < floor && belowFloor[i] < 0:
+				forecast.Alerts = append(forecast.Alerts, ForecastAlert{
+					Date:          date,
+					AccountID:     account.ID,
+					AccountLabel:  account.AccountLabel,
+					Balance:       balance,
+					LowestBalance: balance,
+					Threshold:     floor,
+				})
+				belowFloor[i] = len(forecast.Alerts) - 1
+			case balance < floor:
+				alert := &forecast.Alerts[belowFloor[i]]
+				alert.LowestBalance = math.Min(alert.LowestBalance, balance)
+			default:
+				belowFloor[i] = -1
+			}
+		}
+	}
+
+	for i := range forecast.Accounts {
+		forecast.Accounts[i].EndingBalance = roundCents(balances[i])
+	}
+	return forecast
+}
+
+func forecastEvent(item *models.RecurringItem, amount float64) ForecastEvent {
+	return ForecastEvent{ItemID: item.ID, Name: item.Name, Kind: item.Kind, Amount: roundCents(amount)}
+}
+
+func (s *ForecastService) getItem(ctx context.Context, userID, id primitive.ObjectID) (*models.RecurringItem, error) {
+	item, err := s.recurring.GetItemByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecurringNotFound
+		}
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, ErrRecurringNotFound
+	}
+	return item, nil
+}
+
+func (s *ForecastService) validate(ctx context.Context, item *models.RecurringItem) error {
+	item.Name = strings.TrimSpace(item.Name)
+	switch item.Kind {
+	case models.RecurringIncome, models.RecurringBill, models.RecurringTransfer, models.RecurringDebtPayment:
+	default:
+		return ErrInvalidRecurring
+	}
+	if item.Name == "" || item.Amount <= 0 || !item.Schedule.Valid() {
+		return ErrInvalidRecurring
+	}
+	if item.MovesMoney() == item.ToAccountID.IsZero() || item.ToAccountID == item.AccountID {
+		return ErrInvalidRecurring
+	}
+
+	owned, err := userAccountIDs(ctx, s.accounts, item.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(owned, item.AccountID) || (item.MovesMoney() && !slices.Contains(owned, item.ToAccountID)) {
+		return ErrInvalidRecurring
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// goalPaceWindowDays is how far back contributions are averaged to estimate pace
+	goalPaceWindowDays = 90
+	avgDaysPerMonth    = 365.25 / 12
+)
+
+var (
+	ErrGoalNotFound = errors.New("Error: Savings Goal Not Found")
+	ErrInvalidGoal  = errors.New("Error: Goal Needs A Name, Positive Target, Future Date And Accounts You Own")
+)
+
+// MonthlyContribution is the net amount added to a goal's accounts in one calendar month
+type MonthlyContribution struct {
+	Month  string  `json:"month"`
+	Amount float64 `json:"amount"`
+}
+
+// GoalProgress is a savings goal with its contributions and projections
+type GoalProgress struct {
+	Goal                models.SavingsGoal    `json:"goal"`
+	Saved               float64               `json:"saved"`
+	Contributed         float64               `json:"contributed"`
+	Remaining           float64               `json:"remaining"`
+	PercentComplete     float64               `json:"percent_complete"`
+	MonthsRemaining     float64               `json:"months_remaining"`
+	RequiredMonthly     float64               `json:"required_monthly"`
+	RecentMonthlyPace   float64               `json:"recent_monthly_pace"`
+	Achieved            bool                  `json:"achieved"`
+	OnTrack             bool                  `json:"on_track"`
+	ProjectedCompletion *time.Time            `json:"projected_completion,omitempty"`
+	Contributions       []MonthlyContribution `json:"contributions"`
+}
+
+type GoalService struct {
+	goals        repository.GoalRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewGoalService(goals repository.GoalRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository) *GoalService {
+	return &GoalService{goals: goals, accounts: accounts, transactions: transactions}
+}
+
+// CreateGoal validates and stores a new goal for userID
+func (s *GoalService) CreateGoal(ctx context.Context, userID primitive.ObjectID, goal *models.SavingsGoal) (*GoalProgress, error) {
+	now := time.Now().UTC()
+	goal.ID = primitive.NilObjectID
+	goal.UserID = userID
+	goal.CreatedAt = now
+	if goal.StartDate.IsZero() {
+		goal.StartDate = truncateDay(now)
+	}
+	if err := s.validate(ctx, goal); err != nil {
+		return nil, err
+	}
+
+	if err := s.goals.CreateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, goal, now)
+}
+
+// UpdateGoal replaces the editable fields of one of userID's goals
+func (s *GoalService) UpdateGoal(ctx context.Context, userID, id primitive.ObjectID, changes *models.SavingsGoal) (*GoalProgress, error) {
+	goal, err := s.getGoal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Name = changes.Name
+	goal.AccountIDs = changes.AccountIDs
+	goal.TargetAmount = changes.TargetAmount
+	goal.TargetDate = changes.TargetDate
+	goal.StartingAmount = changes.StartingAmount
+	if !changes.StartDate.IsZero() {
+		goal.StartDate = changes.StartDate
+	}
+	if err := s.validate(ctx, goal); err != nil {
+		return nil, err
+	}
+
+	if err := s.goals.SaveGoal(ctx, goal); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGoalNotFound
+		}
+		return nil, err
+	}
+	return s.progress(ctx, goal, time.Now().UTC())
+}
+
+// DeleteGoal removes one of userID's goals. The linked accounts are untouched.
+func (s *GoalService) DeleteGoal(ctx context.Context, userID, id primitive.ObjectID) error {
+	if _, err := s.getGoal(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.goals.DeleteGoal(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrGoalNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetGoal returns one of userID's goals with its progress
+func (s *GoalService) GetGoal(ctx context.Context, userID, id primitive.ObjectID) (*GoalProgress, error) {
+	goal, err := s.getGoal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, goal, time.Now().UTC())
+}
+
+// ListGoals returns every goal of userID with its progress, soonest target first
+func (s *GoalService) ListGoals(ctx context.Context, userID primitive.ObjectID) ([]GoalProgress, error) {
+	goals, err := s.goals.ListGoalsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	results := []GoalProgress{}
+	for i := range goals {
+		progress, err := s.progress(ctx, &goals[i], now)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *progress)
+	}
+	return results, nil
+}
+
+func (s *GoalService) progress(ctx context.Context, goal *models.SavingsGoal, now time.Time) (*GoalProgress, error) {
+	txns, err := s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: goal.AccountIDs,
+		Start:      goal.StartDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ProjectGoal(goal, txns, now), nil
+}
+
+// ProjectGoal measures a goal against the transactions of its linked accounts.
+// Transfers between two linked accounts cancel out; transfers in from elsewhere count.
+// Pace is the average monthly contribution over the last 90 days (or since the goal
+// started, but never less than a month), and the projection assumes it continues.
+func ProjectGoal(goal *models.SavingsGoal, txns []models.Transaction, now time.Time) *GoalProgress {
+	progress := &GoalProgress{Goal: *goal, Contributions: []MonthlyContribution{}}
+
+	paceStart := now.AddDate(0, 0, -goalPaceWindowDays)
+	if goal.StartDate.After(paceStart) {
+		paceStart = goal.StartDate
+	}
+	var recent float64
+	months := map[string]float64{}
+	for _, txn := range txns {
+		if txn.TransactionDate.Before(goal.StartDate) || txn.TransactionDate.After(now) {
+			continue
+		}
+		amount := txn.SignedAmount()
+		progress.Contributed += amount
+		months[txn.TransactionDate.Format("2006-01")] += amount
+		if !txn.TransactionDate.Before(paceStart) {
+			recent += amount
+		}
+	}
+
+	for month, amount := range months {
+		progress.Contributions = append(progress.Contributions, MonthlyContribution{Month: month, Amount: roundCents(amount)})
+	}
+	slices.SortFunc(progress.Contributions, func(a, b MonthlyContribution) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+
+	progress.Contributed = roundCents(progress.Contributed)
+	progress.Saved = roundCents(goal.StartingAmount + progress.Contributed)
+	progress.Remaining = roundCents(math.Max(goal.TargetAmount-progress.Saved, 0))
+	progress.Achieved = progress.Remaining == 0
+	if goal.TargetAmount > 0 {
+		progress.PercentComplete = math.Round(math.Min(progress.Saved/goal.TargetAmount, 1)*10000) / 100
+	}
+
+	paceDays := math.Max(now.Sub(paceStart).Hours()/24, avgDaysPerMonth)
+	progress.RecentMonthlyPace = roundCents(recent / paceDays * avgDaysPerMonth)
+
+	daysLeft := goal.TargetDate.Sub(now).Hours() / 24
+	progress.MonthsRemaining = math.Max(math.Round(daysLeft/avgDaysPerMonth*10)/10, 0)
+
+	switch {
+	case progress.Achieved:
+		progress.OnTrack = true
+		completed := now
+		progress.ProjectedCompletion = &completed
+	default:
+		// A past-due goal needs the whole remainder now
+		progress.RequiredMonthly = progress.Remaining
+		if daysLeft > avgDaysPerMonth {
+			progress.RequiredMonthly = roundCents(progress.Remaining / (daysLeft / avgDaysPerMonth))
+		}
+		progress.OnTrack = daysLeft > 0 && progress.RecentMonthlyPace >= progress.RequiredMonthly
+
+		if progress.RecentMonthlyPace > 0 {
+			days := progress.Remaining / progress.RecentMonthlyPace * avgDaysPerMonth
+			projected := truncateDay(now.Add(time.Duration(math.Ceil(days)) * 24 * time.Hour))
+			progress.ProjectedCompletion = &projected
+		}
+	}
+
+	return progress
+}
+
+func (s *GoalService) getGoal(ctx context.Context, userID, id primitive.ObjectID) (*models.SavingsGoal, error) {
+	goal, err := s.goals.GetGoalByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGoalNotFound
+		}
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+func (s *GoalService) validate(ctx context.Context, goal *models.SavingsGoal) error {
+	goal.Name = strings.TrimSpace(goal.Name)
+	if goal.Name == "" || goal.TargetAmount <= 0 || goal.StartingAmount < 0 || len(goal.AccountIDs) == 0 ||
+		!goal.TargetDate.After(goal.StartDate) {
+		return ErrInvalidGoal
+	}
+
+	owned, err := userAccountIDs(ctx, s.accounts, goal.UserID)
+	if err != nil {
+		return err
+	}
+	for _, id := range goal.AccountIDs {
+		if !slices.Contains(owned, id) {
+			return ErrInvalidGoal
+		}
+	}
+	return nil
+}
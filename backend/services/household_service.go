@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// InvitationTTL is how long a household invitation token stays valid
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrHouseholdNotFound  = errors.New("Error: Household Not Found")
+	ErrInvalidHousehold   = errors.New("Error: Invalid Household Name, Role Or Member")
+	ErrInvitationInvalid  = errors.New("Error: Invitation Is Invalid, Used Or Expired")
+	ErrBudgetNotOwned     = errors.New("Error: Only A Budget's Owner Can Share It")
+	ErrAccountNotOwned    = errors.New("Error: Only An Account's Owner Can Share It")
+	ErrLastHouseholdOwner = errors.New("Error: A Household Must Keep An Owner")
+	ErrHouseholdChanged   = errors.New("Error: Household Was Changed By Someone Else, Try Again")
+)
+
+// IssuedInvitation is a new invitation with its one-time token, which is never stored
+type IssuedInvitation struct {
+	Invitation models.HouseholdInvitation `json:"invitation"`
+	Token      string                     `json:"token"`
+}
+
+type HouseholdService struct {
+	households repository.HouseholdRepository
+	users      repository.UserRepository
+	accounts   repository.AccountRepository
+	budgets    repository.BudgetRepository
+	tx         repository.TxRunner
+}
+
+func NewHouseholdService(households repository.HouseholdRepository, users repository.UserRepository, accounts repository.AccountRepository,
+	budgets repository.BudgetRepository, tx repository.TxRunner) *HouseholdService {
+	return &HouseholdService{households: households, users: users, accounts: accounts, budgets: budgets, tx: tx}
+}
+
+// CreateHousehold starts a household with userID as its owner
+func (s *HouseholdService) CreateHousehold(ctx context.Context, userID primitive.ObjectID, name string) (*models.Household, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidHousehold
+	}
+
+	now := time.Now().UTC()
+	household := &models.Household{
+		Name:      name,
+		Members:   []models.HouseholdMember{{UserID: userID, Role: models.HouseholdRoleOwner, JoinedAt: now}},
+		Accounts:  []primitive.ObjectID{},
+		Budgets:   []primitive.ObjectID{},
+		CreatedAt: now,
+	}
+	if err := s.households.CreateHousehold(ctx, household); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+func (s *HouseholdService) ListHouseholds(ctx context.Context, userID primitive.ObjectID) ([]models.Household, error) {
+	households, err := s.households.ListByMember(ctx, userID)
+	if households == nil {
+		households = []models.Household{}
+	}
+	return households, err
+}
+
+// GetHousehold returns a household userID belongs to
+func (s *HouseholdService) GetHousehold(ctx context.Context, userID, id primitive.ObjectID) (*models.Household, error) {
+	household, _, err := s.membership(ctx, userID, id)
+	return household, err
+}
+
+// Invite issues a one-time token for email to join as role. Only owners may invite.
+func (s *HouseholdService) Invite(ctx context.Context, userID, id primitive.ObjectID, email, role string) (*IssuedInvitation, error) {
+	household, err := s.requireRole(ctx, userID, id, models.HouseholdRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") || !models.ValidHouseholdRole(role) {
+		return nil, ErrInvalidHousehold
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	invitation := models.HouseholdInvitation{
+		HouseholdID: household.ID,
+		Email:       email,
+		Role:        role,
+		TokenHash:   hashToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   now.Add(InvitationTTL),
+		CreatedAt:   now,
+	}
+	if err := s.households.CreateInvitation(ctx, &invitation); err != nil {
+		return nil, err
+	}
+	return &IssuedInvitation{Invitation: invitation, Token: token}, nil
+}
+
+// ListInvitations returns a household's invitations to its owners
+func (s *HouseholdService) ListInvitations(ctx context.Context, userID, id primitive.ObjectID) ([]models.HouseholdInvitation, error) {
+	if _, err := s.requireRole(ctx, userID, id, models.HouseholdRoleOwner); err != nil {
+		return nil, err
+	}
+	invitations, err := s.households.ListInvitations(ctx, id)
+	if invitations == nil {
+		invitations = []models.HouseholdInvitation{}
+	}
+	return invitations, err
+}
+
+// AcceptInvitation adds userID to the invitation's household. The token must be
+// unused, unexpired and addressed to the user's email. The invitation is used up in
+// the same transaction that adds the member, so it stays open if the join fails.
+func (s *HouseholdService) AcceptInvitation(ctx context.Context, userID primitive.ObjectID, token string) (*models.Household, error) {
+	invitation, err := s.households.GetInvitationByTokenHash(ctx, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvitationInvalid
+		}
+		return nil, err
+	}
+	if !invitation.AcceptedBy.IsZero() || time.Now().UTC().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationInvalid
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(user.Email), invitation.Email) {
+		return nil, ErrInvitationInvalid
+	}
+
+	household, err := s.households.GetHouseholdByID(ctx, invitation.HouseholdID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrHouseholdNotFound
+		}
+		return nil, err
+	}
+	joined := false
+	if _, ok := household.Member(userID); !ok {
+		household.Members = append(household.Members, models.HouseholdMember{UserID: userID, Role: invitation.Role, JoinedAt: time.Now().UTC()})
+		joined = true
+	}
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.households.AcceptInvitation(ctx, invitation.ID, userID); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrInvitationInvalid
+			}
+			return err
+		}
+		if !joined {
+			return nil
+		}
+		return s.save(ctx, household)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// SetMemberRole changes a member's role. Only owners may, and the last owner cannot step down.
+func (s *HouseholdService) SetMemberRole(ctx context.Context, userID, id, memberID primitive.ObjectID, role string) (*models.Household, error) {
+	household, err := s.requireRole(ctx, userID, id, models.HouseholdRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	member, ok := household.Member(memberID)
+	if !ok || !models.ValidHouseholdRole(role) {
+		return nil, ErrInvalidHousehold
+	}
+	if member.Role == models.HouseholdRoleOwner && role != models.HouseholdRoleOwner && countOwners(household) == 1 {
+		return nil, ErrLastHouseholdOwner
+	}
+
+	member.Role = role
+	if err := s.save(ctx, household); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// RemoveMember takes a member out of the household. Owners may remove anyone and any
+// member may leave; the last owner cannot.
+func (s *HouseholdService) RemoveMember(ctx context.Context, userID, id, memberID primitive.ObjectID) (*models.Household, error) {
+	household, actor, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != memberID && actor.Role != models.HouseholdRoleOwner {
+		return nil, ErrForbidden
+	}
+	member, ok := household.Member(memberID)
+	if !ok {
+		return nil, ErrInvalidHousehold
+	}
+	if member.Role == models.HouseholdRoleOwner && countOwners(household) == 1 {
+		return nil, ErrLastHouseholdOwner
+	}
+
+	household.Members = slices.DeleteFunc(household.Members, func(m models.HouseholdMember) bool {
+		return m.UserID == memberID
+	})
+	if err := s.save(ctx, household); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// ShareAccount shares one of userID's own accounts with the household
+func (s *HouseholdService) ShareAccount(ctx context.Context, userID, id, accountID primitive.ObjectID) (*models.Household, error) {
+	household, err := s.requireRole(ctx, userID, id, models.HouseholdRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if account == nil || account.OwnerID != userID {
+		return nil, ErrAccountNotOwned
+	}
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.accounts.ShareWithHousehold(ctx, accountID, household.ID); err != nil {
+			return err
+		}
+		if slices.Contains(household.Accounts, accountID) {
+			return nil
+		}
+		household.Accounts = append(household.Accounts, accountID)
+		return s.save(ctx, household)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// UnshareAccount stops sharing an account. The account's owner or a household owner may.
+func (s *HouseholdService) UnshareAccount(ctx context.Context, userID, id, accountID primitive.ObjectID) (*models.Household, error) {
+	household, member, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if member.Role != models.HouseholdRoleOwner && (account == nil || account.OwnerID != userID) {
+		return nil, ErrForbidden
+	}
+
+	household.Accounts = slices.DeleteFunc(household.Accounts, func(shared primitive.ObjectID) bool {
+		return shared == accountID
+	})
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if account != nil {
+			if err := s.accounts.UnshareFromHousehold(ctx, accountID, household.ID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return err
+			}
+		}
+		return s.save(ctx, household)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// ShareBudget shares a budget userID owns with the household
+func (s *HouseholdService) ShareBudget(ctx context.Context, userID, id, budgetID primitive.ObjectID) (*models.Household, error) {
+	household, err := s.requireRole(ctx, userID, id, models.HouseholdRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := s.budgets.GetBudgetByID(ctx, budgetID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, err
+	}
+	if budget.UserID != userID {
+		return nil, ErrBudgetNotOwned
+	}
+
+	if !slices.Contains(household.Budgets, budgetID) {
+		household.Budgets = append(household.Budgets, budgetID)
+		if err := s.save(ctx, household); err != nil {
+			return nil, err
+		}
+	}
+	return household, nil
+}
+
+// UnshareBudget stops sharing a budget. The budget's owner or a household owner may.
+func (s *HouseholdService) UnshareBudget(ctx context.Context, userID, id, budgetID primitive.ObjectID) (*models.Household, error) {
+	household, member, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != models.HouseholdRoleOwner {
+		budget, err := s.budgets.GetBudgetByID(ctx, budgetID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		if budget == nil || budget.UserID != userID {
+			return nil, ErrForbidden
+		}
+	}
+
+	household.Budgets = slices.DeleteFunc(household.Budgets, func(shared primitive.ObjectID) bool {
+		return shared == budgetID
+	})
+	if err := s.save(ctx, household); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// membership loads a household and userID's place in it. Non-members get
+// ErrHouseholdNotFound so households cannot be probed.
+func (s *HouseholdService) membership(ctx context.Context, userID, id primitive.ObjectID) (*models.Household, *models.HouseholdMember, error) {
+	household, err := s.households.GetHouseholdByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, ErrHouseholdNotFound
+		}
+		return nil, nil, err
+	}
+	member, ok := household.Member(userID)
+	if !ok {
+		return nil, nil, ErrHouseholdNotFound
+	}
+	return household, member, nil
+}
+
+// requireRole loads a household userID belongs to with at least role
+func (s *HouseholdService) requireRole(ctx context.Context, userID, id primitive.ObjectID, role string) (*models.Household, error) {
+	household, member, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if householdRank(member.Role) < householdRank(role) {
+		return nil, ErrForbidden
+	}
+	return household, nil
+}
+
+// save stores a household loaded by membership. Households are never deleted, so a
+// save that matches nothing lost a race with another save of the same household.
+func (s *HouseholdService) save(ctx context.Context, household *models.Household) error {
+	if err := s.households.SaveHousehold(ctx, household); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrHouseholdChanged
+		}
+		return err
+	}
+	return nil
+}
+
+func householdRank(role string) int {
+	switch role {
+	case models.HouseholdRoleOwner:
+		return 3
+	case models.HouseholdRoleEditor:
+		return 2
+	case models.HouseholdRoleViewer:
+		return 1
+	}
+	return 0
+}
+
+func countOwners(household *models.Household) int {
+	owners := 0
+	for _, member := range household.Members {
+		if member.Role == models.HouseholdRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var ErrInvalidInterestSettings = errors.New("Error: Invalid Interest Settings")
+
+// InterestPosting is interest moved into (or charged to) an account's balance at month end
+type InterestPosting struct {
+	Date   time.Time `json:"date"`
+	Amount float64   `json:"amount"`
+}
+
+// InterestStatement summarizes an account's interest for tax reporting
+type InterestStatement struct {
+	AccountID            primitive.ObjectID `json:"account_id"`
+	Year                 int                `json:"year"`
+	YearToDate           float64            `json:"year_to_date"`
+	AccruedUnposted      float64            `json:"accrued_unposted"`
+	InterestRate         float64            `json:"interest_rate"`
+	InterestMethod       string             `json:"interest_method"`
+	CompoundingFrequency string             `json:"compounding_frequency"`
+	DayCountConvention   string             `json:"day_count_convention"`
+	LastAccrualDate      time.Time          `json:"last_accrual_date"`
+}
+
+// InterestRunResult reports what one accrual run did
+type InterestRunResult struct {
+	AsOf     time.Time `json:"as_of"`
+	Accounts int       `json:"accounts"`
+	Postings int       `json:"postings"`
+	Skipped  int       `json:"skipped"`
+	Failures int       `json:"failures"`
+}
+
+type InterestService struct {
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	tx           repository.TxRunner
+	access       AccessPolicy
+	events       *EventBus
+}
+
+func NewInterestService(accounts repository.AccountRepository, transactions repository.TransactionRepository, tx repository.TxRunner, access AccessPolicy) *InterestService {
+	return &InterestService{accounts: accounts, transactions: transactions, tx: tx, access: access}
+}
+
+// SetEventBus publishes transaction.created events for posted interest on bus
+func (s *InterestService) SetEventBus(bus *EventBus) {
+	s.events = bus
+}
+
+// UpdateSettings changes the rate and accrual conventions of an account
+func (s *InterestService) UpdateSettings(ctx context.Context, userID, id primitive.ObjectID, settings *models.Account) (*models.Account, error) {
+	if !validInterestSettings(settings) {
+		return nil, ErrInvalidInterestSettings
+	}
+	if err := s.access.CheckAccount(ctx, userID, id, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accounts.UpdateInterestSettings(ctx, id, settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// Statement returns the year-to-date interest of an account
+func (s *InterestService) Statement(ctx context.Context, userID, id primitive.ObjectID) (*InterestStatement, error) {
+	account, err := s.accounts.GetAccountByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, id, AccessRead); err != nil {
+		return nil, err
+	}
+
+	statement := &InterestStatement{
+		AccountID:            account.ID,
+		Year:                 account.InterestYear,
+		YearToDate:           account.AcquiredInterest,
+		AccruedUnposted:      roundCents(account.AccruedInterest),
+		InterestRate:         account.InterestRate,
+		InterestMethod:       account.InterestMethod,
+		CompoundingFrequency: account.CompoundingFrequency,
+		DayCountConvention:   account.DayCountConvention,
+		LastAccrualDate:      account.LastAccrualDate,
+	}
+	if statement.Year != time.Now().UTC().Year() {
+		statement.Year = time.Now().UTC().Year()
+		statement.YearToDate = 0
+	}
+	return statement, nil
+}
+
+// RunAccruals accrues daily interest on every interest-bearing account up to asOf and
+// posts an interest transaction for each month end crossed
+func (s *InterestService) RunAccruals(ctx context.Context, asOf time.Time) (*InterestRunResult, error) {
+	accounts, err := s.accounts.ListInterestBearing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InterestRunResult{AsOf: asOf, Accounts: len(accounts)}
+	for i := range accounts {
+		postings, err := s.accrueAccount(ctx, &accounts[i], asOf)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// another run accrued this account since it was listed
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			log.Printf("interest accrual failed for account %s: %v", accounts[i].ID.Hex(), err)
+			result.Failures++
+			continue
+		}
+		result.Postings += postings
+	}
+
+	return result, nil
+}
+
+func (s *InterestService) accrueAccount(ctx context.Context, account *models.Account, asOf time.Time) (int, error) {
+	previous := account.LastAccrualDate
+	postings := AccrueInterest(account, asOf)
+	txns := make([]*models.Transaction, len(postings))
+	for i, posting := range postings {
+		txns[i] = interestTransaction(account, posting)
+	}
+
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.accounts.SaveInterestState(ctx, account, previous); err != nil {
+			return err
+		}
+		for _, txn := range txns {
+			if err := s.transactions.CreateTransaction(ctx, txn); err != nil {
+				return err
+			}
+			if err := s.accounts.AdjustBalance(ctx, account.ID, txn.SignedAmount()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, txn := range txns {
+		s.events.Publish(ctx, account.OwnerID, models.EventTransactionCreated, *txn)
+	}
+	return len(postings), nil
+}
+
+// AccrueInterest advances the account's accrual day by day from LastAccrualDate to asOf.
+// Interest accrues on the balance using the account's day-count convention; on the last
+// day of each month the accrued amount (rounded to cents) is posted to the balance and
+// added to the year-to-date AcquiredInterest. The account is updated in place.
+//
+// Compounding is modelled by which interest earns interest: with daily compounding the
+// unposted accrual is part of the base; otherwise posted interest only joins the base at
+// the next compounding boundary, and simple interest never does.
+func AccrueInterest(account *models.Account, asOf time.Time) []InterestPosting {
+	asOf = truncateDay(asOf)
+	if account.LastAccrualDate.IsZero() {
+		account.LastAccrualDate = asOf
+		return nil
+	}
+
+	var postings []InterestPosting
+	method := valueOr(account.InterestMethod, models.InterestMethodCompound)
+	frequency := valueOr(account.CompoundingFrequency, models.CompoundingMonthly)
+	convention := valueOr(account.DayCountConvention, models.DayCountActual365)
+
+	for day := truncateDay(account.LastAccrualDate); day.Before(asOf); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+
+		base := math.Abs(account.CurrentBalance) - account.UncompoundedInterest
+		if method == models.InterestMethodCompound && frequency == models.CompoundingDaily {
+			base += account.AccruedInterest
+		}
+		// Assets earn on positive balances and liabilities are charged on amounts owed
+		if base > 0 && account.IsLiability() == (account.CurrentBalance < 0) {
+			account.AccruedInterest += base * account.InterestRate * DayCountFraction(convention, day, next)
+		}
+
+		if next.Day() == 1 {
+			amount := roundCents(account.AccruedInterest)
+			if amount > 0 {
+				postings = append(postings, InterestPosting{Date: day, Amount: amount})
+				account.AccruedInterest -= amount
+				if account.IsLiability() {
+					account.CurrentBalance -= amount
+				} else {
+					account.CurrentBalance += amount
+				}
+
+				if account.InterestYear != day.Year() {
+					account.InterestYear = day.Year()
+					account.AcquiredInterest = 0
+				}
+				account.AcquiredInterest = roundCents(account.AcquiredInterest + amount)
+				account.UncompoundedInterest += amount
+			}
+		}
+
+		if method == models.InterestMethodCompound && compoundsOn(frequency, next) {
+			account.UncompoundedInterest = 0
+		}
+	}
+
+	account.LastAccrualDate = asOf
+	return postings
+}
+
+// DayCountFraction returns the fraction of a year between from and to under the given convention
+func DayCountFraction(convention string, from, to time.Time) float64 {
+	from, to = truncateDay(from), truncateDay(to)
+	days := to.Sub(from).Hours() / 24
+
+	switch convention {
+	case models.DayCountActual360:
+		return days / 360
+	case models.DayCountActualActual:
+		return days / float64(daysInYear(from.Year()))
+	case models.DayCount30360:
+		d1, d2 := from.Day(), to.Day()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		return float64(360*(to.Year()-from.Year())+30*(int(to.Month())-int(from.Month()))+(d2-d1)) / 360
+	default:
+		return days / 365
+	}
+}
+
+// compoundsOn reports whether posted interest joins the accrual base at the start of day
+func compoundsOn(frequency string, day time.Time) bool {
+	switch frequency {
+	case models.CompoundingDaily:
+		return true
+	case models.CompoundingMonthly:
+		return day.Day() == 1
+	case models.CompoundingQuarterly:
+		return day.Day() == 1 && (day.Month()-1)%3 == 0
+	case models.CompoundingAnnually:
+		return day.Day() == 1 && day.Month() == time.January
+	}
+	return false
+}
+
+func interestTransaction(account *models.Account, posting InterestPosting) *models.Transaction {
+	txn := &models.Transaction{
+		ID:                primitive.NewObjectID(),
+		Name:              "Interest Earned",
+		AccountID:         account.ID,
+		AccountNumber:     account.MaskedAccountNumber(),
+		Category:          "Interest",
+		Type:              models.TransactionTypeCredit,
+		Status:            models.TransactionStatusPosted,
+		Amount:            float32(posting.Amount),
+		TransactionDate:   posting.Date,
+		TransactionPosted: posting.Date,
+		Description:       "Monthly interest for " + posting.Date.Format("January 2006"),
+	}
+	if account.IsLiability() {
+		txn.Name = "Interest Charged"
+		txn.Type = models.TransactionTypeDebit
+	}
+	return txn
+}
+
+func validInterestSettings(settings *models.Account) bool {
+	if settings.InterestRate < 0 || settings.InterestRate > 1 {
+		return false
+	}
+	switch settings.InterestMethod {
+	case "", models.InterestMethodSimple, models.InterestMethodCompound:
+	default:
+		return false
+	}
+	switch settings.CompoundingFrequency {
+	case "", models.CompoundingDaily, models.CompoundingMonthly, models.CompoundingQuarterly, models.CompoundingAnnually:
+	default:
+		return false
+	}
+	switch settings.DayCountConvention {
+	case "", models.DayCountActual365, models.DayCountActual360, models.DayCountActualActual, models.DayCount30360:
+	default:
+		return false
+	}
+	return true
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
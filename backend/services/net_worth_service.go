@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SnapshotRunResult reports what one net worth snapshot run did
+type SnapshotRunResult struct {
+	Date      time.Time `json:"date"`
+	Users     int       `json:"users"`
+	Snapshots int       `json:"snapshots"`
+	Failures  int       `json:"failures"`
+}
+
+// NetWorthService records each user's net worth once a day so it can be charted over time
+type NetWorthService struct {
+	users     repository.UserRepository
+	accounts  repository.AccountRepository
+	snapshots repository.NetWorthRepository
+}
+
+func NewNetWorthService(users repository.UserRepository, accounts repository.AccountRepository, snapshots repository.NetWorthRepository) *NetWorthService {
+	return &NetWorthService{users: users, accounts: accounts, snapshots: snapshots}
+}
+
+// TakeSnapshots stores today's net worth for every user with accounts. Running it again
+// the same day replaces that day's snapshots.
+func (s *NetWorthService) TakeSnapshots(ctx context.Context, asOf time.Time) (*SnapshotRunResult, error) {
+	users, err := s.users.GetAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SnapshotRunResult{Date: truncateDay(asOf.UTC()), Users: len(users)}
+	for i := range users {
+		accountIDs, err := userAccountIDs(ctx, s.accounts, users[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(accountIDs) == 0 {
+			continue
+		}
+		if err := s.snapshot(ctx, users[i].ID, accountIDs, result.Date); err != nil {
+			log.Printf("net worth snapshot failed for user %s: %v", users[i].ID.Hex(), err)
+			result.Failures++
+			continue
+		}
+		result.Snapshots++
+	}
+
+	return result, nil
+}
+
+func (s *NetWorthService) snapshot(ctx context.Context, userID primitive.ObjectID, accountIDs []primitive.ObjectID, date time.Time) error {
+	accounts := make([]models.Account, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	snapshot := NetWorth(accounts)
+	snapshot.UserID, snapshot.Date, snapshot.CreatedAt = userID, date, time.Now().UTC()
+	return s.snapshots.SaveSnapshot(ctx, &snapshot)
+}
+
+// NetWorth splits account balances into assets and liabilities. Liability accounts
+// carry negative balances while money is owed, so either kind of account lands on the
+// side its balance's sign says.
+func NetWorth(accounts []models.Account) models.NetWorthSnapshot {
+	var snapshot models.NetWorthSnapshot
+	for _, account := range accounts {
+		if account.CurrentBalance < 0 {
+			snapshot.Liabilities += account.CurrentBalance
+		} else {
+			snapshot.Assets += account.CurrentBalance
+		}
+	}
+	snapshot.Assets, snapshot.Liabilities = roundCents(snapshot.Assets), roundCents(snapshot.Liabilities)
+	snapshot.NetWorth = roundCents(snapshot.Assets + snapshot.Liabilities)
+	return snapshot
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+)
+
+var ErrNoEmailAddress = errors.New("Error: User Has No Email Address")
+
+// smtpTimeout bounds a whole email send when the caller's context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// NotificationChannel delivers fired alerts to a user. Rules name the channels they use.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, user *models.User, notification *models.Notification) error
+}
+
+// InAppChannel stores notifications in the user's inbox
+type InAppChannel struct {
+	alerts repository.AlertRepository
+}
+
+func NewInAppChannel(alerts repository.AlertRepository) *InAppChannel {
+	return &InAppChannel{alerts: alerts}
+}
+
+func (c *InAppChannel) Name() string {
+	return models.ChannelInApp
+}
+
+func (c *InAppChannel) Send(ctx context.Context, user *models.User, notification *models.Notification) error {
+	return c.alerts.CreateNotification(ctx, notification)
+}
+
+// SMTPChannel emails notifications as plain text through an SMTP server. Credentials are
+// optional; net/smtp only sends them over TLS or to localhost.
+type SMTPChannel struct {
+	host string
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPChannel(host string, port int, from, username, password string) *SMTPChannel {
+	channel := &SMTPChannel{host: host, addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from}
+	if username != "" {
+		channel.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return channel
+}
+
+func (c *SMTPChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, user *models.User, notification *models.Notification) error {
+	to := strings.TrimSpace(user.Email)
+	if to == "" {
+		return ErrNoEmailAddress
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Closing the connection unblocks a stalled exchange when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := c.send(conn, to, emailMessage(c.from, to, notification)); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// send runs the exchange smtp.SendMail would over conn, upgrading to TLS when the server
+// offers it
+func (c *SMTPChannel) send(conn net.Conn, to string, msg []byte) error {
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return err
+		}
+	}
+	if c.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(c.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage formats a notification as a plain text email. The subject is kept to one
+// line so text from transactions cannot add headers.
+func emailMessage(from, to string, notification *models.Notification) []byte {
+	subject := strings.Join(strings.Fields(notification.Title), " ")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return msg.Bytes()
+}
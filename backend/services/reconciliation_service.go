@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrReconciliationNotFound   = errors.New("Error: Reconciliation Not Found")
+	ErrReconciliationInProgress = errors.New("Error: Account Already Has A Reconciliation In Progress")
+	ErrReconciliationClosed     = errors.New("Error: Reconciliation Already Completed")
+	ErrReconciliationUnbalanced = errors.New("Error: Cleared Transactions Do Not Match The Statement Balance")
+	ErrTransactionsNotOpen      = errors.New("Error: Transactions Are Not Open On This Account")
+)
+
+// ReconciliationWorksheet is an in-progress reconciliation with the transactions still to review
+type ReconciliationWorksheet struct {
+	Reconciliation *models.Reconciliation `json:"reconciliation"`
+	Transactions   []models.Transaction   `json:"transactions"`
+	ClearedTotal   float64                `json:"cleared_total"`
+	Difference     float64                `json:"difference"`
+}
+
+type ReconciliationService struct {
+	accounts        repository.AccountRepository
+	transactions    repository.TransactionRepository
+	reconciliations repository.ReconciliationRepository
+	tx              repository.TxRunner
+	access          AccessPolicy
+}
+
+func NewReconciliationService(accounts repository.AccountRepository, transactions repository.TransactionRepository, reconciliations repository.ReconciliationRepository, tx repository.TxRunner, access AccessPolicy) *ReconciliationService {
+	return &ReconciliationService{accounts: accounts, transactions: transactions, reconciliations: reconciliations, tx: tx, access: access}
+}
+
+// Start opens a reconciliation against a statement ending balance and date.
+// The opening balance carries over from the last completed reconciliation.
+// An account has at most one reconciliation in progress; the unique index on
+// in-progress reconciliations settles concurrent starts.
+func (s *ReconciliationService) Start(ctx context.Context, userID, accountID primitive.ObjectID, statementBalance float64, statementDate time.Time) (*ReconciliationWorksheet, error) {
+	if _, err := s.accounts.GetAccountByID(ctx, accountID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.reconciliations.GetInProgress(ctx, accountID); err == nil {
+		return nil, ErrReconciliationInProgress
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	rec := &models.Reconciliation{
+		AccountID:        accountID,
+		StatementDate:    statementDate,
+		StatementBalance: statementBalance,
+		Status:           models.ReconciliationInProgress,
+		StartedAt:        time.Now().UTC(),
+	}
+
+	last, err := s.reconciliations.GetLastCompleted(ctx, accountID)
+	if err == nil {
+		rec.OpeningBalance = last.StatementBalance
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	if err := s.reconciliations.CreateReconciliation(ctx, rec); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrReconciliationInProgress
+		}
+		return nil, err
+	}
+
+	return s.worksheet(ctx, rec)
+}
+
+// GetWorksheet lists the unreconciled posted transactions up to the statement date and the remaining difference
+func (s *ReconciliationService) GetWorksheet(ctx context.Context, userID, id primitive.ObjectID) (*ReconciliationWorksheet, error) {
+	rec, err := s.getReconciliation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, rec.AccountID, AccessRead); err != nil {
+		return nil, err
+	}
+	if rec.Status == models.ReconciliationCompleted {
+		return &ReconciliationWorksheet{
+			Reconciliation: rec,
+			Transactions:   []models.Transaction{},
+			ClearedTotal:   rec.ClearedTotal,
+			Difference:     rec.Difference,
+		}, nil
+	}
+
+	return s.worksheet(ctx, rec)
+}
+
+// SetCleared marks transactions as cleared (or uncleared) on the reconciliation's account
+func (s *ReconciliationService) SetCleared(ctx context.Context, userID, id primitive.ObjectID, txnIDs []primitive.ObjectID, cleared bool) (*ReconciliationWorksheet, error) {
+	rec, err := s.openReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := s.transactions.SetCleared(ctx, rec.AccountID, txnIDs, cleared)
+	if err != nil {
+		return nil, err
+	}
+	if matched != int64(len(txnIDs)) {
+		return nil, ErrTransactionsNotOpen
+	}
+
+	return s.worksheet(ctx, rec)
+}
+
+// Complete finishes a balanced reconciliation, locking its cleared transactions and
+// recording how far the account's CurrentBalance is from the statement balance
+func (s *ReconciliationService) Complete(ctx context.Context, userID, id primitive.ObjectID) (*models.Reconciliation, error) {
+	rec, err := s.openReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := s.worksheet(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if math.Abs(sheet.Difference) >= 0.005 {
+		return nil, ErrReconciliationUnbalanced
+	}
+
+	account, err := s.accounts.GetAccountByID(ctx, rec.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.TransactionIDs = []primitive.ObjectID{}
+	for i := range sheet.Transactions {
+		if sheet.Transactions[i].Cleared {
+			rec.TransactionIDs = append(rec.TransactionIDs, sheet.Transactions[i].ID)
+		}
+	}
+	rec.ClearedTotal = sheet.ClearedTotal
+	rec.Difference = sheet.Difference
+	rec.AccountBalance = account.CurrentBalance
+	rec.BalanceDiscrepancy = roundCents(account.CurrentBalance - rec.StatementBalance)
+	rec.Status = models.ReconciliationCompleted
+	rec.CompletedAt = time.Now().UTC()
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.transactions.LockTransactions(ctx, rec.TransactionIDs, rec.ID); err != nil {
+			return err
+		}
+		return s.reconciliations.SaveReconciliation(ctx, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// History lists every reconciliation of the account, newest statement first
+func (s *ReconciliationService) History(ctx context.Context, userID, accountID primitive.ObjectID) ([]models.Reconciliation, error) {
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessRead); err != nil {
+		return nil, err
+	}
+	recs, err := s.reconciliations.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if recs == nil {
+		recs = []models.Reconciliation{}
+	}
+	return recs, nil
+}
+
+func (s *ReconciliationService) worksheet(ctx context.Context, rec *models.Reconciliation) (*ReconciliationWorksheet, error) {
+	// pending authorizations can still change or drop off, so only posted ones reconcile
+	txns, err := s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs:   []primitive.ObjectID{rec.AccountID},
+		End:          rec.StatementDate,
+		Unreconciled: true,
+		Status:       models.TransactionStatusPosted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if txns == nil {
+		txns = []models.Transaction{}
+	}
+
+	sheet := &ReconciliationWorksheet{Reconciliation: rec, Transactions: txns}
+	for i := range txns {
+		if txns[i].Cleared {
+			sheet.ClearedTotal += txns[i].SignedAmount()
+		}
+	}
+	sheet.ClearedTotal = roundCents(sheet.ClearedTotal)
+	sheet.Difference = roundCents(rec.StatementBalance - (rec.OpeningBalance + sheet.ClearedTotal))
+
+	return sheet, nil
+}
+
+func (s *ReconciliationService) getReconciliation(ctx context.Context, id primitive.ObjectID) (*models.Reconciliation, error) {
+	rec, err := s.reconciliations.GetReconciliationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrReconciliationNotFound
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// openReconciliation loads an in-progress reconciliation the user may modify
+func (s *ReconciliationService) openReconciliation(ctx context.Context, userID, id primitive.ObjectID) (*models.Reconciliation, error) {
+	rec, err := s.getReconciliation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, rec.AccountID, AccessWrite); err != nil {
+		return nil, err
+	}
+	if rec.Status != models.ReconciliationInProgress {
+		return nil, ErrReconciliationClosed
+	}
+	return rec, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrInvalidReport      = errors.New("Error: Report Must Group By category, merchant, account Or tag Over day, week, month Or year Buckets")
+	ErrInvalidReportRange = errors.New("Error: Report Range Must End After It Starts And Span At Most 400 Buckets")
+)
+
+// reportMaxBuckets bounds how many time buckets one report may cover
+const reportMaxBuckets = 400
+
+// reportDefaultBuckets is how many buckets, ending with the current one, a report covers
+// when no start date is given
+var reportDefaultBuckets = map[string]int{
+	models.ReportDaily:   30,
+	models.ReportWeekly:  12,
+	models.ReportMonthly: 12,
+	models.ReportYearly:  5,
+}
+
+// ReportRequest selects what a spending report covers. No accounts means every account
+// the user can read.
+type ReportRequest struct {
+	AccountIDs []primitive.ObjectID
+	GroupBy    string
+	Interval   string
+	Start      time.Time
+	End        time.Time
+}
+
+// ReportTotal is the money moved for one group key
+type ReportTotal struct {
+	Key      string  `json:"key"`
+	Label    string  `json:"label"`
+	Income   float64 `json:"income"`
+	Spending float64 `json:"spending"`
+	Net      float64 `json:"net"`
+	Count    int     `json:"count"`
+}
+
+// ReportGroup totals one group key over the whole report, with its average per bucket and
+// how it compares to the previous period of the same length
+type ReportGroup struct {
+	ReportTotal
+	AverageIncome    float64 `json:"average_income"`
+	AverageSpending  float64 `json:"average_spending"`
+	PreviousIncome   float64 `json:"previous_income"`
+	PreviousSpending float64 `json:"previous_spending"`
+	SpendingChange   float64 `json:"spending_change"`
+	// SpendingChangePercent is nil when nothing was spent in the previous period
+	SpendingChangePercent *float64 `json:"spending_change_percent"`
+}
+
+// ReportBucket is one interval of the report, broken down by group key
+type ReportBucket struct {
+	Start    time.Time     `json:"start"`
+	Income   float64       `json:"income"`
+	Spending float64       `json:"spending"`
+	Net      float64       `json:"net"`
+	Groups   []ReportTotal `json:"groups"`
+}
+
+// SpendingReport charts income and spending by group and time bucket. Start and End are
+// widened to whole buckets; the previous period is the same number of buckets just before.
+// In tag reports a transaction with several tags counts toward each, and so the totals
+// count it more than once.
+type SpendingReport struct {
+	GroupBy       string         `json:"group_by"`
+	Interval      string         `json:"interval"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	PreviousStart time.Time      `json:"previous_start"`
+	PreviousEnd   time.Time      `json:"previous_end"`
+	Total         ReportGroup    `json:"total"`
+	Groups        []ReportGroup  `json:"groups"`
+	Buckets       []ReportBucket `json:"buckets"`
+}
+
+// ReportService aggregates transactions into spending reports in the database, so
+// clients can chart spending without downloading every transaction
+type ReportService struct {
+	reports  repository.ReportRepository
+	accounts repository.AccountRepository
+	access   AccessPolicy
+}
+
+func NewReportService(reports repository.ReportRepository, accounts repository.AccountRepository, access AccessPolicy) *ReportService {
+	return &ReportService{reports: reports, accounts: accounts, access: access}
+}
+
+// Spending reports income and spending for the request. Without a start date it covers
+// the last few buckets up to now: 30 days, 12 weeks, 12 months or 5 years.
+func (s *ReportService) Spending(ctx context.Context, userID primitive.ObjectID, req ReportRequest, now time.Time) (*SpendingReport, error) {
+	if !validReportGroup(req.GroupBy) || reportDefaultBuckets[req.Interval] == 0 {
+		return nil, ErrInvalidReport
+	}
+	if req.End.IsZero() {
+		req.End = now
+	}
+	if req.Start.IsZero() {
+		req.Start = addReportInterval(reportBucketStart(req.Interval, req.End), req.Interval, 1-reportDefaultBuckets[req.Interval])
+	}
+	span, err := newReportSpan(req.Interval, req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIDs, err := readableAccountIDs(ctx, s.access, userID, req.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows, previous []repository.ReportRow
+	if len(accountIDs) > 0 {
+		query := repository.ReportQuery{AccountIDs: accountIDs, Start: span.start, End: span.end, GroupBy: req.GroupBy, Interval: req.Interval}
+		if rows, err = s.reports.SpendingTotals(ctx, query); err != nil {
+			return nil, err
+		}
+		query.Start, query.End = span.previousStart, span.previousEnd
+		if previous, err = s.reports.SpendingTotals(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := BuildSpendingReport(req.GroupBy, req.Interval, req.Start, req.End, rows, previous)
+	if err != nil {
+		return nil, err
+	}
+	if req.GroupBy == models.ReportByAccount {
+		if err := s.labelAccounts(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// BuildSpendingReport assembles a report from aggregated rows for the buckets between
+// start and end and from the rows of the previous period. Every bucket is listed, empty
+// or not, and averages are taken over all of them. Groups are ordered by spending, highest first.
+func BuildSpendingReport(groupBy, interval string, start, end time.Time, rows, previous []repository.ReportRow) (*SpendingReport, error) {
+	span, err := newReportSpan(interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SpendingReport{
+		GroupBy:       groupBy,
+		Interval:      interval,
+		Start:         span.start,
+		End:           span.end,
+		PreviousStart: span.previousStart,
+		PreviousEnd:   span.previousEnd,
+		Total:         ReportGroup{ReportTotal: ReportTotal{Label: "Total"}},
+		Groups:        []ReportGroup{},
+		Buckets:       make([]ReportBucket, len(span.buckets)),
+	}
+	bucketIndex := map[int64]int{}
+	for i, bucket := range span.buckets {
+		report.Buckets[i] = ReportBucket{Start: bucket, Groups: []ReportTotal{}}
+		bucketIndex[bucket.Unix()] = i
+	}
+
+	groups := map[string]*ReportGroup{}
+	group := func(row repository.ReportRow) *ReportGroup {
+		g, ok := groups[row.Key]
+		if !ok {
+			g = &ReportGroup{ReportTotal: ReportTotal{Key: row.Key, Label: row.Label}}
+			groups[row.Key] = g
+		}
+		return g
+	}
+	for _, row := range rows {
+		i, ok := bucketIndex[row.Bucket.Unix()]
+		if !ok {
+			continue
+		}
+		bucket := &report.Buckets[i]
+		bucket.Income += row.Income
+		bucket.Spending += row.Spending
+		bucket.Groups = append(bucket.Groups, ReportTotal{Key: row.Key, Label: row.Label, Income: roundCents(row.Income), Spending: roundCents(row.Spending),
+			Net: roundCents(row.Income - row.Spending), Count: row.Count})
+
+		for _, g := range []*ReportGroup{group(row), &report.Total} {
+			g.Income += row.Income
+			g.Spending += row.Spending
+			g.Count += row.Count
+		}
+	}
+	for _, row := range previous {
+		for _, g := range []*ReportGroup{group(row), &report.Total} {
+			g.PreviousIncome += row.Income
+			g.PreviousSpending += row.Spending
+		}
+	}
+
+	for i := range report.Buckets {
+		bucket := &report.Buckets[i]
+		bucket.Net = roundCents(bucket.Income - bucket.Spending)
+		bucket.Income, bucket.Spending = roundCents(bucket.Income), roundCents(bucket.Spending)
+		slices.SortFunc(bucket.Groups, func(a, b ReportTotal) int { return cmp.Compare(a.Key, b.Key) })
+	}
+	buckets := float64(len(span.buckets))
+	for _, g := range append([]*ReportGroup{&report.Total}, slices.Collect(maps.Values(groups))...) {
+		g.Net = roundCents(g.Income - g.Spending)
+		g.AverageIncome = roundCents(g.Income / buckets)
+		g.AverageSpending = roundCents(g.Spending / buckets)
+		g.SpendingChange = roundCents(g.Spending - g.PreviousSpending)
+		if g.PreviousSpending > 0 {
+			percent := roundCents(g.SpendingChange / g.PreviousSpending * 100)
+			g.SpendingChangePercent = &percent
+		}
+		g.Income, g.Spending = roundCents(g.Income), roundCents(g.Spending)
+		g.PreviousIncome, g.PreviousSpending = roundCents(g.PreviousIncome), roundCents(g.PreviousSpending)
+		if g != &report.Total {
+			report.Groups = append(report.Groups, *g)
+		}
+	}
+	slices.SortFunc(report.Groups, func(a, b ReportGroup) int {
+		return cmp.Or(cmp.Compare(b.Spending, a.Spending), cmp.Compare(a.Key, b.Key))
+	})
+
+	return report, nil
+}
+
+// labelAccounts replaces account IDs with account labels in an account report
+func (s *ReportService) labelAccounts(ctx context.Context, report *SpendingReport) error {
+	labels := map[string]string{}
+	label := func(key string) (string, error) {
+		if name, ok := labels[key]; ok {
+			return name, nil
+		}
+		labels[key] = key
+		id, err := primitive.ObjectIDFromHex(key)
+		if err != nil {
+			return key, nil
+		}
+		account, err := s.accounts.GetAccountByID(ctx, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return key, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		labels[key] = account.AccountLabel
+		return account.AccountLabel, nil
+	}
+
+	var err error
+	for i := range report.Groups {
+		if report.Groups[i].Label, err = label(report.Groups[i].Key); err != nil {
+			return err
+		}
+	}
+	for i := range report.Buckets {
+		for j := range report.Buckets[i].Groups {
+			if report.Buckets[i].Groups[j].Label, err = label(report.Buckets[i].Groups[j].Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reportSpan is a report range widened to whole buckets, with the previous period of the same length
+type reportSpan struct {
+	start, end                 time.Time
+	previousStart, previousEnd time.Time
+	buckets                    []time.Time
+}
+
+func newReportSpan(interval string, start, end time.Time) (*reportSpan, error) {
+	if reportDefaultBuckets[interval] == 0 {
+		return nil, ErrInvalidReport
+	}
+	if end.Before(start) {
+		return nil, ErrInvalidReportRange
+	}
+
+	span := &reportSpan{}
+	for bucket := reportBucketStart(interval, start); !bucket.After(end); bucket = addReportInterval(bucket, interval, 1) {
+		if len(span.buckets) == reportMaxBuckets {
+			return nil, ErrInvalidReportRange
+		}
+		span.buckets = append(span.buckets, bucket)
+	}
+	span.start = span.buckets[0]
+	span.end = addReportInterval(span.buckets[len(span.buckets)-1], interval, 1).Add(-time.Nanosecond)
+	span.previousStart = addReportInterval(span.start, interval, -len(span.buckets))
+	span.previousEnd = span.start.Add(-time.Nanosecond)
+	return span, nil
+}
+
+// reportBucketStart truncates t to the start of its bucket in UTC, as $dateTrunc does.
+// Weeks start on Monday.
+func reportBucketStart(interval string, t time.Time) time.Time {
+	day := truncateDay(t)
+	switch interval {
+	case models.ReportWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.ReportMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case models.ReportYearly:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// addReportInterval moves a bucket start n buckets forward, or back when n is negative
+func addReportInterval(bucket time.Time, interval string, n int) time.Time {
+	switch interval {
+	case models.ReportWeekly:
+		return bucket.AddDate(0, 0, 7*n)
+	case models.ReportMonthly:
+		return bucket.AddDate(0, n, 0)
+	case models.ReportYearly:
+		return bucket.AddDate(n, 0, 0)
+	}
+	return bucket.AddDate(0, 0, n)
+}
+
+func validReportGroup(groupBy string) bool {
+	switch groupBy {
+	case models.ReportByCategory, models.ReportByMerchant, models.ReportByAccount, models.ReportByTag:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrProgramNotFound    = errors.New("Error: Reward Program Not Found")
+	ErrInvalidReward      = errors.New("Error: Invalid Reward Program, Rule Or Redemption")
+	ErrInsufficientPoints = errors.New("Error: Not Enough Points To Redeem")
+)
+
+// CardRewards is the rewards position of one card
+type CardRewards struct {
+	AccountID      primitive.ObjectID `json:"account_id"`
+	AccountLabel   string             `json:"account_label"`
+	ProgramID      primitive.ObjectID `json:"program_id"`
+	ProgramName    string             `json:"program_name"`
+	PointValue     float64            `json:"point_value"`
+	PointsEarned   float64            `json:"points_earned"`
+	PointsRedeemed float64            `json:"points_redeemed"`
+	PointsBalance  float64            `json:"points_balance"`
+	BalanceValue   float64            `json:"balance_value"`
+	Spend          float64            `json:"spend"`
+	EffectiveRate  float64            `json:"effective_cashback_rate"`
+	RedeemedValue  float64            `json:"redeemed_value"`
+	EarnedValue    float64            `json:"earned_value"`
+	Rules          []RewardRuleView   `json:"rules"`
+}
+
+// RewardRuleView is an earn rule as shown in the summary
+type RewardRuleView struct {
+	Category   string  `json:"category"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// RewardsSummary totals every card of a user
+type RewardsSummary struct {
+	Cards         []CardRewards `json:"cards"`
+	TotalValue    float64       `json:"total_balance_value"`
+	TotalSpend    float64       `json:"total_spend"`
+	EffectiveRate float64       `json:"effective_cashback_rate"`
+}
+
+type RewardService struct {
+	rewards      repository.RewardRepository
+	users        repository.UserRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	tx           repository.TxRunner
+	access       AccessPolicy
+}
+
+func NewRewardService(rewards repository.RewardRepository, users repository.UserRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository, tx repository.TxRunner, access AccessPolicy) *RewardService {
+	return &RewardService{rewards: rewards, users: users, accounts: accounts, transactions: transactions, tx: tx, access: access}
+}
+
+// Enrich calculates the points a new card purchase earns from the card's earn rules.
+// Each split allocation earns at its own category's multiplier. Cards without rules
+// keep whatever points the bank reported.
+func (s *RewardService) Enrich(ctx context.Context, txn *models.Transaction) error {
+	if txn.AccountID.IsZero() || txn.IsTransfer() || txn.Type != models.TransactionTypeDebit {
+		return nil
+	}
+
+	rules, err := s.rewards.ListRules(ctx, []primitive.ObjectID{txn.AccountID})
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var points float64
+	for _, alloc := range txn.Allocations() {
+		points += float64(alloc.Amount) * earnMultiplier(rules, alloc.Category)
+	}
+	txn.PointsRewarded = float32(math.Round(points*100) / 100)
+
+	return nil
+}
+
+func (s *RewardService) ListPrograms(ctx context.Context) ([]models.RewardProgram, error) {
+	programs, err := s.rewards.ListPrograms(ctx)
+	if programs == nil {
+		programs = []models.RewardProgram{}
+	}
+	return programs, err
+}
+
+func (s *RewardService) CreateProgram(ctx context.Context, program *models.RewardProgram) error {
+	if program.Name == "" || program.PointValue < 0 {
+		return ErrInvalidReward
+	}
+	program.CreatedAt = time.Now().UTC()
+	return s.rewards.CreateProgram(ctx, program)
+}
+
+// ListRules returns the earn rules of a card userID may read
+func (s *RewardService) ListRules(ctx context.Context, userID, accountID primitive.ObjectID) ([]models.RewardRule, error) {
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessRead); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.rewards.ListRules(ctx, []primitive.ObjectID{accountID})
+	if rules == nil {
+		rules = []models.RewardRule{}
+	}
+	return rules, err
+}
+
+// CreateRule adds an earn rule to a card userID may write to
+func (s *RewardService) CreateRule(ctx context.Context, userID primitive.ObjectID, rule *models.RewardRule) error {
+	if rule.AccountID.IsZero() || rule.Multiplier < 0 {
+		return ErrInvalidReward
+	}
+	if err := s.access.CheckAccount(ctx, userID, rule.AccountID, AccessWrite); err != nil {
+		return err
+	}
+	if _, err := s.getProgram(ctx, rule.ProgramID); err != nil {
+		return err
+	}
+	return s.rewards.CreateRule(ctx, rule)
+}
+
+// Redeem spends points from a card's balance at the program's point value. The value is
+// always worked out here; the card's redeemed total is raised in the same transaction as the
+// redemption is recorded, and only while it stays within the points earned.
+func (s *RewardService) Redeem(ctx context.Context, userID primitive.ObjectID, redemption *models.RewardRedemption) error {
+	if redemption.Points <= 0 {
+		return ErrInvalidReward
+	}
+
+	summary, err := s.Summary(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, card := range summary.Cards {
+		if card.AccountID != redemption.AccountID {
+			continue
+		}
+		if redemption.Points > card.PointsBalance+1e-9 {
+			return ErrInsufficientPoints
+		}
+		redemption.ProgramID = card.ProgramID
+		redemption.Value = roundCents(redemption.Points * card.PointValue)
+		redemption.RedeemedAt = time.Now().UTC()
+
+		return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+			err := s.accounts.SpendPoints(ctx, card.AccountID, redemption.Points, card.PointsEarned)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrInsufficientPoints
+			}
+			if err != nil {
+				return err
+			}
+			return s.rewards.CreateRedemption(ctx, redemption)
+		})
+	}
+
+	return ErrAccountNotFound
+}
+
+// Summary reports points balances, their value and the effective cashback rate of each card the user holds
+func (s *RewardService) Summary(ctx context.Context, userID primitive.ObjectID) (*RewardsSummary, error) {
+	accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RewardsSummary{Cards: []CardRewards{}}
+	if len(accountIDs) == 0 {
+		return summary, nil
+	}
+
+	rules, err := s.rewards.ListRules(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	redemptions, err := s.rewards.ListRedemptions(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs:       accountIDs,
+		ExcludeTransfers: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cards := map[primitive.ObjectID]*CardRewards{}
+	var order []primitive.ObjectID
+	for _, rule := range rules {
+		card, ok := cards[rule.AccountID]
+		if !ok {
+			card, err = s.newCard(ctx, rule)
+			if err != nil {
+				return nil, err
+			}
+			cards[rule.AccountID] = card
+			order = append(order, rule.AccountID)
+		}
+		card.Rules = append(card.Rules, RewardRuleView{Category: rule.Category, Multiplier: rule.Multiplier})
+	}
+
+	for i := range txns {
+		card, ok := cards[txns[i].AccountID]
+		if !ok {
+			continue
+		}
+		card.PointsEarned += float64(txns[i].PointsRewarded)
+		if txns[i].Type == models.TransactionTypeDebit {
+			card.Spend += float64(txns[i].Amount)
+		}
+	}
+	for _, redemption := range redemptions {
+		if card, ok := cards[redemption.AccountID]; ok {
+			card.PointsRedeemed += redemption.Points
+			card.RedeemedValue += redemption.Value
+		}
+	}
+
+	var totalEarnedValue float64
+	for _, id := range order {
+		card := cards[id]
+		card.PointsBalance = card.PointsEarned - card.PointsRedeemed
+		card.BalanceValue = roundCents(card.PointsBalance * card.PointValue)
+		card.EarnedValue = roundCents(card.PointsEarned * card.PointValue)
+		card.Spend = roundCents(card.Spend)
+		if card.Spend > 0 {
+			card.EffectiveRate = math.Round(card.EarnedValue/card.Spend*10000) / 10000
+		}
+
+		summary.Cards = append(summary.Cards, *card)
+		summary.TotalValue += card.BalanceValue
+		summary.TotalSpend += card.Spend
+		totalEarnedValue += card.EarnedValue
+	}
+	summary.TotalValue = roundCents(summary.TotalValue)
+	if summary.TotalSpend > 0 {
+		summary.EffectiveRate = math.Round(totalEarnedValue/summary.TotalSpend*10000) / 10000
+	}
+
+	return summary, nil
+}
+
+func (s *RewardService) newCard(ctx context.Context, rule models.RewardRule) (*CardRewards, error) {
+	card := &CardRewards{AccountID: rule.AccountID, ProgramID: rule.ProgramID}
+
+	if account, err := s.accounts.GetAccountByID(ctx, rule.AccountID); err == nil {
+		card.AccountLabel = account.AccountLabel
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	program, err := s.getProgram(ctx, rule.ProgramID)
+	if err != nil && !errors.Is(err, ErrProgramNotFound) {
+		return nil, err
+	}
+	if program != nil {
+		card.ProgramName = program.Name
+		card.PointValue = program.PointValue
+	}
+
+	return card, nil
+}
+
+func (s *RewardService) getProgram(ctx context.Context, id primitive.ObjectID) (*models.RewardProgram, error) {
+	program, err := s.rewards.GetProgramByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrProgramNotFound
+		}
+		return nil, err
+	}
+	return program, nil
+}
+
+// earnMultiplier picks the category's rule, falling back to the card's base rate
+func earnMultiplier(rules []models.RewardRule, category string) float64 {
+	var base float64
+	for _, rule := range rules {
+		if rule.Category == "" {
+			base = rule.Multiplier
+		} else if strings.EqualFold(rule.Category, category) {
+			return rule.Multiplier
+		}
+	}
+	return base
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrDuplicateJob     = errors.New("Error: A Job With This Name Is Already Registered")
+	ErrInvalidJobFilter = errors.New("Error: Job Run Status Must Be succeeded Or failed")
+)
+
+const (
+	// JobLease is how long an instance holds a job's lock, and so the longest a run may take
+	JobLease = 15 * time.Minute
+	// JobMaxRetries is how many times a failed run is retried before waiting for the
+	// next scheduled time
+	JobMaxRetries = 3
+	// jobRetryBase is the delay before the first retry; each retry doubles it
+	jobRetryBase = time.Minute
+	jobRetryMax  = time.Hour
+	// jobRunLimit is how many runs a listing returns by default and at most
+	jobRunLimit = 100
+)
+
+// JobFunc does one run of a background job. Its result is stored with the run.
+type JobFunc func(ctx context.Context, now time.Time) (any, error)
+
+type scheduledJob struct {
+	name     string
+	expr     string
+	schedule CronSchedule
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs on cron schedules. Job state lives in Mongo, so every
+// instance may run a scheduler and a lock makes sure only one of them runs each job.
+// Failed runs are retried with exponential backoff.
+type Scheduler struct {
+	jobs     repository.JobRepository
+	instance string
+	registry []scheduledJob
+}
+
+func NewScheduler(jobs repository.JobRepository) *Scheduler {
+	return &Scheduler{jobs: jobs, instance: schedulerInstance()}
+}
+
+// Register adds a job to run on a cron schedule, such as "0 2 * * *" or "@hourly"
+func (s *Scheduler) Register(name, expr string, run JobFunc) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+	for _, job := range s.registry {
+		if job.name == name {
+			return ErrDuplicateJob
+		}
+	}
+	s.registry = append(s.registry, scheduledJob{name: name, expr: expr, schedule: schedule, run: run})
+	return nil
+}
+
+// Run records the registered jobs and then runs whichever are due every poll interval
+// until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context, poll time.Duration) {
+	if err := s.Ensure(ctx, time.Now().UTC()); err != nil {
+		log.Printf("job registration failed: %v", err)
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Tick(ctx, now.UTC())
+		}
+	}
+}
+
+// Ensure stores every registered job, scheduling new and rescheduled ones from now
+func (s *Scheduler) Ensure(ctx context.Context, now time.Time) error {
+	for _, job := range s.registry {
+		if err := s.jobs.EnsureJob(ctx, job.name, job.expr, job.schedule.Next(now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tick runs every due job this instance can lock, one after another, and returns the runs
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) []models.JobRun {
+	var runs []models.JobRun
+	for _, job := range s.registry {
+		state, err := s.jobs.AcquireLock(ctx, job.name, s.instance, now, now.Add(JobLease))
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("job %s could not be locked: %v", job.name, err)
+			}
+			continue
+		}
+		runs = append(runs, s.execute(ctx, job, state, now))
+	}
+	return runs
+}
+
+// Jobs lists every job's schedule, lock and last outcome
+func (s *Scheduler) Jobs(ctx context.Context) ([]models.JobState, error) {
+	return s.jobs.ListJobs(ctx)
+}
+
+// Runs lists recent runs, optionally of one job or with one status
+func (s *Scheduler) Runs(ctx context.Context, job, status string, limit int64) ([]models.JobRun, error) {
+	if status != "" && status != models.JobRunSucceeded && status != models.JobRunFailed {
+		return nil, ErrInvalidJobFilter
+	}
+	if limit <= 0 || limit > jobRunLimit {
+		limit = jobRunLimit
+	}
+	return s.jobs.ListRuns(ctx, repository.JobRunFilter{Job: job, Status: status, Limit: limit})
+}
+
+// execute runs a locked job, records the run and releases the lock with the job's next
+// run time
+func (s *Scheduler) execute(ctx context.Context, job scheduledJob, state *models.JobState, now time.Time) models.JobRun {
+	run := models.JobRun{Job: job.name, Instance: s.instance, Attempt: state.Attempt, StartedAt: now}
+
+	runCtx, cancel := context.WithTimeout(ctx, JobLease)
+	result, err := safeRun(runCtx, job.run, now)
+	cancel()
+	run.FinishedAt = time.Now().UTC()
+
+	next := job.schedule.Next(now)
+	state.LastRunAt = now
+	if err != nil {
+		log.Printf("job %s failed on attempt %d: %v", job.name, state.Attempt, err)
+		run.Status, run.Error = models.JobRunFailed, err.Error()
+		state.LastStatus, state.LastError = models.JobRunFailed, err.Error()
+		state.Attempt++
+		if retry := now.Add(RetryBackoff(state.Attempt)); state.Attempt <= JobMaxRetries && retry.Before(next) {
+			next = retry
+		} else {
+			state.Attempt = 0
+		}
+	} else {
+		run.Status, run.Result = models.JobRunSucceeded, resultFields(result)
+		state.LastStatus, state.LastError, state.Attempt = models.JobRunSucceeded, "", 0
+	}
+	state.NextRunAt = next
+
+	if err := s.jobs.CreateRun(ctx, &run); err != nil {
+		log.Printf("job %s run could not be recorded: %v", job.name, err)
+	}
+	if err := s.jobs.FinishRun(ctx, job.name, s.instance, state); err != nil {
+		log.Printf("job %s lock could not be released: %v", job.name, err)
+	}
+	return run
+}
+
+// RetryBackoff is how long to wait before a job's nth retry
+func RetryBackoff(attempt int) time.Duration {
+	delay := jobRetryBase
+	for i := 1; i < attempt && delay < jobRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, jobRetryMax)
+}
+
+// safeRun turns a panicking job into a failed run rather than a crashed server
+func safeRun(ctx context.Context, run JobFunc, now time.Time) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx, now)
+}
+
+// resultFields stores a job's result struct under the same field names the API uses
+func resultFields(result any) map[string]any {
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// schedulerInstance names this process in job locks and runs
+func schedulerInstance() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
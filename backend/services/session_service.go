@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SessionTTL = 12 * time.Hour
+	// MinSessionSecret is the shortest secret, in bytes, session tokens may be signed with
+	MinSessionSecret  = 32
+	MinPasswordLength = 8
+
+	passwordIterations = 600_000
+	passwordSaltSize   = 16
+	passwordKeySize    = 32
+)
+
+var (
+	ErrInvalidCredentials = errors.New("Error: Invalid Login Or Password")
+	ErrInvalidSession     = errors.New("Error: Session Is Invalid Or Expired")
+	ErrWeakPassword       = errors.New("Error: Passwords Need At Least 8 Characters")
+)
+
+// sessionHeader is the header of every session token: HMAC-SHA256 signed JWTs
+var sessionHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Session is a verified session token
+type Session struct {
+	ID        string             `json:"id"`
+	UserID    primitive.ObjectID `json:"user_id"`
+	IssuedAt  time.Time          `json:"issued_at"`
+	ExpiresAt time.Time          `json:"expires_at"`
+}
+
+// IssuedLogin is a new session with its token. Users with two-factor authentication
+// still have to verify a code with the session before it reaches anything else.
+type IssuedLogin struct {
+	Session           Session `json:"session"`
+	SessionToken      string  `json:"session_token"`
+	TwoFactorRequired bool    `json:"two_factor_required"`
+}
+
+// sessionClaims are the JWT claims of a session token
+type sessionClaims struct {
+	Subject   string `json:"sub"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// SessionService signs users in with their password and verifies the session tokens it
+// issues, which are JWTs signed with HMAC-SHA256 under a server secret
+type SessionService struct {
+	users  repository.UserRepository
+	secret []byte
+}
+
+func NewSessionService(users repository.UserRepository, secret []byte) *SessionService {
+	return &SessionService{users: users, secret: secret}
+}
+
+// Login checks a username or email and password and issues a session
+func (s *SessionService) Login(ctx context.Context, login, password string) (*IssuedLogin, error) {
+	login = strings.TrimSpace(login)
+	if login == "" {
+		return nil, ErrInvalidCredentials
+	}
+	users, err := s.users.ListUsersByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	var user *models.User
+	for i := range users {
+		if CheckPassword(users[i].PasswordHash, password) {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	token, session, err := s.SignSession(user.ID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedLogin{Session: *session, SessionToken: token, TwoFactorRequired: user.TOTPEnabled}, nil
+}
+
+// SignSession issues a session token for userID, valid for SessionTTL from now
+func (s *SessionService) SignSession(userID primitive.ObjectID, now time.Time) (string, *Session, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	session := &Session{
+		ID:        hex.EncodeToString(raw),
+		UserID:    userID,
+		IssuedAt:  now.Truncate(time.Second),
+		ExpiresAt: now.Add(SessionTTL).Truncate(time.Second),
+	}
+
+	claims, err := json.Marshal(sessionClaims{
+		Subject:   userID.Hex(),
+		ID:        session.ID,
+		IssuedAt:  session.IssuedAt.Unix(),
+		ExpiresAt: session.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	signed := sessionHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signed + "." + s.sign(signed), session, nil
+}
+
+// AuthenticateSession verifies a session token's signature and expiry
+func (s *SessionService) AuthenticateSession(ctx context.Context, token string) (*Session, error) {
+	header, rest, ok := strings.Cut(token, ".")
+	if !ok || header != sessionHeader {
+		return nil, ErrInvalidSession
+	}
+	payload, signature, ok := strings.Cut(rest, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(header+"."+payload))) {
+		return nil, ErrInvalidSession
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, ErrInvalidSession
+	}
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil || claims.ID == "" {
+		return nil, ErrInvalidSession
+	}
+
+	session := &Session{
+		ID:        claims.ID,
+		UserID:    userID,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0).UTC(),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}
+	if !time.Now().UTC().Before(session.ExpiresAt) {
+		return nil, ErrInvalidSession
+	}
+	return session, nil
+}
+
+func (s *SessionService) sign(signed string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HashPassword derives a salted PBKDF2-SHA256 hash of password, stored as
+// "pbkdf2-sha256$<iterations>$<salt>$<key>"
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeySize)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches a hash from HashPassword. Users
+// without a password never match.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrConnectionNotFound = errors.New("Error: Bank Connection Not Found")
+	ErrUnknownProvider    = errors.New("Error: Unknown Aggregation Provider")
+	ErrInvalidConnection  = errors.New("Error: Connection Needs A Provider And An Access Token")
+
+	errSettlesLocked = errors.New("Error: Pending Transaction Is Reconciled Or Void")
+)
+
+// maxSyncPages stops a provider that keeps reporting more pages from looping forever
+const maxSyncPages = 100
+
+// SyncResult reports what one sync of a connection did
+type SyncResult struct {
+	ConnectionID primitive.ObjectID `json:"connection_id"`
+	Accounts     int                `json:"accounts"`
+	Created      int                `json:"created"`
+	Updated      int                `json:"updated"`
+	// Skipped counts posted transactions left out because the pending transaction they
+	// settle is reconciled or void
+	Skipped  int    `json:"skipped"`
+	Failures int    `json:"failures"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SyncRunResult reports what one scheduled sync of every connection did
+type SyncRunResult struct {
+	Connections int `json:"connections"`
+	Created     int `json:"created"`
+	Updated     int `json:"updated"`
+	Skipped     int `json:"skipped"`
+	Failures    int `json:"failures"`
+}
+
+// ConnectResult is a new connection and the outcome of its first sync
+type ConnectResult struct {
+	Connection *models.BankConnection `json:"connection"`
+	Sync       *SyncResult            `json:"sync"`
+}
+
+// SyncService pulls accounts and transactions from aggregation providers into the
+// user's accounts. Everything is keyed by the provider's IDs, so syncing again only
+// updates what changed and pending transactions are replaced by their posted versions.
+type SyncService struct {
+	connections  repository.ConnectionRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	users        repository.UserRepository
+	cipher       *FieldCipher
+	providers    map[string]AggregationProvider
+	enrichers    []TransactionEnricher
+	events       *EventBus
+}
+
+func NewSyncService(connections repository.ConnectionRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository, users repository.UserRepository, cipher *FieldCipher) *SyncService {
+	return &SyncService{
+		connections:  connections,
+		accounts:     accounts,
+		transactions: transactions,
+		users:        users,
+		cipher:       cipher,
+		providers:    map[string]AggregationProvider{},
+	}
+}
+
+// RegisterProvider makes a provider available to new and existing connections
+func (s *SyncService) RegisterProvider(provider AggregationProvider) {
+	s.providers[provider.Name()] = provider
+}
+
+// AddEnricher registers an enricher that runs on every synced transaction before it is stored
+func (s *SyncService) AddEnricher(enricher TransactionEnricher) {
+	s.enrichers = append(s.enrichers, enricher)
+}
+
+// SetEventBus publishes sync.failed, transaction.created and balance.low events on bus
+func (s *SyncService) SetEventBus(bus *EventBus) {
+	s.events = bus
+}
+
+// Connect stores an encrypted access token for the user and runs the first sync
+func (s *SyncService) Connect(ctx context.Context, userID primitive.ObjectID, providerName, accessToken string) (*ConnectResult, error) {
+	accessToken = strings.TrimSpace(accessToken)
+	if providerName == "" || accessToken == "" {
+		return nil, ErrInvalidConnection
+	}
+	if _, ok := s.providers[providerName]; !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	connection := &models.BankConnection{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Provider:  providerName,
+		CreatedAt: time.Now().UTC(),
+	}
+	sealed, err := s.cipher.Encrypt(ctx, accessToken, accessTokenBinding(connection.ID))
+	if err != nil {
+		return nil, err
+	}
+	connection.AccessTokenSealed = sealed
+	if err := s.connections.CreateConnection(ctx, connection); err != nil {
+		return nil, err
+	}
+
+	result, err := s.sync(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	return &ConnectResult{Connection: connection, Sync: result}, nil
+}
+
+func (s *SyncService) ListConnections(ctx context.Context, userID primitive.ObjectID) ([]models.BankConnection, error) {
+	return s.connections.ListByUser(ctx, userID)
+}
+
+// Sync brings one of the user's connections up to date
+func (s *SyncService) Sync(ctx context.Context, userID, connectionID primitive.ObjectID) (*SyncResult, error) {
+	connection, err := s.getConnection(ctx, userID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, connection)
+}
+
+// SyncAll brings every connection of the user up to date. A failing connection is
+// reported in its result without stopping the others.
+func (s *SyncService) SyncAll(ctx context.Context, userID primitive.ObjectID) ([]SyncResult, error) {
+	connections, err := s.connections.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SyncResult, 0, len(connections))
+	for i := range connections {
+		result, err := s.sync(ctx, &connections[i])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// SyncAllConnections brings every user's connections up to date. Connections whose
+// sync fails count as failures; their error is kept on the connection.
+func (s *SyncService) SyncAllConnections(ctx context.Context) (*SyncRunResult, error) {
+	connections, err := s.connections.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncRunResult{Connections: len(connections)}
+	for i := range connections {
+		synced, err := s.sync(ctx, &connections[i])
+		if err != nil {
+			log.Printf("sync failed for connection %s: %v", connections[i].ID.Hex(), err)
+			result.Failures++
+			continue
+		}
+		result.Created += synced.Created
+		result.Updated += synced.Updated
+		result.Skipped += synced.Skipped
+		if synced.Error != "" || synced.Failures > 0 {
+			result.Failures++
+		}
+	}
+
+	return result, nil
+}
+
+// Disconnect forgets a connection and its access token. Synced accounts and their
+// transactions stay with the user.
+func (s *SyncService) Disconnect(ctx context.Context, userID, connectionID primitive.ObjectID) error {
+	if err := s.connections.DeleteConnection(ctx, connectionID, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrConnectionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RotateSecrets re-encrypts access tokens sealed under a retired master key
+func (s *SyncService) RotateSecrets(ctx context.Context, result *KeyRotationResult) error {
+	connections, err := s.connections.ListStaleTokens(ctx, s.cipher.keys.ActiveKeyID())
+	if err != nil {
+		return err
+	}
+	for i := range connections {
+		result.record("connection", connections[i].ID, s.resealToken(ctx, &connections[i]))
+	}
+	return nil
+}
+
+func (s *SyncService) resealToken(ctx context.Context, connection *models.BankConnection) error {
+	sealed, err := s.cipher.Reseal(ctx, connection.AccessTokenSealed, accessTokenBinding(connection.ID))
+	if err != nil || sealed == nil {
+		return err
+	}
+	return s.connections.ResealToken(ctx, connection.ID, connection.AccessTokenSealed, sealed)
+}
+
+// sync pulls a connection's accounts, balances and new transactions. Provider failures
+// are saved on the connection and reported in the result; the cursor only advances
+// when every transaction was stored.
+func (s *SyncService) sync(ctx context.Context, connection *models.BankConnection) (*SyncResult, error) {
+	result := &SyncResult{ConnectionID: connection.ID}
+
+	cursor, syncErr := s.pull(ctx, connection, result)
+	if syncErr != nil {
+		log.Printf("sync failed for connection %s: %v", connection.ID.Hex(), syncErr)
+		result.Error = syncErr.Error()
+		s.events.Publish(ctx, connection.UserID, models.EventSyncFailed, result)
+	}
+
+	now := time.Now().UTC()
+	if err := s.connections.SaveSyncState(ctx, connection.ID, cursor, now, result.Error); err != nil {
+		return nil, err
+	}
+	if syncErr == nil {
+		connection.Cursor, connection.LastSyncedAt, connection.LastError = cursor, now, ""
+	} else {
+		connection.LastError = result.Error
+	}
+	return result, nil
+}
+
+func (s *SyncService) pull(ctx context.Context, connection *models.BankConnection, result *SyncResult) (string, error) {
+	provider, ok := s.providers[connection.Provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	accessToken, err := s.cipher.Decrypt(ctx, connection.AccessTokenSealed, accessTokenBinding(connection.ID))
+	if err != nil {
+		return "", err
+	}
+
+	providerAccounts, err := provider.ListAccounts(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+	accounts := make(map[string]*models.Account, len(providerAccounts))
+	for _, providerAccount := range providerAccounts {
+		account, err := s.accounts.UpsertExternalAccount(ctx, &models.Account{
+			OwnerID:            connection.UserID,
+			AccountLabel:       providerAccount.Name,
+			AccountType:        providerAccount.Type,
+			AccountNumberLast4: providerAccount.Mask,
+			ConnectionID:       connection.ID,
+			ExternalID:         providerAccount.ExternalID,
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := s.users.AddAccount(ctx, connection.UserID, account.ID); err != nil {
+			return "", err
+		}
+		accounts[providerAccount.ExternalID] = account
+	}
+	result.Accounts = len(accounts)
+
+	balances, err := provider.FetchBalances(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+	for _, balance := range balances {
+		account, ok := accounts[balance.AccountExternalID]
+		if !ok {
+			continue
+		}
+		if err := s.accounts.SetBalances(ctx, account.ID, balance.Current, balance.Available); err != nil {
+			return "", err
+		}
+		publishLowBalance(ctx, s.events, connection.UserID, account, balance.Available)
+	}
+
+	cursor := connection.Cursor
+	for pages := 0; pages < maxSyncPages; pages++ {
+		page, err := provider.FetchTransactions(ctx, accessToken, cursor)
+		if err != nil {
+			return "", err
+		}
+		for _, providerTxn := range page.Transactions {
+			account, ok := accounts[providerTxn.AccountExternalID]
+			if !ok {
+				result.Failures++
+				continue
+			}
+			txn := SyncedTransaction(account, providerTxn)
+			created, err := s.storeSynced(ctx, txn, providerTxn.PendingExternalID)
+			if errors.Is(err, errSettlesLocked) {
+				log.Printf("sync skipped transaction %s: pending transaction %s is reconciled or void", providerTxn.ExternalID, providerTxn.PendingExternalID)
+				result.Skipped++
+				continue
+			}
+			if err != nil {
+				log.Printf("sync failed for transaction %s: %v", providerTxn.ExternalID, err)
+				result.Failures++
+				continue
+			}
+			if created {
+				s.events.Publish(ctx, connection.UserID, models.EventTransactionCreated, *txn)
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+		cursor = page.NextCursor
+		if !page.HasMore {
+			break
+		}
+	}
+	if result.Failures > 0 {
+		// upserts are idempotent, so the next sync safely retries from the old cursor
+		return connection.Cursor, nil
+	}
+	return cursor, nil
+}
+
+// storeSynced enriches a synced transaction and upserts it, reporting whether it was created.
+// A posted transaction settling a pending one that is reconciled or void cannot take it
+// over, and is refused with errSettlesLocked rather than stored as a second copy.
+func (s *SyncService) storeSynced(ctx context.Context, txn *models.Transaction, pendingExternalID string) (bool, error) {
+	if pendingExternalID != "" {
+		pending, err := s.transactions.GetExternalTransaction(ctx, txn.AccountID, pendingExternalID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return false, err
+		}
+		if pending != nil && (pending.IsLocked() || pending.Status == models.TransactionStatusVoid) {
+			return false, errSettlesLocked
+		}
+	}
+	if err := enrichTransaction(ctx, s.enrichers, txn); err != nil {
+		return false, err
+	}
+	return s.transactions.UpsertExternalTransaction(ctx, txn, pendingExternalID)
+}
+
+// SyncedTransaction converts a provider transaction on account into a transaction, with
+// the signed amount split into a debit or credit
+func SyncedTransaction(account *models.Account, providerTxn ProviderTransaction) *models.Transaction {
+	txn := &models.Transaction{
+		Name:            providerTxn.Name,
+		AccountID:       account.ID,
+		AccountNumber:   account.MaskedAccountNumber(),
+		Category:        providerTxn.Category,
+		Type:            models.TransactionTypeCredit,
+		Status:          models.TransactionStatusPosted,
+		Amount:          float32(math.Abs(providerTxn.Amount)),
+		TransactionDate: providerTxn.Date,
+		ExternalID:      providerTxn.ExternalID,
+	}
+	if providerTxn.Amount < 0 {
+		txn.Type = models.TransactionTypeDebit
+	}
+	if providerTxn.Pending {
+		txn.Status = models.TransactionStatusPending
+	} else {
+		txn.TransactionPosted = providerTxn.PostedDate
+	}
+	return txn
+}
+
+func (s *SyncService) getConnection(ctx context.Context, userID, id primitive.ObjectID) (*models.BankConnection, error) {
+	connection, err := s.connections.GetConnectionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrConnectionNotFound
+		}
+		return nil, err
+	}
+	if connection.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return connection, nil
+}
+
+func accessTokenBinding(connectionID primitive.ObjectID) string {
+	return connectionID.Hex() + ":access_token"
+}
@@ -0,0 +1,40 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Test NewRecurringCharge - only the second similar charge a month apart is new
+func TestNewRecurringCharge(t *testing.T) {
+	charge := func(name string, amount float32, y int, m time.Month, d int) models.Transaction {
+		return models.Transaction{ID: primitive.NewObjectID(), Name: name, Type: models.TransactionTypeDebit, Amount: amount, TransactionDate: date(y, m, d)}
+	}
+	first := charge("StreamFlix", 15.99, 2026, 8, 14)
+	second := charge("streamflix ", 15.99, 2026, 9, 14)
+	third := charge("StreamFlix", 15.99, 2026, 10, 14)
+	grocery := charge("Corner Grocery", 80, 2026, 9, 14)
+
+	cases := []struct {
+		name    string
+		txn     models.Transaction
+		history []models.Transaction
+		want    bool
+	}{
+		{"first charge", first, []models.Transaction{first}, false},
+		{"second charge", second, []models.Transaction{first, grocery, second}, true},
+		{"third charge", third, []models.Transaction{first, second, third}, false},
+		{"different amount", charge("StreamFlix", 22.99, 2026, 9, 14), []models.Transaction{first}, false},
+		{"same week", charge("StreamFlix", 15.99, 2026, 8, 20), []models.Transaction{first}, false},
+		{"refund", models.Transaction{Name: "StreamFlix", Type: models.TransactionTypeCredit, Amount: 15.99, TransactionDate: date(2026, 9, 14)}, []models.Transaction{first}, false},
+	}
+	for _, tc := range cases {
+		if got := services.NewRecurringCharge(&tc.txn, tc.history); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Test Recurrence - a custom day of month starts on the first matching day after StartDate
+func TestRecurrence_DayOfMonth(t *testing.T) {
+	schedule := models.Recurrence{Frequency: models.FrequencyMonthly, DayOfMonth: 15, StartDate: date(2026, 1, 20)}
+
+	first, ok := schedule.Next(date(2026, 1, 1))
+	if !ok || !first.Equal(date(2026, 2, 15)) {
+		t.Errorf("Expected first period on Feb 15, got %v", first)
+	}
+	next, _ := schedule.Next(first)
+	if !next.Equal(date(2026, 3, 15)) {
+		t.Errorf("Expected next period on Mar 15, got %v", next)
+	}
+
+	yearly := models.Recurrence{Frequency: models.FrequencyYearly, StartDate: date(2024, 2, 29), EndDate: date(2026, 12, 31)}
+	got := yearly.Occurrences(date(2024, 1, 1), date(2030, 1, 1))
+	if len(got) != 3 || !got[1].Equal(date(2025, 2, 28)) {
+		t.Errorf("Expected three yearly occurrences clamped to Feb 28, got %v", got)
+	}
+}
+
+// Test TemplateReport - tagged and categorized spending per period with hit rate and streak
+func TestTemplateReport(t *testing.T) {
+	template := &models.BudgetTemplate{Name: "Food", Categories: []string{"Groceries"}, MinimumSpending: 100, MaximumSpending: 300}
+	var budgets []models.Budget
+	for _, start := range []time.Time{date(2026, 1, 15), date(2026, 2, 15), date(2026, 3, 15), date(2026, 4, 15)} {
+		budgets = append(budgets, models.Budget{
+			ID:              primitive.NewObjectID(),
+			MinimumSpending: 100,
+			MaximumSpending: 300,
+			StartDate:       start,
+			EndDate:         start.AddDate(0, 1, 0).Add(-time.Nanosecond),
+		})
+	}
+	spend := func(on time.Time, txnType, category string, amount float32) models.Transaction {
+		return models.Transaction{Type: txnType, Category: category, Amount: amount, TransactionDate: on}
+	}
+	txns := []models.Transaction{
+		spend(date(2026, 1, 20), models.TransactionTypeDebit, "groceries", 250),
+		spend(date(2026, 2, 16), models.TransactionTypeDebit, "Groceries", 280),
+		{Type: models.TransactionTypeDebit, Category: "Dining", BudgetID: budgets[1].ID, Amount: 100, TransactionDate: date(2026, 3, 1)},
+		spend(date(2026, 3, 14), models.TransactionTypeDebit, "Dining", 75),
+		spend(date(2026, 3, 20), models.TransactionTypeDebit, "Groceries", 200),
+		spend(date(2026, 4, 2), models.TransactionTypeCredit, "Groceries", 30),
+		spend(date(2026, 4, 18), models.TransactionTypeDebit, "Groceries", 50),
+	}
+
+	report := services.TemplateReport(template, budgets, txns, date(2026, 4, 20))
+
+	wantSpent := []float64{250, 380, 170, 50}
+	for i, period := range report.Periods {
+		if period.Spent != wantSpent[i] {
+			t.Errorf("Period %d: expected spent %v, got %v", i, wantSpent[i], period.Spent)
+		}
+	}
+	if !report.Periods[3].Current || report.Periods[2].Current {
+		t.Error("Expected only the last period to be current")
+	}
+	if report.PeriodsCompleted != 3 || report.PeriodsMet != 2 || report.PeriodsOver != 1 || report.CurrentStreak != 1 {
+		t.Errorf("Expected 3 completed, 2 met, 1 over, streak 1, got %+v", report)
+	}
+	if report.TotalSpent != 800 || report.AverageSpent != 266.67 || report.HitRate != 0.6667 {
+		t.Errorf("Expected total 800, average 266.67, hit rate 0.6667, got %v, %v, %v", report.TotalSpent, report.AverageSpent, report.HitRate)
+	}
+}
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Test EquityGrant.Vests - a one year cliff vests a quarter at once, then monthly
+func TestEquityGrant_Vests(t *testing.T) {
+	grant := models.EquityGrant{Shares: 1000, VestingStart: date(2025, 1, 31), VestingMonths: 48, CliffMonths: 12, IntervalMonths: 1}
+
+	vests := grant.Vests()
+	if len(vests) != 37 {
+		t.Fatalf("Expected 37 vests, got %d", len(vests))
+	}
+	if !vests[0].Date.Equal(date(2026, 1, 31)) || vests[0].Shares != 250 {
+		t.Errorf("Expected 250 shares at the cliff, got %+v", vests[0])
+	}
+	if !vests[1].Date.Equal(date(2026, 2, 28)) {
+		t.Errorf("Expected the next vest at the end of February, got %s", vests[1].Date.Format("2006-01-02"))
+	}
+	total := 0
+	for _, vest := range vests {
+		total += vest.Shares
+	}
+	if total != 1000 || !vests[36].Date.Equal(date(2029, 1, 31)) {
+		t.Errorf("Expected all 1000 shares vested by 2029-01-31, got %d by %s", total, vests[36].Date.Format("2006-01-02"))
+	}
+}
+
+// Test MatchBillPayment - payments match by account, payee, amount and due date
+func TestMatchBillPayment(t *testing.T) {
+	checking := primitive.NewObjectID()
+	rent := models.Bill{Payee: "Maple Apartments", Amount: 1450, AccountID: checking, Active: true,
+		Schedule: models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: date(2026, 1, 3)}}
+	power := models.Bill{Payee: "City Power", Amount: 90, Estimated: true, AccountID: checking, Active: true,
+		Schedule: models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: date(2026, 1, 20)}}
+	paid := rent
+	paid.Payments = []models.BillPayment{{DueDate: date(2026, 10, 3)}}
+	debit := func(name string, amount float32, day int) models.Transaction {
+		return models.Transaction{Name: name, AccountID: checking, Type: models.TransactionTypeDebit, Amount: amount, TransactionDate: date(2026, 10, day)}
+	}
+
+	cases := []struct {
+		name string
+		bill models.Bill
+		txn  models.Transaction
+		due  time.Time
+	}{
+		{"exact on time", rent, debit("MAPLE APARTMENTS RENT 0423", 1450, 1), date(2026, 10, 3)},
+		{"late payment", rent, debit("Maple Apartments", 1450, 12), date(2026, 10, 3)},
+		{"punctuated description", rent, debit("MAPLE-APARTMENTS*ONLINE", 1450, 3), date(2026, 10, 3)},
+		{"wrong amount", rent, debit("Maple Apartments", 1400, 3), time.Time{}},
+		{"other payee", rent, debit("Oak Apartments", 1450, 3), time.Time{}},
+		{"already paid", paid, debit("Maple Apartments", 1450, 3), time.Time{}},
+		{"estimated within range", power, debit("City Power & Light", 104.12, 18), date(2026, 10, 20)},
+		{"estimated too high", power, debit("City Power", 140, 18), time.Time{}},
+	}
+	for _, tc := range cases {
+		due, ok := services.MatchBillPayment(&tc.bill, &tc.txn)
+		if ok != !tc.due.IsZero() || !due.Equal(tc.due) {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.name, tc.due.Format("2006-01-02"), due.Format("2006-01-02"), ok)
+		}
+	}
+}
+
+// Test FormatICS - all-day events with escaped text, folded lines and CRLF endings
+func TestFormatICS(t *testing.T) {
+	events := []services.CalendarEvent{
+		{Date: date(2026, 11, 3), Kind: services.CalendarBill, Title: "Maple Apartments, Unit 4; rent due", Amount: 1450, Paid: true, SourceID: primitive.NewObjectID()},
+		{Date: date(2026, 11, 15), Kind: services.CalendarVest, Title: strings.Repeat("Très long grant name ", 5), Shares: 21, SourceID: primitive.NewObjectID()},
+	}
+
+	ics := string(services.FormatICS(events, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"DTSTART;VALUE=DATE:20261103\r\nDTEND;VALUE=DATE:20261104\r\n",
+		`SUMMARY:Maple Apartments\, Unit 4\; rent due`,
+		"DESCRIPTION:$1450.00 (paid)\r\n",
+		"DTSTAMP:20261018T120000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in:\n%s", want, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 2 {
+		t.Errorf("Expected two events")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded to 75 octets, got %d: %q", len(line), line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(ics, "\r\n ", ""); !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("Très long grant name ", 5)) {
+		t.Errorf("Expected the folded summary to unfold intact")
+	}
+}
@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/services"
+)
+
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// fetchAll pages through a fake bank's transactions from cursor
+func fetchAll(t *testing.T, bank *services.FakeBankProvider, token, cursor string) ([]services.ProviderTransaction, string) {
+	t.Helper()
+	var txns []services.ProviderTransaction
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("Expected paging to finish")
+		}
+		page, err := bank.FetchTransactions(context.Background(), token, cursor)
+		if err != nil {
+			t.Fatalf("FetchTransactions failed: %v", err)
+		}
+		txns = append(txns, page.Transactions...)
+		cursor = page.NextCursor
+		if !page.HasMore {
+			return txns, cursor
+		}
+	}
+}
+
+// Test FakeBankProvider - the same token and day always produce the same bank
+func TestFakeBankProvider_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	first := services.NewFakeBankProvider(fixedClock(now))
+	second := services.NewFakeBankProvider(fixedClock(now))
+
+	accounts, err := first.ListAccounts(ctx, "token-a")
+	if err != nil || len(accounts) != 3 {
+		t.Fatalf("Expected three accounts, got %d (%v)", len(accounts), err)
+	}
+	again, _ := second.ListAccounts(ctx, "token-a")
+	other, _ := second.ListAccounts(ctx, "token-b")
+	if !reflect.DeepEqual(accounts, again) || accounts[0].ExternalID == other[0].ExternalID {
+		t.Errorf("Expected accounts to depend only on the token")
+	}
+
+	txns, _ := fetchAll(t, first, "token-a", "")
+	repeat, _ := fetchAll(t, second, "token-a", "")
+	if len(txns) == 0 || !reflect.DeepEqual(txns, repeat) {
+		t.Errorf("Expected identical, non-empty histories, got %d and %d transactions", len(txns), len(repeat))
+	}
+
+	balances, _ := first.FetchBalances(ctx, "token-a")
+	sameBalances, _ := second.FetchBalances(ctx, "token-a")
+	if !reflect.DeepEqual(balances, sameBalances) {
+		t.Errorf("Expected identical balances, got %+v and %+v", balances, sameBalances)
+	}
+
+	if _, err := first.ListAccounts(ctx, " "); err != services.ErrProviderAuth {
+		t.Errorf("Expected an empty token to need re-authorization, got %v", err)
+	}
+}
+
+// Test FakeBankProvider - the cursor resumes after the last posted day and today's
+// pending transactions post the next day
+func TestFakeBankProvider_CursorAndPending(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	today := services.NewFakeBankProvider(fixedClock(now))
+	tomorrow := services.NewFakeBankProvider(fixedClock(now.AddDate(0, 0, 1)))
+
+	txns, cursor := fetchAll(t, today, "token-a", "")
+	if cursor != "2026-10-17" {
+		t.Fatalf("Expected the cursor to stop at yesterday, got %q", cursor)
+	}
+	pending := map[string]bool{}
+	for _, txn := range txns {
+		if txn.Date.Before(now.AddDate(0, 0, -91)) {
+			t.Errorf("Expected history to start 90 days back, got %s", txn.Date)
+		}
+		if txn.Pending {
+			pending[txn.ExternalID] = true
+		}
+	}
+
+	if len(pending) == 0 {
+		t.Fatalf("Expected pending transactions today")
+	}
+
+	next, nextCursor := fetchAll(t, tomorrow, "token-a", cursor)
+	if nextCursor != "2026-10-18" {
+		t.Errorf("Expected the cursor to advance one day, got %q", nextCursor)
+	}
+	posted := 0
+	for _, txn := range next {
+		if txn.Date.Before(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected nothing from before the cursor, got %s", txn.Date)
+		}
+		if pending[txn.PendingExternalID] && !txn.Pending {
+			posted++
+		}
+	}
+	if posted != len(pending) {
+		t.Errorf("Expected all %d pending transactions to post, got %d", len(pending), posted)
+	}
+}
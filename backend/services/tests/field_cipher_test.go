@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/samuriot/track-me/services"
+)
+
+func testKeys(t *testing.T, active string) *services.LocalKeyProvider {
+	t.Helper()
+	keys, err := services.NewLocalKeyProvider(active, map[string][]byte{
+		"2026-04": bytes.Repeat([]byte{1}, 32),
+		"2026-10": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	return keys
+}
+
+// Test FieldCipher - values round trip and never store the plaintext
+func TestFieldCipher_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cipher := services.NewFieldCipher(testKeys(t, "2026-10"))
+
+	sealed, err := cipher.Encrypt(ctx, "000123456789", "acct:account_number")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if sealed.KeyID != "2026-10" || bytes.Contains(sealed.Ciphertext, []byte("123456789")) {
+		t.Errorf("Expected a value sealed under 2026-10 without plaintext, got %+v", sealed)
+	}
+
+	plaintext, err := cipher.Decrypt(ctx, sealed, "acct:account_number")
+	if err != nil || plaintext != "000123456789" {
+		t.Errorf("Expected 000123456789, got %q (%v)", plaintext, err)
+	}
+}
+
+// Test FieldCipher - a value copied to another account or field does not decrypt
+func TestFieldCipher_BindingMismatch(t *testing.T) {
+	ctx := context.Background()
+	cipher := services.NewFieldCipher(testKeys(t, "2026-10"))
+
+	sealed, _ := cipher.Encrypt(ctx, "000123456789", "acct-a:account_number")
+	if _, err := cipher.Decrypt(ctx, sealed, "acct-b:account_number"); err == nil {
+		t.Error("Expected decrypting under another binding to fail")
+	}
+}
+
+// Test FieldCipher - values sealed under a retired key stay readable and are stale
+func TestFieldCipher_RetiredKey(t *testing.T) {
+	ctx := context.Background()
+	old := services.NewFieldCipher(testKeys(t, "2026-04"))
+	sealed, _ := old.Encrypt(ctx, "021000021", "acct:routing_number")
+
+	rotated := services.NewFieldCipher(testKeys(t, "2026-10"))
+	if !rotated.Stale(sealed) {
+		t.Error("Expected a value sealed under 2026-04 to be stale once 2026-10 is active")
+	}
+	plaintext, err := rotated.Decrypt(ctx, sealed, "acct:routing_number")
+	if err != nil || plaintext != "021000021" {
+		t.Errorf("Expected 021000021, got %q (%v)", plaintext, err)
+	}
+}
+
+// Test ValidRoutingNumber - the ABA check digit must match
+func TestValidRoutingNumber(t *testing.T) {
+	cases := map[string]bool{
+		"021000021":  true,
+		"011000015":  true,
+		"021000022":  false,
+		"02100002":   false,
+		"02100002a":  false,
+		"0210000210": false,
+	}
+	for number, want := range cases {
+		if got := services.ValidRoutingNumber(number); got != want {
+			t.Errorf("ValidRoutingNumber(%q) = %v, want %v", number, got, want)
+		}
+	}
+}
+
+// Test GenerateLocalKeyFile - the generated file loads and is never overwritten
+func TestGenerateLocalKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "field_keys.json")
+	if err := services.GenerateLocalKeyFile(path, date(2026, 10, 19)); err != nil {
+		t.Fatalf("GenerateLocalKeyFile failed: %v", err)
+	}
+	keys, err := services.LoadLocalKeyProvider(path)
+	if err != nil || keys.ActiveKeyID() != "2026-10" {
+		t.Fatalf("Expected a key file active on 2026-10, got %v", err)
+	}
+
+	if err := services.GenerateLocalKeyFile(path, date(2026, 11, 1)); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected the existing key file to be kept, got %v", err)
+	}
+}
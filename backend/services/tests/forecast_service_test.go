@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Test Recurrence - monthly schedules clamp to the end of shorter months
+func TestRecurrence_MonthEndClamp(t *testing.T) {
+	schedule := models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: date(2026, 1, 31)}
+
+	got := schedule.Occurrences(date(2026, 1, 1), date(2026, 4, 30))
+	want := []time.Time{date(2026, 1, 31), date(2026, 2, 28), date(2026, 3, 31), date(2026, 4, 30)}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d occurrences, got %v", len(want), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Occurrence %d: expected %s, got %s", i, want[i].Format("2006-01-02"), got[i].Format("2006-01-02"))
+		}
+	}
+}
+
+// Test BuildForecast - income, bills and capped debt payments with floor alerts
+func TestBuildForecast(t *testing.T) {
+	checking := models.Account{ID: primitive.NewObjectID(), AccountLabel: "Checking", AccountType: models.AccountTypeChecking, CurrentBalance: 500}
+	loan := models.Account{ID: primitive.NewObjectID(), AccountLabel: "Car Loan", AccountType: models.AccountTypeLoan, CurrentBalance: -300}
+	monthly := func(start time.Time) models.Recurrence {
+		return models.Recurrence{Frequency: models.FrequencyMonthly, StartDate: start}
+	}
+	items := []models.RecurringItem{
+		{Name: "Rent", Kind: models.RecurringBill, AccountID: checking.ID, Amount: 1000, Schedule: monthly(date(2025, 12, 31)), Active: true},
+		{Name: "Paycheck", Kind: models.RecurringIncome, AccountID: checking.ID, Amount: 1200, Active: true,
+			Schedule: models.Recurrence{Frequency: models.FrequencyBiweekly, StartDate: date(2026, 1, 30)}},
+		{Name: "Loan minimum", Kind: models.RecurringDebtPayment, AccountID: checking.ID, ToAccountID: loan.ID, Amount: 200, Schedule: monthly(date(2026, 1, 5)), Active: true},
+		{Name: "Loan extra", Kind: models.RecurringDebtPayment, AccountID: checking.ID, ToAccountID: loan.ID, Amount: 200, Schedule: monthly(date(2026, 1, 6)), Active: true},
+		{Name: "Old gym", Kind: models.RecurringBill, AccountID: checking.ID, Amount: 50, Schedule: monthly(date(2026, 1, 1)), Active: false},
+	}
+
+	forecast := services.BuildForecast([]models.Account{checking, loan}, items, date(2026, 1, 28), 30, 600)
+
+	checkingForecast, loanForecast := forecast.Accounts[0], forecast.Accounts[1]
+	if len(checkingForecast.Days) != 30 || !checkingForecast.Days[0].Date.Equal(date(2026, 1, 29)) {
+		t.Fatalf("Expected 30 days starting Jan 29, got %d starting %v", len(checkingForecast.Days), checkingForecast.Days[0].Date)
+	}
+	if checkingForecast.EndingBalance != 2800 {
+		t.Errorf("Expected checking to end at 2800, got %v", checkingForecast.EndingBalance)
+	}
+	if loanForecast.EndingBalance != 0 {
+		t.Errorf("Expected the loan to be paid off without overpaying, got %v", loanForecast.EndingBalance)
+	}
+	if checkingForecast.LowestBalance != 400 || !checkingForecast.LowestDate.Equal(date(2026, 2, 6)) {
+		t.Errorf("Expected lowest balance 400 on Feb 6, got %v on %v", checkingForecast.LowestBalance, checkingForecast.LowestDate)
+	}
+
+	if len(forecast.Alerts) != 2 {
+		t.Fatalf("Expected 2 floor alerts, got %+v", forecast.Alerts)
+	}
+	second := forecast.Alerts[1]
+	if second.AccountID != checking.ID || !second.Date.Equal(date(2026, 2, 5)) || second.LowestBalance != 400 {
+		t.Errorf("Expected checking alert from Feb 5 bottoming at 400, got %+v", second)
+	}
+}
@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func deposit(account primitive.ObjectID, amount float32, on models.Transaction) models.Transaction {
+	on.AccountID = account
+	on.Type = models.TransactionTypeCredit
+	on.Amount = amount
+	return on
+}
+
+// Test ProjectGoal - a slowing pace falls behind the required monthly amount
+func TestProjectGoal_BehindPace(t *testing.T) {
+	savings := primitive.NewObjectID()
+	goal := &models.SavingsGoal{
+		AccountIDs:     []primitive.ObjectID{savings},
+		TargetAmount:   12000,
+		StartingAmount: 1000,
+		StartDate:      date(2026, 1, 1),
+		TargetDate:     date(2027, 1, 1),
+	}
+	var txns []models.Transaction
+	for month := 1; month <= 6; month++ {
+		txns = append(txns, deposit(savings, 1000, models.Transaction{TransactionDate: date(2026, 1, 1).AddDate(0, month-1, 0)}))
+	}
+	txns = append(txns, models.Transaction{AccountID: savings, Type: models.TransactionTypeDebit, Amount: 500, TransactionDate: date(2026, 3, 15)})
+
+	progress := services.ProjectGoal(goal, txns, date(2026, 7, 1))
+
+	if progress.Contributed != 5500 || progress.Saved != 6500 || progress.Remaining != 5500 {
+		t.Errorf("Expected contributed 5500, saved 6500, remaining 5500, got %v, %v, %v", progress.Contributed, progress.Saved, progress.Remaining)
+	}
+	if math.Abs(progress.RequiredMonthly-909.82) > 0.01 {
+		t.Errorf("Expected ~909.82 required per month, got %v", progress.RequiredMonthly)
+	}
+	// Only the May and June deposits fall in the 90-day window
+	if math.Abs(progress.RecentMonthlyPace-676.39) > 0.01 {
+		t.Errorf("Expected ~676.39 monthly pace, got %v", progress.RecentMonthlyPace)
+	}
+	if progress.OnTrack {
+		t.Error("Expected goal to be behind pace")
+	}
+	if progress.ProjectedCompletion == nil || !progress.ProjectedCompletion.After(goal.TargetDate) {
+		t.Errorf("Expected projected completion after the target date, got %v", progress.ProjectedCompletion)
+	}
+	if len(progress.Contributions) != 6 || progress.Contributions[2].Month != "2026-03" || progress.Contributions[2].Amount != 500 {
+		t.Errorf("Expected six monthly contributions with March netting 500, got %+v", progress.Contributions)
+	}
+}
+
+// Test ProjectGoal - transfers between linked accounts do not count as contributions
+func TestProjectGoal_InternalTransfersCancel(t *testing.T) {
+	savings, brokerage := primitive.NewObjectID(), primitive.NewObjectID()
+	transferID := primitive.NewObjectID()
+	goal := &models.SavingsGoal{
+		AccountIDs:   []primitive.ObjectID{savings, brokerage},
+		TargetAmount: 3000,
+		StartDate:    date(2026, 5, 1),
+		TargetDate:   date(2026, 12, 1),
+	}
+	txns := []models.Transaction{
+		deposit(savings, 3000, models.Transaction{TransactionDate: date(2026, 5, 2)}),
+		{AccountID: savings, Type: models.TransactionTypeDebit, Amount: 1000, TransferID: transferID, TransactionDate: date(2026, 6, 1)},
+		deposit(brokerage, 1000, models.Transaction{TransferID: transferID, TransactionDate: date(2026, 6, 1)}),
+	}
+
+	progress := services.ProjectGoal(goal, txns, date(2026, 7, 1))
+
+	if progress.Saved != 3000 || !progress.Achieved || !progress.OnTrack {
+		t.Errorf("Expected goal achieved at 3000, got saved %v achieved %v", progress.Saved, progress.Achieved)
+	}
+	if progress.PercentComplete != 100 || progress.RequiredMonthly != 0 {
+		t.Errorf("Expected 100%% complete with nothing required, got %v%% and %v", progress.PercentComplete, progress.RequiredMonthly)
+	}
+}
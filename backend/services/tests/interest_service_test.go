@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Test DayCountFraction - each convention over a single month
+func TestDayCountFraction(t *testing.T) {
+	cases := []struct {
+		convention string
+		from, to   time.Time
+		want       float64
+	}{
+		{models.DayCountActual365, date(2026, 1, 1), date(2026, 2, 1), 31.0 / 365},
+		{models.DayCountActual360, date(2026, 1, 1), date(2026, 2, 1), 31.0 / 360},
+		{models.DayCountActualActual, date(2028, 1, 1), date(2028, 2, 1), 31.0 / 366},
+		{models.DayCount30360, date(2026, 1, 1), date(2026, 2, 1), 30.0 / 360},
+		{models.DayCount30360, date(2026, 1, 30), date(2026, 1, 31), 0},
+		{models.DayCount30360, date(2026, 2, 28), date(2026, 3, 1), 3.0 / 360},
+	}
+
+	for _, tc := range cases {
+		if got := services.DayCountFraction(tc.convention, tc.from, tc.to); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%s %s-%s: expected %v, got %v", tc.convention, tc.from.Format("Jan 2"), tc.to.Format("Jan 2"), tc.want, got)
+		}
+	}
+}
+
+// Test AccrueInterest - simple interest posts at each month end and tracks year to date
+func TestAccrueInterest_SimpleMonthlyPosting(t *testing.T) {
+	account := &models.Account{
+		AccountType:        models.AccountTypeSavings,
+		CurrentBalance:     10000,
+		InterestRate:       0.0365,
+		InterestMethod:     models.InterestMethodSimple,
+		DayCountConvention: models.DayCountActual365,
+		LastAccrualDate:    date(2026, 1, 1),
+		InterestYear:       2025,
+		AcquiredInterest:   120,
+	}
+
+	postings := services.AccrueInterest(account, date(2026, 3, 1))
+
+	if len(postings) != 2 {
+		t.Fatalf("Expected 2 postings, got %d", len(postings))
+	}
+	if postings[0].Amount != 31 || !postings[0].Date.Equal(date(2026, 1, 31)) {
+		t.Errorf("Expected $31.00 posted on Jan 31, got %+v", postings[0])
+	}
+	// Simple interest never earns interest, so February accrues on the original principal
+	if postings[1].Amount != 28 {
+		t.Errorf("Expected $28.00 posted for February, got %v", postings[1].Amount)
+	}
+	if account.CurrentBalance != 10059 {
+		t.Errorf("Expected balance 10059, got %v", account.CurrentBalance)
+	}
+	if account.InterestYear != 2026 || account.AcquiredInterest != 59 {
+		t.Errorf("Expected 2026 year to date of 59, got %d/%v", account.InterestYear, account.AcquiredInterest)
+	}
+}
+
+// Test AccrueInterest - daily compounding over a year matches the closed form
+func TestAccrueInterest_DailyCompounding(t *testing.T) {
+	account := &models.Account{
+		AccountType:          models.AccountTypeSavings,
+		CurrentBalance:       10000,
+		InterestRate:         0.05,
+		InterestMethod:       models.InterestMethodCompound,
+		CompoundingFrequency: models.CompoundingDaily,
+		DayCountConvention:   models.DayCountActual365,
+		LastAccrualDate:      date(2026, 1, 1),
+	}
+
+	services.AccrueInterest(account, date(2027, 1, 1))
+
+	want := 10000 * math.Pow(1+0.05/365, 365)
+	if math.Abs(account.CurrentBalance-want) > 0.05 {
+		t.Errorf("Expected balance near %.2f, got %.2f", want, account.CurrentBalance)
+	}
+}
+
+// Test AccrueInterest - loans are charged interest that increases the amount owed
+func TestAccrueInterest_LoanCharges(t *testing.T) {
+	account := &models.Account{
+		AccountType:     models.AccountTypeLoan,
+		CurrentBalance:  -12000,
+		InterestRate:    0.0365,
+		InterestMethod:  models.InterestMethodSimple,
+		LastAccrualDate: date(2026, 4, 1),
+	}
+
+	postings := services.AccrueInterest(account, date(2026, 5, 1))
+
+	if len(postings) != 1 || postings[0].Amount != 36 {
+		t.Fatalf("Expected a single $36.00 charge, got %+v", postings)
+	}
+	if account.CurrentBalance != -12036 {
+		t.Errorf("Expected balance -12036, got %v", account.CurrentBalance)
+	}
+}
@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"github.com/samuriot/track-me/services"
+)
+
+// Test BuildSpendingReport - buckets widen to whole months, empty ones stay, averages span
+// every bucket and groups compare against the previous three months
+func TestBuildSpendingReport(t *testing.T) {
+	rows := []repository.ReportRow{
+		{Bucket: date(2026, time.January, 1), Key: "Groceries", Label: "Groceries", Spending: 300, Count: 5},
+		{Bucket: date(2026, time.January, 1), Key: "Salary", Label: "Salary", Income: 4000, Count: 1},
+		{Bucket: date(2026, time.March, 1), Key: "Groceries", Label: "Groceries", Spending: 150.10, Count: 2},
+	}
+	previous := []repository.ReportRow{
+		{Bucket: date(2025, time.November, 1), Key: "Groceries", Label: "Groceries", Spending: 600.40},
+		{Bucket: date(2025, time.December, 1), Key: "Dining", Label: "Dining", Spending: 90},
+	}
+
+	report, err := services.BuildSpendingReport(models.ReportByCategory, models.ReportMonthly, date(2026, time.January, 15), date(2026, time.March, 10), rows, previous)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !report.Start.Equal(date(2026, time.January, 1)) || !report.End.Equal(date(2026, time.April, 1).Add(-time.Nanosecond)) ||
+		!report.PreviousStart.Equal(date(2025, time.October, 1)) || !report.PreviousEnd.Equal(date(2026, time.January, 1).Add(-time.Nanosecond)) {
+		t.Errorf("Expected January through March against October through December, got %v-%v against %v-%v",
+			report.Start, report.End, report.PreviousStart, report.PreviousEnd)
+	}
+	if len(report.Buckets) != 3 || report.Buckets[1].Spending != 0 || len(report.Buckets[1].Groups) != 0 ||
+		report.Buckets[0].Net != 3700 || len(report.Buckets[0].Groups) != 2 {
+		t.Errorf("Expected three buckets with an empty February, got %+v", report.Buckets)
+	}
+
+	if len(report.Groups) != 3 || report.Groups[0].Key != "Groceries" || report.Groups[1].Key != "Dining" || report.Groups[2].Key != "Salary" {
+		t.Fatalf("Expected groups ordered by spending then key, got %+v", report.Groups)
+	}
+	groceries := report.Groups[0]
+	if groceries.Spending != 450.10 || groceries.Count != 7 || groceries.AverageSpending != 150.03 || groceries.PreviousSpending != 600.40 ||
+		groceries.SpendingChange != -150.30 || groceries.SpendingChangePercent == nil || *groceries.SpendingChangePercent != -25.03 {
+		t.Errorf("Unexpected groceries totals: %+v", groceries)
+	}
+	if dining := report.Groups[1]; dining.Spending != 0 || dining.SpendingChange != -90 || *dining.SpendingChangePercent != -100 {
+		t.Errorf("Expected dining to drop to nothing, got %+v", dining)
+	}
+	if salary := report.Groups[2]; salary.Income != 4000 || salary.AverageIncome != 1333.33 || salary.SpendingChangePercent != nil {
+		t.Errorf("Expected salary income without a spending change percentage, got %+v", salary)
+	}
+	if total := report.Total; total.Spending != 450.10 || total.Income != 4000 || total.PreviousSpending != 690.40 || total.Count != 8 {
+		t.Errorf("Unexpected report total: %+v", total)
+	}
+}
+
+// Test BuildSpendingReport - weeks start on Monday and ranges are bounded
+func TestBuildSpendingReport_Range(t *testing.T) {
+	report, err := services.BuildSpendingReport(models.ReportByTag, models.ReportWeekly, date(2026, time.October, 14), date(2026, time.October, 19), nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Buckets) != 2 || !report.Buckets[0].Start.Equal(date(2026, time.October, 12)) || !report.PreviousStart.Equal(date(2026, time.September, 28)) {
+		t.Errorf("Expected the weeks of October 12 and 19 against the two before, got %+v from %v", report.Buckets, report.PreviousStart)
+	}
+
+	if _, err := services.BuildSpendingReport(models.ReportByTag, models.ReportDaily, date(2025, time.January, 1), date(2026, time.June, 1), nil, nil); !errors.Is(err, services.ErrInvalidReportRange) {
+		t.Errorf("Expected 500 days to be refused, got %v", err)
+	}
+	if _, err := services.BuildSpendingReport(models.ReportByTag, models.ReportMonthly, date(2026, time.June, 1), date(2026, time.January, 1), nil, nil); !errors.Is(err, services.ErrInvalidReportRange) {
+		t.Errorf("Expected a range ending before it starts to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+// Test ParseCron - schedules fire at the next matching minute
+func TestCronSchedule_Next(t *testing.T) {
+	// Sunday 2026-10-18 14:07 UTC
+	after := time.Date(2026, 10, 18, 14, 7, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 18, 14, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 18, 14, 15, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 31 * *", time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// both day fields restricted: the 1st of the month or any Friday
+		{"0 0 1 * 5", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		schedule, err := services.ParseCron(tc.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) failed: %v", tc.expr, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(tc.want) {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+
+	never, _ := services.ParseCron("0 0 30 2 *")
+	if got := never.Next(after); !got.IsZero() {
+		t.Errorf("Expected Feb 30 never to fire, got %s", got)
+	}
+}
+
+// Test ParseCron - malformed expressions are refused
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@often"} {
+		if _, err := services.ParseCron(expr); err != services.ErrInvalidCron {
+			t.Errorf("Expected %q to be invalid, got %v", expr, err)
+		}
+	}
+}
+
+// Test RetryBackoff - delays double from a minute up to an hour
+func TestRetryBackoff(t *testing.T) {
+	want := map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 3: 4 * time.Minute, 7: time.Hour, 20: time.Hour}
+	for attempt, delay := range want {
+		if got := services.RetryBackoff(attempt); got != delay {
+			t.Errorf("Attempt %d: expected %s, got %s", attempt, delay, got)
+		}
+	}
+}
+
+// Test NetWorth - negative balances count as liabilities whatever the account type
+func TestNetWorth(t *testing.T) {
+	snapshot := services.NetWorth([]models.Account{
+		{AccountType: models.AccountTypeChecking, CurrentBalance: 1250.10},
+		{AccountType: models.AccountTypeSavings, CurrentBalance: 8000},
+		{AccountType: models.AccountTypeCreditCard, CurrentBalance: -640.35},
+		{AccountType: models.AccountTypeCreditCard, CurrentBalance: 25},
+		{AccountType: models.AccountTypeChecking, CurrentBalance: -10},
+	})
+	if snapshot.Assets != 9275.10 || snapshot.Liabilities != -650.35 || snapshot.NetWorth != 8624.75 {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+}
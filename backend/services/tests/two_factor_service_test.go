@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/services"
+)
+
+// Test TOTPCode - matches the SHA1 test vectors of RFC 6238, truncated to six digits
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		if got := services.TOTPCode(secret, unix/services.TOTPPeriod); got != want {
+			t.Errorf("TOTPCode at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+// Test MatchTOTP - codes from one step either side are accepted, older ones are not
+func TestMatchTOTP_AllowsOneStepOfDrift(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / services.TOTPPeriod
+
+	for _, drift := range []int64{-1, 0, 1} {
+		matched, ok := services.MatchTOTP(secret, services.TOTPCode(secret, step+drift), now)
+		if !ok || matched != step+drift {
+			t.Errorf("Expected a code %d steps off to match step %d, got %d, %v", drift, step+drift, matched, ok)
+		}
+	}
+	if _, ok := services.MatchTOTP(secret, services.TOTPCode(secret, step-2), now); ok {
+		t.Error("Expected a code two steps old to be refused")
+	}
+	if _, ok := services.MatchTOTP(secret, "12345", now); ok {
+		t.Error("Expected a malformed code to be refused")
+	}
+}
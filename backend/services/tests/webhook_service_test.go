@@ -0,0 +1,44 @@
+package services_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/services"
+)
+
+// Test SignWebhook - the signature is an HMAC-SHA256 of the timestamp and body
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"type":"webhook.test"}`)
+	mac := hmac.New(sha256.New, []byte("tm_whsec_secret"))
+	mac.Write([]byte("1792300000." + string(body)))
+	want := "t=1792300000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := services.SignWebhook("tm_whsec_secret", 1792300000, body); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if services.SignWebhook("other", 1792300000, body) == want || services.SignWebhook("tm_whsec_secret", 1792300001, body) == want {
+		t.Errorf("Expected the secret and timestamp to change the signature")
+	}
+}
+
+// Test WebhookBackoff - retries double from 30 seconds up to six hours
+func TestWebhookBackoff(t *testing.T) {
+	want := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 5: 8 * time.Minute, 12: 6 * time.Hour}
+	for attempt, delay := range want {
+		if got := services.WebhookBackoff(attempt); got != delay {
+			t.Errorf("Attempt %d: expected %s, got %s", attempt, delay, got)
+		}
+	}
+}
+
+// Test ValidEventType - test events cannot be subscribed to
+func TestValidEventType(t *testing.T) {
+	if !models.ValidEventType(models.EventSyncFailed) || models.ValidEventType(models.EventWebhookTest) || models.ValidEventType("budget.created") {
+		t.Errorf("Unexpected event type validation")
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Token lifetimes, in days
+const (
+	DefaultTokenDays = 90
+	MaxTokenDays     = 365
+)
+
+// tokenTouchInterval limits how often last-used tracking writes for a busy token
+const tokenTouchInterval = time.Minute
+
+var (
+	ErrTokenNotFound = errors.New("Error: Access Token Not Found")
+	ErrInvalidToken  = errors.New("Error: Access Token Is Invalid, Revoked Or Expired")
+	ErrInvalidScopes = errors.New("Error: Invalid Token Name, Scopes Or Expiry")
+)
+
+// IssuedToken is a new token with its secret, which is only ever shown once
+type IssuedToken struct {
+	Token       models.PersonalAccessToken `json:"token"`
+	AccessToken string                     `json:"access_token"`
+}
+
+type TokenService struct {
+	tokens repository.TokenRepository
+	users  repository.UserRepository
+}
+
+func NewTokenService(tokens repository.TokenRepository, users repository.UserRepository) *TokenService {
+	return &TokenService{tokens: tokens, users: users}
+}
+
+// CreateToken issues a token for userID. Only admins may grant the admin scope.
+func (s *TokenService) CreateToken(ctx context.Context, userID primitive.ObjectID, name string, scopes []string, days int) (*IssuedToken, error) {
+	name = strings.TrimSpace(name)
+	if days == 0 {
+		days = DefaultTokenDays
+	}
+	if name == "" || len(scopes) == 0 || days < 0 || days > MaxTokenDays {
+		return nil, ErrInvalidScopes
+	}
+	for _, scope := range scopes {
+		if !models.ValidScope(scope) {
+			return nil, ErrInvalidScopes
+		}
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if slices.Contains(scopes, models.ScopeAdmin) && user.EffectiveRole() != models.RoleAdmin {
+		return nil, ErrForbidden
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := models.TokenPrefix + hex.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	token := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Hint:      secret[:len(models.TokenPrefix)+4],
+		TokenHash: hashToken(secret),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		ExpiresAt: now.AddDate(0, 0, days),
+		CreatedAt: now,
+	}
+	if err := s.tokens.CreateToken(ctx, &token); err != nil {
+		return nil, err
+	}
+	return &IssuedToken{Token: token, AccessToken: secret}, nil
+}
+
+func (s *TokenService) ListTokens(ctx context.Context, userID primitive.ObjectID) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.tokens.ListByUser(ctx, userID)
+	if tokens == nil {
+		tokens = []models.PersonalAccessToken{}
+	}
+	return tokens, err
+}
+
+func (s *TokenService) RevokeToken(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.tokens.RevokeToken(ctx, id, userID, time.Now().UTC()); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AuthenticateToken resolves a bearer token to its stored record and records its use
+func (s *TokenService) AuthenticateToken(ctx context.Context, secret string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(secret, models.TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	token, err := s.tokens.GetTokenByHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !token.Usable(now) {
+		return nil, ErrInvalidToken
+	}
+	if now.Sub(token.LastUsedAt) >= tokenTouchInterval {
+		if err := s.tokens.TouchToken(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = now
+	}
+	return token, nil
+}
@@ -0,0 +1,619 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("Error: Transaction Not Found")
+	ErrInvalidSplit        = errors.New("Error: Splits Must Be Positive, Categorized And Sum To The Transaction Amount")
+	ErrTransactionLocked   = errors.New("Error: Transaction Is Reconciled And Locked")
+	ErrTransactionVoid     = errors.New("Error: Transaction Is Already Void")
+	ErrInvalidStatus       = errors.New("Error: Invalid Transaction Status")
+	ErrInvalidTags         = errors.New("Error: Transactions Take At Most 20 Tags Of Up To 40 Characters")
+)
+
+const (
+	// pendingMatchWindow is how long a pending authorization may take to post
+	pendingMatchWindow = 10 * 24 * time.Hour
+	// pendingTipAllowance is how much larger than its authorization a posted amount may be (e.g. a tip)
+	pendingTipAllowance = 0.30
+	// maxTags and maxTagLength bound the tags on one transaction
+	maxTags      = 20
+	maxTagLength = 40
+)
+
+// ImportResult counts what happened to each imported transaction
+type ImportResult struct {
+	Created          []models.Transaction `json:"created"`
+	Posted           []models.Transaction `json:"posted"`
+	AvailableBalance float64              `json:"available_balance"`
+}
+
+// CashFlowSummary totals money in and out of a set of accounts.
+// Transfers between accounts are neither income nor spending and are left out.
+type CashFlowSummary struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Income   float64   `json:"income"`
+	Spending float64   `json:"spending"`
+	Net      float64   `json:"net"`
+}
+
+// CategoryTotal is the money moved in one category, counting split allocations separately
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Income   float64 `json:"income"`
+	Spending float64 `json:"spending"`
+	Count    int     `json:"count"`
+}
+
+// LowBalance is the data of a balance.low event, raised when an asset account's
+// available balance drops below zero
+type LowBalance struct {
+	AccountID        primitive.ObjectID `json:"account_id"`
+	AccountLabel     string             `json:"account_label"`
+	AvailableBalance float64            `json:"available_balance"`
+}
+
+// publishLowBalance raises balance.low when available takes an asset account's
+// available balance from zero or more to below zero
+func publishLowBalance(ctx context.Context, bus *EventBus, userID primitive.ObjectID, account *models.Account, available float64) {
+	if account.IsLiability() || account.AvailableBalance < 0 || available >= 0 {
+		return
+	}
+	bus.Publish(ctx, userID, models.EventBalanceLow, LowBalance{
+		AccountID:        account.ID,
+		AccountLabel:     account.AccountLabel,
+		AvailableBalance: available,
+	})
+}
+
+// TransactionEnricher fills in derived fields, such as reward points, before a new transaction is stored
+type TransactionEnricher interface {
+	Enrich(ctx context.Context, txn *models.Transaction) error
+}
+
+// enrichTransaction runs every enricher over txn in the order they were added
+func enrichTransaction(ctx context.Context, enrichers []TransactionEnricher, txn *models.Transaction) error {
+	for _, enricher := range enrichers {
+		if err := enricher.Enrich(ctx, txn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type TransactionService struct {
+	repo      repository.TransactionRepository
+	accounts  repository.AccountRepository
+	budgets   repository.BudgetRepository
+	txRunner  repository.TxRunner
+	access    AccessPolicy
+	enrichers []TransactionEnricher
+	events    *EventBus
+}
+
+func NewTransactionService(repo repository.TransactionRepository, accounts repository.AccountRepository, budgets repository.BudgetRepository, txRunner repository.TxRunner, access AccessPolicy) *TransactionService {
+	return &TransactionService{repo: repo, accounts: accounts, budgets: budgets, txRunner: txRunner, access: access}
+}
+
+// AddEnricher registers an enricher that runs on every imported transaction and
+// again whenever a transaction's splits change
+func (s *TransactionService) AddEnricher(enricher TransactionEnricher) {
+	s.enrichers = append(s.enrichers, enricher)
+}
+
+// SetEventBus publishes transaction.created and balance.low events on bus
+func (s *TransactionService) SetEventBus(bus *EventBus) {
+	s.events = bus
+}
+
+// ListTransactions returns the transactions of the given accounts, optionally filtered
+// by status. Without accounts it covers every account userID can read.
+func (s *TransactionService) ListTransactions(ctx context.Context, userID primitive.ObjectID, accountIDs []primitive.ObjectID, status string, start, end time.Time) ([]models.Transaction, error) {
+	if !validStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	accountIDs, err := s.readableAccounts(ctx, userID, accountIDs)
+	if err != nil || len(accountIDs) == 0 {
+		return []models.Transaction{}, err
+	}
+
+	txns, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: accountIDs,
+		Start:      start,
+		End:        end,
+		Status:     status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if txns == nil {
+		txns = []models.Transaction{}
+	}
+	return txns, nil
+}
+
+// Import records transactions for an account. A posted transaction that matches an
+// outstanding pending authorization settles it in place instead of creating a duplicate;
+// the posted amount may be higher than the authorization to allow for tips. Posted
+// transactions move the account's current balance in the same database transaction that
+// stores them; pending ones only count against the available balance.
+func (s *TransactionService) Import(ctx context.Context, userID, accountID primitive.ObjectID, incoming []models.Transaction) (*ImportResult, error) {
+	if err := s.access.CheckAccount(ctx, userID, accountID, AccessWrite); err != nil {
+		return nil, err
+	}
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	pending, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: []primitive.ObjectID{account.ID},
+		Status:     models.TransactionStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Created: []models.Transaction{}, Posted: []models.Transaction{}}
+	for i := range incoming {
+		txn := incoming[i]
+		if !validStatus(txn.Status) || txn.Status == models.TransactionStatusVoid {
+			return nil, ErrInvalidStatus
+		}
+		if txn.Tags, err = normalizeTags(txn.Tags); err != nil {
+			return nil, err
+		}
+		txn.ID = primitive.NilObjectID
+		txn.AccountID = account.ID
+		txn.AccountNumber = account.MaskedAccountNumber()
+		txn.CreatedBy = userID
+		if txn.Status == "" {
+			txn.Status = models.TransactionStatusPosted
+		}
+		if txn.Status == models.TransactionStatusPosted && txn.TransactionPosted.IsZero() {
+			txn.TransactionPosted = txn.TransactionDate
+		}
+		if err := enrichTransaction(ctx, s.enrichers, &txn); err != nil {
+			return nil, err
+		}
+
+		if txn.Status == models.TransactionStatusPosted {
+			if match := matchPending(pending, &txn); match >= 0 {
+				var posted *models.Transaction
+				err := s.txRunner.WithTransaction(ctx, func(ctx context.Context) error {
+					var err error
+					if posted, err = s.repo.PostPending(ctx, pending[match].ID, &txn); err != nil {
+						if errors.Is(err, mongo.ErrNoDocuments) {
+							return ErrTransactionLocked
+						}
+						return err
+					}
+					return s.applyToBalance(ctx, posted, 1)
+				})
+				if err != nil {
+					return nil, err
+				}
+				pending = append(pending[:match], pending[match+1:]...)
+				result.Posted = append(result.Posted, *posted)
+				continue
+			}
+		}
+
+		err := s.txRunner.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := s.repo.CreateTransaction(ctx, &txn); err != nil {
+				return err
+			}
+			return s.applyToBalance(ctx, &txn, 1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if txn.Status == models.TransactionStatusPending {
+			pending = append(pending, txn)
+		}
+		result.Created = append(result.Created, txn)
+	}
+
+	result.AvailableBalance, err = s.RefreshAvailableBalance(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Created {
+		s.events.Publish(ctx, userID, models.EventTransactionCreated, result.Created[i])
+	}
+	publishLowBalance(ctx, s.events, userID, account, result.AvailableBalance)
+
+	return result, nil
+}
+
+// Void cancels a transaction that will never settle, such as an expired authorization.
+// Voiding a posted transaction takes its amount back off the account's balance. Both
+// legs of a transfer are voided together, so money never leaves one account without
+// arriving in the other.
+func (s *TransactionService) Void(ctx context.Context, userID, id primitive.ObjectID) (*models.Transaction, error) {
+	txn, err := s.writableTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	legs := []models.Transaction{*txn}
+	if !txn.TransferID.IsZero() {
+		if legs, err = s.repo.GetTransactionsByTransferID(ctx, txn.TransferID); err != nil {
+			return nil, err
+		}
+	}
+	for i := range legs {
+		if legs[i].ID != txn.ID {
+			if err := s.access.CheckAccount(ctx, userID, legs[i].AccountID, AccessWrite); err != nil {
+				return nil, err
+			}
+		}
+		if legs[i].IsLocked() {
+			return nil, ErrTransactionLocked
+		}
+		if legs[i].EffectiveStatus() == models.TransactionStatusVoid {
+			return nil, ErrTransactionVoid
+		}
+	}
+
+	err = s.txRunner.WithTransaction(ctx, func(ctx context.Context) error {
+		for i := range legs {
+			if err := s.repo.UpdateStatus(ctx, legs[i].ID, models.TransactionStatusVoid); err != nil {
+				return err
+			}
+			if err := s.applyToBalance(ctx, &legs[i], -1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	txn.Status = models.TransactionStatusVoid
+
+	for i := range legs {
+		if legs[i].AccountID.IsZero() {
+			continue
+		}
+		if _, err := s.RefreshAvailableBalance(ctx, legs[i].AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	return txn, nil
+}
+
+// applyToBalance moves the account's balance by a posted transaction's signed amount, or
+// takes it back off when direction is -1. Pending and void transactions leave it alone.
+func (s *TransactionService) applyToBalance(ctx context.Context, txn *models.Transaction, direction float64) error {
+	if txn.AccountID.IsZero() || txn.EffectiveStatus() != models.TransactionStatusPosted {
+		return nil
+	}
+	return s.accounts.AdjustBalance(ctx, txn.AccountID, direction*txn.SignedAmount())
+}
+
+// RefreshAvailableBalance stores CurrentBalance minus outstanding pending debits as the account's available balance
+func (s *TransactionService) RefreshAvailableBalance(ctx context.Context, accountID primitive.ObjectID) (float64, error) {
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrAccountNotFound
+		}
+		return 0, err
+	}
+
+	pending, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: []primitive.ObjectID{accountID},
+		Status:     models.TransactionStatusPending,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	available := account.CurrentBalance
+	for i := range pending {
+		if pending[i].Type == models.TransactionTypeDebit {
+			available -= float64(pending[i].Amount)
+		}
+	}
+	available = roundCents(available)
+
+	if err := s.accounts.SetAvailableBalance(ctx, accountID, available); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
+
+// Summarize reports income and spending for the given accounts between start and end
+func (s *TransactionService) Summarize(ctx context.Context, userID primitive.ObjectID, accountIDs []primitive.ObjectID, start, end time.Time) (*CashFlowSummary, error) {
+	accountIDs, err := s.readableAccounts(ctx, userID, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(accountIDs) == 0 {
+		return &CashFlowSummary{Start: start, End: end}, nil
+	}
+
+	txns, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs: accountIDs,
+		Start:      start,
+		End:        end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CashFlowSummary{Start: start, End: end}
+	for i := range txns {
+		if txns[i].IsTransfer() {
+			continue
+		}
+		amount := txns[i].SignedAmount()
+		if amount >= 0 {
+			summary.Income += amount
+		} else {
+			summary.Spending -= amount
+		}
+	}
+	summary.Net = summary.Income - summary.Spending
+
+	return summary, nil
+}
+
+// CategoryTotals groups income and spending by category, attributing each split to its own category
+func (s *TransactionService) CategoryTotals(ctx context.Context, userID primitive.ObjectID, accountIDs []primitive.ObjectID, start, end time.Time) ([]CategoryTotal, error) {
+	accountIDs, err := s.readableAccounts(ctx, userID, accountIDs)
+	if err != nil || len(accountIDs) == 0 {
+		return []CategoryTotal{}, err
+	}
+
+	txns, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs:       accountIDs,
+		Start:            start,
+		End:              end,
+		ExcludeTransfers: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := map[string]*CategoryTotal{}
+	for i := range txns {
+		for _, alloc := range txns[i].Allocations() {
+			total, ok := byCategory[alloc.Category]
+			if !ok {
+				total = &CategoryTotal{Category: alloc.Category}
+				byCategory[alloc.Category] = total
+			}
+			if txns[i].Type == models.TransactionTypeDebit {
+				total.Spending += float64(alloc.Amount)
+			} else {
+				total.Income += float64(alloc.Amount)
+			}
+			total.Count++
+		}
+	}
+
+	totals := make([]CategoryTotal, 0, len(byCategory))
+	for _, total := range byCategory {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(a, b int) bool {
+		return totals[a].Spending > totals[b].Spending
+	})
+
+	return totals, nil
+}
+
+// SetSplits replaces a transaction's split allocations. Splits must be positive,
+// categorized and add up to the parent amount; passing none removes the split.
+// Derived fields such as reward points are worked out again for the new allocations.
+func (s *TransactionService) SetSplits(ctx context.Context, userID, id primitive.ObjectID, splits []models.TransactionSplit) (*models.Transaction, error) {
+	txn, err := s.writableTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if txn.IsLocked() {
+		return nil, ErrTransactionLocked
+	}
+
+	if err := s.validateSplits(ctx, userID, txn, splits); err != nil {
+		return nil, err
+	}
+
+	txn.Splits = splits
+	if err := enrichTransaction(ctx, s.enrichers, txn); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateSplits(ctx, id, splits, txn.PointsRewarded)
+}
+
+// SetTags replaces a transaction's tags. Tags are trimmed, lowercased and deduplicated;
+// since they don't affect balances, reconciled transactions may still be retagged.
+func (s *TransactionService) SetTags(ctx context.Context, userID, id primitive.ObjectID, tags []string) (*models.Transaction, error) {
+	if _, err := s.writableTransaction(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateTags(ctx, id, tags)
+}
+
+func (s *TransactionService) readableAccounts(ctx context.Context, userID primitive.ObjectID, accountIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return readableAccountIDs(ctx, s.access, userID, accountIDs)
+}
+
+// readableAccountIDs checks userID may read every requested account, defaulting to all
+// the accounts they can read. An empty result must not reach the repository, where
+// no account filter means every account.
+func readableAccountIDs(ctx context.Context, access AccessPolicy, userID primitive.ObjectID, accountIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(accountIDs) == 0 {
+		return access.AccountIDs(ctx, userID)
+	}
+	for _, id := range accountIDs {
+		if err := access.CheckAccount(ctx, userID, id, AccessRead); err != nil {
+			return nil, err
+		}
+	}
+	return accountIDs, nil
+}
+
+// writableTransaction loads a transaction whose account userID may change
+func (s *TransactionService) writableTransaction(ctx context.Context, userID, id primitive.ObjectID) (*models.Transaction, error) {
+	txn, err := s.repo.GetTransactionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.CheckAccount(ctx, userID, txn.AccountID, AccessWrite); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// validateSplits checks the splits add up to the transaction and that userID may
+// write to every budget they are charged to
+func (s *TransactionService) validateSplits(ctx context.Context, userID primitive.ObjectID, txn *models.Transaction, splits []models.TransactionSplit) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, split := range splits {
+		if split.Amount <= 0 || split.Category == "" {
+			return ErrInvalidSplit
+		}
+		sum += float64(split.Amount)
+	}
+	if math.Abs(sum-float64(txn.Amount)) >= 0.005 {
+		return ErrInvalidSplit
+	}
+
+	checked := map[primitive.ObjectID]bool{}
+	for _, split := range splits {
+		if split.BudgetID.IsZero() || checked[split.BudgetID] {
+			continue
+		}
+		checked[split.BudgetID] = true
+		budget, err := s.budgets.GetBudgetByID(ctx, split.BudgetID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrForbidden
+			}
+			return err
+		}
+		if err := s.access.CheckBudget(ctx, userID, budget, AccessWrite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeTags trims, lowercases, sorts and deduplicates tags, dropping empty ones
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTags
+		}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > maxTags {
+		return nil, ErrInvalidTags
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+func validStatus(status string) bool {
+	switch status {
+	case "", models.TransactionStatusPending, models.TransactionStatusPosted, models.TransactionStatusVoid:
+		return true
+	}
+	return false
+}
+
+// matchPending finds the pending authorization a posted transaction settles, preferring the closest amount.
+// It returns -1 when nothing matches.
+func matchPending(pending []models.Transaction, posted *models.Transaction) int {
+	best, bestGap := -1, math.MaxFloat64
+	for i := range pending {
+		p := &pending[i]
+		if p.Type != posted.Type || !sameMerchant(p.Name, posted.Name) {
+			continue
+		}
+		if posted.TransactionDate.Before(p.TransactionDate.Add(-24*time.Hour)) ||
+			posted.TransactionDate.After(p.TransactionDate.Add(pendingMatchWindow)) {
+			continue
+		}
+
+		authorized, settled := float64(p.Amount), float64(posted.Amount)
+		if settled < authorized-0.005 || settled > authorized*(1+pendingTipAllowance)+0.005 {
+			continue
+		}
+		if gap := settled - authorized; gap < bestGap {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+// sameMerchant compares merchant names loosely, since banks often append store numbers
+// or locations to the posted description
+func sameMerchant(a, b string) bool {
+	a, b = normalizeMerchant(a), normalizeMerchant(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasPrefix(b, a) && (len(a) >= 4 || a == b)
+}
+
+// normalizeMerchant is the one merchant key: the name's letters, lowercased, so spacing,
+// punctuation and store numbers don't tell merchants apart. Merchant reports group on the
+// same key in MongoDB.
+func normalizeMerchant(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// DefaultMatchWindowDays is how far apart the two legs of a transfer may post
+	DefaultMatchWindowDays = 5
+	// MatchLookbackDays is how far back FindMatches looks for outflows to pair
+	MatchLookbackDays = 90
+)
+
+var (
+	ErrMatchNotFound   = errors.New("Error: Transfer Match Not Found")
+	ErrMatchResolved   = errors.New("Error: Transfer Match Already Resolved")
+	ErrAlreadyTransfer = errors.New("Error: Transaction Already Linked To A Transfer")
+)
+
+type TransferMatchService struct {
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	matches      repository.TransferMatchRepository
+	tx           repository.TxRunner
+}
+
+func NewTransferMatchService(accounts repository.AccountRepository, transactions repository.TransactionRepository, matches repository.TransferMatchRepository, tx repository.TxRunner) *TransferMatchService {
+	return &TransferMatchService{accounts: accounts, transactions: transactions, matches: matches, tx: tx}
+}
+
+// FindMatches scans the user's recent unlinked transactions for opposite-sign pairs of equal
+// amount on different accounts within windowDays of each other, and stores new proposals.
+// Only transactions from the last MatchLookbackDays, widened by the window, are loaded.
+func (s *TransferMatchService) FindMatches(ctx context.Context, userID primitive.ObjectID, windowDays int) ([]models.TransferMatch, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultMatchWindowDays
+	}
+
+	accountIDs, err := userAccountIDs(ctx, s.accounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(accountIDs) < 2 {
+		return []models.TransferMatch{}, nil
+	}
+
+	now := time.Now().UTC()
+	txns, err := s.transactions.ListTransactions(ctx, repository.TransactionFilter{
+		AccountIDs:       accountIDs,
+		Start:            now.AddDate(0, 0, -(MatchLookbackDays + windowDays)),
+		ExcludeTransfers: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	proposals := []models.TransferMatch{}
+	used := map[primitive.ObjectID]bool{}
+	for _, candidate := range rankCandidates(txns, windowDays) {
+		if used[candidate.OutflowID] || used[candidate.InflowID] {
+			continue
+		}
+
+		exists, err := s.matches.MatchExists(ctx, candidate.OutflowID, candidate.InflowID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		candidate.UserID = userID
+		candidate.Status = models.TransferMatchPending
+		candidate.CreatedAt = now
+		if err := s.matches.CreateMatch(ctx, &candidate); err != nil {
+			return nil, err
+		}
+
+		used[candidate.OutflowID] = true
+		used[candidate.InflowID] = true
+		proposals = append(proposals, candidate)
+	}
+
+	return proposals, nil
+}
+
+func (s *TransferMatchService) ListMatches(ctx context.Context, userID primitive.ObjectID, status string) ([]models.TransferMatch, error) {
+	return s.matches.ListMatchesByUser(ctx, userID, status)
+}
+
+// AcceptMatch links both transactions under a new transfer ID so reports stop counting them
+func (s *TransferMatchService) AcceptMatch(ctx context.Context, userID, matchID primitive.ObjectID) (*models.TransferMatch, error) {
+	match, err := s.pendingMatch(ctx, userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	transferID := primitive.NewObjectID()
+	txnIDs := []primitive.ObjectID{match.OutflowID, match.InflowID}
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.transactions.LinkTransfer(ctx, txnIDs, transferID); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrAlreadyTransfer
+			}
+			return err
+		}
+		if err := s.matches.ResolveMatch(ctx, match.ID, models.TransferMatchAccepted, transferID); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrMatchResolved
+			}
+			return err
+		}
+		return s.matches.RejectPendingForTransactions(ctx, txnIDs, match.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	match.Status = models.TransferMatchAccepted
+	match.TransferID = transferID
+	match.ResolvedAt = time.Now().UTC()
+	return match, nil
+}
+
+// RejectMatch keeps both transactions as ordinary income and spending
+func (s *TransferMatchService) RejectMatch(ctx context.Context, userID, matchID primitive.ObjectID) (*models.TransferMatch, error) {
+	match, err := s.pendingMatch(ctx, userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.matches.ResolveMatch(ctx, match.ID, models.TransferMatchRejected, primitive.NilObjectID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMatchResolved
+		}
+		return nil, err
+	}
+
+	match.Status = models.TransferMatchRejected
+	match.ResolvedAt = time.Now().UTC()
+	return match, nil
+}
+
+func (s *TransferMatchService) pendingMatch(ctx context.Context, userID, matchID primitive.ObjectID) (*models.TransferMatch, error) {
+	match, err := s.matches.GetMatchByID(ctx, matchID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, err
+	}
+	if match.UserID != userID {
+		return nil, ErrMatchNotFound
+	}
+	if match.Status != models.TransferMatchPending {
+		return nil, ErrMatchResolved
+	}
+	return match, nil
+}
+
+// rankCandidates returns every plausible outflow/inflow pairing, most confident first.
+// Inflows are grouped by amount so each outflow is only compared with equal amounts.
+func rankCandidates(txns []models.Transaction, windowDays int) []models.TransferMatch {
+	var candidates []models.TransferMatch
+	window := time.Duration(windowDays) * 24 * time.Hour
+
+	inflows := map[int64][]*models.Transaction{}
+	for i := range txns {
+		if in := &txns[i]; in.SignedAmount() > 0 {
+			inflows[amountCents(in)] = append(inflows[amountCents(in)], in)
+		}
+	}
+
+	for i := range txns {
+		out := &txns[i]
+		if out.SignedAmount() >= 0 {
+			continue
+		}
+		for _, in := range inflows[amountCents(out)] {
+			if in.AccountID == out.AccountID {
+				continue
+			}
+
+			gap := in.TransactionDate.Sub(out.TransactionDate)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > window {
+				continue
+			}
+
+			daysApart := int(gap.Hours() / 24)
+			candidates = append(candidates, models.TransferMatch{
+				OutflowID:  out.ID,
+				InflowID:   in.ID,
+				Amount:     out.Amount,
+				DaysApart:  daysApart,
+				Confidence: matchConfidence(out, in, daysApart, windowDays),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Confidence > candidates[b].Confidence
+	})
+	return candidates
+}
+
+// amountCents is the transaction's unsigned amount in whole cents
+func amountCents(txn *models.Transaction) int64 {
+	return int64(math.Round(math.Abs(txn.SignedAmount()) * 100))
+}
+
+// matchConfidence scores a pair between 0 and 1: equal amounts are the baseline,
+// posting close together and transfer-like wording raise the score
+func matchConfidence(out, in *models.Transaction, daysApart, windowDays int) float64 {
+	confidence := 0.6 + 0.3*(1-float64(daysApart)/float64(windowDays+1))
+	if looksLikeTransfer(out) || looksLikeTransfer(in) {
+		confidence += 0.1
+	}
+	return math.Round(confidence*100) / 100
+}
+
+func looksLikeTransfer(txn *models.Transaction) bool {
+	text := strings.ToLower(txn.Name + " " + txn.Description + " " + txn.Category)
+	for _, word := range []string{"transfer", "xfer", "tfr"} {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrAccountNotFound  = errors.New("Error: Account Not Found")
+	ErrTransferNotFound = errors.New("Error: Transfer Not Found")
+	ErrInvalidTransfer  = errors.New("Error: Invalid Transfer")
+)
+
+// TransferRequest describes money moving from one account to another
+type TransferRequest struct {
+	FromAccountID primitive.ObjectID
+	ToAccountID   primitive.ObjectID
+	Amount        float32
+	Date          time.Time
+	Description   string
+}
+
+type TransferService struct {
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+	tx           repository.TxRunner
+	access       AccessPolicy
+	events       *EventBus
+}
+
+func NewTransferService(accounts repository.AccountRepository, transactions repository.TransactionRepository, tx repository.TxRunner, access AccessPolicy) *TransferService {
+	return &TransferService{accounts: accounts, transactions: transactions, tx: tx, access: access}
+}
+
+// SetEventBus publishes transaction.created and balance.low events on bus
+func (s *TransferService) SetEventBus(bus *EventBus) {
+	s.events = bus
+}
+
+// CreateTransfer writes the debit and credit legs and moves both balances in one
+// transaction. userID needs write access to both accounts.
+func (s *TransferService) CreateTransfer(ctx context.Context, userID primitive.ObjectID, req TransferRequest) (*models.Transfer, error) {
+	if req.Amount <= 0 || req.FromAccountID.IsZero() || req.ToAccountID.IsZero() || req.FromAccountID == req.ToAccountID {
+		return nil, ErrInvalidTransfer
+	}
+	for _, id := range []primitive.ObjectID{req.FromAccountID, req.ToAccountID} {
+		if err := s.access.CheckAccount(ctx, userID, id, AccessWrite); err != nil {
+			return nil, err
+		}
+	}
+	if req.Date.IsZero() {
+		req.Date = time.Now().UTC()
+	}
+
+	from, err := s.getAccount(ctx, req.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getAccount(ctx, req.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		ID:            primitive.NewObjectID(),
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        req.Amount,
+		TransferDate:  req.Date,
+	}
+	transfer.Debit = transferLeg(transfer, from, models.TransactionTypeDebit, "Transfer to "+to.AccountLabel, req.Description)
+	transfer.Credit = transferLeg(transfer, to, models.TransactionTypeCredit, "Transfer from "+from.AccountLabel, req.Description)
+	transfer.Debit.CreatedBy = userID
+	transfer.Credit.CreatedBy = userID
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.transactions.CreateTransaction(ctx, transfer.Debit); err != nil {
+			return err
+		}
+		if err := s.transactions.CreateTransaction(ctx, transfer.Credit); err != nil {
+			return err
+		}
+		if err := s.accounts.AdjustBalance(ctx, from.ID, -float64(req.Amount)); err != nil {
+			return err
+		}
+		return s.accounts.AdjustBalance(ctx, to.ID, float64(req.Amount))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, userID, models.EventTransactionCreated, *transfer.Debit)
+	s.events.Publish(ctx, userID, models.EventTransactionCreated, *transfer.Credit)
+	publishLowBalance(ctx, s.events, userID, from, roundCents(from.AvailableBalance-float64(req.Amount)))
+
+	return transfer, nil
+}
+
+// GetTransfer rebuilds a transfer from its two linked transactions. userID needs
+// read access to at least one side.
+func (s *TransferService) GetTransfer(ctx context.Context, userID, id primitive.ObjectID) (*models.Transfer, error) {
+	legs, err := s.transactions.GetTransactionsByTransferID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{ID: id}
+	for i := range legs {
+		leg := &legs[i]
+		switch leg.Type {
+		case models.TransactionTypeDebit:
+			transfer.Debit = leg
+			transfer.FromAccountID = leg.AccountID
+		case models.TransactionTypeCredit:
+			transfer.Credit = leg
+			transfer.ToAccountID = leg.AccountID
+		}
+		transfer.Amount = leg.Amount
+		transfer.TransferDate = leg.TransactionDate
+	}
+
+	if transfer.Debit == nil || transfer.Credit == nil {
+		return nil, ErrTransferNotFound
+	}
+	if err := s.access.CheckAccount(ctx, userID, transfer.FromAccountID, AccessRead); err != nil {
+		if !errors.Is(err, ErrForbidden) {
+			return nil, err
+		}
+		if err := s.access.CheckAccount(ctx, userID, transfer.ToAccountID, AccessRead); err != nil {
+			return nil, err
+		}
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) getAccount(ctx context.Context, id primitive.ObjectID) (*models.Account, error) {
+	account, err := s.accounts.GetAccountByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func transferLeg(transfer *models.Transfer, account *models.Account, txnType string, name string, description string) *models.Transaction {
+	return &models.Transaction{
+		ID:                primitive.NewObjectID(),
+		Name:              name,
+		AccountID:         account.ID,
+		AccountNumber:     account.MaskedAccountNumber(),
+		Category:          "Transfer",
+		Type:              txnType,
+		Status:            models.TransactionStatusPosted,
+		TransferID:        transfer.ID,
+		Amount:            transfer.Amount,
+		TransactionDate:   transfer.TransferDate,
+		TransactionPosted: transfer.TransferDate,
+		Description:       description,
+	}
+}
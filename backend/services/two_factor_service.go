@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TOTP parameters, the defaults every authenticator app supports
+const (
+	TOTPIssuer  = "TrackMe"
+	TOTPPeriod  = 30
+	TOTPDigits  = 6
+	totpModulus = 1_000_000
+	totpSkew    = 1
+	totpKeySize = 20
+	// TOTPMaxAttempts codes may be tried in a row before checks lock for TOTPLockout
+	TOTPMaxAttempts = 5
+	TOTPLockout     = 15 * time.Minute
+)
+
+const (
+	RecoveryCodeCount   = 10
+	TwoFactorSessionTTL = 12 * time.Hour
+	StepUpWindow        = 5 * time.Minute
+)
+
+var (
+	ErrTOTPNotEnrolled    = errors.New("Error: Two-Factor Authentication Is Not Set Up")
+	ErrTOTPAlreadyEnabled = errors.New("Error: Two-Factor Authentication Is Already Enabled")
+	ErrInvalidTOTPCode    = errors.New("Error: Invalid Or Already Used Two-Factor Code")
+	ErrTwoFactorRequired  = errors.New("Error: Two-Factor Verification Required")
+	ErrStepUpRequired     = errors.New("Error: Re-Enter A Two-Factor Code To Continue")
+	ErrTOTPLocked         = errors.New("Error: Too Many Two-Factor Attempts, Try Again Later")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPEnrollment is a new TOTP secret, to be added to an authenticator app by scanning
+// ProvisioningURI as a QR code or typing in Secret
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// IssuedSession is a new two-factor session with its token, which is only ever shown once
+type IssuedSession struct {
+	Session      models.TwoFactorSession `json:"session"`
+	SessionToken string                  `json:"session_token"`
+}
+
+// TwoFactorActivation is returned once when TOTP is confirmed; the recovery codes are
+// never shown again
+type TwoFactorActivation struct {
+	RecoveryCodes []string       `json:"recovery_codes"`
+	Session       *IssuedSession `json:"session"`
+}
+
+// TwoFactorService enrolls users in TOTP (RFC 6238) and issues the sessions that prove
+// they passed a TOTP check. TOTP secrets are encrypted with the field cipher and
+// recovery codes are stored hashed.
+type TwoFactorService struct {
+	users    repository.UserRepository
+	sessions repository.TwoFactorRepository
+	cipher   *FieldCipher
+}
+
+func NewTwoFactorService(users repository.UserRepository, sessions repository.TwoFactorRepository, cipher *FieldCipher) *TwoFactorService {
+	return &TwoFactorService{users: users, sessions: sessions, cipher: cipher}
+}
+
+// Enroll starts TOTP enrollment with a fresh secret, replacing any unconfirmed one.
+// Two-factor authentication is only enforced once Confirm succeeds.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID primitive.ObjectID) (*TOTPEnrollment, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret := make([]byte, totpKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encoded := totpEncoding.EncodeToString(secret)
+	sealed, err := s.cipher.Encrypt(ctx, encoded, totpBinding(userID))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SaveTOTP(ctx, userID, sealed, false, nil); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{Secret: encoded, ProvisioningURI: provisioningURI(user, encoded)}, nil
+}
+
+// Confirm turns on two-factor authentication once the user proves their app produces
+// codes, returning recovery codes and a first session for their sign-in session
+func (s *TwoFactorService) Confirm(ctx context.Context, userID primitive.ObjectID, loginSessionID, code string) (*TwoFactorActivation, error) {
+	if loginSessionID == "" {
+		return nil, ErrInvalidSession
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if err := s.checkCode(ctx, user, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SaveTOTP(ctx, userID, user.TOTPSecret, true, hashes); err != nil {
+		return nil, err
+	}
+	session, err := s.issueSession(ctx, userID, loginSessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorActivation{RecoveryCodes: codes, Session: session}, nil
+}
+
+// Verify completes the sign-in session loginSessionID of a user with two-factor
+// authentication, taking either a TOTP code or one of their recovery codes
+func (s *TwoFactorService) Verify(ctx context.Context, userID primitive.ObjectID, loginSessionID, code, recoveryCode string) (*IssuedSession, error) {
+	if loginSessionID == "" {
+		return nil, ErrInvalidSession
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if err := s.checkCode(ctx, user, code, recoveryCode); err != nil {
+		return nil, err
+	}
+	return s.issueSession(ctx, userID, loginSessionID)
+}
+
+// StepUp re-checks a code within a session, opening StepUpWindow for sensitive operations
+func (s *TwoFactorService) StepUp(ctx context.Context, userID primitive.ObjectID, loginSessionID, sessionToken, code, recoveryCode string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnrolled
+	}
+	session, err := s.session(ctx, userID, loginSessionID, sessionToken)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCode(ctx, user, code, recoveryCode); err != nil {
+		return err
+	}
+	return s.sessions.StepUp(ctx, session.ID, time.Now().UTC())
+}
+
+// Disable removes two-factor authentication and ends every session of the user
+func (s *TwoFactorService) Disable(ctx context.Context, userID primitive.ObjectID) error {
+	if err := s.users.SaveTOTP(ctx, userID, nil, false, nil); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.sessions.DeleteUserSessions(ctx, userID)
+}
+
+// CheckSession resolves the two-factor session a request presents alongside its sign-in
+// session. It returns nil for users without two-factor authentication and
+// ErrTwoFactorRequired when one is needed.
+func (s *TwoFactorService) CheckSession(ctx context.Context, userID primitive.ObjectID, loginSessionID, sessionToken string) (*models.TwoFactorSession, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, nil
+	}
+	return s.session(ctx, userID, loginSessionID, sessionToken)
+}
+
+// session looks up a two-factor session, which only counts with the sign-in session it
+// was verified for
+func (s *TwoFactorService) session(ctx context.Context, userID primitive.ObjectID, loginSessionID, sessionToken string) (*models.TwoFactorSession, error) {
+	if loginSessionID == "" || !strings.HasPrefix(sessionToken, models.TwoFactorSessionPrefix) {
+		return nil, ErrTwoFactorRequired
+	}
+	session, err := s.sessions.GetSessionByHash(ctx, hashToken(sessionToken))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTwoFactorRequired
+		}
+		return nil, err
+	}
+	if session.UserID != userID || session.LoginSessionID != loginSessionID || !session.Usable(time.Now().UTC()) {
+		return nil, ErrTwoFactorRequired
+	}
+	return session, nil
+}
+
+func (s *TwoFactorService) issueSession(ctx context.Context, userID primitive.ObjectID, loginSessionID string) (*IssuedSession, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := models.TwoFactorSessionPrefix + hex.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	session := models.TwoFactorSession{
+		UserID:         userID,
+		LoginSessionID: loginSessionID,
+		TokenHash:      hashToken(secret),
+		ExpiresAt:      now.Add(TwoFactorSessionTTL),
+		StepUpAt:       now,
+		CreatedAt:      now,
+	}
+	if err := s.sessions.CreateSession(ctx, &session); err != nil {
+		return nil, err
+	}
+	return &IssuedSession{Session: session, SessionToken: secret}, nil
+}
+
+// checkCode accepts a TOTP code not used before, or else an unused recovery code,
+// spending either so it cannot be replayed. Every check counts as an attempt before the
+// code is looked at, so concurrent guesses can't get past TOTPMaxAttempts; an accepted
+// code resets the count.
+func (s *TwoFactorService) checkCode(ctx context.Context, user *models.User, code, recoveryCode string) error {
+	now := time.Now().UTC()
+	attempts, err := s.users.RecordTOTPAttempt(ctx, user.ID, now)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTOTPLocked
+		}
+		return err
+	}
+	if attempts > TOTPMaxAttempts {
+		if err := s.users.LockTOTP(ctx, user.ID, now.Add(TOTPLockout)); err != nil {
+			return err
+		}
+		return ErrTOTPLocked
+	}
+
+	if code = strings.TrimSpace(code); code != "" {
+		encoded, err := s.cipher.Decrypt(ctx, user.TOTPSecret, totpBinding(user.ID))
+		if err != nil {
+			return err
+		}
+		secret, err := totpEncoding.DecodeString(encoded)
+		if err != nil {
+			return err
+		}
+		step, ok := MatchTOTP(secret, code, now)
+		if !ok {
+			return ErrInvalidTOTPCode
+		}
+		return s.spend(s.users.UseTOTPStep(ctx, user.ID, step))
+	}
+
+	if recoveryCode = normalizeRecoveryCode(recoveryCode); recoveryCode != "" {
+		return s.spend(s.users.UseRecoveryCode(ctx, user.ID, hashToken(recoveryCode)))
+	}
+	return ErrInvalidTOTPCode
+}
+
+// spend maps a failed single-use update to ErrInvalidTOTPCode
+func (s *TwoFactorService) spend(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidTOTPCode
+	}
+	return err
+}
+
+// RotateSecrets re-encrypts TOTP secrets sealed under a retired master key
+func (s *TwoFactorService) RotateSecrets(ctx context.Context, result *KeyRotationResult) error {
+	users, err := s.users.ListStaleTOTP(ctx, s.cipher.keys.ActiveKeyID())
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		result.record("user", users[i].ID, s.resealTOTP(ctx, &users[i]))
+	}
+	return nil
+}
+
+func (s *TwoFactorService) resealTOTP(ctx context.Context, user *models.User) error {
+	sealed, err := s.cipher.Reseal(ctx, user.TOTPSecret, totpBinding(user.ID))
+	if err != nil || sealed == nil {
+		return err
+	}
+	return s.users.ResealTOTP(ctx, user.ID, user.TOTPSecret, sealed)
+}
+
+func (s *TwoFactorService) getUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.users.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// TOTPCode computes the RFC 6238 code of secret for a time step, using HMAC-SHA1
+func TOTPCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%totpModulus)
+}
+
+// MatchTOTP checks code against the time step of now and its neighbours, allowing for
+// clock drift, and returns the step it matched
+func MatchTOTP(secret []byte, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / TOTPPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(TOTPCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// provisioningURI is the otpauth:// URI authenticator apps read from a QR code
+func provisioningURI(user *models.User, secret string) string {
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	if account == "" {
+		account = user.ID.Hex()
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+	return "otpauth://totp/" + url.PathEscape(TOTPIssuer+":"+account) + "?" + query.Encode()
+}
+
+// newRecoveryCodes returns RecoveryCodeCount codes formatted like "abcde-fghij" and
+// their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func totpBinding(userID primitive.ObjectID) string {
+	return userID.Hex() + ":totp_secret"
+}
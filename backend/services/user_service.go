@@ -62,4 +62,4 @@ func (s *UserService)DeleteUserByID(ctx context.Context, id primitive.ObjectID)
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/samuriot/track-me/models"
+	"github.com/samuriot/track-me/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("Error: Webhook Not Found")
+	ErrInvalidWebhook  = errors.New("Error: Webhook Needs An http(s) URL And Known Events")
+	ErrPrivateWebhook  = errors.New("Error: Webhook URL Resolves To A Private Address")
+)
+
+const (
+	// WebhookMaxAttempts is how many times a delivery is sent before it fails for good
+	WebhookMaxAttempts = 8
+	// webhookRetryBase is the delay before the first retry; each retry doubles it
+	webhookRetryBase = 30 * time.Second
+	webhookRetryMax  = 6 * time.Hour
+	// webhookTimeout bounds how long a receiver may take to answer
+	webhookTimeout = 10 * time.Second
+	// webhookLease is how long a worker holds a claimed delivery
+	webhookLease = time.Minute
+	// webhookBatch caps the deliveries one run sends, leaving the rest for the next run
+	webhookBatch = 500
+	// webhookDeliveryLimit is how many deliveries the log returns by default and at most
+	webhookDeliveryLimit = 100
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookSignatureHeader = "X-TrackMe-Signature"
+	WebhookEventHeader     = "X-TrackMe-Event"
+	WebhookDeliveryHeader  = "X-TrackMe-Delivery"
+)
+
+// CreatedWebhook is a new endpoint and its signing secret, which is never shown again
+type CreatedWebhook struct {
+	Endpoint *models.WebhookEndpoint `json:"endpoint"`
+	Secret   string                  `json:"secret"`
+}
+
+// WebhookRunResult reports what one delivery run did
+type WebhookRunResult struct {
+	Delivered int `json:"delivered"`
+	Retrying  int `json:"retrying"`
+	Failed    int `json:"failed"`
+}
+
+// WebhookService sends a user's events to the endpoints they registered. Events are
+// queued as deliveries when published and sent by a background job, which retries
+// failures with exponential backoff. Each request is signed with HMAC-SHA256 so
+// receivers can check it came from us.
+type WebhookService struct {
+	repo   repository.WebhookRepository
+	cipher *FieldCipher
+	client *http.Client
+}
+
+// NewWebhookService creates a webhook service sending with client, or with
+// NewWebhookClient(false) when client is nil
+func NewWebhookService(repo repository.WebhookRepository, cipher *FieldCipher, client *http.Client) *WebhookService {
+	if client == nil {
+		client = NewWebhookClient(false)
+	}
+	return &WebhookService{repo: repo, cipher: cipher, client: client}
+}
+
+// NewWebhookClient returns a client with a short timeout that refuses to connect to
+// loopback, private and link-local addresses. The check runs on the address each
+// connection actually dials, after DNS and on every redirect, so a public name can't be
+// pointed at internal services. allowPrivate lifts it for development and tests.
+func NewWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !publicAddress(ip) {
+				return ErrPrivateWebhook
+			}
+			return nil
+		}
+	}
+
+	// no proxy: the check has to see the receiver's address, not the proxy's
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+// CreateEndpoint registers a URL for the given event types and returns its signing secret
+func (s *WebhookService) CreateEndpoint(ctx context.Context, userID primitive.ObjectID, rawURL, description string, events []string) (*CreatedWebhook, error) {
+	if !validWebhookURL(rawURL) || len(events) == 0 {
+		return nil, ErrInvalidWebhook
+	}
+	for _, event := range events {
+		if !models.ValidEventType(event) {
+			return nil, ErrInvalidWebhook
+		}
+	}
+	events = slices.Clone(events)
+	slices.Sort(events)
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, err
+	}
+	secret := models.WebhookSecretPrefix + hex.EncodeToString(secretBytes)
+
+	endpoint := &models.WebhookEndpoint{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		URL:         rawURL,
+		Description: strings.TrimSpace(description),
+		Events:      slices.Compact(events),
+		CreatedAt:   time.Now().UTC(),
+	}
+	sealed, err := s.cipher.Encrypt(ctx, secret, webhookSecretBinding(endpoint.ID))
+	if err != nil {
+		return nil, err
+	}
+	endpoint.SecretSealed = sealed
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return &CreatedWebhook{Endpoint: endpoint, Secret: secret}, nil
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context, userID primitive.ObjectID) ([]models.WebhookEndpoint, error) {
+	return s.repo.ListEndpoints(ctx, userID)
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.repo.DeleteEndpoint(ctx, id, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Deliveries returns an endpoint's delivery log, newest first
+func (s *WebhookService) Deliveries(ctx context.Context, userID, id primitive.ObjectID, limit int64) ([]models.WebhookDelivery, error) {
+	if _, err := s.getEndpoint(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > webhookDeliveryLimit {
+		limit = webhookDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, id, limit)
+}
+
+// HandleEvent queues the event for every endpoint of its user subscribed to it
+func (s *WebhookService) HandleEvent(ctx context.Context, event models.Event) error {
+	endpoints, err := s.repo.ListSubscribed(ctx, event.UserID, event.Type)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	for i := range endpoints {
+		delivery, err := newDelivery(&endpoints[i], event)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestFire sends a webhook.test event to an endpoint right away and returns the logged
+// delivery. Test deliveries are not retried.
+func (s *WebhookService) TestFire(ctx context.Context, userID, id primitive.ObjectID) (*models.WebhookDelivery, error) {
+	endpoint, err := s.getEndpoint(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.Event{
+		ID:        primitive.NewObjectID(),
+		Type:      models.EventWebhookTest,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]string{"message": "This is a test event from TrackMe"},
+	}
+	delivery, err := newDelivery(endpoint, event)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.attempt(ctx, endpoint, delivery, time.Now().UTC(), false)
+	if err := s.repo.SaveAttempt(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue sends every delivery whose next attempt is due
+func (s *WebhookService) DeliverDue(ctx context.Context, now time.Time) (*WebhookRunResult, error) {
+	result := &WebhookRunResult{}
+	for range webhookBatch {
+		delivery, err := s.repo.ClaimDelivery(ctx, now, now.Add(webhookLease))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		endpoint, err := s.repo.GetEndpointByID(ctx, delivery.EndpointID)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			delivery.Status, delivery.LastError = models.DeliveryFailed, "endpoint was deleted"
+		case err != nil:
+			return result, err
+		default:
+			s.attempt(ctx, endpoint, delivery, now, true)
+		}
+		if err := s.repo.SaveAttempt(ctx, delivery); err != nil {
+			log.Printf("webhook delivery %s could not be saved: %v", delivery.ID.Hex(), err)
+		}
+
+		switch delivery.Status {
+		case models.DeliveryDelivered:
+			result.Delivered++
+		case models.DeliveryFailed:
+			result.Failed++
+		default:
+			result.Retrying++
+		}
+	}
+	return result, nil
+}
+
+// attempt sends a delivery once and updates its status, scheduling a retry when retry
+// is set and attempts remain
+func (s *WebhookService) attempt(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, now time.Time, retry bool) {
+	delivery.Attempts++
+	status, err := s.send(ctx, endpoint, delivery, now)
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		delivery.Status, delivery.LastError = models.DeliveryDelivered, ""
+		delivery.DeliveredAt, delivery.NextAttemptAt = time.Now().UTC(), time.Time{}
+		return
+	}
+	delivery.LastError = err.Error()
+	if retry && delivery.Attempts < WebhookMaxAttempts {
+		delivery.NextAttemptAt = now.Add(WebhookBackoff(delivery.Attempts))
+		return
+	}
+	delivery.Status, delivery.NextAttemptAt = models.DeliveryFailed, time.Time{}
+}
+
+// send posts the delivery's payload, returning the receiver's status code
+func (s *WebhookService) send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	secret, err := s.cipher.Decrypt(ctx, endpoint.SecretSealed, webhookSecretBinding(endpoint.ID))
+	if err != nil {
+		return 0, err
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TrackMe-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, now.Unix(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the signature header for a body sent at timestamp: the Unix time
+// and the hex HMAC-SHA256 of "timestamp.body". Receivers recompute it with their secret
+// and should refuse old timestamps to stop replays.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookBackoff is how long to wait after a delivery's nth failed attempt
+func WebhookBackoff(attempt int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempt && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMax)
+}
+
+// RotateSecrets re-encrypts signing secrets sealed under a retired master key
+func (s *WebhookService) RotateSecrets(ctx context.Context, result *KeyRotationResult) error {
+	endpoints, err := s.repo.ListStaleSecrets(ctx, s.cipher.keys.ActiveKeyID())
+	if err != nil {
+		return err
+	}
+	for i := range endpoints {
+		result.record("webhook", endpoints[i].ID, s.resealSecret(ctx, &endpoints[i]))
+	}
+	return nil
+}
+
+func (s *WebhookService) resealSecret(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	sealed, err := s.cipher.Reseal(ctx, endpoint.SecretSealed, webhookSecretBinding(endpoint.ID))
+	if err != nil || sealed == nil {
+		return err
+	}
+	return s.repo.ResealSecret(ctx, endpoint.ID, endpoint.SecretSealed, sealed)
+}
+
+func (s *WebhookService) getEndpoint(ctx context.Context, userID, id primitive.ObjectID) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpointByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	if endpoint.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return endpoint, nil
+}
+
+func newDelivery(endpoint *models.WebhookEndpoint, event models.Event) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookDelivery{
+		ID:            primitive.NewObjectID(),
+		EndpointID:    endpoint.ID,
+		UserID:        endpoint.UserID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       string(payload),
+		Status:        models.DeliveryPending,
+		NextAttemptAt: event.CreatedAt,
+		CreatedAt:     event.CreatedAt,
+	}, nil
+}
+
+func validWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// publicAddress reports whether ip may receive webhooks
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+func webhookSecretBinding(endpointID primitive.ObjectID) string {
+	return endpointID.Hex() + ":webhook_secret"
+}